@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"nfip-community-book/data"
+	"nfip-community-book/states"
+	"nfip-community-book/store"
+)
+
+// The exit codes, which are the same for every command, so that scripts
+// can tell what went wrong without reading the message.
+const (
+	exitOK = 0
+
+	// Something went wrong, like the status book couldn't be downloaded
+	exitError = 1
+
+	// The command was run with flags or arguments that it doesn't take
+	exitUsage = 2
+
+	// There's no community with the CID, or saved query with the name
+	exitNotFound = 3
+
+	// The communities didn't pass validate or refresh's rules, or diff
+	// found changes with --exit-code
+	exitInvalid = 4
+)
+
+// A commandError is an error that the command exits with a code other than
+// exitError for.
+type commandError struct {
+	code int
+	err  error
+}
+
+func (e commandError) Error() string {
+	return e.err.Error()
+}
+
+func (e commandError) Unwrap() error {
+	return e.err
+}
+
+func usageError(err error) error {
+	return commandError{exitUsage, err}
+}
+
+func notFound(format string, args ...any) error {
+	return commandError{exitNotFound, fmt.Errorf(format, args...)}
+}
+
+// exitCode is the code that the command exits with for the error.
+func exitCode(err error) int {
+	var cmdErr commandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.code
+	}
+	if err != nil {
+		return exitError
+	}
+
+	return exitOK
+}
+
+// checkArgs checks a command's arguments with one of cobra's checks, so
+// that the wrong number of them is a usage error.
+func checkArgs(check cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := check(cmd, args); err != nil {
+			return usageError(err)
+		}
+		return nil
+	}
+}
+
+// A cli is what every command shares: the log and the flags on the root
+// command.
+type cli struct {
+	l *log.Logger
+
+	// The sample communities that are built into the binary are used
+	// rather than the status book
+	demo bool
+
+	// The format that what's found is written in, which is picked by
+	// whether stdout is a terminal if it's blank
+	output string
+}
+
+// newRootCommand is the nfip command and every subcommand. Running it
+// without a subcommand serves the API, the same as serve.
+func newRootCommand(l *log.Logger) *cobra.Command {
+	c := &cli{l: l}
+
+	root := &cobra.Command{
+		Use:   "nfip",
+		Short: "Look up and search the communities in FEMA's NFIP Community Status Book",
+		Long: `Look up and search the communities in FEMA's NFIP Community Status Book.
+
+The status book is downloaded to nation.csv the first time it's needed, or
+the communities are read from the store in NFIP_STORE if it's set. Any other
+command runs the nfip-<command> executable on the PATH.
+
+What the commands find is written as a table in a terminal, and as JSON
+when it's piped somewhere. --output picks the format instead.
+
+Exit codes:
+  0  ok
+  1  something went wrong
+  2  the flags or arguments were wrong
+  3  the community or saved query wasn't found
+  4  the communities didn't pass validate or refresh's rules, or diff found
+     changes with --exit-code`,
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.checkOutput()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.serve()
+		},
+	}
+
+	root.PersistentFlags().BoolVar(&c.demo, "demo", false, "use the sample communities that are built into the binary")
+	root.PersistentFlags().StringVarP(&c.output, "output", "o", "", "the format to write in, one of "+strings.Join(outputFormats, ", ")+", which is a table in a terminal and JSON otherwise")
+	root.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return usageError(err)
+	})
+
+	root.AddCommand(
+		c.serveCommand(),
+		c.demoCommand(),
+		c.searchCommand(),
+		c.getCommand(),
+		c.exportCommand(),
+		c.refreshCommand(),
+		c.statsCommand(),
+		c.diffCommand(),
+		c.validateCommand(),
+		c.queryCommand(),
+		c.historyCommand(),
+		c.indexCommand(),
+		c.schemaCommand(),
+		c.tuiCommand(),
+	)
+
+	return root
+}
+
+// stderr is the log for the commands that write to stdout, so that the
+// logs don't end up in what's written.
+func (c *cli) stderr() *log.Logger {
+	return log.New(os.Stderr, c.l.Prefix(), c.l.Flags())
+}
+
+// communities are the communities that the commands look up, which are the
+// sample ones with --demo.
+func (c *cli) communities() (data.NFIPCommunityStatuses, error) {
+	if c.demo {
+		return data.GetDemoNFIPCommunityStatusBook()
+	}
+
+	return loadCommunities(c.stderr())
+}
+
+// communityStore is the store that the commands look communities up in,
+// which is the sample ones in memory with --demo. It has to be closed with
+// closeStore.
+func (c *cli) communityStore(ctx context.Context) (store.Store, error) {
+	if c.demo {
+		communities, err := data.GetDemoNFIPCommunityStatusBook()
+		if err != nil {
+			return nil, err
+		}
+		return store.NewMemory(communities), nil
+	}
+
+	return openCommunities(ctx, c.stderr())
+}
+
+// pluginArgs are the arguments for a plugin, starting with its name, if
+// they aren't for one of the root command's subcommands. The root's flags,
+// like --demo, can come before the name, and aren't passed to the plugin.
+// Cobra only adds help and completion when it runs, so they're added first.
+func pluginArgs(root *cobra.Command, args []string) ([]string, bool) {
+	args, ok := skipRootFlags(root, args)
+	if !ok || len(args) == 0 || strings.HasPrefix(args[0], cobra.ShellCompRequestCmd) {
+		return nil, false
+	}
+
+	root.InitDefaultHelpCmd()
+	root.InitDefaultCompletionCmd()
+	if _, _, err := root.Find(args); err == nil {
+		return nil, false
+	}
+	return args, true
+}
+
+// skipRootFlags is the arguments after the root command's flags, and
+// whether they were all flags that it has, so cobra can complain about
+// the ones that aren't.
+func skipRootFlags(root *cobra.Command, args []string) ([]string, bool) {
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		arg := args[0]
+		if arg == "--" {
+			return nil, false
+		}
+
+		var flag *pflag.Flag
+		attached := strings.Contains(arg, "=")
+		if name, ok := strings.CutPrefix(arg, "--"); ok {
+			name, _, _ = strings.Cut(name, "=")
+			flag = root.PersistentFlags().Lookup(name)
+		} else {
+			flag = root.PersistentFlags().ShorthandLookup(arg[1:2])
+			attached = attached || len(arg) > 2
+		}
+		if flag == nil {
+			return nil, false
+		}
+
+		args = args[1:]
+		if len(flag.NoOptDefVal) == 0 && !attached {
+			if len(args) == 0 {
+				return nil, false
+			}
+			args = args[1:]
+		}
+	}
+
+	return args, true
+}
+
+// writeJSON writes the value to stdout as indented JSON.
+func writeJSON(v any) error {
+	e := json.NewEncoder(os.Stdout)
+	e.SetIndent("", "  ")
+	return e.Encode(v)
+}
+
+// filterFlags narrow down the communities, and are the same for every
+// command that takes them.
+type filterFlags struct {
+	state string
+	query string
+}
+
+func (f *filterFlags) add(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.state, "state", "", "only the communities in the state, as an abbreviation, a name or a FIPS code")
+	cmd.Flags().StringVarP(&f.query, "query", "q", "", "only the communities that match the query, like 'county:harris AND crs'")
+}
+
+// filters are the filters for the flags that were given.
+func (f *filterFlags) filters() ([]data.Filter, error) {
+	var filters []data.Filter
+
+	if len(f.state) > 0 {
+		if _, ok := states.Lookup(f.state); !ok {
+			return nil, usageError(fmt.Errorf("unknown state %s", strconv.Quote(f.state)))
+		}
+		filters = append(filters, data.ByState(f.state))
+	}
+
+	if len(f.query) > 0 {
+		filter, err := data.ParseQuery(f.query)
+		if err != nil {
+			return nil, usageError(err)
+		}
+		filters = append(filters, filter)
+	}
+
+	return filters, nil
+}
+
+// apply is the communities that pass the filters.
+func (f *filterFlags) apply(communities data.NFIPCommunityStatuses) (data.NFIPCommunityStatuses, error) {
+	filters, err := f.filters()
+	if err != nil || len(filters) == 0 {
+		return communities, err
+	}
+
+	return *communities.Apply(filters...), nil
+}
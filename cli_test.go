@@ -0,0 +1,155 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// runCommand runs nfip with the arguments, and is what it wrote to stdout
+// and the code that it would have exited with. Stdout is a file, so what's
+// written is JSON unless --output says otherwise.
+func runCommand(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+
+	out, _, code := runCommandWithStderr(t, args...)
+	return out, code
+}
+
+// runCommandWithStderr is runCommand with what was written to stderr too.
+func runCommandWithStderr(t *testing.T, args ...string) (string, string, int) {
+	t.Helper()
+
+	dir := t.TempDir()
+	stdout, err := os.Create(dir + "/stdout")
+	if err != nil {
+		t.Fatalf("expected a file for stdout, got %s", err)
+	}
+	defer stdout.Close()
+
+	stderr, err := os.Create(dir + "/stderr")
+	if err != nil {
+		t.Fatalf("expected a file for stderr, got %s", err)
+	}
+	defer stderr.Close()
+
+	realStdout, realStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdout, stderr
+	defer func() {
+		os.Stdout, os.Stderr = realStdout, realStderr
+	}()
+
+	root := newRootCommand(log.New(io.Discard, "", 0))
+	root.SetArgs(args)
+	root.SetOut(io.Discard)
+	root.SetErr(io.Discard)
+	code := exitCode(root.Execute())
+
+	out, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatalf("expected to read stdout, got %s", err)
+	}
+
+	errOut, err := os.ReadFile(stderr.Name())
+	if err != nil {
+		t.Fatalf("expected to read stderr, got %s", err)
+	}
+	return string(out), string(errOut), code
+}
+
+// A commandTest is a command, the code it exits with, and what it writes.
+type commandTest struct {
+	name     string
+	args     []string
+	code     int
+	contains []string
+	excludes []string
+}
+
+func (tc commandTest) run(t *testing.T) {
+	t.Helper()
+
+	out, code := runCommand(t, tc.args...)
+	if code != tc.code {
+		t.Errorf("expected %v to exit with %d, got %d\n%s", tc.args, tc.code, code, out)
+	}
+
+	for _, s := range tc.contains {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected %v to write %q, got\n%s", tc.args, s, out)
+		}
+	}
+	for _, s := range tc.excludes {
+		if strings.Contains(out, s) {
+			t.Errorf("expected %v to not write %q, got\n%s", tc.args, s, out)
+		}
+	}
+}
+
+func TestRootCommand(t *testing.T) {
+	for _, tc := range []commandTest{
+		{name: "invalid output", args: []string{"--demo", "-o", "bogus", "stats"}, code: exitUsage},
+		{name: "output in any case", args: []string{"--demo", "-o", "CSV", "stats"}, code: exitOK, contains: []string{"BY,WHICH,COUNT\n"}},
+		{name: "unknown flag", args: []string{"--demo", "stats", "--bogus"}, code: exitUsage},
+		{name: "too many arguments", args: []string{"--demo", "stats", "extra"}, code: exitUsage},
+		{name: "help", args: []string{"--help"}, code: exitOK},
+	} {
+		t.Run(tc.name, tc.run)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		code int
+	}{
+		{nil, exitOK},
+		{io.EOF, exitError},
+		{usageError(io.EOF), exitUsage},
+		{notFound("no community with the CID %d", 1), exitNotFound},
+		{commandError{exitInvalid, io.EOF}, exitInvalid},
+	} {
+		if code := exitCode(tc.err); code != tc.code {
+			t.Errorf("expected %v to exit with %d, got %d", tc.err, tc.code, code)
+		}
+	}
+}
+
+func TestPluginArgs(t *testing.T) {
+	root := newRootCommand(log.New(io.Discard, "", 0))
+
+	for _, tc := range []struct {
+		args   []string
+		plugin []string
+	}{
+		{nil, nil},
+		{[]string{"--demo"}, nil},
+		{[]string{"search", "houston"}, nil},
+		{[]string{"--demo", "search", "houston"}, nil},
+		{[]string{"-o", "json", "search", "houston"}, nil},
+		{[]string{"help"}, nil},
+		{[]string{"completion", "bash"}, nil},
+		{[]string{"--bogus", "floodmap"}, nil},
+		{[]string{"--", "floodmap"}, nil},
+		{[]string{"-o"}, nil},
+		{[]string{"floodmap", "480296"}, []string{"floodmap", "480296"}},
+		{[]string{"--demo", "territory"}, []string{"territory"}},
+		{[]string{"-o", "json", "territory", "--region", "4"}, []string{"territory", "--region", "4"}},
+		{[]string{"--output=json", "territory"}, []string{"territory"}},
+		{[]string{"-ojson", "--demo", "territory"}, []string{"territory"}},
+	} {
+		if plugin, _ := pluginArgs(root, tc.args); !reflect.DeepEqual(plugin, tc.plugin) {
+			t.Errorf("expected %v to run the plugin with %v, got %v", tc.args, tc.plugin, plugin)
+		}
+	}
+}
+
+func TestRunPlugin(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	if code := runPlugin(log.New(io.Discard, "", 0), "floodmap", nil); code != exitUsage {
+		t.Errorf("expected a command without a plugin to exit with %d, got %d", exitUsage, code)
+	}
+}
@@ -0,0 +1,280 @@
+// Package client is a client for the REST API in the server package, so
+// that other services can look up communities from a server that's
+// running somewhere without writing the requests and decoding the JSON
+// themselves. The communities are the same as the data package's.
+//
+//	c := client.New("https://nfip.example.com", client.WithAPIKey(key))
+//	page, err := c.Search(ctx, "houston", client.State("TX"))
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"nfip-community-book/data"
+	"nfip-community-book/problem"
+)
+
+var (
+	ErrNotFound     = fmt.Errorf("not found")
+	ErrUnauthorized = fmt.Errorf("unauthorized")
+	ErrBadRequest   = fmt.Errorf("bad request")
+	ErrServer       = fmt.Errorf("the server couldn't answer")
+)
+
+// The version of the API that the client uses.
+const apiPrefix = "/v1"
+
+const userAgent = "nfip-community-book-client"
+
+// A Client makes requests to the API at its base URL.
+type Client struct {
+	baseURL string
+	http    *http.Client
+	apiKey  string
+}
+
+type Option func(*Client)
+
+// WithHTTPClient makes the requests with the client rather than
+// http.DefaultClient, for timeouts or a different transport.
+func WithHTTPClient(h *http.Client) Option {
+	return func(c *Client) {
+		c.http = h
+	}
+}
+
+// WithAPIKey sends the key with every request, for servers that need one.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// New is a client for the API at the base URL, like
+// https://nfip.example.com, without the /v1.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{baseURL: strings.TrimSuffix(baseURL, "/"), http: http.DefaultClient}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+type requestIDKey struct{}
+
+// WithRequestID sends the ID in X-Request-ID with the requests that are
+// made with the context, rather than the server making one up, so that
+// they can be found in its logs by the ID of the request they're for.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// A CommunitiesPage is one page of every community, and the link to the
+// next one if there is one.
+type CommunitiesPage struct {
+	APIVersion string `json:"api_version"`
+	data.StatusPage
+	Next string `json:"next,omitempty"`
+}
+
+// A SearchPage is one page of search results, and the link to the next
+// one if there is one.
+type SearchPage struct {
+	APIVersion string `json:"api_version"`
+	data.SearchPage
+	Next string `json:"next,omitempty"`
+}
+
+// A LookupResult is the communities that were found, in the order their
+// CIDs were given, and the CIDs that weren't.
+type LookupResult struct {
+	APIVersion  string                     `json:"api_version"`
+	Communities data.NFIPCommunityStatuses `json:"communities"`
+	Unknown     []int                      `json:"unknown"`
+}
+
+// Communities is a page of every community. Params page, sort and filter
+// them the same way the API's parameters do.
+func (c *Client) Communities(ctx context.Context, params ...Param) (*CommunitiesPage, error) {
+	var page CommunitiesPage
+	if err := c.getJSON(ctx, c.url("/communities", params), &page); err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+// Search is a page of the communities that match the term, best first.
+func (c *Client) Search(ctx context.Context, term string, params ...Param) (*SearchPage, error) {
+	var page SearchPage
+	if err := c.getJSON(ctx, c.url("/search", append(params, query(term))), &page); err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+// GetCommunity is the community with the CID. It's ErrNotFound if there
+// isn't one.
+func (c *Client) GetCommunity(ctx context.Context, cid int) (*data.NFIPCommunityStatus, error) {
+	var community data.NFIPCommunityStatus
+	if err := c.getJSON(ctx, c.url("/communities/"+strconv.Itoa(cid), nil), &community); err != nil {
+		return nil, err
+	}
+
+	return &community, nil
+}
+
+// Lookup looks up a batch of CIDs at once, up to 1000 of them.
+func (c *Client) Lookup(ctx context.Context, cids []int) (*LookupResult, error) {
+	body, err := json.Marshal(cids)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, c.url("/communities/lookup", nil), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result LookupResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("could not read the lookup: %w", err)
+	}
+
+	return &result, nil
+}
+
+// url is the endpoint's URL with the params.
+func (c *Client) url(path string, params []Param) string {
+	values := url.Values{}
+	for _, param := range params {
+		param(values)
+	}
+
+	u := c.baseURL + apiPrefix + path
+	if len(values) > 0 {
+		u += "?" + values.Encode()
+	}
+
+	return u
+}
+
+// link is the URL of a link that the API returned, like the next page,
+// which is relative to the server.
+func (c *Client) link(path string) string {
+	return c.baseURL + path
+}
+
+func (c *Client) getJSON(ctx context.Context, u string, v any) error {
+	resp, err := c.do(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("could not read the response from %s: %w", u, err)
+	}
+
+	return nil
+}
+
+// do makes the request, and turns anything but a 2xx into an error with
+// the message that the API gave.
+func (c *Client) do(ctx context.Context, method, u string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok && len(id) > 0 {
+		req.Header.Set("X-Request-ID", id)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if len(c.apiKey) > 0 {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	return nil, responseError(resp)
+}
+
+// An Error is a problem that the API returned, for telling errors apart
+// by their code, like problem.CommunityNotFound, with errors.As. It wraps
+// the error that's closest to its status, so errors.Is still works too.
+type Error struct {
+	problem.Problem
+	err error
+}
+
+func (e *Error) Error() string {
+	message := e.Detail
+	if len(message) == 0 {
+		message = http.StatusText(e.Status)
+	}
+	if len(e.Code) > 0 {
+		message += " [" + string(e.Code) + "]"
+	}
+
+	if len(e.RequestID) > 0 {
+		return fmt.Sprintf("%s: %d %s (request %s)", e.err, e.Status, message, e.RequestID)
+	}
+	return fmt.Sprintf("%s: %d %s", e.err, e.Status, message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// responseError is the error for a response that wasn't ok. It has the
+// request's ID, for finding it in the server's logs. Servers from before
+// errors were problems only sent back the message, in error.
+func responseError(resp *http.Response) error {
+	var p problem.Problem
+	json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&p)
+
+	p.Status = resp.StatusCode
+	if len(p.Detail) == 0 {
+		p.Detail = p.Error
+	}
+	if len(p.RequestID) == 0 {
+		p.RequestID = resp.Header.Get("X-Request-ID")
+	}
+
+	var err error
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		err = ErrNotFound
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		err = ErrUnauthorized
+	case resp.StatusCode >= 500:
+		err = ErrServer
+	default:
+		err = ErrBadRequest
+	}
+
+	return &Error{p, err}
+}
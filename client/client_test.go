@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"nfip-community-book/data"
+	"nfip-community-book/problem"
+	"nfip-community-book/server"
+	"nfip-community-book/store"
+)
+
+func newTestClient(t *testing.T, serverOpts []server.Option, opts ...Option) *Client {
+	t.Helper()
+
+	communities, err := data.GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("expected the demo status book, got %s", err)
+	}
+
+	serverOpts = append(serverOpts, server.WithLogHandler(slog.NewJSONHandler(io.Discard, nil)))
+	ts := httptest.NewServer(server.New(store.NewMemory(communities), serverOpts...))
+	t.Cleanup(ts.Close)
+
+	return New(ts.URL+"/", opts...)
+}
+
+func TestSearch(t *testing.T) {
+	c := newTestClient(t, nil)
+
+	page, err := c.Search(context.Background(), "houston", State("TX"), Limit(1))
+	if err != nil {
+		t.Fatalf("expected a page of results, got %s", err)
+	}
+	if len(page.Results) != 1 || page.Results[0].Community.CID != 480296 || page.APIVersion != "v1" {
+		t.Errorf("expected Houston first, got %+v", page)
+	}
+	if page.Total > 1 && len(page.Next) == 0 {
+		t.Errorf("expected a link to the next page")
+	}
+
+	if _, err := c.Search(context.Background(), "houston", Limit(5000)); !errors.Is(err, ErrBadRequest) {
+		t.Errorf("expected too big a limit to be a bad request, got %v", err)
+	}
+}
+
+func TestCommunities(t *testing.T) {
+	c := newTestClient(t, nil)
+
+	page, err := c.Communities(context.Background(), State("TX"), Fields("cid", "state"))
+	if err != nil {
+		t.Fatalf("expected a page of communities, got %s", err)
+	}
+	if page.Total != 3 || len(page.Communities) != 3 || page.Communities[0].State != "TX" || len(page.Communities[0].CommunityName) > 0 {
+		t.Errorf("expected the CIDs and states of the communities in Texas, got %+v", page)
+	}
+}
+
+func TestGetCommunity(t *testing.T) {
+	c := newTestClient(t, nil)
+
+	community, err := c.GetCommunity(context.Background(), 480296)
+	if err != nil || community.BaseName != "HOUSTON" {
+		t.Errorf("expected Houston, got %+v %v", community, err)
+	}
+
+	_, err = c.GetCommunity(context.Background(), 999999)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a CID that isn't there to be not found, got %v", err)
+	}
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code != problem.CommunityNotFound || apiErr.Status != 404 {
+		t.Errorf("expected the problem's code, got %v", err)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	c := newTestClient(t, nil)
+
+	result, err := c.Lookup(context.Background(), []int{480296, 999999})
+	if err != nil {
+		t.Fatalf("expected the lookup, got %s", err)
+	}
+	if len(result.Communities) != 1 || result.Communities[0].CID != 480296 || len(result.Unknown) != 1 || result.Unknown[0] != 999999 {
+		t.Errorf("expected Houston and an unknown CID, got %+v", result)
+	}
+}
+
+func TestAPIKey(t *testing.T) {
+	keys := []server.Option{server.WithAPIKeys([]server.APIKey{{Name: "test", Key: "secret"}})}
+
+	if _, err := newTestClient(t, keys).GetCommunity(context.Background(), 480296); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected to be unauthorized without a key, got %v", err)
+	}
+
+	if _, err := newTestClient(t, keys, WithAPIKey("secret")).GetCommunity(context.Background(), 480296); err != nil {
+		t.Errorf("expected the key to be sent, got %s", err)
+	}
+}
+
+func TestStreamCommunities(t *testing.T) {
+	c := newTestClient(t, nil)
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+
+	// Small pages, so that it has to follow the links
+	var streamed data.NFIPCommunityStatuses
+	for community, err := range c.StreamCommunities(context.Background(), Limit(10)) {
+		if err != nil {
+			t.Fatalf("expected every community, got %s", err)
+		}
+		streamed = append(streamed, community)
+	}
+
+	if len(streamed) != len(communities) {
+		t.Fatalf("expected %d communities, got %d", len(communities), len(streamed))
+	}
+	for i := range communities {
+		if streamed[i].CID != communities[i].CID {
+			t.Errorf("expected %d at %d, got %d", communities[i].CID, i, streamed[i].CID)
+		}
+	}
+
+	n := 0
+	for range c.StreamCommunities(context.Background(), Limit(10)) {
+		if n++; n == 15 {
+			break
+		}
+	}
+	if n != 15 {
+		t.Errorf("expected the loop to be broken out of, got %d", n)
+	}
+}
+
+func TestStreamSearch(t *testing.T) {
+	c := newTestClient(t, nil)
+
+	var first *data.NFIPCommunityStatus
+	for community, err := range c.StreamSearch(context.Background(), "houston") {
+		if err != nil {
+			t.Fatalf("expected the results, got %s", err)
+		}
+		if first == nil {
+			first = &community
+		}
+	}
+	if first == nil || first.CID != 480296 {
+		t.Errorf("expected Houston first, got %+v", first)
+	}
+
+	for _, err := range c.StreamSearch(context.Background(), "houston", Sort("population")) {
+		if !errors.Is(err, ErrBadRequest) {
+			t.Errorf("expected an invalid sort to be a bad request, got %v", err)
+		}
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	c := newTestClient(t, nil)
+
+	ctx := WithRequestID(context.Background(), "support-ticket-42")
+	_, err := c.GetCommunity(ctx, 999999)
+	if err == nil || !strings.Contains(err.Error(), "(request support-ticket-42)") {
+		t.Errorf("expected the request's ID to be sent and to be in the error, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Param is one of the parameters that lists take, for paging, sorting,
+// filtering and trimming them.
+type Param func(url.Values)
+
+// Limit is how many communities are on a page, up to 1000.
+func Limit(n int) Param {
+	return func(v url.Values) {
+		v.Set("limit", strconv.Itoa(n))
+	}
+}
+
+// Offset is how many communities are skipped before the page.
+func Offset(n int) Param {
+	return func(v url.Values) {
+		v.Set("offset", strconv.Itoa(n))
+	}
+}
+
+// Sort sorts them by relevance, name, cid or crs_class.
+func Sort(by string) Param {
+	return func(v url.Values) {
+		v.Set("sort", by)
+	}
+}
+
+// State is only the communities in the state, as an abbreviation, a name
+// or a FIPS code.
+func State(state string) Param {
+	return func(v url.Values) {
+		v.Set("state", state)
+	}
+}
+
+// Participating is only the communities that are participating in the
+// NFIP, or only the ones that aren't.
+func Participating(participating bool) Param {
+	return func(v url.Values) {
+		v.Set("participating", strconv.FormatBool(participating))
+	}
+}
+
+// Tribal is only the tribal communities, or only the ones that aren't.
+func Tribal(tribal bool) Param {
+	return func(v url.Values) {
+		v.Set("tribal", strconv.FormatBool(tribal))
+	}
+}
+
+// CRSClassAtMost is only the communities in the CRS with this class or
+// better.
+func CRSClassAtMost(class int) Param {
+	return func(v url.Values) {
+		v.Set("crs_class_max", strconv.Itoa(class))
+	}
+}
+
+// Between is only the communities with the date, named the same as it is
+// in JSON, like curr_eff_map_date, in the range. Both days are included,
+// and either one can be left as the zero time to leave that end open.
+func Between(date string, from, to time.Time) Param {
+	return func(v url.Values) {
+		if !from.IsZero() {
+			v.Set(date+"_from", from.Format(time.DateOnly))
+		}
+		if !to.IsZero() {
+			v.Set(date+"_to", to.Format(time.DateOnly))
+		}
+	}
+}
+
+// Fields only returns these fields of the communities, named the same as
+// they are in JSON. The rest are left as their zero values.
+func Fields(names ...string) Param {
+	return func(v url.Values) {
+		v.Set("fields", strings.Join(names, ","))
+	}
+}
+
+func query(term string) Param {
+	return func(v url.Values) {
+		v.Set("q", term)
+	}
+}
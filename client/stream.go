@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	"nfip-community-book/data"
+)
+
+// Streams ask for the biggest pages there are, unless they're given a
+// Limit, so that it takes as few requests as it can.
+const streamLimit = 1000
+
+var nextLink = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// StreamCommunities ranges over every community that the params pick,
+// rather than a page of them. Each page is read as NDJSON one community at
+// a time, and the next one is only asked for once the loop gets to it, so
+// they don't all have to be held in memory. The loop stops at the first
+// error.
+//
+//	for community, err := range c.StreamCommunities(ctx, client.State("TX")) {
+//		...
+//	}
+func (c *Client) StreamCommunities(ctx context.Context, params ...Param) iter.Seq2[data.NFIPCommunityStatus, error] {
+	return c.stream(ctx, c.url("/communities", streamParams(params)))
+}
+
+// StreamSearch ranges over every community that matches the term, best
+// first, the same way StreamCommunities does. The scores aren't in NDJSON,
+// so they're just the communities.
+func (c *Client) StreamSearch(ctx context.Context, term string, params ...Param) iter.Seq2[data.NFIPCommunityStatus, error] {
+	return c.stream(ctx, c.url("/search", append(streamParams(params), query(term))))
+}
+
+func streamParams(params []Param) []Param {
+	ndjson := func(v url.Values) {
+		v.Set("format", "ndjson")
+	}
+	return append(append([]Param{Limit(streamLimit)}, params...), ndjson)
+}
+
+func (c *Client) stream(ctx context.Context, u string) iter.Seq2[data.NFIPCommunityStatus, error] {
+	return func(yield func(data.NFIPCommunityStatus, error) bool) {
+		for len(u) > 0 {
+			next, err := c.streamPage(ctx, u, yield)
+			if errors.Is(err, errStopped) {
+				return
+			}
+			if err != nil {
+				yield(data.NFIPCommunityStatus{}, err)
+				return
+			}
+
+			u = ""
+			if len(next) > 0 {
+				u = c.link(next)
+			}
+		}
+	}
+}
+
+// errStopped is when the loop was broken out of, which isn't an error.
+var errStopped = fmt.Errorf("stopped")
+
+// streamPage yields each community on the page, and returns the link to
+// the next one.
+func (c *Client) streamPage(ctx context.Context, u string, yield func(data.NFIPCommunityStatus, error) bool) (string, error) {
+	resp, err := c.do(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	d := json.NewDecoder(resp.Body)
+	for {
+		var community data.NFIPCommunityStatus
+		err := d.Decode(&community)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("could not read a community from %s: %w", u, err)
+		}
+
+		if !yield(community, nil) {
+			return "", errStopped
+		}
+	}
+
+	var next string
+	if match := nextLink.FindStringSubmatch(resp.Header.Get("Link")); match != nil {
+		next = match[1]
+	}
+
+	return next, nil
+}
@@ -0,0 +1,128 @@
+package data
+
+import (
+	"math"
+	"sort"
+	"strconv"
+)
+
+type StateCount struct {
+	State string `json:"state"`
+	Count int    `json:"count"`
+}
+
+type ProgramCount struct {
+	Program ProgramKind `json:"program"`
+	Count   int         `json:"count"`
+}
+
+type CRSClassCount struct {
+	Class int `json:"class"`
+	Count int `json:"count"`
+}
+
+type StateParticipation struct {
+	State         string  `json:"state"`
+	Communities   int     `json:"communities"`
+	Participating int     `json:"participating"`
+	Rate          float64 `json:"rate"`
+}
+
+// GroupBy splits the communities into groups by their key,
+// keeping them in the same order within each group.
+func (c NFIPCommunityStatuses) GroupBy(key func(NFIPCommunityStatus) string) map[string]NFIPCommunityStatuses {
+	groups := make(map[string]NFIPCommunityStatuses)
+	for _, community := range c {
+		k := key(community)
+		groups[k] = append(groups[k], community)
+	}
+
+	return groups
+}
+
+// sortedKeys returns the keys of the groups in order.
+func sortedKeys(groups map[string]NFIPCommunityStatuses) []string {
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func byState(c NFIPCommunityStatus) string {
+	return c.State
+}
+
+// CountByState counts the communities in each state, in order by the
+// state's abbreviation. Communities without a state are counted under "".
+func (c NFIPCommunityStatuses) CountByState() []StateCount {
+	groups := c.GroupBy(byState)
+
+	var counts []StateCount
+	for _, state := range sortedKeys(groups) {
+		counts = append(counts, StateCount{state, len(groups[state])})
+	}
+
+	return counts
+}
+
+// CountByProgram counts the communities in each program
+// that has any, in the same order as the ProgramKinds.
+func (c NFIPCommunityStatuses) CountByProgram() []ProgramCount {
+	tallies := make(map[ProgramKind]int)
+	for _, community := range c {
+		tallies[community.Program]++
+	}
+
+	var counts []ProgramCount
+	for program := ProgramUnknown; program <= ProgramWithdrawn; program++ {
+		if tallies[program] > 0 {
+			counts = append(counts, ProgramCount{program, tallies[program]})
+		}
+	}
+
+	return counts
+}
+
+// CountByCRSClass counts the CRS communities in each class from 1 to 10.
+// Classes without any communities are still counted so that there's always
+// one for each class. Communities that aren't in the CRS aren't counted.
+func (c NFIPCommunityStatuses) CountByCRSClass() []CRSClassCount {
+	groups := c.GroupBy(func(community NFIPCommunityStatus) string {
+		if !community.IsCRSParticipant() {
+			return ""
+		}
+
+		return strconv.Itoa(*community.CurClass)
+	})
+
+	counts := make([]CRSClassCount, 10)
+	for class := 1; class <= 10; class++ {
+		counts[class-1] = CRSClassCount{class, len(groups[strconv.Itoa(class)])}
+	}
+
+	return counts
+}
+
+// ParticipationRateByState is the percentage of communities in each
+// state that are participating in the NFIP, to a tenth of a percent.
+func (c NFIPCommunityStatuses) ParticipationRateByState() []StateParticipation {
+	groups := c.GroupBy(byState)
+
+	var rates []StateParticipation
+	for _, state := range sortedKeys(groups) {
+		sp := StateParticipation{State: state, Communities: len(groups[state])}
+		for _, community := range groups[state] {
+			if community.ParticipatingCommunity {
+				sp.Participating++
+			}
+		}
+
+		sp.Rate = math.Round(float64(sp.Participating)/float64(sp.Communities)*1000) / 10
+		rates = append(rates, sp)
+	}
+
+	return rates
+}
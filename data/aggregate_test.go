@@ -0,0 +1,57 @@
+package data
+
+import "testing"
+
+func TestAggregates(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	groups := communities.GroupBy(func(c NFIPCommunityStatus) string { return string(c.Type) })
+	if len(groups["county"]) == 0 || groups["tribal"][0].CID != 120678 {
+		t.Errorf("expected the communities to be grouped by type, got %v", groups)
+	}
+
+	counts := communities.CountByState()
+	total := 0
+	for i, sc := range counts {
+		total += sc.Count
+		if i > 0 && counts[i-1].State >= sc.State {
+			t.Errorf("expected the states to be in order, got %s before %s", counts[i-1].State, sc.State)
+		}
+		if sc.State == "TX" && sc.Count != 3 {
+			t.Errorf("expected 3 communities in Texas, got %d", sc.Count)
+		}
+	}
+
+	if total != len(communities) {
+		t.Errorf("expected every community to be counted once, got %d of %d", total, len(communities))
+	}
+
+	total = 0
+	for _, pc := range communities.CountByProgram() {
+		total += pc.Count
+	}
+
+	if total != len(communities) {
+		t.Errorf("expected every community to be in a program, got %d of %d", total, len(communities))
+	}
+
+	classes := communities.CountByCRSClass()
+	if len(classes) != 10 || classes[0].Class != 1 || classes[9].Class != 10 {
+		t.Errorf("expected a count for every CRS class, got %v", classes)
+	}
+
+	for _, cc := range classes {
+		if cc.Class == 5 && cc.Count != len(*communities.Apply(CRSClassAtMost(5), Not(CRSClassAtMost(4)))) {
+			t.Errorf("expected the class 5 count to match the filter, got %d", cc.Count)
+		}
+	}
+
+	for _, sp := range communities.ParticipationRateByState() {
+		if sp.State == "TX" && (sp.Communities != 3 || sp.Participating != 2 || sp.Rate != 66.7) {
+			t.Errorf("expected 2 of 3 Texas communities to be participating, got %+v", sp)
+		}
+	}
+}
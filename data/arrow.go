@@ -0,0 +1,220 @@
+package data
+
+import (
+	"io"
+	"time"
+
+	"github.com/apache/arrow/go/v17/arrow"
+	"github.com/apache/arrow/go/v17/arrow/array"
+	"github.com/apache/arrow/go/v17/arrow/ipc"
+	"github.com/apache/arrow/go/v17/arrow/memory"
+)
+
+// How many communities go in each record batch that ToArrow writes.
+const arrowBatchSize = 4096
+
+// An arrowColumn is one column of the communities in Arrow, and
+// how to add a community's value to it.
+type arrowColumn struct {
+	field    arrow.Field
+	appendTo func(b array.Builder, c NFIPCommunityStatus)
+}
+
+// The columns are named and ordered the same as the fields in JSON, except
+// that the flags are a column each. Anything that's missing from the status
+// book is null.
+var arrowColumns = []arrowColumn{
+	{arrow.Field{Name: "cid", Type: arrow.PrimitiveTypes.Int32}, func(b array.Builder, c NFIPCommunityStatus) {
+		b.(*array.Int32Builder).Append(int32(c.CID))
+	}},
+	{arrowString("community_name"), func(b array.Builder, c NFIPCommunityStatus) {
+		appendArrowString(b, c.CommunityName)
+	}},
+	{arrowBool("suspended"), func(b array.Builder, c NFIPCommunityStatus) {
+		b.(*array.BooleanBuilder).Append(c.Flags.Suspended)
+	}},
+	{arrowBool("withdrawn"), func(b array.Builder, c NFIPCommunityStatus) {
+		b.(*array.BooleanBuilder).Append(c.Flags.Withdrawn)
+	}},
+	{arrowBool("minimally_flood_prone"), func(b array.Builder, c NFIPCommunityStatus) {
+		b.(*array.BooleanBuilder).Append(c.Flags.MinimallyFloodProne)
+	}},
+	{arrowBool("non_flood_prone"), func(b array.Builder, c NFIPCommunityStatus) {
+		b.(*array.BooleanBuilder).Append(c.Flags.NonFloodProne)
+	}},
+	{arrowString("kind"), func(b array.Builder, c NFIPCommunityStatus) {
+		appendArrowString(b, string(c.Kind))
+	}},
+	{arrowString("base_name"), func(b array.Builder, c NFIPCommunityStatus) {
+		appendArrowString(b, c.BaseName)
+	}},
+	{arrowString("type"), func(b array.Builder, c NFIPCommunityStatus) {
+		appendArrowString(b, string(c.Type))
+	}},
+	{arrowString("county"), func(b array.Builder, c NFIPCommunityStatus) {
+		appendArrowString(b, c.County)
+	}},
+	{arrowString("state"), func(b array.Builder, c NFIPCommunityStatus) {
+		appendArrowString(b, c.State)
+	}},
+	{arrow.Field{Name: "state_fips", Type: arrow.PrimitiveTypes.Int32, Nullable: true}, func(b array.Builder, c NFIPCommunityStatus) {
+		if c.StateFIPS == 0 {
+			b.AppendNull()
+			return
+		}
+		b.(*array.Int32Builder).Append(int32(c.StateFIPS))
+	}},
+	{arrowDate("fhbm_identified"), func(b array.Builder, c NFIPCommunityStatus) {
+		appendArrowDate(b, c.FHBMIdentified)
+	}},
+	{arrowDate("firm_identified"), func(b array.Builder, c NFIPCommunityStatus) {
+		appendArrowDate(b, c.FIRMIdentified)
+	}},
+	{arrowDate("curr_eff_map_date"), func(b array.Builder, c NFIPCommunityStatus) {
+		appendArrowDate(b, c.CurrEffMapDate)
+	}},
+	{arrowDate("reg_emer_date"), func(b array.Builder, c NFIPCommunityStatus) {
+		appendArrowDate(b, c.RegEmerDate)
+	}},
+	{arrowBool("tribal"), func(b array.Builder, c NFIPCommunityStatus) {
+		b.(*array.BooleanBuilder).Append(c.Tribal)
+	}},
+	{arrowDate("crs_entry_date"), func(b array.Builder, c NFIPCommunityStatus) {
+		appendArrowDate(b, c.CRSEntryDate)
+	}},
+	{arrowDate("curr_eff_date"), func(b array.Builder, c NFIPCommunityStatus) {
+		appendArrowDate(b, c.CurrEffDate)
+	}},
+	{arrow.Field{Name: "cur_class", Type: arrow.PrimitiveTypes.Int32, Nullable: true}, func(b array.Builder, c NFIPCommunityStatus) {
+		if c.CurClass == nil {
+			b.AppendNull()
+			return
+		}
+		b.(*array.Int32Builder).Append(int32(*c.CurClass))
+	}},
+	{arrowString("percent_disc_sfha"), func(b array.Builder, c NFIPCommunityStatus) {
+		appendArrowString(b, c.PercentDiscSFHA)
+	}},
+	{arrowString("percent_non_sfha"), func(b array.Builder, c NFIPCommunityStatus) {
+		appendArrowString(b, c.PercentNonSFHA)
+	}},
+	{arrowString("program"), func(b array.Builder, c NFIPCommunityStatus) {
+		if c.Program == ProgramUnknown {
+			b.AppendNull()
+			return
+		}
+		b.(*array.StringBuilder).Append(c.Program.String())
+	}},
+	{arrowBool("participating_community"), func(b array.Builder, c NFIPCommunityStatus) {
+		b.(*array.BooleanBuilder).Append(c.ParticipatingCommunity)
+	}},
+}
+
+var arrowSchema = newArrowSchema()
+
+func newArrowSchema() *arrow.Schema {
+	fields := make([]arrow.Field, len(arrowColumns))
+	for i, column := range arrowColumns {
+		fields[i] = column.field
+	}
+
+	return arrow.NewSchema(fields, nil)
+}
+
+// ArrowSchema is the schema of the records and tables of communities.
+func ArrowSchema() *arrow.Schema {
+	return arrowSchema
+}
+
+// ArrowRecord puts the communities into a single Arrow record. It has
+// to be released when it's done with.
+func (c *NFIPCommunityStatuses) ArrowRecord(mem memory.Allocator) arrow.Record {
+	return newArrowRecord(mem, *c)
+}
+
+// ArrowTable puts the communities into an Arrow table, in record batches
+// of the same size that ToArrow writes. It has to be released when it's
+// done with.
+func (c *NFIPCommunityStatuses) ArrowTable(mem memory.Allocator) arrow.Table {
+	var records []arrow.Record
+	for start := 0; start < len(*c); start += arrowBatchSize {
+		end := minInt(start+arrowBatchSize, len(*c))
+		records = append(records, newArrowRecord(mem, (*c)[start:end]))
+	}
+
+	table := array.NewTableFromRecords(arrowSchema, records)
+	for _, record := range records {
+		record.Release()
+	}
+
+	return table
+}
+
+// ToArrow writes the communities out in the Arrow IPC stream format, so
+// that they can be read straight into anything that speaks Arrow.
+func (c *NFIPCommunityStatuses) ToArrow(w io.Writer) error {
+	mem := memory.NewGoAllocator()
+	aw := ipc.NewWriter(w, ipc.WithSchema(arrowSchema), ipc.WithAllocator(mem))
+
+	for start := 0; start < len(*c); start += arrowBatchSize {
+		end := minInt(start+arrowBatchSize, len(*c))
+
+		record := newArrowRecord(mem, (*c)[start:end])
+		err := aw.Write(record)
+		record.Release()
+
+		if err != nil {
+			aw.Close()
+			return err
+		}
+	}
+
+	return aw.Close()
+}
+
+func newArrowRecord(mem memory.Allocator, communities NFIPCommunityStatuses) arrow.Record {
+	b := array.NewRecordBuilder(mem, arrowSchema)
+	defer b.Release()
+
+	b.Reserve(len(communities))
+	for _, community := range communities {
+		for i, column := range arrowColumns {
+			column.appendTo(b.Field(i), community)
+		}
+	}
+
+	return b.NewRecord()
+}
+
+func arrowString(name string) arrow.Field {
+	return arrow.Field{Name: name, Type: arrow.BinaryTypes.String, Nullable: true}
+}
+
+func arrowBool(name string) arrow.Field {
+	return arrow.Field{Name: name, Type: arrow.FixedWidthTypes.Boolean}
+}
+
+func arrowDate(name string) arrow.Field {
+	return arrow.Field{Name: name, Type: arrow.FixedWidthTypes.Date32, Nullable: true}
+}
+
+func appendArrowString(b array.Builder, s string) {
+	if len(s) == 0 {
+		b.AppendNull()
+		return
+	}
+
+	b.(*array.StringBuilder).Append(s)
+}
+
+// Dates are days since 1970-01-01, going by the date in the status
+// book rather than what time it was in UTC.
+func appendArrowDate(b array.Builder, t *time.Time) {
+	if t == nil {
+		b.AppendNull()
+		return
+	}
+
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	b.(*array.Date32Builder).Append(arrow.Date32(day.Unix() / (24 * 60 * 60)))
+}
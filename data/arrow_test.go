@@ -0,0 +1,95 @@
+package data
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v17/arrow/array"
+	"github.com/apache/arrow/go/v17/arrow/ipc"
+	"github.com/apache/arrow/go/v17/arrow/memory"
+)
+
+func TestArrowRecord(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+	springfield := communities.Search("springfield").Communities()
+
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	record := springfield.ArrowRecord(mem)
+	defer record.Release()
+
+	if record.NumRows() != 1 || int(record.NumCols()) != len(arrowColumns) {
+		t.Fatalf("expected 1 row and %d columns, got %d and %d", len(arrowColumns), record.NumRows(), record.NumCols())
+	}
+
+	community := springfield[0]
+	if cid := record.Column(0).(*array.Int32).Value(0); int(cid) != community.CID {
+		t.Errorf("expected the CID to be %d, got %d", community.CID, cid)
+	}
+
+	if name := record.Column(1).(*array.String).Value(0); name != community.CommunityName {
+		t.Errorf("expected the name to be %s, got %s", community.CommunityName, name)
+	}
+
+	index := ArrowSchema().FieldIndices("curr_eff_map_date")[0]
+	days := record.Column(index).(*array.Date32).Value(0)
+	if date := time.Unix(int64(days)*24*60*60, 0).UTC().Format(jsonDateLayout); date != community.CurrEffMapDate.Format(jsonDateLayout) {
+		t.Errorf("expected the map date to be %s, got %s", community.CurrEffMapDate.Format(jsonDateLayout), date)
+	}
+
+	index = ArrowSchema().FieldIndices("cur_class")[0]
+	if community.CurClass == nil && !record.Column(index).IsNull(0) {
+		t.Errorf("expected no CRS class to be null")
+	}
+}
+
+func TestArrowTable(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	table := communities.ArrowTable(mem)
+	defer table.Release()
+
+	if int(table.NumRows()) != len(communities) {
+		t.Errorf("expected %d rows, got %d", len(communities), table.NumRows())
+	}
+}
+
+func TestToArrow(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+
+	var buf bytes.Buffer
+	if err := communities.ToArrow(&buf); err != nil {
+		t.Fatalf("expected the communities to be written, got %s", err)
+	}
+
+	r, err := ipc.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("expected the stream to be read, got %s", err)
+	}
+	defer r.Release()
+
+	if !r.Schema().Equal(ArrowSchema()) {
+		t.Errorf("expected the schema to be %s, got %s", ArrowSchema(), r.Schema())
+	}
+
+	rows := 0
+	for r.Next() {
+		record := r.Record()
+		cids := record.Column(0).(*array.Int32)
+		for i := 0; i < cids.Len(); i++ {
+			if int(cids.Value(i)) != communities[rows+i].CID {
+				t.Fatalf("expected row %d to be %d, got %d", rows+i, communities[rows+i].CID, cids.Value(i))
+			}
+		}
+		rows += int(record.NumRows())
+	}
+
+	if rows != len(communities) {
+		t.Errorf("expected %d rows, got %d", len(communities), rows)
+	}
+}
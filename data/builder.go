@@ -0,0 +1,181 @@
+package data
+
+import "sort"
+
+// A SortField is what the results of a Query are sorted by.
+type SortField int
+
+const (
+	// Relevance leaves searches with the most relevant first,
+	// and everything else in the same order as the status book.
+	Relevance SortField = iota
+	Name
+	CID
+
+	// CRSClass puts the best classes first, and
+	// communities that aren't in the CRS last.
+	CRSClass
+)
+
+// A Query is built up one step at a time and then run against the status
+// book. It's the same as refining a ResultSet, but it can be put together
+// before the communities are at hand and run more than once.
+//
+//	page := data.NewQuery().State("TX").Participating().NameContains("houston").SortBy(data.Name).Limit(20).Run(cb)
+type Query struct {
+	filters []Filter
+	term    string
+	folding folding
+	sort    SortField
+	offset  int
+	limit   int
+}
+
+func NewQuery() *Query {
+	return &Query{}
+}
+
+// Where keeps the communities that pass every filter.
+func (q *Query) Where(filters ...Filter) *Query {
+	q.filters = append(q.filters, filters...)
+	return q
+}
+
+// State keeps the communities in the state, however it's written. See ByState.
+func (q *Query) State(state string) *Query {
+	return q.Where(ByState(state))
+}
+
+func (q *Query) Participating() *Query {
+	return q.Where(Participating(true))
+}
+
+func (q *Query) NotParticipating() *Query {
+	return q.Where(Participating(false))
+}
+
+func (q *Query) Tribal() *Query {
+	return q.Where(Tribal(true))
+}
+
+func (q *Query) InProgram(program ProgramKind) *Query {
+	return q.Where(InProgram(program))
+}
+
+func (q *Query) CRSClassAtMost(class int) *Query {
+	return q.Where(CRSClassAtMost(class))
+}
+
+func (q *Query) NameContains(term string) *Query {
+	return q.Where(NameContains(term))
+}
+
+func (q *Query) CountyContains(term string) *Query {
+	return q.Where(CountyContains(term))
+}
+
+func (q *Query) CID(cid int) *Query {
+	return q.Where(func(c NFIPCommunityStatus) bool {
+		return c.CID == cid
+	})
+}
+
+// Search ranks the communities that are left by how well they match the
+// term, the same way NFIPCommunityStatuses.Search does.
+func (q *Query) Search(term string) *Query {
+	q.term = term
+	return q
+}
+
+// MatchDiacritics makes the search count accents, so "Mayaguez" doesn't
+// find "MAYAGÜEZ". By default they're ignored.
+func (q *Query) MatchDiacritics() *Query {
+	q.folding.keepDiacritics = true
+	return q
+}
+
+func (q *Query) SortBy(field SortField) *Query {
+	q.sort = field
+	return q
+}
+
+func (q *Query) Offset(offset int) *Query {
+	q.offset = offset
+	return q
+}
+
+// Limit is how many results there are at most.
+// It's 0 by default, which means there's no limit.
+func (q *Query) Limit(limit int) *Query {
+	q.limit = limit
+	return q
+}
+
+// Filter is every filter in the query put together.
+func (q *Query) Filter() Filter {
+	return All(q.filters...)
+}
+
+// Refine filters, searches and sorts the communities, but doesn't page them.
+func (q *Query) Refine(c NFIPCommunityStatuses) ResultSet {
+	rs := c.Refine().Filter(q.filters...)
+	if len(q.term) > 0 {
+		rs = rs.search(q.term, q.folding)
+	}
+
+	if less := q.less(); less != nil {
+		rs = rs.SortBy(less)
+	}
+
+	return rs
+}
+
+// less is how the results are sorted, or nil if they're
+// left in the order they're in.
+func (q *Query) less() func(a, b NFIPCommunityStatus) bool {
+	switch q.sort {
+	case Name:
+		return func(a, b NFIPCommunityStatus) bool {
+			return a.CommunityName < b.CommunityName
+		}
+	case CID:
+		return func(a, b NFIPCommunityStatus) bool {
+			return a.CID < b.CID
+		}
+	case CRSClass:
+		return func(a, b NFIPCommunityStatus) bool {
+			if !a.IsCRSParticipant() || !b.IsCRSParticipant() {
+				return a.IsCRSParticipant() && !b.IsCRSParticipant()
+			}
+			return *a.CurClass < *b.CurClass
+		}
+	}
+
+	return nil
+}
+
+// Run runs the query against the communities and returns the page of results.
+func (q *Query) Run(c NFIPCommunityStatuses) SearchPage {
+	return q.Refine(c).Page(q.offset, q.limit)
+}
+
+// RunResults filters, sorts and pages results that were already searched
+// for, like by an index that caches its searches or a store, rather than
+// searching the communities again. The query's search term isn't used.
+func (q *Query) RunResults(results SearchResults) SearchPage {
+	keep := q.Filter()
+	kept := SearchResults{}
+	for _, r := range results {
+		if keep(r.Community) {
+			kept = append(kept, r)
+		}
+	}
+
+	if less := q.less(); less != nil {
+		sort.SliceStable(kept, func(i, j int) bool {
+			return less(kept[i].Community, kept[j].Community)
+		})
+	}
+
+	return kept.Page(q.offset, q.limit)
+}
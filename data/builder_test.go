@@ -0,0 +1,94 @@
+package data
+
+import "testing"
+
+func TestQueryBuilder(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	page := NewQuery().State("TX").Participating().NameContains("houston").SortBy(Name).Limit(20).Run(communities)
+	if page.Total != 1 || page.Results[0].Community.CID != 480296 {
+		t.Errorf("expected only Houston, got %v", page.Results.Communities())
+	}
+
+	// Sorting and paging
+	page = NewQuery().Where(Participating(true)).SortBy(CID).Offset(2).Limit(3).Run(communities)
+	if page.Total != 32 || len(page.Results) != 3 {
+		t.Fatalf("expected 3 of 32 participating communities, got %d of %d", len(page.Results), page.Total)
+	}
+
+	for i := 1; i < len(page.Results); i++ {
+		if page.Results[i-1].Community.CID > page.Results[i].Community.CID {
+			t.Errorf("expected the results to be sorted by CID, got %v", page.Results.Communities())
+		}
+	}
+
+	ranked := NewQuery().CRSClassAtMost(10).SortBy(CRSClass).Refine(communities).Communities()
+	for i := 1; i < len(ranked); i++ {
+		if *ranked[i-1].CurClass > *ranked[i].CurClass {
+			t.Errorf("expected the best CRS classes first, got %d before %d", *ranked[i-1].CurClass, *ranked[i].CurClass)
+		}
+	}
+
+	// Searches are ranked, and can be narrowed down by the filters
+	page = NewQuery().Search("cook").Run(communities)
+	if page.Total != 2 || page.Results[0].Community.CID != 170054 || page.Results[0].Score <= page.Results[1].Score {
+		t.Errorf("expected Cook County and then Chicago, got %v", page.Results)
+	}
+
+	page = NewQuery().Search("cook").NameContains("chicago").Run(communities)
+	if page.Total != 1 || page.Results[0].Community.CID != 170074 {
+		t.Errorf("expected only Chicago, got %v", page.Results.Communities())
+	}
+
+	// A query can be run more than once
+	q := NewQuery().CID(170074)
+	for i := 0; i < 2; i++ {
+		if page := q.Run(communities); page.Total != 1 {
+			t.Errorf("expected the same community every time, got %d", page.Total)
+		}
+	}
+
+	if !q.Filter()(communities[13]) || q.Filter()(communities[0]) {
+		t.Errorf("expected the filter to only pass CID 170074")
+	}
+}
+
+func TestQueryRunResults(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+
+	queries := map[string]func() *Query{
+		"relevance": func() *Query { return NewQuery() },
+		"filtered":  func() *Query { return NewQuery().State("TX") },
+		"by name":   func() *Query { return NewQuery().SortBy(Name).Limit(3) },
+		"by CRS":    func() *Query { return NewQuery().SortBy(CRSClass).Offset(1) },
+	}
+
+	for name, query := range queries {
+		for _, term := range []string{"county", "new", "zzz"} {
+			expected := query().Search(term).Run(communities)
+			page := query().RunResults(communities.Search(term))
+
+			if page.Total != expected.Total || page.Offset != expected.Offset || len(page.Results) != len(expected.Results) {
+				t.Fatalf("expected %s for %s to be %d results, got %d", name, term, expected.Total, page.Total)
+			}
+
+			for i := range page.Results {
+				if page.Results[i].Community.CID != expected.Results[i].Community.CID || page.Results[i].Score != expected.Results[i].Score {
+					t.Errorf("expected %s for %s to be %v, got %v", name, term, expected.Results.Communities(), page.Results.Communities())
+					break
+				}
+			}
+		}
+	}
+
+	// The results that are passed in aren't touched
+	results := communities.Search("county")
+	first := results[0].Community.CID
+	NewQuery().SortBy(CID).RunResults(results)
+	if results[0].Community.CID != first {
+		t.Errorf("expected the results to be left alone")
+	}
+}
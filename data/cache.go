@@ -0,0 +1,145 @@
+package data
+
+import (
+	"container/list"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A SearchCache keeps the results of the most recent searches so that the
+// same search over and over, like from a search box that searches as it's
+// typed in, doesn't have to look through the communities every time. When
+// it's full, the search that was used the longest time ago is dropped.
+//
+// Results from the cache are shared between everyone who searched for the
+// same thing, so they shouldn't be changed.
+//
+// Every index that's built with the cache is a new generation of it, and
+// only the newest generation's results are kept, so a search that's still
+// running on the index from before a refresh can't put its results back.
+type SearchCache struct {
+	mu         sync.Mutex
+	size       int
+	entries    map[string]*list.Element
+	recent     *list.List
+	generation uint64
+
+	hits   int
+	misses int
+}
+
+type cacheEntry struct {
+	key     string
+	results SearchResults
+}
+
+// NewSearchCache makes a cache that holds the results of up to size searches.
+func NewSearchCache(size int) *SearchCache {
+	return &SearchCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		recent:  list.New(),
+	}
+}
+
+// newGeneration empties the cache for an index that's been built for
+// different communities, and is the generation that it caches under.
+func (sc *SearchCache) newGeneration() uint64 {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	sc.generation++
+	sc.entries = make(map[string]*list.Element)
+	sc.recent.Init()
+	return sc.generation
+}
+
+func generationKey(generation uint64, key string) string {
+	return strconv.FormatUint(generation, 10) + "\x00" + key
+}
+
+func (sc *SearchCache) get(generation uint64, key string) (SearchResults, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	e, ok := sc.entries[generationKey(generation, key)]
+	if !ok {
+		sc.misses++
+		return nil, false
+	}
+
+	sc.hits++
+	sc.recent.MoveToFront(e)
+	return e.Value.(*cacheEntry).results, true
+}
+
+// add caches the results, unless they're from an older generation.
+func (sc *SearchCache) add(generation uint64, key string, results SearchResults) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if sc.size <= 0 || generation != sc.generation {
+		return
+	}
+
+	key = generationKey(generation, key)
+
+	if e, ok := sc.entries[key]; ok {
+		e.Value.(*cacheEntry).results = results
+		sc.recent.MoveToFront(e)
+		return
+	}
+
+	sc.entries[key] = sc.recent.PushFront(&cacheEntry{key, results})
+
+	if sc.recent.Len() > sc.size {
+		oldest := sc.recent.Back()
+		sc.recent.Remove(oldest)
+		delete(sc.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Purge empties the cache. NewCommunityIndex does it when it's given the
+// cache, since the communities have changed.
+func (sc *SearchCache) Purge() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	sc.entries = make(map[string]*list.Element)
+	sc.recent.Init()
+}
+
+// Len is how many searches are cached.
+func (sc *SearchCache) Len() int {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	return sc.recent.Len()
+}
+
+// Stats are how many searches were found in the cache and how many weren't.
+func (sc *SearchCache) Stats() (hits, misses int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	return sc.hits, sc.misses
+}
+
+// searchCacheKey is what a search is cached under: the kind of search, any
+// options it had that change the results, and the term. Terms are the same
+// no matter their case or spacing, except for queries, where case matters,
+// and the same with or without accents unless they're kept.
+func searchCacheKey(kind, options, term string, f folding) string {
+	if IsQuery(term) {
+		term = strings.TrimSpace(term)
+	} else {
+		term = f.fold(strings.Join(strings.Fields(term), " "))
+	}
+
+	if f.keepDiacritics {
+		options += "\x00diacritics"
+	}
+
+	return kind + "\x00" + options + "\x00" + term
+}
@@ -0,0 +1,121 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearchCache(t *testing.T) {
+	cache := NewSearchCache(2)
+	cache.add(0, "a", SearchResults{{Score: 1}})
+	cache.add(0, "b", SearchResults{{Score: 2}})
+
+	// Using "a" makes "b" the oldest, so it's the one that's dropped
+	if _, ok := cache.get(0, "a"); !ok {
+		t.Fatalf("expected \"a\" to be cached")
+	}
+
+	cache.add(0, "c", SearchResults{{Score: 3}})
+
+	if _, ok := cache.get(0, "b"); ok {
+		t.Errorf("expected \"b\" to have been dropped")
+	}
+
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.get(0, key); !ok {
+			t.Errorf("expected \"%s\" to still be cached", key)
+		}
+	}
+
+	if hits, misses := cache.Stats(); hits != 3 || misses != 1 {
+		t.Errorf("expected 3 hits and 1 miss, got %d and %d", hits, misses)
+	}
+
+	cache.Purge()
+	if cache.Len() != 0 {
+		t.Errorf("expected the cache to be empty after a purge, got %d", cache.Len())
+	}
+
+	// A cache with no room doesn't keep anything
+	empty := NewSearchCache(0)
+	empty.add(0, "a", SearchResults{})
+	if empty.Len() != 0 {
+		t.Errorf("expected nothing to be cached, got %d", empty.Len())
+	}
+}
+
+func TestSearchCacheKey(t *testing.T) {
+	if searchCacheKey("search", "", "  Cook   County ", folding{}) != searchCacheKey("search", "", "cook county", folding{}) {
+		t.Errorf("expected the case and spacing of terms not to matter")
+	}
+
+	if searchCacheKey("search", "", "cook", folding{}) == searchCacheKey("phonetic", "", "cook", folding{}) {
+		t.Errorf("expected different kinds of searches to be cached separately")
+	}
+
+	// "and" is only an operator in capitals
+	if searchCacheKey("search", "", "state:TX AND tribal", folding{}) == searchCacheKey("search", "", "state:tx and tribal", folding{}) {
+		t.Errorf("expected the case of queries to matter")
+	}
+
+	// Accents only matter when they're kept
+	if searchCacheKey("search", "", "Añasco", folding{}) != searchCacheKey("search", "", "anasco", folding{}) {
+		t.Errorf("expected accents not to matter by default")
+	}
+	if searchCacheKey("search", "", "Añasco", folding{keepDiacritics: true}) == searchCacheKey("search", "", "anasco", folding{keepDiacritics: true}) {
+		t.Errorf("expected accents to matter when they're kept")
+	}
+	if searchCacheKey("search", "", "anasco", folding{}) == searchCacheKey("search", "", "anasco", folding{keepDiacritics: true}) {
+		t.Errorf("expected searches that keep accents to be cached separately")
+	}
+}
+
+func TestCommunityIndexCache(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	cache := NewSearchCache(10)
+	idx := NewCommunityIndex(communities, WithSearchCache(cache))
+
+	expected := communities.Search("cook")
+	for _, term := range []string{"cook", "COOK "} {
+		if results := idx.Search(term); !reflect.DeepEqual(results, expected) {
+			t.Errorf("expected the same results for \"%s\" with a cache, got %v", term, results.Communities())
+		}
+	}
+
+	idx.FuzzySearch("sprinfield")
+	idx.FuzzySearch("sprinfield", WithMinSimilarity(0.5))
+	idx.PhoneticSearch("filadelfia")
+
+	if hits, misses := cache.Stats(); hits != 1 || misses != 4 {
+		t.Errorf("expected 1 hit and 4 misses, got %d and %d", hits, misses)
+	}
+
+	// Building an index for refreshed communities starts the cache over
+	NewCommunityIndex(communities[:1], WithSearchCache(cache))
+	if cache.Len() != 0 {
+		t.Errorf("expected the cache to be purged, got %d", cache.Len())
+	}
+}
+
+func TestCommunityIndexCacheRefreshed(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+	cache := NewSearchCache(10)
+	old := NewCommunityIndex(communities[:1], WithSearchCache(cache))
+
+	// A search that's still running on the old index after the new one
+	// is built can't put its results in the cache for the new one
+	next := NewCommunityIndex(communities, WithSearchCache(cache))
+	stale := old.Search("houston")
+
+	if results := next.Search("houston"); !reflect.DeepEqual(results, communities.Search("houston")) || reflect.DeepEqual(results, stale) {
+		t.Errorf("expected the new index's own results for houston, got %v", results.Communities())
+	}
+
+	if results := old.Search("houston"); !reflect.DeepEqual(results, stale) {
+		t.Errorf("expected the old index to still find its own communities, got %v", results.Communities())
+	}
+}
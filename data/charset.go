@@ -0,0 +1,85 @@
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"unicode/utf8"
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Windows-1252 is the same as Latin-1 except for 0x80-0x9F, where it has
+// printable characters instead of control codes. Bytes that aren't assigned
+// a character are kept as the control code with the same value.
+var windows1252 = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+// textReader reads the status book as UTF-8 no matter how it was saved. A
+// leading byte order mark is dropped, valid UTF-8 is passed through, and any
+// byte that isn't valid UTF-8 is treated as a Windows-1252 character, which is
+// what the status book is in when it isn't UTF-8.
+type textReader struct {
+	br  *bufio.Reader
+	buf []byte
+}
+
+func newTextReader(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+
+	if b, err := br.Peek(len(utf8BOM)); err == nil && bytes.Equal(b, utf8BOM) {
+		br.Discard(len(utf8BOM))
+	}
+
+	return &textReader{br: br}
+}
+
+func (tr *textReader) Read(p []byte) (int, error) {
+	n := 0
+
+	// Copy over anything left from a character that didn't fit last time
+	if len(tr.buf) > 0 {
+		n = copy(p, tr.buf)
+		tr.buf = tr.buf[n:]
+		return n, nil
+	}
+
+	for n < len(p) {
+		r, size, err := tr.br.ReadRune()
+		if err != nil {
+			if n > 0 && err == io.EOF {
+				return n, nil
+			}
+			return n, err
+		}
+
+		// An invalid byte comes back as an error rune with a size of 1
+		if r == utf8.RuneError && size == 1 {
+			tr.br.UnreadRune()
+			b, _ := tr.br.ReadByte()
+			r = decodeWindows1252(b)
+		}
+
+		var encoded [utf8.UTFMax]byte
+		size = utf8.EncodeRune(encoded[:], r)
+
+		copied := copy(p[n:], encoded[:size])
+		n += copied
+		if copied < size {
+			tr.buf = append(tr.buf[:0], encoded[copied:size]...)
+			break
+		}
+	}
+
+	return n, nil
+}
+
+func decodeWindows1252(b byte) rune {
+	if b >= 0x80 && b <= 0x9F {
+		return windows1252[b-0x80]
+	}
+
+	return rune(b)
+}
@@ -0,0 +1,38 @@
+package data
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestTextReader(t *testing.T) {
+	tests := map[string]string{
+		// UTF-8 is left alone
+		"AÑASCO, MUNICIPIO DE": "AÑASCO, MUNICIPIO DE",
+
+		// A byte order mark is dropped
+		"\xEF\xBB\xBFCID": "CID",
+
+		// Latin-1 and Windows-1252 characters are converted to UTF-8
+		"MAYAG\xDCEZ":         "MAYAGÜEZ",
+		"\x93QUOTED\x94 \x96": "“QUOTED” –",
+	}
+
+	for in, expected := range tests {
+		// Read one byte at a time to make sure characters split across reads are kept
+		b, err := io.ReadAll(iotest.OneByteReader(newTextReader(strings.NewReader(in))))
+		if err != nil || string(b) != expected {
+			t.Errorf("expected %q to be read as %q, got %q (%v)", in, expected, b, err)
+		}
+	}
+}
+
+func TestUnmarshalWithBOM(t *testing.T) {
+	record := "=\"170604\",\"SPRINGFIELD, CITY OF\",SANGAMON COUNTY,,,,,No,,,,,,R,Yes"
+	result, err := Unmarshal(strings.NewReader("\xEF\xBB\xBF" + testHeader + "\n" + record))
+	if err != nil || len(result.Communities) != 1 || result.Communities[0].CID != 170604 {
+		t.Errorf("expected the status book to be parsed with a byte order mark, got %v", err)
+	}
+}
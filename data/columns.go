@@ -0,0 +1,80 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+)
+
+var ErrMissingColumn = fmt.Errorf("missing column")
+
+// The names that each column of the status book can go by in the header. The
+// first name is the one FEMA uses today and the rest are known alternatives.
+var statusColumnNames = [numStatusColumns][]string{
+	StatusCID:                    {"CID", "Community ID", "Community Number"},
+	StatusCommunityName:          {"Community Name", "Community"},
+	StatusCounty:                 {"County", "Counties"},
+	StatusFHBMIdentified:         {"Init FHBM Identified", "FHBM Identified", "Initial FHBM Identified"},
+	StatusFIRMIdentified:         {"Init FIRM Identified", "FIRM Identified", "Initial FIRM Identified"},
+	StatusCurrEffMapDate:         {"Curr Eff Map Date", "Current Effective Map Date"},
+	StatusRegEmerDate:            {"Reg-Emer Date", "Reg Emer Date", "Regular-Emergency Date"},
+	StatusTribal:                 {"Tribal"},
+	StatusCRSEntryDate:           {"CRS Entry Date"},
+	StatusCurrEffDate:            {"Curr Eff Date", "Current Effective Date"},
+	StatusCurClass:               {"Curr Class", "Cur Class", "Current Class", "CRS Class"},
+	StatusPercentDiscSFHA:        {"% Disc SFHA", "Percent Disc SFHA", "% Discount SFHA"},
+	StausPercentNonSFHA:          {"% Disc Non SFHA", "Percent Disc Non SFHA", "% Discount Non SFHA"},
+	StatusProgram:                {"Program"},
+	StatusParticipatingCommunity: {"Participating in NFIP", "Participating", "Participating Community"},
+}
+
+// WithColumnAliases adds more names that a column can go by in the header,
+// for when FEMA renames a column before we've caught up with it.
+func WithColumnAliases(column int, aliases ...string) ParseOption {
+	return func(o *parseOptions) {
+		if o.columnAliases == nil {
+			o.columnAliases = make(map[int][]string)
+		}
+
+		o.columnAliases[column] = append(o.columnAliases[column], aliases...)
+	}
+}
+
+// mapColumns finds where each column is in the header so that
+// the columns can be read by name instead of by position.
+func mapColumns(header []string, aliases map[int][]string) ([numStatusColumns]int, error) {
+	var columns [numStatusColumns]int
+
+	positions := make(map[string]int)
+	for i, name := range header {
+		positions[normalizeColumnName(name)] = i
+	}
+
+	var missing []string
+	for column, names := range statusColumnNames {
+		names = append(names, aliases[column]...)
+		columns[column] = -1
+
+		for _, name := range names {
+			if i, ok := positions[normalizeColumnName(name)]; ok {
+				columns[column] = i
+				break
+			}
+		}
+
+		if columns[column] < 0 {
+			missing = append(missing, fmt.Sprintf("\"%s\" (tried %s)", names[0], strings.Join(names, ", ")))
+		}
+	}
+
+	if len(missing) > 0 {
+		return columns, fmt.Errorf("%w: %s not found in header %s", ErrMissingColumn, strings.Join(missing, ", "), strings.Join(header, ","))
+	}
+
+	return columns, nil
+}
+
+// Column names are compared without regard to case or spacing.
+func normalizeColumnName(name string) string {
+	name = strings.Trim(name, "\"= ")
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,61 @@
+package data
+
+import (
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testHeader = "CID,Community Name,County,Init FHBM Identified,Init FIRM Identified,Curr Eff Map Date,Reg-Emer Date,Tribal,CRS Entry Date,Curr Eff Date,Curr Class,% Disc SFHA,% Disc Non SFHA,Program,Participating in NFIP"
+
+func TestMapColumns(t *testing.T) {
+	header := strings.Split(testHeader, ",")
+	columns, err := mapColumns(header, nil)
+	if err != nil {
+		t.Fatalf("expected the header to be mapped, got %s", err)
+	}
+
+	for column, i := range columns {
+		if column != i {
+			t.Errorf("expected column %d to be at position %d, got %d", column, column, i)
+		}
+	}
+
+	// Columns should be found wherever they are
+	header[StatusCID], header[StatusCounty] = header[StatusCounty], header[StatusCID]
+	columns, err = mapColumns(header, nil)
+	if err != nil || columns[StatusCID] != StatusCounty || columns[StatusCounty] != StatusCID {
+		t.Errorf("expected swapped columns to be found, got %v (%v)", columns, err)
+	}
+
+	// A renamed column is missing unless it has an alias
+	header[StatusTribal] = "Tribal Community"
+	_, err = mapColumns(header, nil)
+	if !errors.Is(err, ErrMissingColumn) {
+		t.Errorf("expected the renamed tribal column to be missing")
+	}
+
+	_, err = mapColumns(header, map[int][]string{StatusTribal: {"tribal community"}})
+	if err != nil {
+		t.Errorf("expected the renamed tribal column to be found by its alias, got %s", err)
+	}
+}
+
+func TestUnmarshalReorderedColumns(t *testing.T) {
+	// New columns and columns in a different order shouldn't change what's parsed
+	header := strings.Replace(testHeader, "CID,Community Name", "Community Name,Region,CID", 1)
+	record := `"SPRINGFIELD, CITY OF",5,="170604",SANGAMON COUNTY,05/31/74,03/16/81,01/19/18,03/16/81,No,,,,,,R,Yes`
+
+	reader := csv.NewReader(strings.NewReader(header + "\n" + record + "\n"))
+	reader.LazyQuotes = true
+	communities, err := unmarshal(reader)
+	if err != nil || len(communities) != 1 {
+		t.Fatalf("expected one community to be parsed, got %d (%v)", len(communities), err)
+	}
+
+	c := communities[0]
+	if c.CID != 170604 || c.CommunityName != "SPRINGFIELD, CITY OF" || c.County != "SANGAMON COUNTY" || !c.ParticipatingCommunity {
+		t.Errorf("expected the community to be parsed by column name, got %+v", c)
+	}
+}
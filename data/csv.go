@@ -0,0 +1,160 @@
+package data
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The status book's codes for each program. A program that isn't known
+// is left blank, the same as it is in the status book.
+var programCodes = map[ProgramKind]string{
+	ProgramUnknown:          "",
+	ProgramEmergency:        "E",
+	ProgramRegular:          "R",
+	ProgramNotParticipating: "N",
+	ProgramSuspended:        "S",
+	ProgramWithdrawn:        "W",
+}
+
+// ToCSV writes the communities out in the same format and column order
+// as the Community Status Book's nation.csv, unless WithFields picks
+// the columns.
+func (c *NFIPCommunityStatuses) ToCSV(w io.Writer, opts ...EncodeOption) error {
+	o := newEncodeOptions(opts)
+	fields, err := o.bookFields()
+	if err != nil {
+		return err
+	}
+
+	w, closeWriter, err := o.compress(w)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+
+	header := make([]string, len(fields))
+	for i, f := range fields {
+		header[i] = f.header()
+	}
+
+	if err := writeCSVRecord(bw, header); err != nil {
+		return err
+	}
+
+	row := make([]string, len(fields))
+	for _, community := range *c {
+		record, err := community.csvRecord(o)
+		if err != nil {
+			return err
+		}
+
+		for i, f := range fields {
+			row[i] = f.csvValue(community, record)
+		}
+
+		if err := writeCSVRecord(bw, row); err != nil {
+			return err
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+
+	return closeWriter()
+}
+
+func (c NFIPCommunityStatus) csvRecord(o encodeOptions) ([]string, error) {
+	record := make([]string, numStatusColumns)
+
+	record[StatusCID] = fmt.Sprintf("%06d", c.CID)
+	if !o.stripEquals {
+		record[StatusCID] = "=\"" + record[StatusCID] + "\""
+	}
+
+	record[StatusCommunityName] = formatCommunityName(c.CommunityName, c.Flags)
+	record[StatusCounty] = c.County
+	record[StatusFHBMIdentified] = formatShortDate(c.FHBMIdentified)
+	record[StatusFIRMIdentified] = formatShortDate(c.FIRMIdentified)
+	record[StatusCurrEffMapDate] = formatShortDate(c.CurrEffMapDate)
+	record[StatusRegEmerDate] = formatShortDate(c.RegEmerDate)
+	record[StatusTribal] = formatYesNo(c.Tribal)
+	record[StatusCRSEntryDate] = formatShortDate(c.CRSEntryDate)
+	record[StatusCurrEffDate] = formatShortDate(c.CurrEffDate)
+
+	if c.CurClass != nil {
+		record[StatusCurClass] = strconv.Itoa(*c.CurClass)
+	}
+
+	record[StatusPercentDiscSFHA] = c.PercentDiscSFHA
+	record[StausPercentNonSFHA] = c.PercentNonSFHA
+
+	program, ok := programCodes[c.Program]
+	if !ok {
+		return nil, fmt.Errorf("%w %d for community %06d", ErrInvalidProgram, int(c.Program), c.CID)
+	}
+	record[StatusProgram] = program
+	record[StatusParticipatingCommunity] = formatYesNo(c.ParticipatingCommunity)
+
+	return record, nil
+}
+
+// writeCSVRecord quotes fields the same way encoding/csv does, except that
+// fields using the ="..." convention are written as is so they keep working.
+func writeCSVRecord(w *bufio.Writer, record []string) error {
+	for i, field := range record {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+
+		isFormula := strings.HasPrefix(field, "=\"") && strings.HasSuffix(field, "\"") && strings.Count(field, "\"") == 2
+		if (!isFormula && strings.ContainsAny(field, ",\"\r\n")) || strings.HasPrefix(field, " ") {
+			field = "\"" + strings.ReplaceAll(field, "\"", "\"\"") + "\""
+		}
+
+		w.WriteString(field)
+	}
+
+	_, err := w.WriteString("\r\n")
+	return err
+}
+
+// formatCommunityName puts the status markers back on the end of the name.
+func formatCommunityName(name string, flags CommunityFlags) string {
+	if flags.MinimallyFloodProne {
+		name += " (M)"
+	}
+	if flags.NonFloodProne {
+		name += " (NSFHA)"
+	}
+	if flags.Suspended {
+		name += " (S)"
+	}
+	if flags.Withdrawn {
+		name += " (W)"
+	}
+
+	return name
+}
+
+// Dates are written the way the status book has them, with two digit years.
+func formatShortDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format("01/02/06")
+}
+
+func formatYesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+
+	return "No"
+}
@@ -0,0 +1,84 @@
+package data
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestToCSVRoundTrip(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("expected the demo status book to be parsed, got %s", err)
+	}
+
+	// Writing the communities out and parsing them again should give back the same communities
+	var buf bytes.Buffer
+	if err := communities.ToCSV(&buf); err != nil {
+		t.Fatalf("expected the communities to be written, got %s", err)
+	}
+
+	result, err := Unmarshal(&buf)
+	if err != nil {
+		t.Fatalf("expected the written communities to be parsed, got %s", err)
+	}
+
+	if !reflect.DeepEqual(communities, result.Communities) {
+		t.Errorf("expected the communities to be the same after a round trip")
+	}
+
+	if len(result.SchemaChanges) != 0 {
+		t.Errorf("expected the header to be unchanged, got %v", result.SchemaChanges)
+	}
+}
+
+func TestToCSVProgramCodes(t *testing.T) {
+	programs := []ProgramKind{ProgramUnknown, ProgramEmergency, ProgramRegular, ProgramNotParticipating, ProgramSuspended, ProgramWithdrawn}
+
+	communities := make(NFIPCommunityStatuses, len(programs))
+	for i, program := range programs {
+		communities[i] = NFIPCommunityStatus{CID: 10001 + i, CommunityName: "ABBEVILLE, CITY OF", ParticipatingCommunity: true, Program: program}
+	}
+
+	var buf bytes.Buffer
+	if err := communities.ToCSV(&buf); err != nil {
+		t.Fatalf("expected the communities to be written, got %s", err)
+	}
+
+	result, err := Unmarshal(&buf)
+	if err != nil {
+		t.Fatalf("expected the written communities to be parsed, got %s", err)
+	}
+
+	for i, c := range result.Communities {
+		if c.Program != programs[i] {
+			t.Errorf("expected %s to be written and read back, got %s", programs[i], c.Program)
+		}
+	}
+
+	// A program that doesn't have a code can't be written
+	communities = NFIPCommunityStatuses{{CID: 10001, Program: ProgramKind(42)}}
+	if err := communities.ToCSV(&bytes.Buffer{}); !errors.Is(err, ErrInvalidProgram) {
+		t.Errorf("expected a program without a code to fail, got %v", err)
+	}
+}
+
+func TestToCSVQuoting(t *testing.T) {
+	communities := NFIPCommunityStatuses{{CID: 10001, CommunityName: "ABBEVILLE, CITY OF", County: "HENRY COUNTY"}}
+
+	var buf bytes.Buffer
+	communities.ToCSV(&buf)
+	record := strings.Split(buf.String(), "\r\n")[1]
+	if !strings.HasPrefix(record, `="010001","ABBEVILLE, CITY OF",HENRY COUNTY,`) {
+		t.Errorf("expected the CID to keep its equals prefix and the name to be quoted, got %s", record)
+	}
+
+	buf.Reset()
+	communities.ToCSV(&buf, WithoutEqualsPrefix())
+	record = strings.Split(buf.String(), "\r\n")[1]
+	if !strings.HasPrefix(record, `010001,"ABBEVILLE, CITY OF",`) {
+		t.Errorf("expected the CID to be written without the equals prefix, got %s", record)
+	}
+}
@@ -0,0 +1,58 @@
+package data
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+)
+
+// A small sample of communities from the Community Status Book that's built
+// into the binary so the service can be tried out without downloading anything.
+// It isn't kept up to date and shouldn't be used for anything but demos.
+//
+//go:embed snapshot/nation.csv
+var snapshot []byte
+
+func GetDemoNFIPCommunityStatusBook(opts ...ParseOption) (NFIPCommunityStatuses, error) {
+	csvReader := csv.NewReader(bytes.NewReader(snapshot))
+	csvReader.LazyQuotes = true
+	communities, err := unmarshal(csvReader, opts...)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the demo NFIP Community book. Reason: %s", err.Error())
+	}
+
+	return communities, nil
+}
+
+// GetDemoNFIPCommunityRatingSystem builds the CRS ratings out of
+// the CRS columns of the communities in the demo status book.
+func GetDemoNFIPCommunityRatingSystem() (NFIPCommunityRatings, error) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		return nil, err
+	}
+
+	var crs NFIPCommunityRatings
+	for _, c := range communities {
+		if !c.IsCRSParticipant() {
+			continue
+		}
+
+		var cr NFIPCommunityRating
+		cr.State = c.State
+		cr.CommunityNumber = fmt.Sprintf("%06d", c.CID)
+		cr.CommunityName = c.CommunityName
+		cr.CRSEntryDate = formatShortDate(c.CRSEntryDate)
+		cr.CurrentEffectiveDate = formatShortDate(c.CurrEffDate)
+		cr.CurrentClass = strconv.Itoa(*c.CurClass)
+		cr.DiscountForSFHA = c.PercentDiscSFHA
+		cr.DiscountForNonSFHA = c.PercentNonSFHA
+
+		crs = append(crs, cr)
+	}
+
+	return crs, nil
+}
@@ -0,0 +1,27 @@
+package data
+
+import "testing"
+
+func TestGetDemoNFIPCommunityStatusBook(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("expected the demo status book to be parsed, got %s", err)
+	}
+
+	if len(communities) == 0 {
+		t.Fatalf("expected the demo status book to have communities")
+	}
+
+	// Every community in the snapshot should be in a known state
+	for _, c := range communities {
+		if len(c.State) == 0 {
+			t.Errorf("expected CID %06d to have a state", c.CID)
+		}
+	}
+
+	// The CRS ratings are built from the communities in the CRS
+	crs, err := GetDemoNFIPCommunityRatingSystem()
+	if err != nil || len(crs) == 0 {
+		t.Errorf("expected the demo CRS ratings to be built, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+var ErrDownloadFailed = fmt.Errorf("download failed")
+
+// downloadFile downloads the URL to the file, and returns the status it
+// was downloaded with and how big it is. It's written to a temporary file
+// next to the file first, and only renamed to it once all of it is there,
+// so a download that fails part way never leaves a file behind that looks
+// like it's been cached. Anything but a 2xx status is an error.
+//
+// If verify isn't nil, it's called with the temporary file before it's
+// renamed, and the file is thrown away if it returns an error, which is
+// returned as it is.
+func downloadFile(ctx context.Context, url, filename string, verify func(filename string) error) (status int, n int64, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, 0, fmt.Errorf("%w: %s returned %s", ErrDownloadFailed, url, resp.Status)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return resp.StatusCode, 0, err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}()
+
+	n, err = io.Copy(f, resp.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return resp.StatusCode, n, fmt.Errorf("%w: %s: %s", ErrDownloadFailed, url, err)
+	}
+
+	if verify != nil {
+		if err = verify(f.Name()); err != nil {
+			return resp.StatusCode, n, err
+		}
+	}
+
+	return resp.StatusCode, n, os.Rename(f.Name(), filename)
+}
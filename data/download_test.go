@@ -0,0 +1,96 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDownloadFile(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/nation.csv":
+			rw.Write([]byte("CID,Community Name\n"))
+		case "/truncated.csv":
+			// The body is shorter than it says it is, like a download
+			// that's cut off part way
+			rw.Header().Set("Content-Length", "100")
+			rw.Write([]byte("CID,Community"))
+		default:
+			http.Error(rw, "not found", http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "nation.csv")
+
+	status, n, err := downloadFile(context.Background(), ts.URL+"/nation.csv", filename, nil)
+	if err != nil || status != http.StatusOK || n != 19 {
+		t.Fatalf("expected the file to be downloaded, got %d, %d bytes, %v", status, n, err)
+	}
+	if b, _ := os.ReadFile(filename); string(b) != "CID,Community Name\n" {
+		t.Errorf("expected the file to have what was downloaded, got %q", b)
+	}
+
+	for _, path := range []string{"/missing.csv", "/truncated.csv"} {
+		_, _, err := downloadFile(context.Background(), ts.URL+path, filename, nil)
+		if !errors.Is(err, ErrDownloadFailed) {
+			t.Errorf("expected %s to fail, got %v", path, err)
+		}
+
+		// The file that was there is left alone, and nothing else is
+		if b, _ := os.ReadFile(filename); string(b) != "CID,Community Name\n" {
+			t.Errorf("expected %s to leave the file alone, got %q", path, b)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+			t.Errorf("expected %s to leave nothing behind, got %d files", path, len(entries))
+		}
+	}
+
+	// A download that's all there but isn't what was expected is thrown away too
+	errNotABook := errors.New("not a status book")
+	_, _, err = downloadFile(context.Background(), ts.URL+"/nation.csv", filename, func(download string) error {
+		if b, _ := os.ReadFile(download); string(b) != "CID,Community Name\n" {
+			t.Errorf("expected to verify what was downloaded, got %q", b)
+		}
+		return errNotABook
+	})
+	if !errors.Is(err, errNotABook) {
+		t.Errorf("expected the verify error, got %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("expected a download that didn't verify to leave nothing behind, got %d files", len(entries))
+	}
+
+	if err := verifyNFIPCommunityStatusBook(filename); !errors.Is(err, ErrDownloadFailed) {
+		t.Errorf("expected a file without any communities not to verify, got %v", err)
+	}
+
+	book := filepath.Join(dir, "book.csv")
+	os.WriteFile(book, snapshot, 0644)
+	if err := verifyNFIPCommunityStatusBook(book); err != nil {
+		t.Errorf("expected the snapshot to verify, got %s", err)
+	}
+}
+
+func TestNFIPCommunityStatusBookDownloaded(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	if _, err := NFIPCommunityStatusBookDownloaded(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a status book that isn't there to be an error, got %v", err)
+	}
+
+	downloaded := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	os.WriteFile(NFIPCommunityStatusBookFilename, snapshot, 0644)
+	os.Chtimes(NFIPCommunityStatusBookFilename, downloaded, downloaded)
+
+	if when, err := NFIPCommunityStatusBookDownloaded(); err != nil || !when.Equal(downloaded) {
+		t.Errorf("expected %s, got %s %v", downloaded, when, err)
+	}
+}
@@ -0,0 +1,192 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DuplicatePolicy is what to do with communities that share a CID.
+type DuplicatePolicy int
+
+const (
+	// Keep every community, even if it shares a CID with another one.
+	DuplicatesKeepAll DuplicatePolicy = iota
+
+	// Keep the first community with each CID.
+	DuplicatesKeepFirst
+
+	// Keep the last community with each CID, where it was first seen.
+	DuplicatesKeepLast
+
+	// Keep the first community with each CID, filling in any
+	// fields that it's missing from the later ones.
+	DuplicatesMerge
+
+	// Fail the parse when any CID shows up more than once.
+	DuplicatesError
+)
+
+var ErrDuplicateCID = fmt.Errorf("duplicate CID")
+var ErrInvalidDuplicatePolicy = fmt.Errorf("invalid duplicate policy")
+
+var duplicatePolicyNames = map[DuplicatePolicy]string{
+	DuplicatesKeepAll:   "keep-all",
+	DuplicatesKeepFirst: "keep-first",
+	DuplicatesKeepLast:  "keep-last",
+	DuplicatesMerge:     "merge",
+	DuplicatesError:     "error",
+}
+
+func (p DuplicatePolicy) String() string {
+	return duplicatePolicyNames[p]
+}
+
+// ParseDuplicatePolicy converts a policy's name (e.g. "keep-first") to a DuplicatePolicy.
+func ParseDuplicatePolicy(s string) (DuplicatePolicy, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for policy, name := range duplicatePolicyNames {
+		if name == s {
+			return policy, nil
+		}
+	}
+
+	return DuplicatesKeepAll, fmt.Errorf("%w: %s", ErrInvalidDuplicatePolicy, s)
+}
+
+// WithDuplicatePolicy sets what's done with communities that share a CID.
+// It only applies to Unmarshal since the others need to see the whole file.
+func WithDuplicatePolicy(policy DuplicatePolicy) ParseOption {
+	return func(o *parseOptions) {
+		o.duplicatePolicy = policy
+	}
+}
+
+// A DuplicateCID is a CID shared by more than one community, along with
+// where each of those communities is. When this comes from parsing the
+// status book, an index of i means the community was on line i+2.
+type DuplicateCID struct {
+	CID     int   `json:"cid"`
+	Indexes []int `json:"indexes"`
+}
+
+// Duplicates finds every CID that's shared by more than one community.
+func (c NFIPCommunityStatuses) Duplicates() []DuplicateCID {
+	indexes := make(map[int][]int)
+	for i, community := range c {
+		indexes[community.CID] = append(indexes[community.CID], i)
+	}
+
+	var duplicates []DuplicateCID
+	for cid, is := range indexes {
+		if len(is) > 1 {
+			duplicates = append(duplicates, DuplicateCID{cid, is})
+		}
+	}
+
+	// Report them in the order that they first show up
+	sort.Slice(duplicates, func(i, j int) bool {
+		return duplicates[i].Indexes[0] < duplicates[j].Indexes[0]
+	})
+
+	return duplicates
+}
+
+// applyDuplicatePolicy removes the duplicate communities according to the policy.
+func applyDuplicatePolicy(c NFIPCommunityStatuses, duplicates []DuplicateCID, policy DuplicatePolicy) (NFIPCommunityStatuses, error) {
+	if len(duplicates) == 0 || policy == DuplicatesKeepAll {
+		return c, nil
+	}
+
+	if policy == DuplicatesError {
+		var cids []string
+		for _, d := range duplicates {
+			cids = append(cids, fmt.Sprintf("%06d (lines %s)", d.CID, joinLines(d.Indexes)))
+		}
+
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateCID, strings.Join(cids, ", "))
+	}
+
+	// Work out what goes in place of the first community with
+	// each CID and which of the later ones need to be dropped
+	drop := make(map[int]bool)
+	for _, d := range duplicates {
+		first := d.Indexes[0]
+
+		for _, i := range d.Indexes[1:] {
+			switch policy {
+			case DuplicatesKeepLast:
+				c[first] = c[i]
+			case DuplicatesMerge:
+				mergeCommunity(&c[first], c[i])
+			}
+
+			drop[i] = true
+		}
+	}
+
+	kept := make(NFIPCommunityStatuses, 0, len(c)-len(drop))
+	for i, community := range c {
+		if !drop[i] {
+			kept = append(kept, community)
+		}
+	}
+
+	return kept, nil
+}
+
+// mergeCommunity fills in any of the fields that dst is missing from src.
+func mergeCommunity(dst *NFIPCommunityStatus, src NFIPCommunityStatus) {
+	if len(dst.CommunityName) == 0 {
+		dst.CommunityName, dst.Flags = src.CommunityName, src.Flags
+		dst.Kind, dst.BaseName, dst.Type = src.Kind, src.BaseName, src.Type
+	}
+	if len(dst.County) == 0 {
+		dst.County = src.County
+	}
+	if len(dst.State) == 0 {
+		dst.State, dst.StateFIPS = src.State, src.StateFIPS
+	}
+	if dst.FHBMIdentified == nil {
+		dst.FHBMIdentified = src.FHBMIdentified
+	}
+	if dst.FIRMIdentified == nil {
+		dst.FIRMIdentified = src.FIRMIdentified
+	}
+	if dst.CurrEffMapDate == nil {
+		dst.CurrEffMapDate = src.CurrEffMapDate
+	}
+	if dst.RegEmerDate == nil {
+		dst.RegEmerDate = src.RegEmerDate
+	}
+	if dst.CRSEntryDate == nil {
+		dst.CRSEntryDate = src.CRSEntryDate
+	}
+	if dst.CurrEffDate == nil {
+		dst.CurrEffDate = src.CurrEffDate
+	}
+	if dst.CurClass == nil {
+		dst.CurClass = src.CurClass
+	}
+	if len(dst.PercentDiscSFHA) == 0 {
+		dst.PercentDiscSFHA = src.PercentDiscSFHA
+	}
+	if len(dst.PercentNonSFHA) == 0 {
+		dst.PercentNonSFHA = src.PercentNonSFHA
+	}
+	if dst.Program == ProgramUnknown {
+		dst.Program = src.Program
+	}
+
+	dst.Tribal = dst.Tribal || src.Tribal
+	dst.ParticipatingCommunity = dst.ParticipatingCommunity || src.ParticipatingCommunity
+}
+
+func joinLines(indexes []int) string {
+	lines := make([]string, len(indexes))
+	for i, index := range indexes {
+		lines[i] = fmt.Sprint(index + 2)
+	}
+
+	return strings.Join(lines, ", ")
+}
@@ -0,0 +1,66 @@
+package data
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var duplicateRecords = []string{
+	`="170604","SPRINGFIELD, CITY OF",SANGAMON COUNTY,,,,,No,,,,,,R,Yes`,
+	`="170074","CHICAGO, CITY OF",COOK COUNTY,,,,,No,,,,,,R,Yes`,
+	`="170604","SPRINGFIELD, CITY OF",,05/31/74,03/16/81,01/19/18,03/16/81,No,,,,,,R,Yes`,
+}
+
+func unmarshalDuplicates(t *testing.T, policy DuplicatePolicy) (ParseResult, error) {
+	t.Helper()
+	file := testHeader + "\n" + strings.Join(duplicateRecords, "\n")
+	return Unmarshal(strings.NewReader(file), WithDuplicatePolicy(policy))
+}
+
+func TestDuplicatePolicies(t *testing.T) {
+	// Every community is kept by default, but the duplicates are still reported
+	result, err := unmarshalDuplicates(t, DuplicatesKeepAll)
+	if err != nil || len(result.Communities) != 3 {
+		t.Fatalf("expected every community to be kept, got %d (%v)", len(result.Communities), err)
+	}
+
+	if len(result.Duplicates) != 1 || result.Duplicates[0].CID != 170604 || len(result.Duplicates[0].Indexes) != 2 {
+		t.Errorf("expected CID 170604 to be reported as a duplicate, got %v", result.Duplicates)
+	}
+
+	// Keeping the first drops the later one's dates
+	result, _ = unmarshalDuplicates(t, DuplicatesKeepFirst)
+	if len(result.Communities) != 2 || result.Communities[0].County != "SANGAMON COUNTY" || result.Communities[0].FIRMIdentified != nil {
+		t.Errorf("expected the first Springfield to be kept, got %+v", result.Communities)
+	}
+
+	// Keeping the last keeps it in the first one's place
+	result, _ = unmarshalDuplicates(t, DuplicatesKeepLast)
+	if len(result.Communities) != 2 || result.Communities[0].County != "" || result.Communities[0].FIRMIdentified == nil {
+		t.Errorf("expected the last Springfield to be kept, got %+v", result.Communities)
+	}
+
+	// Merging keeps the county from the first and the dates from the last
+	result, _ = unmarshalDuplicates(t, DuplicatesMerge)
+	if len(result.Communities) != 2 || result.Communities[0].County != "SANGAMON COUNTY" || result.Communities[0].FIRMIdentified == nil {
+		t.Errorf("expected the Springfields to be merged, got %+v", result.Communities)
+	}
+
+	_, err = unmarshalDuplicates(t, DuplicatesError)
+	if !errors.Is(err, ErrDuplicateCID) {
+		t.Errorf("expected a duplicate CID error, got %v", err)
+	}
+}
+
+func TestParseDuplicatePolicy(t *testing.T) {
+	policy, err := ParseDuplicatePolicy("Keep-Last")
+	if err != nil || policy != DuplicatesKeepLast {
+		t.Errorf("expected \"Keep-Last\" to be parsed to %s", DuplicatesKeepLast)
+	}
+
+	_, err = ParseDuplicatePolicy("keep-both")
+	if !errors.Is(err, ErrInvalidDuplicatePolicy) {
+		t.Errorf("expected \"keep-both\" to not be a policy")
+	}
+}
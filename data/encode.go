@@ -0,0 +1,33 @@
+package data
+
+// An EncodeOption changes how communities are written out by the encoders.
+type EncodeOption func(*encodeOptions)
+
+type encodeOptions struct {
+	stripEquals   bool
+	sheetPerState bool
+	fields        []string
+	sqlTable      string
+	sqlDialect    SQLDialect
+	sqlCopy       bool
+	gzip          bool
+	gzipLevel     int
+}
+
+// WithoutEqualsPrefix writes CIDs as plain numbers instead of the status
+// book's ="012345" convention (which keeps spreadsheets from dropping the
+// leading zero).
+func WithoutEqualsPrefix() EncodeOption {
+	return func(o *encodeOptions) {
+		o.stripEquals = true
+	}
+}
+
+func newEncodeOptions(opts []EncodeOption) encodeOptions {
+	var o encodeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
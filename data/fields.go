@@ -0,0 +1,275 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrUnknownField = fmt.Errorf("unknown field")
+
+// noStatusColumn is the column of the fields that aren't in the status
+// book, like the state, which comes from the CID.
+const noStatusColumn = -1
+
+// A statusField is one of a community's fields, named the same as it is in
+// JSON, along with its column in the status book and the column it has in
+// tables.
+type statusField struct {
+	name   string
+	column int
+	table  Column
+}
+
+// statusFields are all of the fields that can be picked with WithFields,
+// in the same order as they are in JSON.
+var statusFields = []statusField{
+	{"cid", StatusCID, CIDColumn},
+	{"community_name", StatusCommunityName, NameColumn},
+	{"flags", noStatusColumn, FlagsColumn},
+	{"kind", noStatusColumn, Column{Header: "KIND", value: func(c NFIPCommunityStatus) string {
+		return string(c.Kind)
+	}}},
+	{"base_name", noStatusColumn, Column{Header: "BASE NAME", value: func(c NFIPCommunityStatus) string {
+		return c.BaseName
+	}}},
+	{"type", noStatusColumn, Column{Header: "TYPE", value: func(c NFIPCommunityStatus) string {
+		return string(c.Type)
+	}}},
+	{"county", StatusCounty, CountyColumn},
+	{"state", noStatusColumn, StateColumn},
+	{"state_fips", noStatusColumn, Column{Header: "STATE FIPS", value: func(c NFIPCommunityStatus) string {
+		if c.StateFIPS == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%02d", c.StateFIPS)
+	}}},
+	{"fhbm_identified", StatusFHBMIdentified, dateColumn("FHBM IDENTIFIED", StatusFHBMIdentified)},
+	{"firm_identified", StatusFIRMIdentified, dateColumn("FIRM IDENTIFIED", StatusFIRMIdentified)},
+	{"curr_eff_map_date", StatusCurrEffMapDate, MapDateColumn},
+	{"reg_emer_date", StatusRegEmerDate, dateColumn("REG-EMER DATE", StatusRegEmerDate)},
+	{"tribal", StatusTribal, TribalColumn},
+	{"crs_entry_date", StatusCRSEntryDate, dateColumn("CRS ENTRY DATE", StatusCRSEntryDate)},
+	{"curr_eff_date", StatusCurrEffDate, dateColumn("CURR EFF DATE", StatusCurrEffDate)},
+	{"cur_class", StatusCurClass, ClassColumn},
+	{"percent_disc_sfha", StatusPercentDiscSFHA, DiscountColumn},
+	{"percent_non_sfha", StausPercentNonSFHA, Column{Header: "NON-SFHA DISCOUNT", value: func(c NFIPCommunityStatus) string {
+		return c.PercentNonSFHA
+	}}},
+	{"program", StatusProgram, ProgramColumn},
+	{"participating_community", StatusParticipatingCommunity, ParticipatingColumn},
+}
+
+// FlagsColumn has the status book's markers for the community's
+// flags, the same as they are after its name in the status book.
+var FlagsColumn = Column{Header: "FLAGS", value: func(c NFIPCommunityStatus) string {
+	return strings.TrimSpace(formatCommunityName("", c.Flags))
+}}
+
+func dateColumn(header string, column int) Column {
+	return Column{Header: header, value: func(c NFIPCommunityStatus) string {
+		t, _ := c.statusDate(column)
+		return formatJSONDate(t)
+	}}
+}
+
+// WithFields only writes the fields with these names, in this order, rather
+// than all of them. They're named the same as they are in JSON. Fields that
+// aren't in the status book, like the state, can be picked for CSVs and
+// workbooks too. Tables pick their columns with FieldColumns instead.
+//
+//	cb.ToCSV(w, data.WithFields("cid", "community_name", "state", "cur_class"))
+func WithFields(fields ...string) EncodeOption {
+	return func(o *encodeOptions) {
+		o.fields = fields
+	}
+}
+
+// FieldColumns are the table columns for the fields with these names,
+// for showing the same fields in a table that WithFields picks.
+func FieldColumns(fields ...string) ([]Column, error) {
+	picked, err := pickFields(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]Column, len(picked))
+	for i, f := range picked {
+		columns[i] = f.table
+	}
+
+	return columns, nil
+}
+
+// FieldNames are the names of all of the fields that can be picked with
+// WithFields, in the same order as they are in JSON.
+func FieldNames() []string {
+	names := make([]string, len(statusFields))
+	for i, f := range statusFields {
+		names[i] = f.name
+	}
+
+	return names
+}
+
+func pickFields(names []string) ([]statusField, error) {
+	fields := make([]statusField, len(names))
+	for i, name := range names {
+		f, ok := findField(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownField, name)
+		}
+		fields[i] = f
+	}
+
+	return fields, nil
+}
+
+func findField(name string) (statusField, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, f := range statusFields {
+		if f.name == name {
+			return f, true
+		}
+	}
+
+	return statusField{}, false
+}
+
+// bookFields are the fields that go in CSVs and workbooks: the ones that
+// were picked, or the status book's own columns if none were.
+func (o encodeOptions) bookFields() ([]statusField, error) {
+	if len(o.fields) > 0 {
+		return pickFields(o.fields)
+	}
+
+	var fields []statusField
+	for _, f := range statusFields {
+		if f.column != noStatusColumn {
+			fields = append(fields, f)
+		}
+	}
+
+	return fields, nil
+}
+
+// What the fields that aren't in the status book are called in CSVs and workbooks.
+var extraFieldHeaders = map[string]string{
+	"flags":      "Flags",
+	"kind":       "Kind",
+	"base_name":  "Base Name",
+	"type":       "Type",
+	"state":      "State",
+	"state_fips": "State FIPS",
+}
+
+// header is what the field is called in CSVs and workbooks. Fields in the
+// status book go by the same name as they do there.
+func (f statusField) header() string {
+	if f.column != noStatusColumn {
+		return statusColumnNames[f.column][0]
+	}
+
+	return extraFieldHeaders[f.name]
+}
+
+// csvValue is the field's value in the record that the community has in
+// the status book, or the same as in a table if it isn't in the status book.
+func (f statusField) csvValue(c NFIPCommunityStatus, record []string) string {
+	if f.column != noStatusColumn {
+		return record[f.column]
+	}
+
+	return f.table.value(c)
+}
+
+// A ProjectedStatus is a community with only some of its fields. It's
+// written as JSON and YAML with just those fields, in the order they were
+// picked.
+type ProjectedStatus struct {
+	community NFIPCommunityStatus
+	fields    []statusField
+}
+
+// Project is the communities with only the fields with these names, in
+// this order. They're named the same as with WithFields.
+func (c NFIPCommunityStatuses) Project(fields ...string) ([]ProjectedStatus, error) {
+	picked, err := pickFields(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	projected := make([]ProjectedStatus, len(c))
+	for i, community := range c {
+		projected[i] = ProjectedStatus{community, picked}
+	}
+
+	return projected, nil
+}
+
+func (p ProjectedStatus) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(p.community)
+	if err != nil {
+		return nil, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, err
+	}
+
+	// Fields that are left out when they're empty stay left out
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for _, f := range p.fields {
+		value, ok := all[f.name]
+		if !ok {
+			continue
+		}
+
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(strconv.Quote(f.name))
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// MarshalYAML picks the fields out of the community's YAML, so that they're
+// written the same way they are without picking them.
+func (p ProjectedStatus) MarshalYAML() (interface{}, error) {
+	var all yaml.Node
+	if err := all.Encode(p.community); err != nil {
+		return nil, err
+	}
+
+	picked := &yaml.Node{Kind: yaml.MappingNode}
+	for _, f := range p.fields {
+		for i := 0; i+1 < len(all.Content); i += 2 {
+			if all.Content[i].Value == f.name {
+				picked.Content = append(picked.Content, all.Content[i], all.Content[i+1])
+				break
+			}
+		}
+	}
+
+	return picked, nil
+}
+
+// project picks the fields out of the communities for JSON and YAML if any
+// were picked.
+func (c *NFIPCommunityStatuses) project(o encodeOptions) (interface{}, error) {
+	if len(o.fields) == 0 {
+		return c, nil
+	}
+
+	return c.Project(o.fields...)
+}
@@ -0,0 +1,142 @@
+package data
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Every field in JSON can be picked, and they're named the same.
+func TestStatusFieldsMatchJSON(t *testing.T) {
+	typ := reflect.TypeOf(statusJSON{})
+	if typ.NumField() != len(statusFields) {
+		t.Fatalf("expected %d fields, got %d", typ.NumField(), len(statusFields))
+	}
+
+	for i, f := range statusFields {
+		if name := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]; name != f.name {
+			t.Errorf("expected field %d to be %s, got %s", i, name, f.name)
+		}
+	}
+}
+
+func TestToJSONWithFields(t *testing.T) {
+	class := 7
+	communities := NFIPCommunityStatuses{
+		{CID: 480287, CommunityName: "HOUSTON, CITY OF", State: "TX", CurClass: &class},
+		{CID: 170604, CommunityName: "SPRINGFIELD, CITY OF", State: "IL"},
+	}
+
+	var buf bytes.Buffer
+	if err := communities.ToJSON(&buf, WithFields("state", "cid", "cur_class")); err != nil {
+		t.Fatalf("expected the fields to be written, got %s", err)
+	}
+
+	expected := `[{"state":"TX","cid":480287,"cur_class":7},{"state":"IL","cid":170604}]` + "\n"
+	if buf.String() != expected {
+		t.Errorf("expected %s, got %s", expected, buf.String())
+	}
+
+	var streamed bytes.Buffer
+	communities.StreamJSON(&streamed, WithFields("state", "cid", "cur_class"))
+	if streamed.String() != expected {
+		t.Errorf("expected the streamed JSON to be %s, got %s", expected, streamed.String())
+	}
+}
+
+func TestToNDJSONAndYAMLWithFields(t *testing.T) {
+	class := 7
+	communities := NFIPCommunityStatuses{
+		{CID: 480287, CommunityName: "HOUSTON, CITY OF", State: "TX", CurClass: &class},
+		{CID: 170604, CommunityName: "SPRINGFIELD, CITY OF", State: "IL"},
+	}
+
+	var buf bytes.Buffer
+	if err := communities.ToNDJSON(&buf, WithFields("cid", "state", "cur_class")); err != nil {
+		t.Fatalf("expected the fields to be written as NDJSON, got %s", err)
+	}
+
+	expected := `{"cid":480287,"state":"TX","cur_class":7}` + "\n" +
+		`{"cid":170604,"state":"IL"}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := communities.ToYAML(&buf, WithFields("community_name", "cid")); err != nil {
+		t.Fatalf("expected the fields to be written as YAML, got %s", err)
+	}
+
+	expected = "- community_name: HOUSTON, CITY OF\n" +
+		"  cid: 480287\n" +
+		"- community_name: SPRINGFIELD, CITY OF\n" +
+		"  cid: 170604\n"
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+}
+
+func TestToCSVWithFields(t *testing.T) {
+	class := 7
+	communities := NFIPCommunityStatuses{
+		{CID: 480287, CommunityName: "HOUSTON, CITY OF", State: "TX", StateFIPS: 48, CurClass: &class},
+	}
+
+	var buf bytes.Buffer
+	if err := communities.ToCSV(&buf, WithFields("cid", "community_name", "state", "state_fips", "cur_class")); err != nil {
+		t.Fatalf("expected the fields to be written, got %s", err)
+	}
+
+	expected := "CID,Community Name,State,State FIPS,Curr Class\r\n" +
+		"=\"480287\",\"HOUSTON, CITY OF\",TX,48,7\r\n"
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+}
+
+func TestFieldColumns(t *testing.T) {
+	communities := NFIPCommunityStatuses{
+		{CID: 480287, CommunityName: "HOUSTON, CITY OF", State: "TX", Flags: CommunityFlags{Suspended: true}},
+	}
+
+	columns, err := FieldColumns("cid", "state", "flags")
+	if err != nil {
+		t.Fatalf("expected the columns, got %s", err)
+	}
+
+	var buf bytes.Buffer
+	communities.ToTable(&buf, columns...)
+
+	expected := "CID     STATE  FLAGS\n" +
+		"480287  TX     (S)\n"
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+}
+
+func TestUnknownFields(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+
+	var buf bytes.Buffer
+	if err := communities.ToJSON(&buf, WithFields("cid", "population")); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("expected an unknown field for JSON, got %v", err)
+	}
+
+	if err := communities.ToNDJSON(&buf, WithFields("population")); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("expected an unknown field for NDJSON, got %v", err)
+	}
+
+	if err := communities.ToCSV(&buf, WithFields("population")); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("expected an unknown field for CSV, got %v", err)
+	}
+
+	if _, err := communities.toWorkbook(newEncodeOptions([]EncodeOption{WithFields("population")})); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("expected an unknown field for XLSX, got %v", err)
+	}
+
+	if _, err := FieldColumns("population"); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("expected an unknown field for tables, got %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package data
+
+import (
+	"strings"
+	"time"
+
+	"nfip-community-book/states"
+)
+
+// A Filter decides whether a community should be kept. Filters can be
+// combined with All, Any and Not to build up more complicated queries.
+type Filter func(NFIPCommunityStatus) bool
+
+// Apply keeps the communities that pass every one of the filters.
+func (c NFIPCommunityStatuses) Apply(filters ...Filter) *NFIPCommunityStatuses {
+	return c.searchBy(All(filters...))
+}
+
+// FilterFunc keeps the communities that keep returns true for. It's the
+// same as Apply with a single filter, for conditions that are easier to
+// write out than to build up from the filters here, e.g.
+//
+//	old := time.Now().AddDate(-10, 0, 0)
+//	c.FilterFunc(func(community NFIPCommunityStatus) bool {
+//		return community.IsCRSParticipant() && *community.CurClass <= 6 &&
+//			community.CurrEffMapDate != nil && community.CurrEffMapDate.Before(old)
+//	})
+func (c NFIPCommunityStatuses) FilterFunc(keep func(NFIPCommunityStatus) bool) NFIPCommunityStatuses {
+	return *c.searchBy(keep)
+}
+
+// Map turns each community into something else, in the same order.
+func Map[T any](c NFIPCommunityStatuses, fn func(NFIPCommunityStatus) T) []T {
+	mapped := make([]T, len(c))
+	for i, community := range c {
+		mapped[i] = fn(community)
+	}
+
+	return mapped
+}
+
+// Reduce combines the communities into one value, starting with
+// initial and passing what it's built up so far along with each
+// community to fn.
+func Reduce[T any](c NFIPCommunityStatuses, initial T, fn func(T, NFIPCommunityStatus) T) T {
+	acc := initial
+	for _, community := range c {
+		acc = fn(acc, community)
+	}
+
+	return acc
+}
+
+// All passes communities that pass every filter.
+func All(filters ...Filter) Filter {
+	return func(c NFIPCommunityStatus) bool {
+		for _, f := range filters {
+			if !f(c) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// Any passes communities that pass at least one of the filters.
+func Any(filters ...Filter) Filter {
+	return func(c NFIPCommunityStatus) bool {
+		for _, f := range filters {
+			if f(c) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+func Not(f Filter) Filter {
+	return func(c NFIPCommunityStatus) bool {
+		return !f(c)
+	}
+}
+
+// State passes communities in the state with the abbreviation.
+func State(abbreviation string) Filter {
+	abbreviation = strings.ToUpper(strings.TrimSpace(abbreviation))
+	return func(c NFIPCommunityStatus) bool {
+		return c.State == abbreviation
+	}
+}
+
+// NameContains passes communities whose name contains the term, ignoring case.
+func NameContains(term string) Filter {
+	term = foldString(term)
+	return func(c NFIPCommunityStatus) bool {
+		return strings.Contains(c.searchKeys().lowerName, term)
+	}
+}
+
+// CountyContains passes communities whose county contains the term, ignoring case.
+func CountyContains(term string) Filter {
+	term = foldString(term)
+	return func(c NFIPCommunityStatus) bool {
+		return strings.Contains(c.searchKeys().lowerCounty, term)
+	}
+}
+
+// ByState passes communities in the state, which can be given
+// by its abbreviation ("FL"), its name ("Florida") or its FIPS
+// code ("12"). States that don't exist never pass.
+func ByState(state string) Filter {
+	s, ok := states.Lookup(state)
+	return func(c NFIPCommunityStatus) bool {
+		return ok && c.StateFIPS == s.FIPS
+	}
+}
+
+func Participating(participating bool) Filter {
+	return func(c NFIPCommunityStatus) bool {
+		return c.ParticipatingCommunity == participating
+	}
+}
+
+func Tribal(tribal bool) Filter {
+	return func(c NFIPCommunityStatus) bool {
+		return c.Tribal == tribal
+	}
+}
+
+func InProgram(program ProgramKind) Filter {
+	return func(c NFIPCommunityStatus) bool {
+		return c.Program == program
+	}
+}
+
+// CRSClassAtMost passes CRS communities with a class that's at least as
+// good as the class. Lower classes are better, so CRSClassAtMost(7) passes
+// classes 1 through 7. Communities that aren't in the CRS never pass.
+func CRSClassAtMost(class int) Filter {
+	return func(c NFIPCommunityStatus) bool {
+		return c.IsCRSParticipant() && *c.CurClass <= class
+	}
+}
+
+// A DateField gets one of a community's dates, which is nil if it's missing.
+type DateField func(NFIPCommunityStatus) *time.Time
+
+var (
+	FHBMIdentifiedField DateField = func(c NFIPCommunityStatus) *time.Time { return c.FHBMIdentified }
+	FIRMIdentifiedField DateField = func(c NFIPCommunityStatus) *time.Time { return c.FIRMIdentified }
+	CurrEffMapDateField DateField = func(c NFIPCommunityStatus) *time.Time { return c.CurrEffMapDate }
+	RegEmerDateField    DateField = func(c NFIPCommunityStatus) *time.Time { return c.RegEmerDate }
+	CRSEntryDateField   DateField = func(c NFIPCommunityStatus) *time.Time { return c.CRSEntryDate }
+	CurrEffDateField    DateField = func(c NFIPCommunityStatus) *time.Time { return c.CurrEffDate }
+)
+
+// DateBefore passes communities whose date is before t. Communities
+// missing the date never pass any of the date filters except DateMissing,
+// so to keep them too, use Any(DateMissing(field), DateBefore(field, t)).
+func DateBefore(field DateField, t time.Time) Filter {
+	return func(c NFIPCommunityStatus) bool {
+		d := field(c)
+		return d != nil && d.Before(t)
+	}
+}
+
+func DateAfter(field DateField, t time.Time) Filter {
+	return func(c NFIPCommunityStatus) bool {
+		d := field(c)
+		return d != nil && d.After(t)
+	}
+}
+
+// DateBetween passes communities whose date is from from to to, including both.
+func DateBetween(field DateField, from, to time.Time) Filter {
+	return func(c NFIPCommunityStatus) bool {
+		d := field(c)
+		return d != nil && !d.Before(from) && !d.After(to)
+	}
+}
+
+// DateMissing passes communities that don't have the date.
+func DateMissing(field DateField) Filter {
+	return func(c NFIPCommunityStatus) bool {
+		return field(c) == nil
+	}
+}
+
+// FIRMIdentifiedAfter passes communities whose initial FIRM was identified
+// after the date. Communities without a FIRM never pass.
+func FIRMIdentifiedAfter(date time.Time) Filter {
+	return DateAfter(FIRMIdentifiedField, date)
+}
+
+func FIRMIdentifiedBefore(date time.Time) Filter {
+	return DateBefore(FIRMIdentifiedField, date)
+}
+
+// CurrentMapEffectiveBetween passes communities whose current map became
+// effective between the dates, including both. For the maps that became
+// effective in the last 12 months:
+//
+//	now := time.Now()
+//	CurrentMapEffectiveBetween(now.AddDate(-1, 0, 0), now)
+func CurrentMapEffectiveBetween(from, to time.Time) Filter {
+	return DateBetween(CurrEffMapDateField, from, to)
+}
+
+// RegEmerBefore passes communities that entered the regular or emergency
+// program before the date. Communities that never entered never pass.
+func RegEmerBefore(date time.Time) Filter {
+	return DateBefore(RegEmerDateField, date)
+}
+
+func RegEmerAfter(date time.Time) Filter {
+	return DateAfter(RegEmerDateField, date)
+}
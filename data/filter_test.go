@@ -0,0 +1,159 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilters(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	// No filters keeps everything
+	if matches := *communities.Apply(); len(matches) != len(communities) {
+		t.Errorf("expected every community to be kept, got %d", len(matches))
+	}
+
+	if matches := *communities.Apply(State("fl")); len(matches) != 3 {
+		t.Errorf("expected 3 communities in Florida, got %d", len(matches))
+	}
+
+	if matches := *communities.Apply(State("FL"), Tribal(true)); len(matches) != 1 || matches[0].CID != 120678 {
+		t.Errorf("expected only the Seminole Tribe, got %v", matches)
+	}
+
+	// States can be given however they're written
+	for _, state := range []string{"FL", "Florida", "12"} {
+		if matches := *communities.Apply(ByState(state)); len(matches) != 3 {
+			t.Errorf("expected 3 communities in \"%s\", got %d", state, len(matches))
+		}
+	}
+
+	if matches := *communities.Apply(ByState("Atlantis")); len(matches) != 0 {
+		t.Errorf("expected no communities in a state that doesn't exist, got %d", len(matches))
+	}
+
+	for _, c := range *communities.Apply(CRSClassAtMost(5)) {
+		if c.CurClass == nil || *c.CurClass > 5 {
+			t.Errorf("expected only CRS classes 1 to 5, got %v", c.CurClass)
+		}
+	}
+
+	after := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.Local)
+	for _, c := range *communities.Apply(FIRMIdentifiedAfter(after)) {
+		if c.FIRMIdentified == nil || !c.FIRMIdentified.After(after) {
+			t.Errorf("expected only FIRMs identified after 2000, got %v", c.FIRMIdentified)
+		}
+	}
+
+	// Any and Not can be combined
+	notParticipating := *communities.Apply(Participating(false))
+	either := *communities.Apply(Any(State("TX"), State("IL")))
+	if len(either) != 6 {
+		t.Errorf("expected 6 communities in Texas or Illinois, got %d", len(either))
+	}
+
+	if matches := *communities.Apply(Not(Participating(false))); len(matches)+len(notParticipating) != len(communities) {
+		t.Errorf("expected Not to keep every community the filter didn't")
+	}
+}
+
+func TestDateFilters(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	from := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2020, time.December, 31, 0, 0, 0, 0, time.Local)
+
+	matches := *communities.Apply(CurrentMapEffectiveBetween(from, to))
+	if len(matches) == 0 {
+		t.Fatalf("expected maps that became effective in 2020")
+	}
+
+	for _, c := range matches {
+		if c.CurrEffMapDate == nil || c.CurrEffMapDate.Year() != 2020 {
+			t.Errorf("expected only maps that became effective in 2020, got %v", c.CurrEffMapDate)
+		}
+	}
+
+	// Both ends are included
+	mobile := *communities.SearchByCID(15000)
+	june := time.Date(2020, time.June, 5, 0, 0, 0, 0, time.Local)
+	if len(*mobile.Apply(CurrentMapEffectiveBetween(june, june))) != 1 {
+		t.Errorf("expected Mobile's map to be effective from June 5th to June 5th")
+	}
+
+	// Missing dates never pass unless they're asked for
+	rioBravo := *communities.SearchByCID(481658)
+	if len(*rioBravo.Apply(RegEmerBefore(to))) != 0 || len(*rioBravo.Apply(RegEmerAfter(to))) != 0 {
+		t.Errorf("expected a community that isn't in the NFIP to not pass a date filter")
+	}
+
+	if len(*rioBravo.Apply(Any(DateMissing(RegEmerDateField), RegEmerBefore(to)))) != 1 {
+		t.Errorf("expected a community that isn't in the NFIP to be missing its date")
+	}
+
+	before := *communities.Apply(RegEmerBefore(from))
+	missing := *communities.Apply(DateMissing(RegEmerDateField))
+	after := *communities.Apply(Not(RegEmerBefore(from)), Not(DateMissing(RegEmerDateField)))
+	if len(before)+len(missing)+len(after) != len(communities) {
+		t.Errorf("expected every community to be before, after or missing its date")
+	}
+}
+
+func TestFilterFunc(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	old := time.Date(2012, time.January, 1, 0, 0, 0, 0, time.Local)
+	matches := communities.FilterFunc(func(c NFIPCommunityStatus) bool {
+		return c.IsCRSParticipant() && *c.CurClass <= 6 && c.CurrEffMapDate != nil && c.CurrEffMapDate.Before(old)
+	})
+
+	if len(matches) == 0 {
+		t.Fatalf("expected some CRS class 6 or better communities with old maps")
+	}
+
+	for _, c := range matches {
+		if *c.CurClass > 6 || !c.CurrEffMapDate.Before(old) {
+			t.Errorf("expected class 6 or better with a map before 2012, got %d and %v", *c.CurClass, c.CurrEffMapDate)
+		}
+	}
+
+	if none := communities.FilterFunc(func(NFIPCommunityStatus) bool { return false }); len(none) != 0 {
+		t.Errorf("expected nothing to be kept, got %d", len(none))
+	}
+}
+
+func TestMapReduce(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	cids := Map(communities, func(c NFIPCommunityStatus) int { return c.CID })
+	if len(cids) != len(communities) || cids[0] != communities[0].CID {
+		t.Errorf("expected a CID for every community in order, got %v", cids)
+	}
+
+	participating := Reduce(communities, 0, func(n int, c NFIPCommunityStatus) int {
+		if c.ParticipatingCommunity {
+			n++
+		}
+		return n
+	})
+
+	if expected := len(*communities.Apply(Participating(true))); participating != expected {
+		t.Errorf("expected %d participating communities, got %d", expected, participating)
+	}
+
+	if empty := Map(NFIPCommunityStatuses{}, func(c NFIPCommunityStatus) string { return c.State }); len(empty) != 0 {
+		t.Errorf("expected nothing to be mapped, got %v", empty)
+	}
+}
@@ -0,0 +1,76 @@
+package data
+
+import "strings"
+
+// CommunityFlags are the statuses that the status book marks by
+// putting a parenthetical suffix after the community's name.
+type CommunityFlags struct {
+	Suspended           bool `json:"suspended,omitempty" yaml:"suspended,omitempty" xml:"suspended,omitempty"`
+	Withdrawn           bool `json:"withdrawn,omitempty" yaml:"withdrawn,omitempty" xml:"withdrawn,omitempty"`
+	MinimallyFloodProne bool `json:"minimally_flood_prone,omitempty" yaml:"minimally_flood_prone,omitempty" xml:"minimally_flood_prone,omitempty"`
+	NonFloodProne       bool `json:"non_flood_prone,omitempty" yaml:"non_flood_prone,omitempty" xml:"non_flood_prone,omitempty"`
+}
+
+// Each of the suffixes (without the parentheses) that sets a flag.
+var communityNameMarkers = map[string]func(f *CommunityFlags){
+	"S":                       func(f *CommunityFlags) { f.Suspended = true },
+	"SUSP":                    func(f *CommunityFlags) { f.Suspended = true },
+	"SUSPENDED":               func(f *CommunityFlags) { f.Suspended = true },
+	"W":                       func(f *CommunityFlags) { f.Withdrawn = true },
+	"WITHDRAWN":               func(f *CommunityFlags) { f.Withdrawn = true },
+	"M":                       func(f *CommunityFlags) { f.MinimallyFloodProne = true },
+	"MFP":                     func(f *CommunityFlags) { f.MinimallyFloodProne = true },
+	"MINIMALLY FLOOD PRONE":   func(f *CommunityFlags) { f.MinimallyFloodProne = true },
+	"MINIMALLY FLOOD-PRONE":   func(f *CommunityFlags) { f.MinimallyFloodProne = true },
+	"NSFHA":                   func(f *CommunityFlags) { f.NonFloodProne = true },
+	"NFP":                     func(f *CommunityFlags) { f.NonFloodProne = true },
+	"NON FLOOD PRONE":         func(f *CommunityFlags) { f.NonFloodProne = true },
+	"NON-FLOOD-PRONE":         func(f *CommunityFlags) { f.NonFloodProne = true },
+	"NOT FLOOD PRONE":         func(f *CommunityFlags) { f.NonFloodProne = true },
+	"NO SPECIAL FLOOD HAZARD": func(f *CommunityFlags) { f.NonFloodProne = true },
+}
+
+// parseCommunityName strips the status markers off of the end of
+// a community's name and returns the cleaned name with its flags.
+// Parentheticals that aren't markers are left as part of the name.
+func parseCommunityName(s string) (string, CommunityFlags) {
+	var flags CommunityFlags
+	name := strings.TrimSpace(s)
+
+	// A name can have more than one marker, like "EXAMPLE, CITY OF (M) (S)"
+	for strings.HasSuffix(name, ")") {
+		open := strings.LastIndex(name, "(")
+		if open < 0 {
+			break
+		}
+
+		marker := strings.ToUpper(strings.TrimSpace(name[open+1 : len(name)-1]))
+		setFlag, ok := communityNameMarkers[marker]
+		if !ok {
+			break
+		}
+
+		setFlag(&flags)
+		name = strings.TrimSpace(name[:open])
+	}
+
+	return name, flags
+}
+
+// parseDateMarker sets the flag for a marker that the status book has in
+// a date column instead of a date, like "(NSFHA)" in Curr Eff Map Date,
+// and is whether it was one.
+func parseDateMarker(s string, flags *CommunityFlags) bool {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
+		return false
+	}
+
+	setFlag, ok := communityNameMarkers[strings.ToUpper(strings.TrimSpace(s[1:len(s)-1]))]
+	if !ok {
+		return false
+	}
+
+	setFlag(flags)
+	return true
+}
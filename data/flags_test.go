@@ -0,0 +1,96 @@
+package data
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseCommunityName(t *testing.T) {
+	// Names without markers are left alone
+	testString := "SPRINGFIELD, CITY OF"
+	name, flags := parseCommunityName(testString)
+	if name != testString || flags != (CommunityFlags{}) {
+		t.Errorf("expected \"%s\" to be left alone, got \"%s\" %+v", testString, name, flags)
+	}
+
+	testString = "GRANT CITY, CITY OF (S)"
+	name, flags = parseCommunityName(testString)
+	if name != "GRANT CITY, CITY OF" || !flags.Suspended {
+		t.Errorf("expected \"%s\" to be suspended, got \"%s\" %+v", testString, name, flags)
+	}
+
+	// Every marker at the end of the name should be stripped off
+	testString = "FAIRVIEW PARK, CITY OF (NSFHA) (withdrawn)"
+	name, flags = parseCommunityName(testString)
+	if name != "FAIRVIEW PARK, CITY OF" || !flags.NonFloodProne || !flags.Withdrawn || flags.Suspended {
+		t.Errorf("expected \"%s\" to be non-flood-prone and withdrawn, got \"%s\" %+v", testString, name, flags)
+	}
+
+	// Parentheticals that aren't markers are part of the name
+	testString = "ISLE OF PALMS (TOWN)"
+	name, _ = parseCommunityName(testString)
+	if name != testString {
+		t.Errorf("expected \"%s\" to be left alone, got \"%s\"", testString, name)
+	}
+}
+
+// The snapshot doesn't have any markers, so these rows do.
+const flaggedStatusBook = `CID,Community Name,County,Init FHBM Identified,Init FIRM Identified,Curr Eff Map Date,Reg-Emer Date,Tribal,CRS Entry Date,Curr Eff Date,Curr Class,% Disc SFHA,% Disc Non SFHA,Program,Participating in NFIP
+="290107","GRANT CITY, CITY OF (S)",WORTH COUNTY,03/08/74,,(NSFHA),,No,,,,,,,No
+="390211","FAIRVIEW PARK, CITY OF (NSFHA)",CUYAHOGA COUNTY,06/28/74,,(NSFHA),07/01/87,No,,,,,,R,Yes
+`
+
+func TestUnmarshalFlags(t *testing.T) {
+	result, err := Unmarshal(strings.NewReader(flaggedStatusBook))
+	if err != nil {
+		t.Fatalf("expected the status book to be parsed, got %s", err)
+	}
+	if len(result.Communities) != 2 {
+		t.Fatalf("expected 2 communities, got %d", len(result.Communities))
+	}
+
+	grantCity := result.Communities[0]
+	if grantCity.CommunityName != "GRANT CITY, CITY OF" || !grantCity.Flags.Suspended || grantCity.Program != ProgramSuspended {
+		t.Errorf("expected Grant City to be suspended without the marker in its name, got \"%s\" %+v %s", grantCity.CommunityName, grantCity.Flags, grantCity.Program)
+	}
+
+	fairviewPark := result.Communities[1]
+	if fairviewPark.CommunityName != "FAIRVIEW PARK, CITY OF" || !fairviewPark.Flags.NonFloodProne || fairviewPark.Flags.Suspended {
+		t.Errorf("expected Fairview Park to be non-flood-prone without the marker in its name, got \"%s\" %+v", fairviewPark.CommunityName, fairviewPark.Flags)
+	}
+
+	// "(NSFHA)" in a date column is a flag too, rather than a date that
+	// couldn't be parsed
+	if !grantCity.Flags.NonFloodProne || grantCity.CurrEffMapDate != nil {
+		t.Errorf("expected Grant City to be non-flood-prone from its map date, got %+v %v", grantCity.Flags, grantCity.CurrEffMapDate)
+	}
+	if len(result.Unparsable) != 0 {
+		t.Errorf("expected the markers to be parsed, got %v", result.Unparsable)
+	}
+
+	var b bytes.Buffer
+	if err := result.Communities.ToCSV(&b); err != nil {
+		t.Fatalf("expected the communities to be written, got %s", err)
+	}
+	again, err := Unmarshal(&b)
+	if err != nil || len(again.Communities) != 2 || again.Communities[0].Flags != grantCity.Flags {
+		t.Errorf("expected Grant City's flags to be written back, got %+v %v", again.Communities, err)
+	}
+}
+
+func TestParseDateMarker(t *testing.T) {
+	var flags CommunityFlags
+	for _, s := range []string{"06/30/76", "(BOGUS)", "NSFHA", ""} {
+		if parseDateMarker(s, &flags) {
+			t.Errorf("expected \"%s\" not to be a marker", s)
+		}
+	}
+	if flags != (CommunityFlags{}) {
+		t.Errorf("expected no flags, got %+v", flags)
+	}
+
+	if !parseDateMarker(" (nsfha) ", &flags) || !flags.NonFloodProne {
+		t.Errorf("expected (NSFHA) to be non-flood-prone, got %+v", flags)
+	}
+}
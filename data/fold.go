@@ -0,0 +1,101 @@
+package data
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// A folding is how text is folded before it's matched. Case never matters,
+// and accents don't either unless keepDiacritics is set, so by default
+// "Mayaguez" finds "MAYAGÜEZ" and "Añasco" finds "ANASCO".
+type folding struct {
+	keepDiacritics bool
+}
+
+// foldString folds s the default way, ignoring case and accents.
+func foldString(s string) string {
+	return folding{}.fold(s)
+}
+
+// fold folds s so that it can be matched without caring about case, which
+// is more than lowercasing it for some letters (like "ß" and "ss"), and
+// without accents if they're being ignored.
+func (f folding) fold(s string) string {
+	folded, _ := f.foldWithOffsets(s, false)
+	return folded
+}
+
+// foldWithOffsets folds s and, if asked, where each byte of the folded
+// string came from in s, with one more offset at the end for len(s).
+// Folding can change how long the string is, so these are needed to
+// find a match in the folded string in s. Folding ASCII doesn't, so
+// there aren't any offsets for it and they're the same as in s.
+func (f folding) foldWithOffsets(s string, offsets bool) (string, []int) {
+	if isASCII(s) {
+		return strings.ToLower(s), nil
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+
+	var from []int
+	var caser cases.Caser
+	haveCaser := false
+
+	for i, r := range s {
+		before := b.Len()
+
+		switch {
+		case r < utf8.RuneSelf:
+			b.WriteByte(byte(unicode.ToLower(r)))
+		default:
+			letter := string(r)
+			if !f.keepDiacritics {
+				letter = stripDiacritics(letter)
+			}
+
+			if !haveCaser {
+				// Casers keep state, so each string gets its own
+				caser, haveCaser = cases.Fold(), true
+			}
+			b.WriteString(caser.String(letter))
+		}
+
+		if offsets {
+			for j := before; j < b.Len(); j++ {
+				from = append(from, i)
+			}
+		}
+	}
+
+	if offsets {
+		from = append(from, len(s))
+	}
+
+	return b.String(), from
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
+
+// stripDiacritics splits letters from their accents and drops the accents.
+func stripDiacritics(s string) string {
+	decomposed := norm.NFD.String(s)
+	return strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+		return r
+	}, decomposed)
+}
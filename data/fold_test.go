@@ -0,0 +1,111 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFoldString(t *testing.T) {
+	tests := map[string]string{
+		"SPRINGFIELD": "springfield",
+		"Añasco":      "anasco",
+		"MAYAGÜEZ":    "mayaguez",
+		"Straße":      "strasse",
+	}
+
+	for s, expected := range tests {
+		if folded := foldString(s); folded != expected {
+			t.Errorf("expected \"%s\" to fold to \"%s\", got \"%s\"", s, expected, folded)
+		}
+	}
+}
+
+func TestFoldStringKeepsDiacritics(t *testing.T) {
+	if folded := (folding{keepDiacritics: true}).fold("MAYAGÜEZ"); folded != "mayagüez" {
+		t.Errorf("expected the accent to be kept, got \"%s\"", folded)
+	}
+}
+
+func TestIndexAllFold(t *testing.T) {
+	tests := []struct {
+		s, term string
+		matches [][2]int
+	}{
+		{"MAYAGÜEZ MUNICIPALITY", "mayaguez", [][2]int{{0, 9}}},
+		{"MAYAGÜEZ MUNICIPALITY", "municipality", [][2]int{{10, 22}}},
+		{"STRAßBURG", "strass", [][2]int{{0, 6}}},
+		{"CAPE MAY, CAPE MAY", "cape", [][2]int{{0, 4}, {10, 14}}},
+		{"SPRINGFIELD", "austin", nil},
+	}
+
+	for _, test := range tests {
+		if matches := (folding{}).indexAllFold(test.s, test.term); !reflect.DeepEqual(matches, test.matches) {
+			t.Errorf("expected \"%s\" in \"%s\" at %v, got %v", test.term, test.s, test.matches, matches)
+		}
+	}
+}
+
+func TestSearchDiacritics(t *testing.T) {
+	communities := NFIPCommunityStatuses{
+		{CID: 720001, CommunityName: "AÑASCO, MUNICIPALITY OF", County: "AÑASCO MUNICIPIO", State: "PR"},
+		{CID: 720002, CommunityName: "ANASCO, MUNICIPALITY OF", County: "ANASCO MUNICIPIO", State: "PR"},
+		{CID: 720003, CommunityName: "MAYAGUEZ, MUNICIPALITY OF", County: "MAYAGUEZ MUNICIPIO", State: "PR"},
+	}
+
+	for _, term := range []string{"anasco", "Añasco", "AÑASCO"} {
+		results := communities.Search(term)
+		if len(results) != 2 {
+			t.Fatalf("expected \"%s\" to find both spellings, got %v", term, results.Communities())
+		}
+
+		for _, result := range results {
+			end := len("ANASCO")
+			if result.Community.CID == 720001 {
+				end = len("AÑASCO")
+			}
+
+			if len(result.Matches) == 0 || result.Matches[0] != (MatchSpan{"community_name", 0, end}) {
+				t.Errorf("expected \"%s\" to match %s at 0-%d, got %v", term, result.Community.CommunityName, end, result.Matches)
+			}
+		}
+	}
+
+	if results := communities.Search("Mayagüez"); len(results) != 1 || results[0].Community.CID != 720003 {
+		t.Errorf("expected \"Mayagüez\" to find Mayaguez, got %v", results.Communities())
+	}
+}
+
+func TestMatchDiacritics(t *testing.T) {
+	communities := NFIPCommunityStatuses{
+		{CID: 720001, CommunityName: "AÑASCO, MUNICIPALITY OF", County: "AÑASCO MUNICIPIO", State: "PR"},
+		{CID: 720002, CommunityName: "ANASCO, MUNICIPALITY OF", County: "ANASCO MUNICIPIO", State: "PR"},
+	}
+
+	idx := NewCommunityIndex(communities, MatchDiacritics(), WithSearchCache(NewSearchCache(10)))
+	for _, term := range []string{"Añasco", "anasco"} {
+		results := idx.Search(term)
+		if len(results) != 1 {
+			t.Fatalf("expected \"%s\" to only find one spelling, got %v", term, results.Communities())
+		}
+		if results[0].Community.CID == 720001 && term != "Añasco" {
+			t.Errorf("expected \"%s\" not to find Añasco", term)
+		}
+	}
+
+	if suggestions := idx.Autocomplete("añ", 10); len(suggestions) != 2 || suggestions[0] != "AÑASCO MUNICIPIO" {
+		t.Errorf("expected only the names with the accent to be suggested, got %v", suggestions)
+	}
+
+	page := NewQuery().Search("añasco").MatchDiacritics().Run(communities)
+	if page.Total != 1 || page.Results[0].Community.CID != 720001 || len(page.Results[0].Matches) == 0 {
+		t.Errorf("expected the query to only find Añasco, got %+v", page)
+	}
+
+	// Everything else still ignores them
+	if results := NewCommunityIndex(communities).Search("Añasco"); len(results) != 2 {
+		t.Errorf("expected the default index to find both spellings, got %v", results.Communities())
+	}
+	if page := NewQuery().Search("añasco").Run(communities); page.Total != 2 {
+		t.Errorf("expected the default query to find both spellings, got %d", page.Total)
+	}
+}
@@ -0,0 +1,199 @@
+package data
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// By default, fuzzy matches need to be at least this similar,
+// which allows for about one typo in every five letters.
+const DefaultMinSimilarity = 0.8
+
+type FuzzyOption func(*fuzzyOptions)
+
+type fuzzyOptions struct {
+	minSimilarity float64
+}
+
+// WithMinSimilarity sets how similar (from 0 to 1) a community needs to be
+// to the search term to be a match. 1 only allows exact matches.
+func WithMinSimilarity(similarity float64) FuzzyOption {
+	return func(o *fuzzyOptions) {
+		o.minSimilarity = similarity
+	}
+}
+
+// FuzzySearch finds communities whose name or county is close to the term,
+// even if it's misspelled, so "Sprinfield" still finds "SPRINGFIELD, CITY OF".
+// The score is how similar it was, from 0 to 1 for an exact match, and
+// the best matches come first.
+func (c NFIPCommunityStatuses) FuzzySearch(term string, opts ...FuzzyOption) SearchResults {
+	o := fuzzyOptions{minSimilarity: DefaultMinSimilarity}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	termWords := fuzzyWords(term)
+	if len(termWords) == 0 {
+		return nil
+	}
+
+	term = strings.Join(termWords, " ")
+
+	var matches SearchResults
+	for _, community := range c {
+		score := 0.0
+		var spans []MatchSpan
+
+		fields := []struct {
+			name  string
+			value string
+		}{
+			{"community_name", community.CommunityName},
+			{"county", community.County},
+		}
+
+		for _, field := range fields {
+			s, start, end := fuzzyScore(term, len(termWords), fuzzyWordSpans(field.value))
+			if s > score {
+				score = s
+			}
+
+			if s >= o.minSimilarity {
+				spans = append(spans, MatchSpan{field.name, start, end})
+			}
+		}
+
+		if score >= o.minSimilarity {
+			matches = append(matches, SearchResult{community, score, spans})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+
+	return matches
+}
+
+// fuzzyWords splits s into lowercase words, leaving out punctuation.
+func fuzzyWords(s string) []string {
+	return strings.FieldsFunc(foldString(s), notWordRune)
+}
+
+// Accents that are written as their own rune after
+// the letter are still part of the word.
+func notWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r)
+}
+
+// A fuzzyWord is a lowercase word and where it is in the original string.
+type fuzzyWord struct {
+	word       string
+	start, end int
+}
+
+func fuzzyWordSpans(s string) []fuzzyWord {
+	var words []fuzzyWord
+	start := -1
+
+	for i, r := range s {
+		if notWordRune(r) {
+			if start >= 0 {
+				words = append(words, fuzzyWord{foldString(s[start:i]), start, i})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+
+	if start >= 0 {
+		words = append(words, fuzzyWord{foldString(s[start:]), start, len(s)})
+	}
+
+	return words
+}
+
+// fuzzyScore compares the term to every run of the same number of words in
+// the field, so "springfeld" is compared to each word in "SPRINGFIELD, CITY OF"
+// rather than the whole name. It returns the best similarity and where the
+// words that were the most similar are in the field.
+func fuzzyScore(term string, n int, words []fuzzyWord) (float64, int, int) {
+	best, start, end := 0.0, 0, 0
+	for i := 0; i+n <= len(words); i++ {
+		window := make([]string, n)
+		for j := range window {
+			window[j] = words[i+j].word
+		}
+
+		if s := similarity(term, strings.Join(window, " ")); s > best {
+			best, start, end = s, words[i].start, words[i+n-1].end
+		}
+	}
+
+	return best, start, end
+}
+
+// similarity is 1 minus the edit distance between a and b
+// as a fraction of the longer of the two.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+
+	if longest == 0 {
+		return 1
+	}
+
+	return 1 - float64(damerauLevenshtein(ra, rb))/float64(longest)
+}
+
+// damerauLevenshtein is the number of insertions, deletions, substitutions
+// and swaps of neighboring letters it takes to turn a into b. This is the
+// "optimal string alignment" version, which doesn't edit a letter twice.
+func damerauLevenshtein(a, b []rune) int {
+	// Only the last three rows are needed
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = minInt(curr[j], prev2[j-2]+1)
+			}
+		}
+
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	return prev[len(b)]
+}
+
+func minInt(first int, rest ...int) int {
+	m := first
+	for _, n := range rest {
+		if n < m {
+			m = n
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,59 @@
+package data
+
+import "testing"
+
+func TestDamerauLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		distance int
+	}{
+		{"springfield", "springfield", 0},
+		{"sprinfield", "springfield", 1},
+		{"sprnigfield", "springfield", 1},
+		{"", "cook", 4},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, test := range tests {
+		if d := damerauLevenshtein([]rune(test.a), []rune(test.b)); d != test.distance {
+			t.Errorf("expected a distance of %d between \"%s\" and \"%s\", got %d", test.distance, test.a, test.b, d)
+		}
+	}
+}
+
+func TestFuzzySearch(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	matches := communities.FuzzySearch("Sprinfield")
+	if len(matches) != 1 || matches[0].Community.CID != 170604 {
+		t.Fatalf("expected to find Springfield, got %v", matches)
+	}
+
+	if matches[0].Score <= DefaultMinSimilarity || matches[0].Score >= 1 {
+		t.Errorf("expected a close but not exact score, got %f", matches[0].Score)
+	}
+
+	// Exact matches come first
+	matches = communities.FuzzySearch("miami")
+	if len(matches) < 2 || matches[0].Score != 1 {
+		t.Errorf("expected exact matches for Miami, got %v", matches)
+	}
+
+	// Multiple words are compared together
+	matches = communities.FuzzySearch("new yrok")
+	if len(matches) != 1 || matches[0].Community.CID != 360497 {
+		t.Errorf("expected to find New York, got %v", matches)
+	}
+
+	// The threshold can be lowered to allow more typos
+	if matches := communities.FuzzySearch("sprngfld"); len(matches) != 0 {
+		t.Errorf("expected too many typos to not match, got %v", matches)
+	}
+
+	if matches := communities.FuzzySearch("sprngfld", WithMinSimilarity(0.7)); len(matches) != 1 {
+		t.Errorf("expected a lower threshold to match, got %v", matches)
+	}
+}
@@ -0,0 +1,297 @@
+package data
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The Census Bureau's gazetteer files, which have a representative point
+// for every county and place. Places are more precise, but not every
+// community is one, so counties are used for the rest.
+const (
+	GazetteerCountiesURL = "https://www2.census.gov/geo/docs/maps-data/data/gazetteer/2023_Gazetteer/2023_Gaz_counties_national.zip"
+	GazetteerPlacesURL   = "https://www2.census.gov/geo/docs/maps-data/data/gazetteer/2023_Gazetteer/2023_Gaz_place_national.zip"
+)
+
+var ErrInvalidGazetteer = fmt.Errorf("invalid gazetteer")
+
+// A Point is a latitude and longitude in decimal degrees.
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// A Centroid is what a community's point on a map is the middle of.
+type Centroid string
+
+const (
+	CentroidNone   Centroid = ""
+	CentroidPlace  Centroid = "place"
+	CentroidCounty Centroid = "county"
+)
+
+// A Gazetteer finds a point on a map for each community, from the Census
+// Bureau's gazetteer files. See LoadGazetteer and EnsureGazetteer.
+type Gazetteer struct {
+	counties map[string]Point
+	places   map[string]Point
+}
+
+func NewGazetteer() *Gazetteer {
+	return &Gazetteer{
+		counties: make(map[string]Point),
+		places:   make(map[string]Point),
+	}
+}
+
+// Read adds the counties or places in a gazetteer file, which is tab
+// separated with a header. Which one it has is worked out from its GEOIDs,
+// which are 5 digits for counties and 7 for places.
+func (g *Gazetteer) Read(r io.Reader) error {
+	scanner := bufio.NewScanner(newTextReader(r))
+
+	columns := map[string]int{}
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		record := strings.Split(scanner.Text(), "\t")
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+
+		if lineNumber == 1 {
+			for i, name := range record {
+				columns[strings.ToUpper(name)] = i
+			}
+
+			for _, name := range []string{"USPS", "GEOID", "NAME", "INTPTLAT", "INTPTLONG"} {
+				if _, ok := columns[name]; !ok {
+					return fmt.Errorf("%w: missing the %s column", ErrInvalidGazetteer, name)
+				}
+			}
+			continue
+		}
+
+		if len(record) < len(columns) {
+			return fmt.Errorf("%w: only %d columns on line %d", ErrInvalidGazetteer, len(record), lineNumber)
+		}
+
+		lat, err := strconv.ParseFloat(record[columns["INTPTLAT"]], 64)
+		if err != nil {
+			return fmt.Errorf("%w: latitude %s on line %d", ErrInvalidGazetteer, record[columns["INTPTLAT"]], lineNumber)
+		}
+
+		lon, err := strconv.ParseFloat(record[columns["INTPTLONG"]], 64)
+		if err != nil {
+			return fmt.Errorf("%w: longitude %s on line %d", ErrInvalidGazetteer, record[columns["INTPTLONG"]], lineNumber)
+		}
+
+		key := gazetteerKey(record[columns["USPS"]], record[columns["NAME"]])
+		switch len(record[columns["GEOID"]]) {
+		case 5:
+			g.counties[key] = Point{lat, lon}
+		case 7:
+			g.places[key] = Point{lat, lon}
+		default:
+			return fmt.Errorf("%w: GEOID %s on line %d isn't a county or a place", ErrInvalidGazetteer, record[columns["GEOID"]], lineNumber)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// Len is how many counties and places there are.
+func (g *Gazetteer) Len() int {
+	return len(g.counties) + len(g.places)
+}
+
+// Locate finds the community's point on a map. Cities, towns, villages and
+// boroughs are looked for as places, and anything that isn't found is put
+// in the middle of the first of its counties.
+func (g *Gazetteer) Locate(c NFIPCommunityStatus) (Point, Centroid, bool) {
+	switch c.Kind {
+	case NameKindCity, NameKindTown, NameKindVillage, NameKindBorough:
+		// Places are named with what they are, like "Houston city"
+		if p, ok := g.places[gazetteerKey(c.State, c.BaseName+" "+string(c.Kind))]; ok {
+			return p, CentroidPlace, true
+		}
+	}
+
+	county, _, _ := strings.Cut(c.County, "/")
+	if p, ok := g.counties[gazetteerKey(c.State, county)]; ok {
+		return p, CentroidCounty, true
+	}
+
+	return Point{}, CentroidNone, false
+}
+
+// gazetteerKey is what a county or place is found under in the gazetteer.
+// Puerto Rico's municipios are municipalities in the status book.
+func gazetteerKey(state, name string) string {
+	name = foldString(strings.Join(strings.Fields(name), " "))
+	if strings.HasSuffix(name, " municipality") {
+		name = strings.TrimSuffix(name, "municipality") + "municipio"
+	}
+
+	return strings.ToUpper(strings.TrimSpace(state)) + "\x00" + name
+}
+
+// LoadGazetteer reads the gazetteer files, which can be the zip files
+// that the Census Bureau has them in or the text files inside of them.
+func LoadGazetteer(filenames ...string) (*Gazetteer, error) {
+	g := NewGazetteer()
+	for _, filename := range filenames {
+		b, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := g.readFile(filename, b); err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+	}
+
+	return g, nil
+}
+
+func (g *Gazetteer) readFile(filename string, b []byte) error {
+	if !strings.EqualFold(filepath.Ext(filename), ".zip") {
+		return g.Read(bytes.NewReader(b))
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return err
+	}
+
+	for _, zf := range zr.File {
+		if !strings.EqualFold(path.Ext(zf.Name), ".txt") {
+			continue
+		}
+
+		f, err := zf.Open()
+		if err != nil {
+			return err
+		}
+
+		err = g.Read(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EnsureGazetteer downloads the county and place gazetteer files if they
+// aren't cached locally yet, the same as the status book, and loads them.
+func EnsureGazetteer(l *log.Logger) (*Gazetteer, error) {
+	var filenames []string
+	for _, url := range []string{GazetteerCountiesURL, GazetteerPlacesURL} {
+		filename := path.Base(url)
+		if _, err := os.Stat(filename); os.IsNotExist(err) {
+			l.Printf("%s does not exist. Downloading...", filename)
+
+			if _, _, err := downloadFile(context.Background(), url, filename, verifyGazetteer(filename)); err != nil {
+				return nil, err
+			}
+		}
+
+		filenames = append(filenames, filename)
+	}
+
+	return LoadGazetteer(filenames...)
+}
+
+// verifyGazetteer checks that a download of the gazetteer file has
+// counties or places in it. The file's name says whether it's a zip.
+func verifyGazetteer(filename string) func(string) error {
+	return func(download string) error {
+		b, err := os.ReadFile(download)
+		if err != nil {
+			return err
+		}
+
+		g := NewGazetteer()
+		if err := g.readFile(filename, b); err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+		if g.Len() == 0 {
+			return fmt.Errorf("%w: %s doesn't have any counties or places", ErrInvalidGazetteer, filename)
+		}
+
+		return nil
+	}
+}
+
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+// Centroid is a foreign member, which GeoJSON allows alongside its own.
+type geoJSONFeature struct {
+	Type       string          `json:"type"`
+	ID         int             `json:"id"`
+	Geometry   *geoJSONPoint   `json:"geometry"`
+	Properties json.RawMessage `json:"properties"`
+	Centroid   Centroid        `json:"centroid,omitempty"`
+}
+
+type geoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// ToGeoJSON writes the communities as a GeoJSON FeatureCollection, with
+// each one at its point in the gazetteer and its fields as properties.
+// Communities that can't be found are still there, with a null geometry.
+func (c *NFIPCommunityStatuses) ToGeoJSON(w io.Writer, g *Gazetteer, opts ...EncodeOption) error {
+	o := newEncodeOptions(opts)
+
+	var fields []statusField
+	if len(o.fields) > 0 {
+		var err error
+		if fields, err = pickFields(o.fields); err != nil {
+			return err
+		}
+	}
+
+	collection := geoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]geoJSONFeature, len(*c)),
+	}
+
+	for i, community := range *c {
+		var properties interface{} = community
+		if fields != nil {
+			properties = ProjectedStatus{community, fields}
+		}
+
+		b, err := json.Marshal(properties)
+		if err != nil {
+			return err
+		}
+
+		feature := geoJSONFeature{Type: "Feature", ID: community.CID, Properties: b}
+		if p, centroid, ok := g.Locate(community); ok {
+			// GeoJSON puts the longitude first
+			feature.Geometry = &geoJSONPoint{"Point", [2]float64{p.Lon, p.Lat}}
+			feature.Centroid = centroid
+		}
+
+		collection.Features[i] = feature
+	}
+
+	return json.NewEncoder(w).Encode(collection)
+}
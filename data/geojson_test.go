@@ -0,0 +1,174 @@
+package data
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Real gazetteer files have trailing spaces on the header too
+const testCountiesGazetteer = "USPS\tGEOID\tANSICODE\tNAME\tALAND\tAWATER\tALAND_SQMI\tAWATER_SQMI\tINTPTLAT\tINTPTLONG   \n" +
+	"TX\t48201\t01383886\tHarris County\t4422883255\t182036920\t1707.686\t70.284\t29.857273\t-95.393037\n" +
+	"IL\t17031\t01784766\tCook County\t2447370818\t1786313044\t944.93\t689.702\t41.84012\t-87.816874\n" +
+	"PR\t72097\t01804529\tMayagüez Municipio\t201173306\t510638693\t77.674\t197.159\t18.224537\t-67.150616\n"
+
+const testPlacesGazetteer = "USPS\tGEOID\tANSICODE\tNAME\tLSAD\tFUNCSTAT\tALAND\tAWATER\tALAND_SQMI\tAWATER_SQMI\tINTPTLAT\tINTPTLONG\n" +
+	"TX\t4835000\t02410796\tHouston city\t25\tA\t1658584213\t84451798\t640.383\t32.607\t29.786562\t-95.390709\n"
+
+func testGazetteer(t *testing.T) *Gazetteer {
+	g := NewGazetteer()
+	if err := g.Read(strings.NewReader(testCountiesGazetteer)); err != nil {
+		t.Fatalf("expected the counties to be read, got %s", err)
+	}
+	if err := g.Read(strings.NewReader(testPlacesGazetteer)); err != nil {
+		t.Fatalf("expected the places to be read, got %s", err)
+	}
+
+	return g
+}
+
+func TestGazetteerLocate(t *testing.T) {
+	g := testGazetteer(t)
+	if g.Len() != 4 {
+		t.Fatalf("expected 4 counties and places, got %d", g.Len())
+	}
+
+	tests := []struct {
+		name     string
+		cid      int
+		point    Point
+		centroid Centroid
+	}{
+		{"place", 480296, Point{29.786562, -95.390709}, CentroidPlace},
+		{"county", 480287, Point{29.857273, -95.393037}, CentroidCounty},
+		{"city without a place", 170074, Point{41.84012, -87.816874}, CentroidCounty},
+		{"first of several counties", 720000, Point{}, CentroidNone},
+		{"not in the gazetteer", 530071, Point{}, CentroidNone},
+	}
+
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := communities.SearchByCID(tt.cid)
+			if len(*c) != 1 {
+				t.Fatalf("expected community %d to be in the demo", tt.cid)
+			}
+
+			p, centroid, ok := g.Locate((*c)[0])
+			if p != tt.point || centroid != tt.centroid || ok != (tt.centroid != CentroidNone) {
+				t.Errorf("expected %v at %v, got %v at %v", tt.centroid, tt.point, centroid, p)
+			}
+		})
+	}
+}
+
+// Puerto Rico's municipios are found with or without their accents.
+func TestGazetteerMunicipios(t *testing.T) {
+	g := testGazetteer(t)
+
+	p, centroid, ok := g.Locate(NFIPCommunityStatus{State: "PR", County: "MAYAGUEZ MUNICIPALITY/ANASCO MUNICIPALITY"})
+	if !ok || centroid != CentroidCounty || p.Lat != 18.224537 {
+		t.Errorf("expected Mayagüez to be found, got %v at %v", centroid, p)
+	}
+}
+
+func TestGazetteerInvalid(t *testing.T) {
+	tests := []string{
+		"USPS\tGEOID\tNAME\tINTPTLAT\n",
+		"USPS\tGEOID\tNAME\tINTPTLAT\tINTPTLONG\nTX\t48201\tHarris County\tnorth\t-95.393037\n",
+		"USPS\tGEOID\tNAME\tINTPTLAT\tINTPTLONG\nTX\t48\tTexas\t31.4\t-99.3\n",
+		"USPS\tGEOID\tNAME\tINTPTLAT\tINTPTLONG\nTX\t48201\n",
+	}
+
+	for _, tt := range tests {
+		if err := NewGazetteer().Read(strings.NewReader(tt)); !errors.Is(err, ErrInvalidGazetteer) {
+			t.Errorf("expected %q to be invalid, got %v", tt, err)
+		}
+	}
+}
+
+func TestLoadGazetteer(t *testing.T) {
+	dir := t.TempDir()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	f, _ := zw.Create("2023_Gaz_counties_national.txt")
+	f.Write([]byte(testCountiesGazetteer))
+	zw.Close()
+
+	counties := filepath.Join(dir, "counties.zip")
+	places := filepath.Join(dir, "places.txt")
+	os.WriteFile(counties, buf.Bytes(), 0o644)
+	os.WriteFile(places, []byte(testPlacesGazetteer), 0o644)
+
+	g, err := LoadGazetteer(counties, places)
+	if err != nil {
+		t.Fatalf("expected the gazetteer to be loaded, got %s", err)
+	}
+
+	if g.Len() != 4 {
+		t.Errorf("expected 4 counties and places, got %d", g.Len())
+	}
+}
+
+func TestToGeoJSON(t *testing.T) {
+	g := testGazetteer(t)
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+
+	var buf bytes.Buffer
+	if err := communities.ToGeoJSON(&buf, g, WithFields("cid", "community_name")); err != nil {
+		t.Fatalf("expected the communities to be written as GeoJSON, got %s", err)
+	}
+
+	var collection struct {
+		Type     string
+		Features []struct {
+			Type     string
+			ID       int
+			Geometry *struct {
+				Type        string
+				Coordinates []float64
+			}
+			Properties map[string]interface{}
+			Centroid   string
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &collection); err != nil {
+		t.Fatalf("expected the GeoJSON to be read back, got %s", err)
+	}
+
+	if collection.Type != "FeatureCollection" || len(collection.Features) != len(communities) {
+		t.Fatalf("expected a FeatureCollection of %d communities, got %s of %d", len(communities), collection.Type, len(collection.Features))
+	}
+
+	located := 0
+	for _, feature := range collection.Features {
+		if feature.Type != "Feature" || len(feature.Properties) != 2 {
+			t.Errorf("expected a feature with 2 properties, got %v", feature)
+		}
+
+		if feature.ID == 480296 {
+			if feature.Geometry == nil || feature.Geometry.Type != "Point" || feature.Centroid != "place" {
+				t.Fatalf("expected Houston to be a point, got %v", feature.Geometry)
+			}
+
+			if c := feature.Geometry.Coordinates; len(c) != 2 || c[0] != -95.390709 || c[1] != 29.786562 {
+				t.Errorf("expected Houston's longitude and then its latitude, got %v", c)
+			}
+		}
+
+		if feature.Geometry != nil {
+			located++
+		}
+	}
+
+	// Houston and Harris County in Texas, and Chicago and Cook County in Illinois
+	if located != 4 {
+		t.Errorf("expected 4 communities to be located, got %d", located)
+	}
+}
@@ -0,0 +1,52 @@
+package data
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+// WithGzip compresses what's written with gzip. The status book shrinks
+// to a fraction of its size, since so much of it is the same dates and
+// county names over and over.
+func WithGzip() EncodeOption {
+	return WithGzipLevel(gzip.DefaultCompression)
+}
+
+// WithGzipLevel compresses what's written with gzip at the level, from
+// gzip.BestSpeed to gzip.BestCompression.
+func WithGzipLevel(level int) EncodeOption {
+	return func(o *encodeOptions) {
+		o.gzip = true
+		o.gzipLevel = level
+	}
+}
+
+// A gzipWriter can still be flushed all the way through to the writer
+// underneath it, so that streaming doesn't stop when it's compressed.
+type gzipWriter struct {
+	*gzip.Writer
+	under flusher
+}
+
+func (gw gzipWriter) Flush() {
+	gw.Writer.Flush()
+	if gw.under != nil {
+		gw.under.Flush()
+	}
+}
+
+// compress wraps the writer with gzip if it was asked for. What it returns
+// has to be closed once everything has been written to finish the stream.
+func (o encodeOptions) compress(w io.Writer) (io.Writer, func() error, error) {
+	if !o.gzip {
+		return w, func() error { return nil }, nil
+	}
+
+	gz, err := gzip.NewWriterLevel(w, o.gzipLevel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	under, _ := w.(flusher)
+	return gzipWriter{gz, under}, gz.Close, nil
+}
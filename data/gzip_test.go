@@ -0,0 +1,92 @@
+package data
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http/httptest"
+	"testing"
+)
+
+func gunzip(t *testing.T, b []byte) []byte {
+	t.Helper()
+
+	gz, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("expected gzip, got %s", err)
+	}
+
+	uncompressed, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("expected the gzip to be read, got %s", err)
+	}
+
+	return uncompressed
+}
+
+func TestWithGzip(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+
+	exporters := []struct {
+		name   string
+		export func(io.Writer, ...EncodeOption) error
+	}{
+		{"JSON", communities.ToJSON},
+		{"streamed JSON", communities.StreamJSON},
+		{"CSV", communities.ToCSV},
+	}
+
+	for _, tt := range exporters {
+		t.Run(tt.name, func(t *testing.T) {
+			var plain, compressed bytes.Buffer
+			if err := tt.export(&plain, WithFields("cid", "county")); err != nil {
+				t.Fatalf("expected the communities to be written, got %s", err)
+			}
+			if err := tt.export(&compressed, WithFields("cid", "county"), WithGzip()); err != nil {
+				t.Fatalf("expected the communities to be compressed, got %s", err)
+			}
+
+			if compressed.Len() >= plain.Len() {
+				t.Errorf("expected %d bytes to be compressed, got %d", plain.Len(), compressed.Len())
+			}
+
+			if uncompressed := gunzip(t, compressed.Bytes()); !bytes.Equal(uncompressed, plain.Bytes()) {
+				t.Errorf("expected %s, got %s", plain.String(), uncompressed)
+			}
+		})
+	}
+}
+
+func TestWithGzipLevel(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+
+	var buf bytes.Buffer
+	if err := communities.ToJSON(&buf, WithGzipLevel(gzip.BestSpeed)); err != nil {
+		t.Fatalf("expected the communities to be compressed, got %s", err)
+	}
+
+	if err := communities.ToJSON(&buf, WithGzipLevel(42)); err == nil {
+		t.Errorf("expected an invalid level to be an error")
+	}
+}
+
+// Compressed streams are still flushed to the client as they're written.
+func TestStreamJSONGzipFlushes(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+	results := communities.Search("city")
+
+	rec := httptest.NewRecorder()
+	if err := results.StreamJSON(rec, WithGzip()); err != nil {
+		t.Fatalf("expected the results to be streamed, got %s", err)
+	}
+
+	if !rec.Flushed {
+		t.Errorf("expected the response to be flushed")
+	}
+
+	var plain bytes.Buffer
+	results.StreamJSON(&plain)
+	if uncompressed := gunzip(t, rec.Body.Bytes()); !bytes.Equal(uncompressed, plain.Bytes()) {
+		t.Errorf("expected %s, got %s", plain.String(), uncompressed)
+	}
+}
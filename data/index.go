@@ -0,0 +1,417 @@
+package data
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Search terms are broken up into trigrams to look them up in the index,
+// so shorter terms can't use it.
+const trigramLength = 3
+
+// A CommunityIndex is built once after the status book is loaded so that
+// looking up a community doesn't mean scanning through all of them.
+type CommunityIndex struct {
+	communities NFIPCommunityStatuses
+	byCID       map[int]int
+
+	// Every trigram in the lowercase names, counties, CIDs and state
+	// names of the communities, and the indexes of the communities they're in. Any
+	// community that contains a term contains all of the term's trigrams.
+	trigrams map[string][]int
+
+	// The phonetic codes of every word in the communities'
+	// base names, and the indexes of the communities they're in.
+	phonetic map[string][]int
+
+	// Every distinct community and county name, sorted by
+	// their lowercase key so that prefixes can be found
+	// with a binary search.
+	suggestions []suggestion
+
+	// Recent search results, if they're being cached, and the
+	// generation of the cache that this index's are
+	cache      *SearchCache
+	generation uint64
+
+	// How the index's searches and suggestions fold their text
+	folding folding
+}
+
+// An IndexOption changes how a CommunityIndex is built.
+type IndexOption func(*CommunityIndex)
+
+// WithSearchCache caches the results of the index's searches. The cache is
+// emptied when the index is built, and only the newest index's results are
+// kept in it, so the same cache can be given to the index that's built
+// after the communities are refreshed.
+func WithSearchCache(cache *SearchCache) IndexOption {
+	return func(idx *CommunityIndex) {
+		idx.cache = cache
+	}
+}
+
+// MatchDiacritics makes the index's searches and suggestions count accents,
+// so "Mayaguez" doesn't find "MAYAGÜEZ". By default they're ignored.
+func MatchDiacritics() IndexOption {
+	return func(idx *CommunityIndex) {
+		idx.folding.keepDiacritics = true
+	}
+}
+
+type suggestion struct {
+	key  string
+	name string
+}
+
+func NewCommunityIndex(communities NFIPCommunityStatuses, opts ...IndexOption) *CommunityIndex {
+	idx := &CommunityIndex{
+		communities: communities,
+		byCID:       make(map[int]int, len(communities)),
+	}
+
+	for _, opt := range opts {
+		opt(idx)
+	}
+
+	// Anything that was cached was for different communities
+	if idx.cache != nil {
+		idx.generation = idx.cache.newGeneration()
+	}
+
+	// If a CID is in the status book more than once,
+	// the first one is the one that's found.
+	for i, c := range communities {
+		if _, ok := idx.byCID[c.CID]; !ok {
+			idx.byCID[c.CID] = i
+		}
+	}
+
+	idx.trigrams = buildTrigrams(communities, idx.folding)
+	idx.phonetic = buildPhonetic(communities)
+	idx.suggestions = buildSuggestions(communities, idx.folding)
+	return idx
+}
+
+func buildTrigrams(communities NFIPCommunityStatuses, f folding) map[string][]int {
+	trigrams := make(map[string][]int)
+
+	for i, c := range communities {
+		seen := make(map[string]bool)
+		k := c.foldedSearchKeys(f)
+		for _, field := range []string{k.lowerName, k.lowerCounty, k.cid, k.lowerStateName} {
+			for _, gram := range trigramsOf(field) {
+				if !seen[gram] {
+					seen[gram] = true
+					trigrams[gram] = append(trigrams[gram], i)
+				}
+			}
+		}
+	}
+
+	return trigrams
+}
+
+func buildPhonetic(communities NFIPCommunityStatuses) map[string][]int {
+	phonetic := make(map[string][]int)
+	for i, c := range communities {
+		for _, key := range phoneticKeys(c.BaseName) {
+			phonetic[key] = append(phonetic[key], i)
+		}
+	}
+
+	return phonetic
+}
+
+func trigramsOf(s string) []string {
+	var grams []string
+	for i := 0; i+trigramLength <= len(s); i++ {
+		grams = append(grams, s[i:i+trigramLength])
+	}
+
+	return grams
+}
+
+func buildSuggestions(communities NFIPCommunityStatuses, f folding) []suggestion {
+	seen := make(map[string]bool)
+	var suggestions []suggestion
+
+	// Unincorporated areas end with a "*", which isn't something
+	// anyone would type, so they're suggested without it.
+	add := func(name string) {
+		name = strings.TrimSpace(strings.TrimSuffix(name, "*"))
+		key := f.fold(name)
+		if len(key) == 0 || seen[key] {
+			return
+		}
+
+		seen[key] = true
+		suggestions = append(suggestions, suggestion{key, name})
+	}
+
+	for _, c := range communities {
+		add(c.CommunityName)
+
+		// Communities in more than one county have them separated by "/"
+		for _, county := range strings.Split(c.County, "/") {
+			add(county)
+		}
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].key < suggestions[j].key
+	})
+
+	return suggestions
+}
+
+// ByCID finds the community with the CID.
+func (idx *CommunityIndex) ByCID(cid int) (*NFIPCommunityStatus, bool) {
+	i, ok := idx.byCID[cid]
+	if !ok {
+		return nil, false
+	}
+
+	return &idx.communities[i], true
+}
+
+// Search is the same as NFIPCommunityStatuses.Search, but it only looks at
+// the communities that have every trigram in one of the term's variants, or
+// in every word of the term. Terms that are too short or look like a query,
+// or an index that wasn't built with NewCommunityIndex, fall back to
+// searching every community.
+func (idx *CommunityIndex) Search(term string) SearchResults {
+	return idx.cached(searchCacheKey("search", "", term, idx.folding), func() SearchResults {
+		return idx.search(term)
+	})
+}
+
+func (idx *CommunityIndex) search(term string) SearchResults {
+	if idx.trigrams == nil || IsQuery(term) {
+		return idx.communities.search(term, idx.folding)
+	}
+
+	st := newFoldedSearchTerm(term, idx.folding)
+
+	var candidates []int
+	for _, variant := range st.whole {
+		// State abbreviations are too short to have trigrams
+		if len(variant) < trigramLength {
+			return idx.communities.search(term, idx.folding)
+		}
+
+		candidates = unionSorted(candidates, idx.trigramCandidates(variant))
+	}
+
+	if len(st.tokens) > 0 {
+		everyToken, narrowed := idx.tokenCandidates(st.tokens)
+		if !narrowed {
+			return idx.communities.search(term, idx.folding)
+		}
+
+		candidates = unionSorted(candidates, everyToken)
+	}
+
+	communities := make(NFIPCommunityStatuses, len(candidates))
+	for i, c := range candidates {
+		communities[i] = idx.communities[c]
+	}
+
+	return communities.search(term, idx.folding)
+}
+
+// tokenCandidates are the indexes of the communities that could match every
+// word in a term. Words that are too short to have trigrams can't narrow it
+// down, and if none of them can, it reports that nothing was narrowed down.
+func (idx *CommunityIndex) tokenCandidates(tokens [][]string) ([]int, bool) {
+	var candidates []int
+	narrowed := false
+
+	for _, variants := range tokens {
+		var either []int
+		short := false
+		for _, variant := range variants {
+			if len(variant) < trigramLength {
+				short = true
+				break
+			}
+
+			either = unionSorted(either, idx.trigramCandidates(variant))
+		}
+
+		if short {
+			continue
+		}
+
+		if narrowed {
+			candidates = intersectSorted(candidates, either)
+		} else {
+			candidates, narrowed = either, true
+		}
+	}
+
+	return candidates, narrowed
+}
+
+// trigramCandidates are the indexes of the communities
+// that have every trigram in the lowercase term.
+func (idx *CommunityIndex) trigramCandidates(term string) []int {
+	// Start with the rarest trigram since the others can only narrow it down
+	var postings [][]int
+	for _, gram := range trigramsOf(term) {
+		posting, ok := idx.trigrams[gram]
+		if !ok {
+			return nil
+		}
+
+		postings = append(postings, posting)
+	}
+
+	sort.Slice(postings, func(i, j int) bool {
+		return len(postings[i]) < len(postings[j])
+	})
+
+	candidates := postings[0]
+	for _, posting := range postings[1:] {
+		candidates = intersectSorted(candidates, posting)
+	}
+
+	return candidates
+}
+
+// FuzzySearch is the same as NFIPCommunityStatuses.FuzzySearch. There's
+// nothing in the index that helps with it, but its results can be cached.
+func (idx *CommunityIndex) FuzzySearch(term string, opts ...FuzzyOption) SearchResults {
+	o := fuzzyOptions{minSimilarity: DefaultMinSimilarity}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	key := searchCacheKey("fuzzy", strconv.FormatFloat(o.minSimilarity, 'g', -1, 64), term, folding{})
+	return idx.cached(key, func() SearchResults {
+		return idx.communities.FuzzySearch(term, opts...)
+	})
+}
+
+// PhoneticSearch is the same as NFIPCommunityStatuses.PhoneticSearch,
+// but it looks up the codes of the term in the index.
+func (idx *CommunityIndex) PhoneticSearch(term string) SearchResults {
+	return idx.cached(searchCacheKey("phonetic", "", term, folding{}), func() SearchResults {
+		return idx.phoneticSearch(term)
+	})
+}
+
+func (idx *CommunityIndex) phoneticSearch(term string) SearchResults {
+	if idx.phonetic == nil {
+		return idx.communities.PhoneticSearch(term)
+	}
+
+	words := fuzzyWords(term)
+	if len(words) == 0 {
+		return SearchResults{}
+	}
+
+	var candidates []int
+	for i, word := range words {
+		// Either of the word's codes can match
+		p, a := phoneticCodes(word)
+		matches := idx.phonetic[p]
+		if a != p {
+			matches = unionSorted(matches, idx.phonetic[a])
+		}
+
+		if i == 0 {
+			candidates = matches
+		} else {
+			candidates = intersectSorted(candidates, matches)
+		}
+	}
+
+	return idx.communities.phoneticResults(term, candidates)
+}
+
+// cached returns the results that were cached under the key,
+// or searches for them and caches them if there weren't any.
+func (idx *CommunityIndex) cached(key string, search func() SearchResults) SearchResults {
+	if idx.cache == nil {
+		return search()
+	}
+
+	if results, ok := idx.cache.get(idx.generation, key); ok {
+		return results
+	}
+
+	results := search()
+	idx.cache.add(idx.generation, key, results)
+	return results
+}
+
+// unionSorted returns the numbers that are in either sorted list.
+func unionSorted(a, b []int) []int {
+	var either []int
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			either = append(either, a[i])
+			i++
+		case a[i] > b[j]:
+			either = append(either, b[j])
+			j++
+		default:
+			either = append(either, a[i])
+			i++
+			j++
+		}
+	}
+
+	either = append(either, a[i:]...)
+	return append(either, b[j:]...)
+}
+
+// intersectSorted returns the numbers that are in both sorted lists.
+func intersectSorted(a, b []int) []int {
+	var both []int
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			both = append(both, a[i])
+			i++
+			j++
+		}
+	}
+
+	return both
+}
+
+// Autocomplete suggests up to limit community and county names that start
+// with the prefix, in alphabetical order. Names are only suggested once,
+// no matter how many communities have them.
+func (idx *CommunityIndex) Autocomplete(prefix string, limit int) []string {
+	prefix = idx.folding.fold(strings.TrimSpace(prefix))
+	suggestions := []string{}
+
+	i := sort.Search(len(idx.suggestions), func(i int) bool {
+		return idx.suggestions[i].key >= prefix
+	})
+
+	for ; i < len(idx.suggestions) && len(suggestions) < limit; i++ {
+		if !strings.HasPrefix(idx.suggestions[i].key, prefix) {
+			break
+		}
+
+		suggestions = append(suggestions, idx.suggestions[i].name)
+	}
+
+	return suggestions
+}
+
+// Communities are all of the communities in the index.
+func (idx *CommunityIndex) Communities() NFIPCommunityStatuses {
+	return idx.communities
+}
@@ -0,0 +1,132 @@
+package data
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommunityIndexByCID(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	idx := NewCommunityIndex(communities)
+
+	c, ok := idx.ByCID(170074)
+	if !ok || c.CommunityName != "CHICAGO, CITY OF" {
+		t.Errorf("expected to find Chicago, got %v", c)
+	}
+
+	// There aren't any substring matches
+	if c, ok := idx.ByCID(1700); ok {
+		t.Errorf("expected nothing for a partial CID, got %v", c)
+	}
+
+	// The first of any duplicates is the one that's found
+	file := testHeader + "\n" + strings.Join(duplicateRecords, "\n")
+	result, err := Unmarshal(strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err.Error())
+	}
+
+	c, ok = NewCommunityIndex(result.Communities).ByCID(170604)
+	if !ok || c.County != "SANGAMON COUNTY" {
+		t.Errorf("expected the first Springfield, got %v", c)
+	}
+}
+
+func TestAutocomplete(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	idx := NewCommunityIndex(communities)
+
+	// Mobile County is a community and a county, but it's only suggested once
+	suggestions := idx.Autocomplete("mob", 10)
+	if len(suggestions) != 2 || suggestions[0] != "MOBILE COUNTY" || suggestions[1] != "MOBILE, CITY OF" {
+		t.Errorf("expected Mobile County and the City of Mobile, got %v", suggestions)
+	}
+
+	// Counties are split up
+	suggestions = idx.Autocomplete("Fort Bend", 10)
+	if len(suggestions) != 1 || suggestions[0] != "FORT BEND COUNTY" {
+		t.Errorf("expected Fort Bend County, got %v", suggestions)
+	}
+
+	if suggestions := idx.Autocomplete("m", 3); len(suggestions) != 3 {
+		t.Errorf("expected the suggestions to be limited to 3, got %v", suggestions)
+	}
+
+	if suggestions := idx.Autocomplete("zzz", 10); suggestions == nil || len(suggestions) != 0 {
+		t.Errorf("expected no suggestions, got %v", suggestions)
+	}
+}
+
+func BenchmarkAutocomplete(b *testing.B) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	idx := NewCommunityIndex(communities)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		idx.Autocomplete("sa", 10)
+	}
+}
+
+func TestCommunityIndexSearch(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	idx := NewCommunityIndex(communities)
+
+	// The index should find exactly what searching every community does
+	for _, term := range []string{"cook", "COUNTY", "orleans", "view", "170074", "1700", "st", "city of", "atlantis", "county:cook", "cook county il", "chicago cook", "\"miami beach\" fl", "harris tx", "florida county"} {
+		expected := communities.Search(term)
+		if results := idx.Search(term); !reflect.DeepEqual(results, expected) {
+			t.Errorf("expected the same results for \"%s\", got %d instead of %d", term, len(results), len(expected))
+		}
+	}
+
+	// An index that wasn't built still searches
+	unbuilt := CommunityIndex{communities: communities}
+	if results := unbuilt.Search("cook"); len(results) != 2 {
+		t.Errorf("expected 2 results without an index, got %d", len(results))
+	}
+}
+
+func BenchmarkSearch(b *testing.B) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		communities.Search("springfield")
+	}
+}
+
+func BenchmarkCommunityIndexSearch(b *testing.B) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	idx := NewCommunityIndex(communities)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		idx.Search("springfield")
+	}
+}
@@ -0,0 +1,49 @@
+package data
+
+import "strings"
+
+// An interner hands back the same copy of strings that repeat across the
+// status book, like counties, so that a loaded status book doesn't hold
+// thousands of copies of "COOK COUNTY".
+//
+// The CSV reader gives back every field of a record as part of one string
+// for the whole line, so a field that's kept keeps all of the line with it.
+// The copies that are handed back are cloned to let the lines go.
+//
+// It isn't safe to use from more than one goroutine.
+type interner struct {
+	strings map[string]string
+}
+
+func newInterner() *interner {
+	return &interner{
+		strings: make(map[string]string),
+	}
+}
+
+func (in *interner) string(s string) string {
+	if interned, ok := in.strings[s]; ok {
+		return interned
+	}
+
+	s = strings.Clone(s)
+	in.strings[s] = s
+	return s
+}
+
+// intern swaps the community's repeated values for shared copies, and
+// clones the name so that it doesn't keep the line it was read from.
+func (in *interner) intern(nc *NFIPCommunityStatus) {
+	nc.County = in.string(nc.County)
+	nc.PercentDiscSFHA = in.string(nc.PercentDiscSFHA)
+	nc.PercentNonSFHA = in.string(nc.PercentNonSFHA)
+
+	// The base name is part of the name, so it's taken from the clone
+	name := strings.Clone(nc.CommunityName)
+	if i := strings.Index(name, nc.BaseName); i >= 0 {
+		nc.BaseName = name[i : i+len(nc.BaseName)]
+	} else {
+		nc.BaseName = strings.Clone(nc.BaseName)
+	}
+	nc.CommunityName = name
+}
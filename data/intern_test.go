@@ -0,0 +1,100 @@
+package data
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestInterner(t *testing.T) {
+	in := newInterner()
+
+	// Equal strings built separately should come back as one copy
+	first := in.string(strings.ToUpper("cook county"))
+	second := in.string(strings.ToUpper("cook county"))
+	if first != "COOK COUNTY" || second != "COOK COUNTY" || len(in.strings) != 1 {
+		t.Errorf("expected one copy of \"COOK COUNTY\", got %d", len(in.strings))
+	}
+	if unsafe.StringData(first) != unsafe.StringData(second) {
+		t.Errorf("expected both strings to share a copy")
+	}
+
+	// A string that's part of a bigger one is cloned, so it doesn't keep
+	// the bigger one around
+	line := "170074,CHICAGO,COOK COUNTY"
+	county := in.string(line[15:])
+	if unsafe.StringData(county) == unsafe.StringData(line[15:]) {
+		t.Errorf("expected the county to be cloned from the line")
+	}
+
+	records := []string{
+		`="170604","SPRINGFIELD, CITY OF",COOK COUNTY,,,,,No,10/01/92,05/01/19,9,5,5,R,Yes`,
+		`="170074","CHICAGO, CITY OF",COOK COUNTY,,,,,No,10/01/92,05/01/19,9,5,5,R,Yes`,
+	}
+
+	file := testHeader + "\n" + strings.Join(records, "\n")
+	result, err := Unmarshal(strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err.Error())
+	}
+
+	// Counties should be shared across the communities that are parsed
+	springfield, chicago := result.Communities[0], result.Communities[1]
+	if unsafe.StringData(springfield.County) != unsafe.StringData(chicago.County) {
+		t.Errorf("expected both communities to share a county")
+	}
+
+	// But the CRS classes are each community's own, so changing one
+	// doesn't change the other
+	*springfield.CurClass = 8
+	if *chicago.CurClass != 9 {
+		t.Errorf("expected Chicago's CRS class to stay 9, got %d", *chicago.CurClass)
+	}
+
+	if springfield.BaseName != "SPRINGFIELD" || chicago.BaseName != "CHICAGO" {
+		t.Errorf("expected the base names to be kept, got %s and %s", springfield.BaseName, chicago.BaseName)
+	}
+}
+
+// BenchmarkUnmarshalRetained reports how much of the heap the parsed
+// status book keeps, with and without interning.
+func BenchmarkUnmarshalRetained(b *testing.B) {
+	withoutInterning := func(o *parseOptions) {
+		o.skipIntern = true
+	}
+
+	benchmarks := map[string][]ParseOption{
+		"interned":     nil,
+		"not interned": {withoutInterning},
+	}
+
+	for name, opts := range benchmarks {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			var retained int64
+			for i := 0; i < b.N; i++ {
+				before := heapAlloc()
+				result, err := Unmarshal(bytes.NewReader(snapshot), opts...)
+				if err != nil {
+					b.Fatal(err)
+				}
+				retained += heapAlloc() - before
+				runtime.KeepAlive(result)
+			}
+
+			b.ReportMetric(float64(retained)/float64(b.N), "retained-B/op")
+		})
+	}
+}
+
+// heapAlloc is how much of the heap is in use once the garbage is collected.
+func heapAlloc() int64 {
+	runtime.GC()
+
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return int64(ms.HeapAlloc)
+}
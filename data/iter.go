@@ -0,0 +1,56 @@
+package data
+
+import "iter"
+
+// All ranges over every community in order.
+//
+//	for c := range cb.All() {
+//		...
+//	}
+func (c NFIPCommunityStatuses) All() iter.Seq[NFIPCommunityStatus] {
+	return func(yield func(NFIPCommunityStatus) bool) {
+		for _, community := range c {
+			if !yield(community) {
+				return
+			}
+		}
+	}
+}
+
+// Matching ranges over the communities that pass every filter. Unlike
+// Apply, the matches aren't collected up front, so breaking out of the
+// loop early skips checking the rest of the communities.
+func (c NFIPCommunityStatuses) Matching(filters ...Filter) iter.Seq[NFIPCommunityStatus] {
+	keep := All(filters...)
+
+	return func(yield func(NFIPCommunityStatus) bool) {
+		for _, community := range c {
+			if keep(community) && !yield(community) {
+				return
+			}
+		}
+	}
+}
+
+// All ranges over the results in order, most relevant first.
+func (sr SearchResults) All() iter.Seq[SearchResult] {
+	return func(yield func(SearchResult) bool) {
+		for _, r := range sr {
+			if !yield(r) {
+				return
+			}
+		}
+	}
+}
+
+// All ranges over the communities that are left without
+// copying them into a new slice like Communities does.
+func (rs ResultSet) All() iter.Seq[NFIPCommunityStatus] {
+	return func(yield func(NFIPCommunityStatus) bool) {
+		for _, index := range rs.indexes {
+			if !yield(rs.communities[index]) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package data
+
+import "testing"
+
+func TestAll(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	i := 0
+	for c := range communities.All() {
+		if c.CID != communities[i].CID {
+			t.Errorf("expected CID %d at %d, got %d", communities[i].CID, i, c.CID)
+		}
+		i++
+	}
+
+	if i != len(communities) {
+		t.Errorf("expected %d communities, got %d", len(communities), i)
+	}
+
+	i = 0
+	for c := range communities.Refine().Filter(State("FL")).All() {
+		if c.State != "FL" {
+			t.Errorf("expected only communities in Florida, got %s", c.State)
+		}
+		i++
+	}
+
+	if i != 3 {
+		t.Errorf("expected 3 communities in Florida, got %d", i)
+	}
+}
+
+func TestMatching(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	var matches NFIPCommunityStatuses
+	for c := range communities.Matching(State("FL"), Tribal(true)) {
+		matches = append(matches, c)
+	}
+
+	if len(matches) != 1 || matches[0].CID != 120678 {
+		t.Errorf("expected only the Seminole Tribe, got %v", matches)
+	}
+
+	// Breaking out early stops checking the rest
+	checked := 0
+	counting := func(NFIPCommunityStatus) bool {
+		checked++
+		return true
+	}
+
+	for range communities.Matching(counting) {
+		break
+	}
+
+	if checked != 1 {
+		t.Errorf("expected only the first community to be checked, got %d", checked)
+	}
+}
+
+func TestSearchResultsAll(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	results := communities.Search("cook")
+
+	var cids []int
+	for r := range results.All() {
+		cids = append(cids, r.Community.CID)
+	}
+
+	if len(cids) != len(results) || cids[0] != results[0].Community.CID {
+		t.Errorf("expected the results in order, got %v", cids)
+	}
+}
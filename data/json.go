@@ -0,0 +1,148 @@
+package data
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Dates are written in JSON as full dates from RFC 3339 (YYYY-MM-DD).
+const jsonDateLayout = "2006-01-02"
+
+// statusJSON is how a community is laid out in JSON, and in YAML with the
+// same names. Dates are strings and anything that's missing from the status
+// book is left out.
+type statusJSON struct {
+	CID                    int             `json:"cid" yaml:"cid"`
+	CommunityName          string          `json:"community_name,omitempty" yaml:"community_name,omitempty"`
+	Flags                  *CommunityFlags `json:"flags,omitempty" yaml:"flags,omitempty"`
+	Kind                   NameKind        `json:"kind,omitempty" yaml:"kind,omitempty"`
+	BaseName               string          `json:"base_name,omitempty" yaml:"base_name,omitempty"`
+	Type                   CommunityType   `json:"type,omitempty" yaml:"type,omitempty"`
+	County                 string          `json:"county,omitempty" yaml:"county,omitempty"`
+	State                  string          `json:"state,omitempty" yaml:"state,omitempty"`
+	StateFIPS              int             `json:"state_fips,omitempty" yaml:"state_fips,omitempty"`
+	FHBMIdentified         string          `json:"fhbm_identified,omitempty" yaml:"fhbm_identified,omitempty"`
+	FIRMIdentified         string          `json:"firm_identified,omitempty" yaml:"firm_identified,omitempty"`
+	CurrEffMapDate         string          `json:"curr_eff_map_date,omitempty" yaml:"curr_eff_map_date,omitempty"`
+	RegEmerDate            string          `json:"reg_emer_date,omitempty" yaml:"reg_emer_date,omitempty"`
+	Tribal                 bool            `json:"tribal" yaml:"tribal"`
+	CRSEntryDate           string          `json:"crs_entry_date,omitempty" yaml:"crs_entry_date,omitempty"`
+	CurrEffDate            string          `json:"curr_eff_date,omitempty" yaml:"curr_eff_date,omitempty"`
+	CurClass               *int            `json:"cur_class,omitempty" yaml:"cur_class,omitempty"`
+	PercentDiscSFHA        string          `json:"percent_disc_sfha,omitempty" yaml:"percent_disc_sfha,omitempty"`
+	PercentNonSFHA         string          `json:"percent_non_sfha,omitempty" yaml:"percent_non_sfha,omitempty"`
+	Program                ProgramKind     `json:"program,omitempty" yaml:"program,omitempty"`
+	ParticipatingCommunity bool            `json:"participating_community" yaml:"participating_community"`
+}
+
+func (c NFIPCommunityStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.toStatusJSON())
+}
+
+func (c NFIPCommunityStatus) toStatusJSON() statusJSON {
+	sj := statusJSON{
+		CID:                    c.CID,
+		CommunityName:          c.CommunityName,
+		Kind:                   c.Kind,
+		BaseName:               c.BaseName,
+		Type:                   c.Type,
+		County:                 c.County,
+		State:                  c.State,
+		StateFIPS:              c.StateFIPS,
+		FHBMIdentified:         formatJSONDate(c.FHBMIdentified),
+		FIRMIdentified:         formatJSONDate(c.FIRMIdentified),
+		CurrEffMapDate:         formatJSONDate(c.CurrEffMapDate),
+		RegEmerDate:            formatJSONDate(c.RegEmerDate),
+		Tribal:                 c.Tribal,
+		CRSEntryDate:           formatJSONDate(c.CRSEntryDate),
+		CurrEffDate:            formatJSONDate(c.CurrEffDate),
+		CurClass:               c.CurClass,
+		PercentDiscSFHA:        c.PercentDiscSFHA,
+		PercentNonSFHA:         c.PercentNonSFHA,
+		Program:                c.Program,
+		ParticipatingCommunity: c.ParticipatingCommunity,
+	}
+
+	// Only include the flags when the community has any of them
+	if c.Flags != (CommunityFlags{}) {
+		flags := c.Flags
+		sj.Flags = &flags
+	}
+
+	return sj
+}
+
+func (c *NFIPCommunityStatus) UnmarshalJSON(b []byte) error {
+	var sj statusJSON
+	if err := json.Unmarshal(b, &sj); err != nil {
+		return err
+	}
+
+	nc := NFIPCommunityStatus{
+		CID:                    sj.CID,
+		CommunityName:          sj.CommunityName,
+		Kind:                   sj.Kind,
+		BaseName:               sj.BaseName,
+		Type:                   sj.Type,
+		County:                 sj.County,
+		State:                  sj.State,
+		StateFIPS:              sj.StateFIPS,
+		Tribal:                 sj.Tribal,
+		CurClass:               sj.CurClass,
+		PercentDiscSFHA:        sj.PercentDiscSFHA,
+		PercentNonSFHA:         sj.PercentNonSFHA,
+		Program:                sj.Program,
+		ParticipatingCommunity: sj.ParticipatingCommunity,
+	}
+
+	if sj.Flags != nil {
+		nc.Flags = *sj.Flags
+	}
+
+	dates := []struct {
+		s string
+		t **time.Time
+	}{
+		{sj.FHBMIdentified, &nc.FHBMIdentified},
+		{sj.FIRMIdentified, &nc.FIRMIdentified},
+		{sj.CurrEffMapDate, &nc.CurrEffMapDate},
+		{sj.RegEmerDate, &nc.RegEmerDate},
+		{sj.CRSEntryDate, &nc.CRSEntryDate},
+		{sj.CurrEffDate, &nc.CurrEffDate},
+	}
+
+	for _, d := range dates {
+		t, err := parseJSONDate(d.s)
+		if err != nil {
+			return err
+		}
+		*d.t = t
+	}
+
+	nc.keys = newSearchKeys(nc)
+	*c = nc
+	return nil
+}
+
+func formatJSONDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(jsonDateLayout)
+}
+
+// Dates are parsed into the local time zone, the same as when
+// they're parsed from the status book.
+func parseJSONDate(s string) (*time.Time, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+
+	t, err := time.ParseInLocation(jsonDateLayout, s, time.Local)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
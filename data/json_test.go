@@ -0,0 +1,54 @@
+package data
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("expected the demo status book to be parsed, got %s", err)
+	}
+
+	b, err := json.Marshal(communities)
+	if err != nil {
+		t.Fatalf("expected the communities to be marshaled, got %s", err)
+	}
+
+	var result NFIPCommunityStatuses
+	if err := json.Unmarshal(b, &result); err != nil {
+		t.Fatalf("expected the communities to be unmarshaled, got %s", err)
+	}
+
+	if !reflect.DeepEqual(communities, result) {
+		t.Errorf("expected the communities to be the same after a round trip")
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+	springfield := communities.Search("springfield").Communities()
+	if len(springfield) != 1 {
+		t.Fatalf("expected to find Springfield in the demo status book")
+	}
+
+	b, err := json.Marshal(springfield[0])
+	if err != nil {
+		t.Fatalf("expected the community to be marshaled, got %s", err)
+	}
+
+	// Dates are YYYY-MM-DD and Springfield isn't in the CRS, so the CRS fields are left out
+	s := string(b)
+	if !strings.Contains(s, `"firm_identified":"1981-03-16"`) {
+		t.Errorf("expected dates to be written as YYYY-MM-DD, got %s", s)
+	}
+
+	for _, field := range []string{"crs_entry_date", "cur_class", "percent_disc_sfha", "flags", "null"} {
+		if strings.Contains(s, field) {
+			t.Errorf("expected %s to be left out, got %s", field, s)
+		}
+	}
+}
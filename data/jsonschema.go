@@ -0,0 +1,182 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSONSchemaDialect is the version of JSON Schema that GenerateJSONSchema uses.
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// A jsonSchema is the part of JSON Schema that's needed to describe a community.
+type jsonSchema struct {
+	Schema               string           `json:"$schema,omitempty"`
+	Ref                  string           `json:"$ref,omitempty"`
+	Title                string           `json:"title,omitempty"`
+	Description          string           `json:"description,omitempty"`
+	Type                 string           `json:"type,omitempty"`
+	Format               string           `json:"format,omitempty"`
+	Pattern              string           `json:"pattern,omitempty"`
+	Enum                 []string         `json:"enum,omitempty"`
+	Minimum              *int             `json:"minimum,omitempty"`
+	Maximum              *int             `json:"maximum,omitempty"`
+	Properties           jsonSchemaFields `json:"properties,omitempty"`
+	Required             []string         `json:"required,omitempty"`
+	AdditionalProperties *bool            `json:"additionalProperties,omitempty"`
+	Defs                 jsonSchemaFields `json:"$defs,omitempty"`
+}
+
+type jsonSchemaField struct {
+	name   string
+	schema *jsonSchema
+}
+
+// jsonSchemaFields are written in order, so that the properties in the
+// schema are in the same order as they are in a community's JSON.
+type jsonSchemaFields []jsonSchemaField
+
+func (fields jsonSchemaFields) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		b, err := json.Marshal(f.schema)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.WriteString(strconv.Quote(f.name))
+		buf.WriteByte(':')
+		buf.Write(b)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+func intPointer(n int) *int {
+	return &n
+}
+
+func boolPointer(b bool) *bool {
+	return &b
+}
+
+// What each field is, beyond its name and type. Fields that aren't
+// here are described by their type alone.
+var jsonSchemaDetails = map[string]jsonSchema{
+	"cid": {
+		Description: "The community's ID. The first two digits are usually the state's FIPS code.",
+		Minimum:     intPointer(0),
+		Maximum:     intPointer(999999),
+	},
+	"community_name": {Description: "The community's name as it's written in the status book, without its flags."},
+	"flags":          {Description: "The markers after the community's name. Left out when it doesn't have any."},
+	"kind": {
+		Description: "The kind of jurisdiction the community's name says it is.",
+		Enum:        []string{string(NameKindCity), string(NameKindTown), string(NameKindVillage), string(NameKindBorough), string(NameKindTownship), string(NameKindUnincorporatedAreas), string(NameKindCommonwealth), string(NameKindTerritory)},
+	},
+	"base_name": {Description: "The community's name without its kind, like \"HOUSTON\" for \"HOUSTON, CITY OF\"."},
+	"type": {
+		Description: "The type of jurisdiction the community is.",
+		Enum:        []string{string(CommunityTypeCity), string(CommunityTypeTown), string(CommunityTypeVillage), string(CommunityTypeCounty), string(CommunityTypeTribal), string(CommunityTypeOther)},
+	},
+	"county":     {Description: "The counties the community is in, separated by slashes."},
+	"state":      {Description: "The state's postal abbreviation.", Pattern: "^[A-Z]{2}$"},
+	"state_fips": {Description: "The state's FIPS code.", Minimum: intPointer(1), Maximum: intPointer(99)},
+	"fhbm_identified": {
+		Description: "When the first Flood Hazard Boundary Map was issued.",
+		Format:      "date",
+	},
+	"firm_identified": {
+		Description: "When the first Flood Insurance Rate Map was issued.",
+		Format:      "date",
+	},
+	"curr_eff_map_date": {Description: "When the current map went into effect.", Format: "date"},
+	"reg_emer_date":     {Description: "When the community joined the Regular or Emergency Program.", Format: "date"},
+	"tribal":            {Description: "Whether the community is a tribal entity."},
+	"crs_entry_date":    {Description: "When the community joined the Community Rating System.", Format: "date"},
+	"curr_eff_date":     {Description: "When the community's current CRS class went into effect.", Format: "date"},
+	"cur_class": {
+		Description: "The community's CRS class, where 1 is the best. Left out when it isn't in the CRS.",
+		Minimum:     intPointer(1),
+		Maximum:     intPointer(10),
+	},
+	"percent_disc_sfha": {Description: "The premium discount in percent for policies in the Special Flood Hazard Area."},
+	"percent_non_sfha":  {Description: "The premium discount in percent for policies outside of the Special Flood Hazard Area."},
+	"program": {
+		Description: "The NFIP program the community is in.",
+		Enum:        []string{ProgramEmergency.String(), ProgramRegular.String(), ProgramNotParticipating.String(), ProgramSuspended.String(), ProgramWithdrawn.String()},
+	},
+	"participating_community": {Description: "Whether the community participates in the NFIP."},
+}
+
+var jsonSchemaFlagDetails = map[string]jsonSchema{
+	"suspended":             {Description: "The community is suspended from the NFIP, (S) in the status book."},
+	"withdrawn":             {Description: "The community has withdrawn from the NFIP, (W) in the status book."},
+	"minimally_flood_prone": {Description: "The community is minimally flood prone, (M) in the status book."},
+	"non_flood_prone":       {Description: "The community doesn't have any special flood hazard areas, (NSFHA) in the status book."},
+}
+
+// GenerateJSONSchema describes a community in JSON, the same as ToJSON
+// writes each of them, as a JSON Schema. Fields that are left out when
+// they're empty aren't required.
+func GenerateJSONSchema() ([]byte, error) {
+	flags := objectJSONSchema(reflect.TypeOf(CommunityFlags{}), jsonSchemaFlagDetails)
+	flags.Description = "Flags that the status book puts after a community's name."
+
+	schema := objectJSONSchema(reflect.TypeOf(statusJSON{}), jsonSchemaDetails)
+	schema.Schema = JSONSchemaDialect
+	schema.Title = "NFIP community status"
+	schema.Description = "A community in FEMA's NFIP Community Status Book."
+	schema.Defs = jsonSchemaFields{{"flags", flags}}
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// objectJSONSchema describes a struct from its fields' JSON names and types,
+// along with the details for each of them.
+func objectJSONSchema(t reflect.Type, details map[string]jsonSchema) *jsonSchema {
+	schema := &jsonSchema{Type: "object", AdditionalProperties: boolPointer(false)}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		property := details[name]
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		switch {
+		case fieldType == reflect.TypeOf(CommunityFlags{}):
+			property.Ref = "#/$defs/flags"
+		case fieldType == reflect.TypeOf(ProgramKind(0)):
+			property.Type = "string"
+		case fieldType.Kind() == reflect.Int:
+			property.Type = "integer"
+		case fieldType.Kind() == reflect.Bool:
+			property.Type = "boolean"
+		default:
+			property.Type = "string"
+		}
+
+		schema.Properties = append(schema.Properties, jsonSchemaField{name, &property})
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
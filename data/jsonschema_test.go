@@ -0,0 +1,148 @@
+package data
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+// A schema that's only as much of JSON Schema as the tests need.
+type testJSONSchema struct {
+	Schema               string                     `json:"$schema"`
+	Ref                  string                     `json:"$ref"`
+	Type                 string                     `json:"type"`
+	Format               string                     `json:"format"`
+	Pattern              string                     `json:"pattern"`
+	Enum                 []string                   `json:"enum"`
+	Minimum              *float64                   `json:"minimum"`
+	Maximum              *float64                   `json:"maximum"`
+	Properties           map[string]*testJSONSchema `json:"properties"`
+	Required             []string                   `json:"required"`
+	AdditionalProperties *bool                      `json:"additionalProperties"`
+	Defs                 map[string]*testJSONSchema `json:"$defs"`
+}
+
+// validate checks the value against the schema, reporting what doesn't match.
+func (s *testJSONSchema) validate(t *testing.T, root *testJSONSchema, path string, value interface{}) {
+	t.Helper()
+
+	if len(s.Ref) > 0 {
+		root.Defs[s.Ref[len("#/$defs/"):]].validate(t, root, path, value)
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if s.Type != "object" {
+			t.Errorf("%s: expected %s, got an object", path, s.Type)
+			return
+		}
+
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				t.Errorf("%s: expected %s to be there", path, name)
+			}
+		}
+
+		for name, field := range v {
+			property, ok := s.Properties[name]
+			if !ok {
+				t.Errorf("%s: %s isn't in the schema", path, name)
+				continue
+			}
+			property.validate(t, root, path+"."+name, field)
+		}
+	case string:
+		if s.Type != "string" {
+			t.Errorf("%s: expected %s, got %q", path, s.Type, v)
+		}
+
+		if len(s.Enum) > 0 && !containsString(s.Enum, v) {
+			t.Errorf("%s: expected one of %v, got %q", path, s.Enum, v)
+		}
+
+		if len(s.Pattern) > 0 && !regexp.MustCompile(s.Pattern).MatchString(v) {
+			t.Errorf("%s: expected %q to match %s", path, v, s.Pattern)
+		}
+
+		if s.Format == "date" && !regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`).MatchString(v) {
+			t.Errorf("%s: expected a date, got %q", path, v)
+		}
+	case float64:
+		if s.Type != "integer" || v != float64(int(v)) {
+			t.Errorf("%s: expected %s, got %v", path, s.Type, v)
+		}
+
+		if (s.Minimum != nil && v < *s.Minimum) || (s.Maximum != nil && v > *s.Maximum) {
+			t.Errorf("%s: %v is out of range", path, v)
+		}
+	case bool:
+		if s.Type != "boolean" {
+			t.Errorf("%s: expected %s, got %v", path, s.Type, v)
+		}
+	default:
+		t.Errorf("%s: unexpected %v", path, v)
+	}
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestGenerateJSONSchema(t *testing.T) {
+	b, err := GenerateJSONSchema()
+	if err != nil {
+		t.Fatalf("expected a schema, got %s", err)
+	}
+
+	var schema testJSONSchema
+	if err := json.Unmarshal(b, &schema); err != nil {
+		t.Fatalf("expected the schema to be JSON, got %s", err)
+	}
+
+	if schema.Schema != JSONSchemaDialect || schema.Type != "object" {
+		t.Errorf("expected an object in %s, got %s in %s", JSONSchemaDialect, schema.Type, schema.Schema)
+	}
+
+	if expected := []string{"cid", "tribal", "participating_community"}; !reflect.DeepEqual(schema.Required, expected) {
+		t.Errorf("expected %v to be required, got %v", expected, schema.Required)
+	}
+
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+	cls := 3
+	communities = append(communities, NFIPCommunityStatus{CID: 1, CurClass: &cls, Flags: CommunityFlags{Suspended: true, NonFloodProne: true}})
+
+	for _, community := range communities {
+		b, _ := json.Marshal(community)
+
+		var v interface{}
+		json.Unmarshal(b, &v)
+		schema.validate(t, &schema, community.CommunityName, v)
+	}
+}
+
+// The properties are in the same order as the fields in a community's JSON.
+func TestJSONSchemaOrder(t *testing.T) {
+	schema := objectJSONSchema(reflect.TypeOf(statusJSON{}), jsonSchemaDetails)
+
+	if len(schema.Properties) != len(statusFields) {
+		t.Fatalf("expected %d properties, got %d", len(statusFields), len(schema.Properties))
+	}
+
+	for i, f := range statusFields {
+		if schema.Properties[i].name != f.name {
+			t.Errorf("expected property %d to be %s, got %s", i, f.name, schema.Properties[i].name)
+		}
+
+		if _, ok := jsonSchemaDetails[f.name]; !ok {
+			t.Errorf("expected %s to be described", f.name)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package data
+
+import (
+	"strconv"
+	"strings"
+
+	"nfip-community-book/states"
+)
+
+// searchKeys are the folded fields that searches look through. They're
+// worked out once when the community is loaded instead of on every search.
+type searchKeys struct {
+	// What the keys were made from, so that they can be
+	// checked in case the community was changed since
+	name     string
+	baseName string
+	county   string
+	cidValue int
+	state    string
+
+	// How the keys were folded
+	folding folding
+
+	lowerName     string
+	lowerBaseName string
+	lowerCounty   string
+	cid           string
+
+	// Communities in more than one county have them separated by "/"
+	lowerCounties []string
+
+	lowerState     string
+	lowerStateName string
+}
+
+func newSearchKeys(c NFIPCommunityStatus) *searchKeys {
+	return newFoldedSearchKeys(c, folding{})
+}
+
+func newFoldedSearchKeys(c NFIPCommunityStatus, f folding) *searchKeys {
+	lowerCounty := f.fold(c.County)
+
+	var stateName string
+	if s, ok := states.ByAbbreviation(c.State); ok {
+		stateName = s.Name
+	}
+
+	return &searchKeys{
+		name:     c.CommunityName,
+		baseName: c.BaseName,
+		county:   c.County,
+		cidValue: c.CID,
+		state:    c.State,
+
+		folding: f,
+
+		lowerName:     f.fold(c.CommunityName),
+		lowerBaseName: f.fold(c.BaseName),
+		lowerCounty:   lowerCounty,
+		cid:           strconv.Itoa(c.CID),
+		lowerCounties: strings.Split(lowerCounty, "/"),
+
+		lowerState:     f.fold(c.State),
+		lowerStateName: f.fold(stateName),
+	}
+}
+
+func (k *searchKeys) current(c NFIPCommunityStatus, f folding) bool {
+	return k.name == c.CommunityName && k.baseName == c.BaseName && k.county == c.County &&
+		k.cidValue == c.CID && k.state == c.State && k.folding == f
+}
+
+// inState reports whether the lowercase term is the
+// community's state, by its abbreviation or its name.
+func (k *searchKeys) inState(term string) bool {
+	return len(term) > 0 && (term == k.lowerState || term == k.lowerStateName)
+}
+
+// searchKeys returns the community's keys, making them again
+// if they weren't made when it was loaded or are out of date.
+func (c NFIPCommunityStatus) searchKeys() *searchKeys {
+	return c.foldedSearchKeys(folding{})
+}
+
+// foldedSearchKeys is the same as searchKeys, but folded the given way.
+// Keys that aren't folded the default way are made on every search.
+func (c NFIPCommunityStatus) foldedSearchKeys(f folding) *searchKeys {
+	if c.keys != nil && c.keys.current(c, f) {
+		return c.keys
+	}
+
+	return newFoldedSearchKeys(c, f)
+}
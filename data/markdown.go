@@ -0,0 +1,137 @@
+package data
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Markdown needs at least this many dashes under each header.
+const minMarkdownDashes = 3
+
+// ToMarkdown writes the communities out as a GitHub Flavored Markdown
+// table, which can be pasted into issues, wikis and reports. The columns
+// are the same as ToTable's, including how they're cut off and aligned.
+//
+//	| CID    | COMMUNITY            | STATE |
+//	| ------ | -------------------- | ----- |
+//	| 170604 | SPRINGFIELD, CITY OF | IL    |
+func (c *NFIPCommunityStatuses) ToMarkdown(w io.Writer, columns ...Column) error {
+	if len(columns) == 0 {
+		columns = DefaultTableColumns
+	}
+
+	rows := make([][]string, 0, len(*c)+1)
+
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = escapeMarkdown(col.Header)
+	}
+	rows = append(rows, header)
+
+	for _, community := range *c {
+		row := make([]string, len(columns))
+		for i, col := range columns {
+			value := col.value(community)
+			if col.MaxWidth > 0 {
+				value = truncateTableValue(value, col.MaxWidth)
+			}
+			row[i] = escapeMarkdown(value)
+		}
+		rows = append(rows, row)
+	}
+
+	return writeMarkdownRows(w, rows, columns)
+}
+
+// WriteMarkdown writes the rows out as a Markdown table the same way as
+// ToMarkdown, for tables of things that aren't communities. The first row
+// is the header.
+func WriteMarkdown(w io.Writer, rows [][]string) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	escaped := make([][]string, len(rows))
+	for i, row := range rows {
+		escaped[i] = make([]string, len(row))
+		for j, value := range row {
+			escaped[i][j] = escapeMarkdown(value)
+		}
+	}
+
+	return writeMarkdownRows(w, escaped, make([]Column, len(rows[0])))
+}
+
+// writeMarkdownRows writes the rows, which are already escaped, lined up
+// the way that the columns are.
+func writeMarkdownRows(w io.Writer, rows [][]string, columns []Column) error {
+	// The values are already cut off, so this only pads them
+	widths := tableWidths(rows, make([]Column, len(columns)), 0)
+	for i := range widths {
+		widths[i] = maxInt(widths[i], minMarkdownDashes)
+	}
+
+	bw := bufio.NewWriter(w)
+
+	writeMarkdownRow(bw, rows[0], columns, widths)
+	bw.WriteString("|")
+	for i, col := range columns {
+		bw.WriteString(" " + markdownDelimiter(col.Align, widths[i]) + " |")
+	}
+	bw.WriteString("\n")
+
+	for _, row := range rows[1:] {
+		writeMarkdownRow(bw, row, columns, widths)
+	}
+
+	return bw.Flush()
+}
+
+func writeMarkdownRow(w *bufio.Writer, row []string, columns []Column, widths []int) {
+	w.WriteString("|")
+	for i, value := range row {
+		w.WriteString(" " + padMarkdown(value, columns[i].Align, widths[i]) + " |")
+	}
+	w.WriteString("\n")
+}
+
+// markdownDelimiter is the row under the header, which also
+// says how the column is aligned.
+func markdownDelimiter(align Alignment, width int) string {
+	switch align {
+	case AlignLeft:
+		return ":" + strings.Repeat("-", width-1)
+	case AlignCenter:
+		return ":" + strings.Repeat("-", width-2) + ":"
+	case AlignRight:
+		return strings.Repeat("-", width-1) + ":"
+	}
+
+	return strings.Repeat("-", width)
+}
+
+// padMarkdown lines the value up in its column so that the table is
+// easy to read before it's rendered too.
+func padMarkdown(value string, align Alignment, width int) string {
+	padding := width - utf8.RuneCountInString(value)
+	if padding <= 0 {
+		return value
+	}
+
+	switch align {
+	case AlignRight:
+		return strings.Repeat(" ", padding) + value
+	case AlignCenter:
+		return strings.Repeat(" ", padding/2) + value + strings.Repeat(" ", padding-padding/2)
+	}
+
+	return value + strings.Repeat(" ", padding)
+}
+
+// escapeMarkdown keeps a value from breaking out of its cell.
+func escapeMarkdown(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.Join(strings.Fields(s), " ")
+}
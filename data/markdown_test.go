@@ -0,0 +1,61 @@
+package data
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestToMarkdown(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+	springfield := communities.Search("springfield").Communities()
+
+	var buf bytes.Buffer
+	if err := springfield.ToMarkdown(&buf, CIDColumn, NameColumn, StateColumn); err != nil {
+		t.Fatalf("expected a table, got %s", err)
+	}
+
+	expected := "| CID    | COMMUNITY            | STATE |\n" +
+		"| ------ | -------------------- | ----- |\n" +
+		"| 170604 | SPRINGFIELD, CITY OF | IL    |\n"
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+}
+
+func TestToMarkdownAlignment(t *testing.T) {
+	class := 5
+	communities := NFIPCommunityStatuses{
+		{CID: 480287, CommunityName: "HOUSTON | HARRIS", State: "TX", CurClass: &class},
+	}
+
+	var buf bytes.Buffer
+	communities.ToMarkdown(&buf, NameColumn.Truncate(12).Aligned(AlignLeft), StateColumn.Aligned(AlignCenter), ClassColumn.Aligned(AlignRight))
+
+	expected := "| COMMUNITY     | STATE | CRS CLASS |\n" +
+		"| :------------ | :---: | --------: |\n" +
+		"| HOUSTON \\|... |  TX   |         5 |\n"
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	rows := [][]string{
+		{"STATE", "COUNT"},
+		{"TX", "3"},
+		{"A | B", "12"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, rows); err != nil {
+		t.Fatalf("expected a table, got %s", err)
+	}
+
+	expected := "| STATE  | COUNT |\n" +
+		"| ------ | ----- |\n" +
+		"| TX     | 3     |\n" +
+		"| A \\| B | 12    |\n"
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,141 @@
+package data
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NameKind is the kind of jurisdiction a community's name says it is.
+type NameKind string
+
+const (
+	NameKindNone                NameKind = ""
+	NameKindCity                NameKind = "City"
+	NameKindTown                NameKind = "Town"
+	NameKindVillage             NameKind = "Village"
+	NameKindBorough             NameKind = "Borough"
+	NameKindTownship            NameKind = "Township"
+	NameKindUnincorporatedAreas NameKind = "Unincorporated Areas"
+	NameKindCommonwealth        NameKind = "Commonwealth"
+	NameKindTerritory           NameKind = "Territory"
+)
+
+// The designations that are put before or after a community's base name,
+// as in "CITY OF SPRINGFIELD" or (more often) "SPRINGFIELD, CITY OF".
+var nameDesignations = []struct {
+	designation string
+	kind        NameKind
+}{
+	{"CITY OF", NameKindCity},
+	{"TOWN OF", NameKindTown},
+	{"VILLAGE OF", NameKindVillage},
+	{"BOROUGH OF", NameKindBorough},
+	{"TOWNSHIP OF", NameKindTownship},
+	{"UNINCORPORATED AREAS", NameKindUnincorporatedAreas},
+	{"COMMONWEALTH OF", NameKindCommonwealth},
+	{"TERRITORY OF", NameKindTerritory},
+}
+
+// splitCommunityName splits the designation off of a community's name,
+// returning the kind of jurisdiction it is and the name without it.
+func splitCommunityName(name string) (NameKind, string) {
+	name = strings.TrimSpace(name)
+	upper := strings.ToUpper(name)
+
+	for _, nd := range nameDesignations {
+		if strings.HasSuffix(upper, nd.designation) {
+			base := strings.TrimSpace(name[:len(name)-len(nd.designation)])
+			if strings.HasSuffix(base, ",") {
+				return nd.kind, strings.TrimSpace(strings.TrimSuffix(base, ","))
+			}
+		}
+
+		if strings.HasPrefix(upper, nd.designation+" ") {
+			return nd.kind, strings.TrimSpace(name[len(nd.designation):])
+		}
+	}
+
+	// Counties' unincorporated areas are marked with an asterisk
+	if strings.HasSuffix(name, "*") {
+		return NameKindUnincorporatedAreas, strings.TrimSpace(strings.TrimSuffix(name, "*"))
+	}
+
+	return NameKindNone, name
+}
+
+// CommunityType is the type of jurisdiction a community is.
+type CommunityType string
+
+const (
+	CommunityTypeCity    CommunityType = "city"
+	CommunityTypeTown    CommunityType = "town"
+	CommunityTypeVillage CommunityType = "village"
+
+	// The unincorporated areas of a county, parish, or borough
+	CommunityTypeCounty CommunityType = "county"
+
+	CommunityTypeTribal CommunityType = "tribal"
+
+	// Everything else, like townships, territories, and special districts
+	CommunityTypeOther CommunityType = "other"
+)
+
+// Words that only show up in the names of tribal entities.
+var tribalNamePatterns = []string{"TRIBE", "TRIBAL", "NATION", "PUEBLO", "RANCHERIA", "RESERVATION", "BAND OF", "INDIAN COMMUNITY"}
+
+// The county equivalents in states that don't have counties.
+var countyNameSuffixes = []string{" COUNTY", " PARISH", " BOROUGH", " CENSUS AREA", " MUNICIPALITY"}
+
+// classifyCommunity works out the type of jurisdiction
+// from the community's name and whether it's tribal.
+func classifyCommunity(c NFIPCommunityStatus) CommunityType {
+	name := strings.ToUpper(c.BaseName)
+
+	if c.Tribal {
+		return CommunityTypeTribal
+	}
+
+	// A name that says what it is, like "NATIONAL CITY, CITY OF", is taken
+	// at its word before the tribal patterns are looked for in it
+	switch c.Kind {
+	case NameKindCity:
+		return CommunityTypeCity
+	case NameKindTown:
+		return CommunityTypeTown
+	case NameKindVillage:
+		return CommunityTypeVillage
+	case NameKindUnincorporatedAreas:
+		return CommunityTypeCounty
+	}
+
+	if hasTribalName(name) {
+		return CommunityTypeTribal
+	}
+
+	if c.Kind == NameKindNone {
+		// Some counties are listed by just their name
+		for _, suffix := range countyNameSuffixes {
+			if strings.HasSuffix(name, suffix) {
+				return CommunityTypeCounty
+			}
+		}
+	}
+
+	return CommunityTypeOther
+}
+
+// hasTribalName is whether any of the tribal patterns are whole words in
+// the name, so "NATION" matches "NAVAJO NATION" but not "NATIONAL".
+func hasTribalName(name string) bool {
+	words := " " + strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ") + " "
+
+	for _, pattern := range tribalNamePatterns {
+		if strings.Contains(words, " "+pattern+" ") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,62 @@
+package data
+
+import "testing"
+
+func TestSplitCommunityName(t *testing.T) {
+	tests := []struct {
+		name string
+		kind NameKind
+		base string
+	}{
+		{"SPRINGFIELD, CITY OF", NameKindCity, "SPRINGFIELD"},
+		{"NEW HEBRON, TOWN OF", NameKindTown, "NEW HEBRON"},
+		{"Village of Oak Park", NameKindVillage, "Oak Park"},
+		{"HARRIS COUNTY, UNINCORPORATED AREAS", NameKindUnincorporatedAreas, "HARRIS COUNTY"},
+		{"HARRIS COUNTY *", NameKindUnincorporatedAreas, "HARRIS COUNTY"},
+		{"PUERTO RICO, COMMONWEALTH OF", NameKindCommonwealth, "PUERTO RICO"},
+		{"BRICK, TOWNSHIP OF", NameKindTownship, "BRICK"},
+
+		// Designations are only split off when they're separate from the name
+		{"SEMINOLE TRIBE OF FLORIDA", NameKindNone, "SEMINOLE TRIBE OF FLORIDA"},
+		{"KANSAS CITY OF MISSOURI", NameKindNone, "KANSAS CITY OF MISSOURI"},
+	}
+
+	for _, test := range tests {
+		kind, base := splitCommunityName(test.name)
+		if kind != test.kind || base != test.base {
+			t.Errorf("expected \"%s\" to be split into \"%s\" and \"%s\", got \"%s\" and \"%s\"", test.name, test.kind, test.base, kind, base)
+		}
+	}
+}
+
+func TestClassifyCommunity(t *testing.T) {
+	tests := []struct {
+		name     string
+		tribal   bool
+		expected CommunityType
+	}{
+		{"SPRINGFIELD, CITY OF", false, CommunityTypeCity},
+		{"NEW HEBRON, TOWN OF", false, CommunityTypeTown},
+		{"OAK PARK, VILLAGE OF", false, CommunityTypeVillage},
+		{"HARRIS COUNTY *", false, CommunityTypeCounty},
+		{"TERREBONNE PARISH", false, CommunityTypeCounty},
+		{"NORTH SLOPE BOROUGH", false, CommunityTypeCounty},
+		{"SEMINOLE TRIBE OF FLORIDA", false, CommunityTypeTribal},
+		{"PASCUA YAQUI", true, CommunityTypeTribal},
+		{"NAVAJO NATION", false, CommunityTypeTribal},
+		{"NATIONAL CITY, CITY OF", false, CommunityTypeCity},
+		{"NATIONAL PARK, BOROUGH OF", false, CommunityTypeOther},
+		{"PUEBLO, CITY OF", false, CommunityTypeCity},
+		{"BRICK, TOWNSHIP OF", false, CommunityTypeOther},
+		{"PUERTO RICO, COMMONWEALTH OF", false, CommunityTypeOther},
+	}
+
+	for _, test := range tests {
+		c := NFIPCommunityStatus{CommunityName: test.name, Tribal: test.tribal}
+		c.Kind, c.BaseName = splitCommunityName(c.CommunityName)
+
+		if ct := classifyCommunity(c); ct != test.expected {
+			t.Errorf("expected \"%s\" to be a %s, got %s", test.name, test.expected, ct)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package data
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// ToNDJSON writes each community as JSON on a line of its own, so that
+// they can be read one at a time without reading the whole list first.
+// No communities are written as nothing at all. WithFields picks the
+// fields on each line.
+func (c *NFIPCommunityStatuses) ToNDJSON(w io.Writer, opts ...EncodeOption) error {
+	var projected []ProjectedStatus
+	if o := newEncodeOptions(opts); len(o.fields) > 0 {
+		var err error
+		if projected, err = c.Project(o.fields...); err != nil {
+			return err
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	e := json.NewEncoder(bw)
+
+	for i, community := range *c {
+		var v any = community
+		if projected != nil {
+			v = projected[i]
+		}
+
+		if err := e.Encode(v); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
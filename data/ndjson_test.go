@@ -0,0 +1,43 @@
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestToNDJSON(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+
+	var buf bytes.Buffer
+	if err := communities.ToNDJSON(&buf); err != nil {
+		t.Fatalf("expected the communities to be written as NDJSON, got %s", err)
+	}
+
+	var read NFIPCommunityStatuses
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var c NFIPCommunityStatus
+		if err := json.Unmarshal(scanner.Bytes(), &c); err != nil {
+			t.Fatalf("expected a community on line %d, got %s", len(read)+1, err)
+		}
+		read = append(read, c)
+	}
+
+	if len(read) != len(communities) {
+		t.Fatalf("expected %d lines, got %d", len(communities), len(read))
+	}
+
+	for i := range communities {
+		if read[i].CID != communities[i].CID || read[i].CommunityName != communities[i].CommunityName {
+			t.Errorf("expected %s on line %d, got %s", communities[i].CommunityName, i+1, read[i].CommunityName)
+		}
+	}
+
+	buf.Reset()
+	empty := NFIPCommunityStatuses{}
+	if err := empty.ToNDJSON(&buf); err != nil || buf.Len() > 0 {
+		t.Errorf("expected nothing for no communities, got %q %v", buf.String(), err)
+	}
+}
@@ -0,0 +1,61 @@
+package data
+
+import (
+	"runtime"
+	"time"
+)
+
+// A ParseOption changes how the Community Status Book is parsed.
+type ParseOption func(*parseOptions)
+
+type parseOptions struct {
+	yearPivot       int
+	columnAliases   map[int][]string
+	duplicatePolicy DuplicatePolicy
+	onUnparsable    func(UnparsableValue)
+	workers         int
+
+	// Only the benchmarks turn interning off, to compare against
+	skipIntern bool
+}
+
+// How many years past the current one the default pivot is.
+const yearPivotLookahead = 5
+
+// DefaultYearPivot is the current year plus yearPivotLookahead, as two digits.
+func DefaultYearPivot() int {
+	return (time.Now().Year() + yearPivotLookahead) % 100
+}
+
+// WithYearPivot sets the two digit year that dates are split on: years up to
+// and including the pivot are in the 2000s and the rest are in the 1900s.
+func WithYearPivot(pivot int) ParseOption {
+	return func(o *parseOptions) {
+		o.yearPivot = pivot
+	}
+}
+
+// WithWorkers sets how many goroutines parse records at the same time.
+// By default, there's one for each CPU.
+func WithWorkers(n int) ParseOption {
+	return func(o *parseOptions) {
+		o.workers = n
+	}
+}
+
+func newParseOptions(opts []ParseOption) parseOptions {
+	o := parseOptions{
+		yearPivot: DefaultYearPivot(),
+		workers:   runtime.NumCPU(),
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.workers < 1 {
+		o.workers = 1
+	}
+
+	return o
+}
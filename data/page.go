@@ -0,0 +1,52 @@
+package data
+
+// A SearchPage is one page of search results,
+// along with how many results there were in total.
+// Searches that didn't find anything can suggest what
+// might have been meant instead. See DidYouMean.
+type SearchPage struct {
+	Total      int           `json:"total"`
+	Offset     int           `json:"offset"`
+	Limit      int           `json:"limit"`
+	Results    SearchResults `json:"results"`
+	DidYouMean []string      `json:"did_you_mean,omitempty"`
+}
+
+// A StatusPage is the same as a SearchPage, but for
+// searches that don't have a score, like SearchByState.
+type StatusPage struct {
+	Total       int                   `json:"total"`
+	Offset      int                   `json:"offset"`
+	Limit       int                   `json:"limit"`
+	Communities NFIPCommunityStatuses `json:"communities"`
+}
+
+// Page returns up to limit results starting at offset. A limit
+// of 0 or less returns every result after the offset.
+func (sr SearchResults) Page(offset, limit int) SearchPage {
+	start, end := pageBounds(len(sr), offset, limit)
+	return SearchPage{Total: len(sr), Offset: start, Limit: limit, Results: sr[start:end]}
+}
+
+// Page returns up to limit communities starting at offset. A limit
+// of 0 or less returns every community after the offset.
+func (c NFIPCommunityStatuses) Page(offset, limit int) StatusPage {
+	start, end := pageBounds(len(c), offset, limit)
+	return StatusPage{len(c), start, limit, c[start:end]}
+}
+
+func pageBounds(total, offset, limit int) (int, int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > total {
+		offset = total
+	}
+
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+
+	return offset, end
+}
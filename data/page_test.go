@@ -0,0 +1,41 @@
+package data
+
+import "testing"
+
+func TestPage(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	results := communities.Search("county")
+
+	page := results.Page(0, 5)
+	if page.Total != len(results) || len(page.Results) != 5 || page.Results[0].Community.CID != results[0].Community.CID {
+		t.Errorf("expected the first 5 of %d results, got %d of %d", len(results), len(page.Results), page.Total)
+	}
+
+	page = results.Page(5, 5)
+	if page.Offset != 5 || len(page.Results) != 5 || page.Results[0].Community.CID != results[5].Community.CID {
+		t.Errorf("expected the second page to start at the sixth result")
+	}
+
+	// The last page can be short, and pages past the end are empty
+	if page := results.Page(len(results)-2, 5); len(page.Results) != 2 {
+		t.Errorf("expected 2 results on the last page, got %d", len(page.Results))
+	}
+
+	if page := results.Page(len(results)+10, 5); len(page.Results) != 0 || page.Offset != len(results) {
+		t.Errorf("expected an empty page past the end, got %d results at %d", len(page.Results), page.Offset)
+	}
+
+	// No limit is everything after the offset
+	if page := results.Page(-1, 0); len(page.Results) != len(results) || page.Offset != 0 {
+		t.Errorf("expected every result without a limit, got %d", len(page.Results))
+	}
+
+	texas := communities.SearchByState("TX").Page(1, 1)
+	if texas.Total != 3 || len(texas.Communities) != 1 {
+		t.Errorf("expected 1 of 3 communities in Texas, got %d of %d", len(texas.Communities), texas.Total)
+	}
+}
@@ -0,0 +1,268 @@
+package data
+
+import (
+	"sort"
+	"strings"
+)
+
+// Phonetic codes are cut off at this many sounds,
+// which is plenty to tell community names apart.
+const maxPhoneticLength = 6
+
+// phoneticCodes encodes a word by how it sounds, so that words that are
+// spelled differently but sound the same, like "Filadelfia" and
+// "Philadelphia", have the same code. It follows the Metaphone rules, and
+// like Double Metaphone it returns an alternate code for letters that could
+// be said more than one way ("CH" in "CHARLESTON" and "CHRISTIANSBURG").
+// The alternate is the same as the primary code when there isn't one.
+func phoneticCodes(word string) (string, string) {
+	w := []byte(strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return -1
+	}, word))
+
+	if len(w) == 0 {
+		return "", ""
+	}
+
+	at := func(i int) byte {
+		if i < 0 || i >= len(w) {
+			return 0
+		}
+		return w[i]
+	}
+
+	isVowel := func(c byte) bool {
+		return c == 'A' || c == 'E' || c == 'I' || c == 'O' || c == 'U'
+	}
+
+	var primary, alternate strings.Builder
+	add := func(p, a string) {
+		primary.WriteString(p)
+		alternate.WriteString(a)
+	}
+
+	// Some letters at the start of a word are silent or said differently
+	start := 0
+	switch string(w[:min2(len(w))]) {
+	case "AE", "GN", "KN", "PN", "WR":
+		start = 1
+	}
+
+	if w[0] == 'X' {
+		add("S", "S")
+		start = 1
+	} else if w[0] == 'W' && at(1) == 'H' {
+		add("W", "W")
+		start = 2
+	}
+
+	for i := start; i < len(w) && primary.Len() < maxPhoneticLength; i++ {
+		c := w[i]
+
+		// Double letters are only said once, except for C
+		if c != 'C' && i > 0 && at(i-1) == c {
+			continue
+		}
+
+		switch c {
+		case 'A', 'E', 'I', 'O', 'U':
+			// Vowels only matter at the start
+			if i == 0 {
+				add("A", "A")
+			}
+		case 'B':
+			// "MB" at the end, like "PLUMB"
+			if !(at(i-1) == 'M' && i == len(w)-1) {
+				add("P", "P")
+			}
+		case 'C':
+			switch {
+			case at(i+1) == 'I' && at(i+2) == 'A':
+				add("X", "X")
+			case at(i+1) == 'H':
+				add("X", "K")
+				i++
+			case at(i+1) == 'I' || at(i+1) == 'E' || at(i+1) == 'Y':
+				if at(i-1) != 'S' {
+					add("S", "S")
+				}
+			default:
+				add("K", "K")
+			}
+		case 'D':
+			if at(i+1) == 'G' && (at(i+2) == 'E' || at(i+2) == 'I' || at(i+2) == 'Y') {
+				add("J", "J")
+				i++
+			} else {
+				add("T", "T")
+			}
+		case 'G':
+			switch {
+			case at(i+1) == 'H' && i+2 < len(w) && !isVowel(at(i+2)):
+				// Silent like "HIGHLAND"
+			case at(i+1) == 'N' && (i+2 == len(w) || (at(i+2) == 'E' && at(i+3) == 'D' && i+4 == len(w))):
+				// Silent like "SIGN" and "SIGNED"
+			case at(i+1) == 'I' || at(i+1) == 'E' || at(i+1) == 'Y':
+				add("J", "K")
+			default:
+				add("K", "K")
+			}
+		case 'H':
+			afterVowel := isVowel(at(i - 1))
+			beforeVowel := isVowel(at(i + 1))
+			switch at(i - 1) {
+			case 'C', 'S', 'P', 'T', 'G':
+				// Part of another sound
+			default:
+				if beforeVowel || !afterVowel {
+					add("H", "H")
+				}
+			}
+		case 'K':
+			if at(i-1) != 'C' {
+				add("K", "K")
+			}
+		case 'P':
+			if at(i+1) == 'H' {
+				add("F", "F")
+			} else {
+				add("P", "P")
+			}
+		case 'Q':
+			add("K", "K")
+		case 'S':
+			switch {
+			case at(i+1) == 'H':
+				add("X", "X")
+				i++
+			case at(i+1) == 'I' && (at(i+2) == 'O' || at(i+2) == 'A'):
+				add("X", "S")
+			default:
+				add("S", "S")
+			}
+		case 'T':
+			switch {
+			case at(i+1) == 'I' && (at(i+2) == 'O' || at(i+2) == 'A'):
+				add("X", "X")
+			case at(i+1) == 'H':
+				add("0", "T")
+				i++
+			case at(i+1) == 'C' && at(i+2) == 'H':
+				// Silent like "DUTCH"
+			default:
+				add("T", "T")
+			}
+		case 'V':
+			add("F", "F")
+		case 'W', 'Y':
+			if isVowel(at(i + 1)) {
+				add(string(c), string(c))
+			}
+		case 'X':
+			add("KS", "KS")
+		case 'Z':
+			add("S", "S")
+		default:
+			// F, J, L, M, N and R sound like themselves
+			add(string(c), string(c))
+		}
+	}
+
+	return truncate(primary.String()), truncate(alternate.String())
+}
+
+func min2(n int) int {
+	if n < 2 {
+		return n
+	}
+	return 2
+}
+
+func truncate(code string) string {
+	if len(code) > maxPhoneticLength {
+		return code[:maxPhoneticLength]
+	}
+	return code
+}
+
+// phoneticKeys are the codes for every word in s,
+// both primary and alternate, without repeats.
+func phoneticKeys(s string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+
+	for _, word := range fuzzyWords(s) {
+		p, a := phoneticCodes(word)
+		for _, code := range []string{p, a} {
+			if len(code) > 0 && !seen[code] {
+				seen[code] = true
+				keys = append(keys, code)
+			}
+		}
+	}
+
+	return keys
+}
+
+// PhoneticSearch finds communities whose name sounds like the term, so
+// "Filadelfia" still finds "PHILADELPHIA, CITY OF". Every word in the term
+// has to sound like a word in the name. Results are scored by how close
+// the spelling is, with the closest first.
+func (c NFIPCommunityStatuses) PhoneticSearch(term string) SearchResults {
+	var candidates []int
+	for i, community := range c {
+		if soundsLike(term, phoneticKeys(community.BaseName)) {
+			candidates = append(candidates, i)
+		}
+	}
+
+	return c.phoneticResults(term, candidates)
+}
+
+// soundsLike reports whether every word in the term has
+// a code that's in the keys.
+func soundsLike(term string, keys []string) bool {
+	words := fuzzyWords(term)
+	if len(words) == 0 {
+		return false
+	}
+
+	for _, word := range words {
+		p, a := phoneticCodes(word)
+		found := false
+		for _, key := range keys {
+			if key == p || key == a {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c NFIPCommunityStatuses) phoneticResults(term string, candidates []int) SearchResults {
+	term = strings.Join(fuzzyWords(term), " ")
+	results := SearchResults{}
+
+	for _, i := range candidates {
+		name := strings.Join(fuzzyWords(c[i].BaseName), " ")
+		results = append(results, SearchResult{Community: c[i], Score: similarity(term, name)})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	return results
+}
@@ -0,0 +1,56 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPhoneticCodes(t *testing.T) {
+	tests := []struct {
+		word, primary, alternate string
+	}{
+		{"Philadelphia", "FLTLF", "FLTLF"},
+		{"Filadelfia", "FLTLF", "FLTLF"},
+		{"KNOXVILLE", "NKSFL", "NKSFL"},
+		{"Charleston", "XRLSTN", "KRLSTN"},
+		{"Thompson", "0MPSN", "TMPSN"},
+		{"Wright", "RT", "RT"},
+		{"", "", ""},
+	}
+
+	for _, test := range tests {
+		p, a := phoneticCodes(test.word)
+		if p != test.primary || a != test.alternate {
+			t.Errorf("expected \"%s\" to be %s/%s, got %s/%s", test.word, test.primary, test.alternate, p, a)
+		}
+	}
+}
+
+func TestPhoneticSearch(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	results := communities.PhoneticSearch("Filadelfia")
+	if len(results) != 1 || results[0].Community.CID != 420757 {
+		t.Fatalf("expected to find Philadelphia, got %v", results.Communities())
+	}
+
+	// Every word has to match
+	if results := communities.PhoneticSearch("nu yorc"); len(results) != 1 || results[0].Community.CID != 360497 {
+		t.Errorf("expected to find New York, got %v", results.Communities())
+	}
+
+	if results := communities.PhoneticSearch("nu jersey"); len(results) != 0 {
+		t.Errorf("expected nothing for New Jersey, got %v", results.Communities())
+	}
+
+	// The index finds the same communities
+	idx := NewCommunityIndex(communities)
+	for _, term := range []string{"Filadelfia", "nu yorc", "miamy", "karleston", ""} {
+		if results := idx.PhoneticSearch(term); !reflect.DeepEqual(results, communities.PhoneticSearch(term)) {
+			t.Errorf("expected the index to find the same communities for \"%s\", got %v", term, results.Communities())
+		}
+	}
+}
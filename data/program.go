@@ -0,0 +1,94 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ProgramKind is the NFIP program a community is in.
+type ProgramKind int
+
+const (
+	ProgramUnknown ProgramKind = iota
+	ProgramEmergency
+	ProgramRegular
+	ProgramNotParticipating
+	ProgramSuspended
+	ProgramWithdrawn
+)
+
+var ErrInvalidProgram = fmt.Errorf("invalid program")
+
+var programNames = map[ProgramKind]string{
+	ProgramUnknown:          "Unknown",
+	ProgramEmergency:        "Emergency",
+	ProgramRegular:          "Regular",
+	ProgramNotParticipating: "Not Participating",
+	ProgramSuspended:        "Suspended",
+	ProgramWithdrawn:        "Withdrawn",
+}
+
+// The status book uses single letter codes for the program, but
+// we also accept the full names so that users can filter with them.
+var programsByName = map[string]ProgramKind{
+	"e":                 ProgramEmergency,
+	"emergency":         ProgramEmergency,
+	"r":                 ProgramRegular,
+	"regular":           ProgramRegular,
+	"n":                 ProgramNotParticipating,
+	"np":                ProgramNotParticipating,
+	"not participating": ProgramNotParticipating,
+	"notparticipating":  ProgramNotParticipating,
+	"s":                 ProgramSuspended,
+	"suspended":         ProgramSuspended,
+	"w":                 ProgramWithdrawn,
+	"withdrawn":         ProgramWithdrawn,
+}
+
+func (p ProgramKind) String() string {
+	if name, ok := programNames[p]; ok {
+		return name
+	}
+
+	return programNames[ProgramUnknown]
+}
+
+func (p ProgramKind) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+func (p *ProgramKind) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	program, err := ParseProgramKind(s)
+	if err != nil && err != ErrEmptyString {
+		return err
+	}
+
+	*p = program
+	return nil
+}
+
+// ParseProgramKind converts either the status book's program code
+// (e.g. "R") or a program's name (e.g. "Regular") to a ProgramKind.
+func ParseProgramKind(s string) (ProgramKind, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) == 0 {
+		return ProgramUnknown, ErrEmptyString
+	}
+
+	if s == strings.ToLower(programNames[ProgramUnknown]) {
+		return ProgramUnknown, nil
+	}
+
+	program, ok := programsByName[s]
+	if !ok {
+		return ProgramUnknown, ErrInvalidProgram
+	}
+
+	return program, nil
+}
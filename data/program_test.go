@@ -0,0 +1,51 @@
+package data
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseProgramKind(t *testing.T) {
+	// The status book's codes and the full names should both be parsed
+	tests := map[string]ProgramKind{
+		"R":                 ProgramRegular,
+		"e":                 ProgramEmergency,
+		"Regular":           ProgramRegular,
+		"not participating": ProgramNotParticipating,
+		"SUSPENDED":         ProgramSuspended,
+		"Withdrawn":         ProgramWithdrawn,
+	}
+
+	for testString, expected := range tests {
+		program, err := ParseProgramKind(testString)
+		if err != nil || program != expected {
+			t.Errorf("expected \"%s\" to be parsed to %s", testString, expected)
+		}
+	}
+
+	// Return an empty string error when the string is empty
+	_, err := ParseProgramKind("")
+	if err != ErrEmptyString {
+		t.Errorf("expected an empty string error")
+	}
+
+	// Anything else isn't a program
+	testString := "fail me"
+	_, err = ParseProgramKind(testString)
+	if err != ErrInvalidProgram {
+		t.Errorf("%s should not be able to be parsed", testString)
+	}
+}
+
+func TestProgramKindJSON(t *testing.T) {
+	b, err := json.Marshal(ProgramEmergency)
+	if err != nil || string(b) != `"Emergency"` {
+		t.Errorf("expected %s to be marshaled to its name, got %s", ProgramEmergency, b)
+	}
+
+	var program ProgramKind
+	err = json.Unmarshal(b, &program)
+	if err != nil || program != ProgramEmergency {
+		t.Errorf("expected %s to be unmarshaled to %s", b, ProgramEmergency)
+	}
+}
@@ -0,0 +1,126 @@
+package data
+
+import (
+	"time"
+
+	"nfip-community-book/pb"
+)
+
+// ToProto converts the communities to their Protocol Buffers message.
+func (c *NFIPCommunityStatuses) ToProto() *pb.CommunityStatuses {
+	communities := make([]*pb.CommunityStatus, len(*c))
+	for i, community := range *c {
+		communities[i] = community.ToProto()
+	}
+
+	return &pb.CommunityStatuses{Communities: communities}
+}
+
+// FromProto converts the communities back from their Protocol Buffers message.
+func FromProto(p *pb.CommunityStatuses) NFIPCommunityStatuses {
+	communities := make(NFIPCommunityStatuses, len(p.GetCommunities()))
+	for i, community := range p.GetCommunities() {
+		communities[i] = StatusFromProto(community)
+	}
+
+	return communities
+}
+
+func (c NFIPCommunityStatus) ToProto() *pb.CommunityStatus {
+	p := &pb.CommunityStatus{
+		Cid:                    int32(c.CID),
+		CommunityName:          c.CommunityName,
+		Kind:                   string(c.Kind),
+		BaseName:               c.BaseName,
+		Type:                   string(c.Type),
+		County:                 c.County,
+		State:                  c.State,
+		StateFips:              int32(c.StateFIPS),
+		FhbmIdentified:         toProtoDate(c.FHBMIdentified),
+		FirmIdentified:         toProtoDate(c.FIRMIdentified),
+		CurrEffMapDate:         toProtoDate(c.CurrEffMapDate),
+		RegEmerDate:            toProtoDate(c.RegEmerDate),
+		Tribal:                 c.Tribal,
+		CrsEntryDate:           toProtoDate(c.CRSEntryDate),
+		CurrEffDate:            toProtoDate(c.CurrEffDate),
+		PercentDiscSfha:        c.PercentDiscSFHA,
+		PercentNonSfha:         c.PercentNonSFHA,
+		Program:                pb.Program(c.Program),
+		ParticipatingCommunity: c.ParticipatingCommunity,
+	}
+
+	// Only include the flags when the community has any of them
+	if c.Flags != (CommunityFlags{}) {
+		p.Flags = &pb.CommunityFlags{
+			Suspended:           c.Flags.Suspended,
+			Withdrawn:           c.Flags.Withdrawn,
+			MinimallyFloodProne: c.Flags.MinimallyFloodProne,
+			NonFloodProne:       c.Flags.NonFloodProne,
+		}
+	}
+
+	if c.CurClass != nil {
+		class := int32(*c.CurClass)
+		p.CurClass = &class
+	}
+
+	return p
+}
+
+// StatusFromProto converts a community back from its Protocol Buffers message.
+func StatusFromProto(p *pb.CommunityStatus) NFIPCommunityStatus {
+	c := NFIPCommunityStatus{
+		CID:           int(p.GetCid()),
+		CommunityName: p.GetCommunityName(),
+		Flags: CommunityFlags{
+			Suspended:           p.GetFlags().GetSuspended(),
+			Withdrawn:           p.GetFlags().GetWithdrawn(),
+			MinimallyFloodProne: p.GetFlags().GetMinimallyFloodProne(),
+			NonFloodProne:       p.GetFlags().GetNonFloodProne(),
+		},
+		Kind:                   NameKind(p.GetKind()),
+		BaseName:               p.GetBaseName(),
+		Type:                   CommunityType(p.GetType()),
+		County:                 p.GetCounty(),
+		State:                  p.GetState(),
+		StateFIPS:              int(p.GetStateFips()),
+		FHBMIdentified:         fromProtoDate(p.GetFhbmIdentified()),
+		FIRMIdentified:         fromProtoDate(p.GetFirmIdentified()),
+		CurrEffMapDate:         fromProtoDate(p.GetCurrEffMapDate()),
+		RegEmerDate:            fromProtoDate(p.GetRegEmerDate()),
+		Tribal:                 p.GetTribal(),
+		CRSEntryDate:           fromProtoDate(p.GetCrsEntryDate()),
+		CurrEffDate:            fromProtoDate(p.GetCurrEffDate()),
+		PercentDiscSFHA:        p.GetPercentDiscSfha(),
+		PercentNonSFHA:         p.GetPercentNonSfha(),
+		Program:                ProgramKind(p.GetProgram()),
+		ParticipatingCommunity: p.GetParticipatingCommunity(),
+	}
+
+	if p.CurClass != nil {
+		class := int(p.GetCurClass())
+		c.CurClass = &class
+	}
+
+	c.keys = newSearchKeys(c)
+	return c
+}
+
+func toProtoDate(t *time.Time) *pb.Date {
+	if t == nil {
+		return nil
+	}
+
+	return &pb.Date{Year: int32(t.Year()), Month: int32(t.Month()), Day: int32(t.Day())}
+}
+
+// Dates are made in the local time zone, the same as when
+// they're parsed from the status book.
+func fromProtoDate(d *pb.Date) *time.Time {
+	if d == nil {
+		return nil
+	}
+
+	t := time.Date(int(d.GetYear()), time.Month(d.GetMonth()), int(d.GetDay()), 0, 0, 0, 0, time.Local)
+	return &t
+}
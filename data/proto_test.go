@@ -0,0 +1,50 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"nfip-community-book/pb"
+)
+
+func TestProtoRoundTrip(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+
+	b, err := proto.Marshal(communities.ToProto())
+	if err != nil {
+		t.Fatalf("expected the communities to be marshaled, got %s", err)
+	}
+
+	var p pb.CommunityStatuses
+	if err := proto.Unmarshal(b, &p); err != nil {
+		t.Fatalf("expected the communities to be unmarshaled, got %s", err)
+	}
+
+	if result := FromProto(&p); !reflect.DeepEqual(communities, result) {
+		t.Errorf("expected the communities to be the same after a round trip")
+	}
+}
+
+func TestToProto(t *testing.T) {
+	class := 7
+	c := NFIPCommunityStatus{CID: 170604, CurClass: &class, Program: ProgramRegular}
+
+	p := c.ToProto()
+	if p.Flags != nil {
+		t.Errorf("expected no flags to be left out, got %v", p.Flags)
+	}
+
+	if p.CurClass == nil || p.GetCurClass() != 7 {
+		t.Errorf("expected the CRS class to be 7, got %v", p.CurClass)
+	}
+
+	if p.GetProgram() != pb.Program_PROGRAM_REGULAR {
+		t.Errorf("expected the program to be regular, got %s", p.GetProgram())
+	}
+
+	if p := (NFIPCommunityStatus{}).ToProto(); p.CurClass != nil || p.FirmIdentified != nil {
+		t.Errorf("expected a community without a class or dates to leave them unset")
+	}
+}
@@ -0,0 +1,180 @@
+package data
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// The NFIP was created in 1968, so nothing in the status book
+// should have happened before then.
+const nfipStartYear = 1968
+
+// An UnparsableValue is a value from the status book that
+// couldn't be parsed, so the field was left empty.
+type UnparsableValue struct {
+	Line   int    `json:"line"`
+	CID    int    `json:"cid"`
+	Column string `json:"column"`
+	Value  string `json:"value"`
+}
+
+func (uv UnparsableValue) String() string {
+	return fmt.Sprintf("CID %06d: %s: \"%s\" on line %d", uv.CID, uv.Column, uv.Value, uv.Line)
+}
+
+// A MissingDates is a community that's missing dates that its program
+// says it should have.
+type MissingDates struct {
+	CID     int      `json:"cid"`
+	Columns []string `json:"columns"`
+}
+
+// A SuspiciousYear is a date that's either before the NFIP existed or
+// too far in the future. These are usually two digit years that ended
+// up in the wrong century because of the year pivot.
+type SuspiciousYear struct {
+	CID    int       `json:"cid"`
+	Column string    `json:"column"`
+	Date   time.Time `json:"date"`
+}
+
+type ColumnCompleteness struct {
+	Column  string  `json:"column"`
+	Filled  int     `json:"filled"`
+	Percent float64 `json:"percent"`
+}
+
+type QualityReport struct {
+	Rows                 int                  `json:"rows"`
+	MissingDates         []MissingDates       `json:"missing_dates"`
+	UnparsableCRSClasses []UnparsableValue    `json:"unparsable_crs_classes"`
+	BlankCounties        []int                `json:"blank_counties"`
+	SuspiciousYears      []SuspiciousYear     `json:"suspicious_years"`
+	Completeness         []ColumnCompleteness `json:"completeness"`
+}
+
+// Quality reports on how complete and believable the parsed status book is.
+func (r ParseResult) Quality() QualityReport {
+	return r.quality(time.Now())
+}
+
+func (r ParseResult) quality(now time.Time) QualityReport {
+	report := QualityReport{Rows: len(r.Communities)}
+	latest := now.AddDate(1, 0, 0)
+
+	for _, uv := range r.Unparsable {
+		if uv.Column == statusColumnNames[StatusCurClass][0] {
+			report.UnparsableCRSClasses = append(report.UnparsableCRSClasses, uv)
+		}
+	}
+
+	// Booleans are left out since there's no way to tell
+	// a blank apart from a "No" once they've been parsed.
+	var filled [numStatusColumns]int
+	completenessColumns := []int{
+		StatusCID,
+		StatusCommunityName,
+		StatusCounty,
+		StatusFHBMIdentified,
+		StatusFIRMIdentified,
+		StatusCurrEffMapDate,
+		StatusRegEmerDate,
+		StatusCRSEntryDate,
+		StatusCurrEffDate,
+		StatusCurClass,
+		StatusPercentDiscSFHA,
+		StausPercentNonSFHA,
+		StatusProgram,
+	}
+
+	for _, c := range r.Communities {
+		dates := []struct {
+			column int
+			date   *time.Time
+		}{
+			{StatusFHBMIdentified, c.FHBMIdentified},
+			{StatusFIRMIdentified, c.FIRMIdentified},
+			{StatusCurrEffMapDate, c.CurrEffMapDate},
+			{StatusRegEmerDate, c.RegEmerDate},
+			{StatusCRSEntryDate, c.CRSEntryDate},
+			{StatusCurrEffDate, c.CurrEffDate},
+		}
+
+		var missing []string
+		for _, d := range dates {
+			name := statusColumnNames[d.column][0]
+
+			if d.date == nil {
+				if requiresDate(c, d.column) {
+					missing = append(missing, name)
+				}
+				continue
+			}
+
+			filled[d.column]++
+			if d.date.Year() < nfipStartYear || d.date.After(latest) {
+				report.SuspiciousYears = append(report.SuspiciousYears, SuspiciousYear{c.CID, name, *d.date})
+			}
+		}
+
+		if len(missing) > 0 {
+			report.MissingDates = append(report.MissingDates, MissingDates{c.CID, missing})
+		}
+
+		if len(c.County) == 0 {
+			report.BlankCounties = append(report.BlankCounties, c.CID)
+		} else {
+			filled[StatusCounty]++
+		}
+
+		if c.CID != 0 {
+			filled[StatusCID]++
+		}
+		if len(c.CommunityName) > 0 {
+			filled[StatusCommunityName]++
+		}
+		if c.CurClass != nil {
+			filled[StatusCurClass]++
+		}
+		if len(c.PercentDiscSFHA) > 0 {
+			filled[StatusPercentDiscSFHA]++
+		}
+		if len(c.PercentNonSFHA) > 0 {
+			filled[StausPercentNonSFHA]++
+		}
+		if c.Program != ProgramUnknown {
+			filled[StatusProgram]++
+		}
+	}
+
+	for _, column := range completenessColumns {
+		percent := 0.0
+		if report.Rows > 0 {
+			// Round to a tenth of a percent
+			percent = math.Round(float64(filled[column])/float64(report.Rows)*1000) / 10
+		}
+
+		report.Completeness = append(report.Completeness, ColumnCompleteness{statusColumnNames[column][0], filled[column], percent})
+	}
+
+	return report
+}
+
+// requiresDate is whether the community's program means it has to have a
+// date in the column. Every community in the NFIP has the date it joined,
+// but only the regular program's have a FIRM, and not the ones without a
+// flood hazard area. Only CRS participants have the CRS dates. Nothing
+// needs an FHBM date, since plenty of communities never had one.
+func requiresDate(c NFIPCommunityStatus, column int) bool {
+	switch column {
+	case StatusRegEmerDate:
+		return c.ParticipatingCommunity && (c.Program == ProgramRegular || c.Program == ProgramEmergency)
+	case StatusFIRMIdentified:
+		return c.ParticipatingCommunity && c.Program == ProgramRegular && !c.Flags.NonFloodProne
+	case StatusCRSEntryDate, StatusCurrEffDate:
+		return c.IsCRSParticipant()
+	}
+
+	return false
+}
@@ -0,0 +1,64 @@
+package data
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQuality(t *testing.T) {
+	records := []string{
+		`="170604","SPRINGFIELD, CITY OF",SANGAMON COUNTY,05/31/74,03/16/81,01/19/18,03/16/81,No,10/01/92,05/01/19,X,,,R,Yes`,
+		`="170074","CHICAGO, CITY OF",,,,,,No,,,,,,R,Yes`,
+		`="170001","ADDISON, VILLAGE OF",DUPAGE COUNTY,01/03/60,03/16/81,01/19/18,03/16/81,No,,,,,,R,Yes`,
+		`="170002","ALBANY, VILLAGE OF",WHITESIDE COUNTY,,,,04/17/75,No,,,,,,E,Yes`,
+		`="170003","ALEDO, CITY OF (NSFHA)",MERCER COUNTY,,,,06/30/76,No,,,,,,R,Yes`,
+	}
+
+	file := testHeader + "\n" + strings.Join(records, "\n")
+	result, err := Unmarshal(strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err.Error())
+	}
+
+	report := result.quality(time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC))
+	if report.Rows != 5 {
+		t.Errorf("expected 5 rows, got %d", report.Rows)
+	}
+
+	if len(report.UnparsableCRSClasses) != 1 || report.UnparsableCRSClasses[0].CID != 170604 || report.UnparsableCRSClasses[0].Line != 2 {
+		t.Errorf("expected Springfield's CRS class to be unparsable, got %v", report.UnparsableCRSClasses)
+	}
+
+	if len(report.BlankCounties) != 1 || report.BlankCounties[0] != 170074 {
+		t.Errorf("expected Chicago to have a blank county, got %v", report.BlankCounties)
+	}
+
+	// Chicago is in the regular program but doesn't have the date it joined
+	// or a FIRM. Albany's in the emergency program and Aledo doesn't have a
+	// flood hazard area, so neither of them needs a FIRM.
+	if len(report.MissingDates) != 1 || report.MissingDates[0].CID != 170074 || len(report.MissingDates[0].Columns) != 2 {
+		t.Errorf("expected Chicago to be missing 2 dates, got %v", report.MissingDates)
+	}
+
+	if len(report.SuspiciousYears) != 1 || report.SuspiciousYears[0].CID != 170001 || report.SuspiciousYears[0].Date.Year() != 1960 {
+		t.Errorf("expected Addison's 1960 FHBM date to be suspicious, got %v", report.SuspiciousYears)
+	}
+
+	for _, cc := range report.Completeness {
+		switch cc.Column {
+		case "CID":
+			if cc.Filled != 5 || cc.Percent != 100 {
+				t.Errorf("expected every CID to be filled, got %+v", cc)
+			}
+		case "County":
+			if cc.Filled != 4 || cc.Percent != 80 {
+				t.Errorf("expected 80%% of counties to be filled, got %+v", cc)
+			}
+		case "CRS Entry Date":
+			if cc.Filled != 1 || cc.Percent != 20 {
+				t.Errorf("expected 20%% of CRS entry dates to be filled, got %+v", cc)
+			}
+		}
+	}
+}
@@ -0,0 +1,485 @@
+package data
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"nfip-community-book/states"
+)
+
+var ErrInvalidQuery = fmt.Errorf("invalid query")
+
+const queryOperatorChars = ":<>="
+
+// The text fields are folded so that they can be searched without caring about case
+var queryTextFields = map[string]func(NFIPCommunityStatus) string{
+	"name":   func(c NFIPCommunityStatus) string { return c.searchKeys().lowerName },
+	"county": func(c NFIPCommunityStatus) string { return c.searchKeys().lowerCounty },
+}
+
+var queryBoolFields = map[string]func(NFIPCommunityStatus) bool{
+	"tribal":        func(c NFIPCommunityStatus) bool { return c.Tribal },
+	"participating": func(c NFIPCommunityStatus) bool { return c.ParticipatingCommunity },
+	"crs":           func(c NFIPCommunityStatus) bool { return c.IsCRSParticipant() },
+}
+
+var queryDateFields = map[string]DateField{
+	"fhbm":     FHBMIdentifiedField,
+	"firm":     FIRMIdentifiedField,
+	"map":      CurrEffMapDateField,
+	"regemer":  RegEmerDateField,
+	"crsentry": CRSEntryDateField,
+	"crsdate":  CurrEffDateField,
+}
+
+type queryTokenKind int
+
+const (
+	queryTerm queryTokenKind = iota
+	queryAnd
+	queryOr
+	queryNot
+	queryOpen
+	queryClose
+)
+
+type queryToken struct {
+	kind  queryTokenKind
+	field string
+	op    string
+	value string
+}
+
+// Query searches the communities with a query. See ParseQuery.
+func (c NFIPCommunityStatuses) Query(q string) (*NFIPCommunityStatuses, error) {
+	f, err := ParseQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.searchBy(f), nil
+}
+
+// ParseQuery turns a query into a Filter. Queries are terms joined with
+// AND, OR and NOT, and grouped with parentheses. Terms next to each other
+// without an operator are ANDed.
+//
+//	county:Cook AND participating:yes AND NOT tribal
+//	name:"new york" OR (state:TX class<=5)
+//	firm>=2000-01-01 program:emergency
+//
+// A term is either a word or "quoted phrase" that's searched for the same
+// way as Search, or a field followed by an operator and a value. Every
+// field supports ":", and the numbers and dates also support <, <=, > and >=.
+// Dates are written as 2006-01-02, 01/02/06 or just the year. States can
+// be a list of any of them, like state:TX,LA,MS.
+//
+// The yes/no fields (tribal, participating and crs) can be used on their own
+// as a shorthand for "field:yes".
+func ParseQuery(q string) (Filter, error) {
+	tokens, err := lexQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%w: query is empty", ErrInvalidQuery)
+	}
+
+	p := queryParser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("%w: unexpected %s", ErrInvalidQuery, p.tokens[p.pos])
+	}
+
+	return f, nil
+}
+
+// IsQuery guesses whether a search term was meant to be a query. Quotes on
+// their own aren't enough since Search handles "quoted phrases" itself.
+func IsQuery(term string) bool {
+	if strings.ContainsAny(term, queryOperatorChars) {
+		return true
+	}
+
+	for _, word := range strings.Fields(term) {
+		if word == "AND" || word == "OR" || word == "NOT" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t queryToken) String() string {
+	switch t.kind {
+	case queryAnd:
+		return "AND"
+	case queryOr:
+		return "OR"
+	case queryNot:
+		return "NOT"
+	case queryOpen:
+		return "("
+	case queryClose:
+		return ")"
+	}
+
+	return fmt.Sprintf("\"%s%s%s\"", t.field, t.op, t.value)
+}
+
+func lexQuery(q string) ([]queryToken, error) {
+	var tokens []queryToken
+
+	for i := 0; i < len(q); {
+		switch q[i] {
+		case ' ', '\t':
+			i++
+		case '(':
+			tokens = append(tokens, queryToken{kind: queryOpen})
+			i++
+		case ')':
+			tokens = append(tokens, queryToken{kind: queryClose})
+			i++
+		case '"':
+			phrase, n, err := readQueryPhrase(q[i:])
+			if err != nil {
+				return nil, err
+			}
+
+			tokens = append(tokens, queryToken{kind: queryTerm, value: phrase})
+			i += n
+		default:
+			// Words go until the next space, parenthesis or quote
+			j := i
+			for j < len(q) && !strings.ContainsRune(" \t()\"", rune(q[j])) {
+				j++
+			}
+
+			word := q[i:j]
+			i = j
+
+			switch word {
+			case "AND":
+				tokens = append(tokens, queryToken{kind: queryAnd})
+				continue
+			case "OR":
+				tokens = append(tokens, queryToken{kind: queryOr})
+				continue
+			case "NOT":
+				tokens = append(tokens, queryToken{kind: queryNot})
+				continue
+			}
+
+			t := splitQueryTerm(word)
+
+			// The value of a field can be a quoted phrase too
+			if len(t.op) > 0 && len(t.value) == 0 && i < len(q) && q[i] == '"' {
+				phrase, n, err := readQueryPhrase(q[i:])
+				if err != nil {
+					return nil, err
+				}
+
+				t.value = phrase
+				i += n
+			}
+
+			tokens = append(tokens, t)
+		}
+	}
+
+	return tokens, nil
+}
+
+// readQueryPhrase reads a quoted phrase from the start of s and returns
+// the phrase without quotes and how much of s it took up.
+func readQueryPhrase(s string) (string, int, error) {
+	end := strings.IndexByte(s[1:], '"')
+	if end < 0 {
+		return "", 0, fmt.Errorf("%w: missing a closing quote", ErrInvalidQuery)
+	}
+
+	return s[1 : end+1], end + 2, nil
+}
+
+// splitQueryTerm splits a word like "class<=5" into its field, operator
+// and value. Words without an operator are left as just a value.
+func splitQueryTerm(word string) queryToken {
+	start := strings.IndexAny(word, queryOperatorChars)
+	if start <= 0 {
+		return queryToken{kind: queryTerm, value: word}
+	}
+
+	end := start
+	for end < len(word) && strings.IndexByte(queryOperatorChars, word[end]) >= 0 {
+		end++
+	}
+
+	// "class:<=5" and "class<=5" are the same, and so are "state=IL" and "state:IL"
+	op := word[start:end]
+	if len(op) > 1 && op[0] == ':' {
+		op = op[1:]
+	}
+	if op == "=" {
+		op = ":"
+	}
+
+	return queryToken{
+		kind:  queryTerm,
+		field: strings.ToLower(word[:start]),
+		op:    op,
+		value: word[end:],
+	}
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() (queryToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return queryToken{}, false
+	}
+
+	return p.tokens[p.pos], true
+}
+
+func (p *queryParser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind != queryOr {
+			return left, nil
+		}
+
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = Any(left, right)
+	}
+}
+
+func (p *queryParser) parseAnd() (Filter, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind == queryOr || t.kind == queryClose {
+			return left, nil
+		}
+
+		if t.kind == queryAnd {
+			p.pos++
+		}
+
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		left = All(left, right)
+	}
+}
+
+func (p *queryParser) parseNot() (Filter, error) {
+	t, ok := p.peek()
+	if ok && t.kind == queryNot {
+		p.pos++
+		f, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return Not(f), nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *queryParser) parsePrimary() (Filter, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("%w: query ends too soon", ErrInvalidQuery)
+	}
+
+	p.pos++
+
+	switch t.kind {
+	case queryOpen:
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if t, ok := p.peek(); !ok || t.kind != queryClose {
+			return nil, fmt.Errorf("%w: missing a closing parenthesis", ErrInvalidQuery)
+		}
+
+		p.pos++
+		return f, nil
+	case queryTerm:
+		return t.filter()
+	}
+
+	return nil, fmt.Errorf("%w: unexpected %s", ErrInvalidQuery, t)
+}
+
+// filter makes the Filter for a term.
+func (t queryToken) filter() (Filter, error) {
+	if len(t.field) == 0 {
+		if boolField, ok := queryBoolFields[strings.ToLower(t.value)]; ok {
+			return Filter(boolField), nil
+		}
+
+		term := foldString(t.value)
+		return func(c NFIPCommunityStatus) bool {
+			k := c.searchKeys()
+			return strings.Contains(k.lowerName, term) ||
+				strings.Contains(k.lowerCounty, term) ||
+				strings.Contains(strconv.Itoa(c.CID), term)
+		}, nil
+	}
+
+	if len(t.value) == 0 {
+		return nil, fmt.Errorf("%w: %s is missing a value", ErrInvalidQuery, t)
+	}
+
+	compare, ok := queryComparisons[t.op]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown operator %s", ErrInvalidQuery, t.op)
+	}
+
+	if textField, ok := queryTextFields[t.field]; ok {
+		if t.op != ":" {
+			return nil, fmt.Errorf("%w: %s can only be searched with \":\"", ErrInvalidQuery, t.field)
+		}
+
+		term := foldString(t.value)
+		return func(c NFIPCommunityStatus) bool {
+			return strings.Contains(textField(c), term)
+		}, nil
+	}
+
+	if boolField, ok := queryBoolFields[t.field]; ok {
+		want, err := parseBoolFromYesNo(t.value)
+		if err != nil || t.op != ":" {
+			return nil, fmt.Errorf("%w: %s is either yes or no", ErrInvalidQuery, t.field)
+		}
+
+		return func(c NFIPCommunityStatus) bool { return boolField(c) == want }, nil
+	}
+
+	if dateField, ok := queryDateFields[t.field]; ok {
+		return dateQueryFilter(dateField, compare, t.value)
+	}
+
+	switch t.field {
+	case "cid", "class":
+		n, err := strconv.Atoi(t.value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s is not a number", ErrInvalidQuery, t.value)
+		}
+
+		if t.field == "cid" {
+			return func(c NFIPCommunityStatus) bool { return compare(c.CID - n) }, nil
+		}
+
+		return func(c NFIPCommunityStatus) bool {
+			return c.IsCRSParticipant() && compare(*c.CurClass-n)
+		}, nil
+	}
+
+	if t.op != ":" {
+		return nil, fmt.Errorf("%w: %s can only be searched with \":\"", ErrInvalidQuery, t.field)
+	}
+
+	switch t.field {
+	case "state":
+		// A list of states matches any of them
+		var either []Filter
+		for _, state := range strings.Split(t.value, ",") {
+			if _, ok := states.Lookup(state); !ok {
+				return nil, fmt.Errorf("%w: unknown state %s", ErrInvalidQuery, state)
+			}
+
+			either = append(either, ByState(state))
+		}
+
+		return Any(either...), nil
+	case "program":
+		program, err := ParseProgramKind(t.value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unknown program %s", ErrInvalidQuery, t.value)
+		}
+
+		return InProgram(program), nil
+	case "type":
+		communityType := CommunityType(strings.ToLower(t.value))
+		return func(c NFIPCommunityStatus) bool { return c.Type == communityType }, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown field %s", ErrInvalidQuery, t.field)
+}
+
+// The comparisons are given how the community's value compares to the
+// query's value: less than 0 if it's less, 0 if it's the same, etc.
+var queryComparisons = map[string]func(int) bool{
+	":":  func(cmp int) bool { return cmp == 0 },
+	"<":  func(cmp int) bool { return cmp < 0 },
+	"<=": func(cmp int) bool { return cmp <= 0 },
+	">":  func(cmp int) bool { return cmp > 0 },
+	">=": func(cmp int) bool { return cmp >= 0 },
+}
+
+func dateQueryFilter(dateField DateField, compare func(int) bool, value string) (Filter, error) {
+	// Just a year compares the years
+	if len(value) == 4 {
+		if year, err := strconv.Atoi(value); err == nil {
+			return func(c NFIPCommunityStatus) bool {
+				d := dateField(c)
+				return d != nil && compare(d.Year()-year)
+			}, nil
+		}
+	}
+
+	date, err := time.ParseInLocation(jsonDateLayout, value, time.Local)
+	if err != nil {
+		date, err = parseDate(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s is not a date", ErrInvalidQuery, value)
+		}
+	}
+
+	return func(c NFIPCommunityStatus) bool {
+		d := dateField(c)
+		if d == nil {
+			return false
+		}
+
+		cmp := 0
+		if d.Before(date) {
+			cmp = -1
+		} else if d.After(date) {
+			cmp = 1
+		}
+
+		return compare(cmp)
+	}, nil
+}
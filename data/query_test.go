@@ -0,0 +1,71 @@
+package data
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	tests := []struct {
+		query string
+		cids  []int
+	}{
+		{"county:Cook AND participating:yes AND NOT tribal", []int{170054, 170074}},
+		{`name:"new york"`, []int{360497}},
+		{"state:TX class<=7", []int{480296, 480287}},
+		{"state:TX class<=5", []int{480287}},
+		{"tribal", []int{120678}},
+		{"cid=170074 OR cid:170604", []int{170074, 170604}},
+		{"(state:IL OR state:Texas) AND NOT crs", []int{170074, 170604, 481658}},
+		{"springfield", []int{170604}},
+		{"firm>=2007 state:fl", []int{120678}},
+		{"firm>=2007 state:12", []int{120678}},
+		{"firm<1978-01-01 state:FL", []int{120651}},
+	}
+
+	for _, test := range tests {
+		matches, err := communities.Query(test.query)
+		if err != nil {
+			t.Errorf("failed to query \"%s\": %s", test.query, err.Error())
+			continue
+		}
+
+		var cids []int
+		for _, c := range *matches {
+			cids = append(cids, c.CID)
+		}
+
+		if len(cids) != len(test.cids) {
+			t.Errorf("expected %v for \"%s\", got %v", test.cids, test.query, cids)
+			continue
+		}
+
+		for i := range cids {
+			if cids[i] != test.cids[i] {
+				t.Errorf("expected %v for \"%s\", got %v", test.cids, test.query, cids)
+				break
+			}
+		}
+	}
+
+	// Queries that don't make sense are errors
+	for _, query := range []string{"", "state:XX", "name<5", "class:abc", "(tribal", `"unclosed`, "foo:bar", "AND tribal", "firm>soon"} {
+		if _, err := communities.Query(query); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("expected \"%s\" to be an invalid query, got %v", query, err)
+		}
+	}
+
+	// Search understands queries but falls back to a plain search
+	if matches := communities.Search("county:cook NOT tribal"); len(matches) != 2 {
+		t.Errorf("expected 2 communities from a query search, got %d", len(matches))
+	}
+
+	if matches := communities.Search("chicago"); len(matches) != 1 {
+		t.Errorf("expected 1 community from a plain search, got %d", len(matches))
+	}
+}
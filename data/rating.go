@@ -1,10 +1,10 @@
 package data
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"log"
-	"net/http"
 	"os"
 	"strings"
 
@@ -45,22 +45,15 @@ func GetNFIPCommunityRatingSystem(l *log.Logger) (NFIPCommunityRatings, error) {
 	// First check if the file exists and download it if it does not exist
 	if _, err := os.Stat(NFIPCommunityRatingSystemFilename); os.IsNotExist(err) {
 		l.Println("NFIP CRS does not exist. Downloading...")
-		resp, err := http.Get(NFIPCommunityRatingSystemURL)
-
-		if err != nil {
-			l.Println("** Err - ", err)
-			os.Exit(1)
+		verify := func(filename string) error {
+			_, err := xlsx.OpenFile(filename)
+			return err
 		}
-		defer resp.Body.Close()
 
-		f, err := os.Create(NFIPCommunityRatingSystemFilename)
-		if err != nil {
+		if _, _, err := downloadFile(context.Background(), NFIPCommunityRatingSystemURL, NFIPCommunityRatingSystemFilename, verify); err != nil {
 			l.Println("** Err - ", err)
 			os.Exit(1)
 		}
-
-		io.Copy(f, resp.Body)
-		defer f.Close()
 	}
 
 	wb, err := xlsx.OpenFile(NFIPCommunityRatingSystemFilename)
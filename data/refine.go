@@ -0,0 +1,188 @@
+package data
+
+import "sort"
+
+// A ResultSet is a view of some of the communities in a status book that
+// can be refined one step at a time: filtered, searched, sorted and then
+// paged. Each step makes a new ResultSet of just the indexes of the
+// communities that are left, so the communities themselves are never copied
+// and the earlier steps can still be used.
+//
+//	texas := cb.Refine().Filter(State("TX"))
+//	page := texas.Search("harris").SortByName().Page(0, 20)
+type ResultSet struct {
+	communities NFIPCommunityStatuses
+	indexes     []int
+
+	// Set once the results have been searched
+	scores  []float64
+	term    string
+	folding folding
+}
+
+// Refine starts a ResultSet with every community.
+func (c NFIPCommunityStatuses) Refine() ResultSet {
+	indexes := make([]int, len(c))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	return ResultSet{communities: c, indexes: indexes}
+}
+
+func (rs ResultSet) Len() int {
+	return len(rs.indexes)
+}
+
+// Filter keeps the communities that pass every filter, in the same order.
+func (rs ResultSet) Filter(filters ...Filter) ResultSet {
+	f := All(filters...)
+	next := ResultSet{communities: rs.communities, term: rs.term, folding: rs.folding}
+
+	for i, index := range rs.indexes {
+		if f(rs.communities[index]) {
+			next.indexes = append(next.indexes, index)
+			if rs.scores != nil {
+				next.scores = append(next.scores, rs.scores[i])
+			}
+		}
+	}
+
+	return next
+}
+
+// Search narrows the results down to the communities that match the term,
+// with the most relevant first, the same way NFIPCommunityStatuses.Search does.
+func (rs ResultSet) Search(term string) ResultSet {
+	return rs.search(term, folding{})
+}
+
+func (rs ResultSet) search(term string, f folding) ResultSet {
+	if IsQuery(term) {
+		if f, err := ParseQuery(term); err == nil {
+			next := rs.Filter(f)
+			next.scores = make([]float64, len(next.indexes))
+			next.term = ""
+			return next
+		}
+	}
+
+	lowerTerm := f.fold(term)
+	next := ResultSet{communities: rs.communities, term: lowerTerm, folding: f}
+	st := newFoldedSearchTerm(lowerTerm, f)
+
+	for _, index := range rs.indexes {
+		if rank := st.relevance(rs.communities[index].foldedSearchKeys(f)); rank > 0 {
+			next.indexes = append(next.indexes, index)
+			next.scores = append(next.scores, float64(rank)/maxRelevanceRank)
+		}
+	}
+
+	sort.Stable(byScore(next))
+	return next
+}
+
+// SortBy sorts the results with less, keeping the order of
+// communities that are equal. Scores are kept, but no
+// longer have anything to do with the order.
+func (rs ResultSet) SortBy(less func(a, b NFIPCommunityStatus) bool) ResultSet {
+	next := ResultSet{
+		communities: rs.communities,
+		indexes:     append([]int(nil), rs.indexes...),
+		term:        rs.term,
+		folding:     rs.folding,
+	}
+
+	if rs.scores != nil {
+		next.scores = append([]float64(nil), rs.scores...)
+	}
+
+	sort.Stable(byLess{next, less})
+	return next
+}
+
+func (rs ResultSet) SortByName() ResultSet {
+	return rs.SortBy(func(a, b NFIPCommunityStatus) bool {
+		return a.CommunityName < b.CommunityName
+	})
+}
+
+func (rs ResultSet) SortByCID() ResultSet {
+	return rs.SortBy(func(a, b NFIPCommunityStatus) bool {
+		return a.CID < b.CID
+	})
+}
+
+// Communities copies the communities that are left.
+func (rs ResultSet) Communities() NFIPCommunityStatuses {
+	communities := make(NFIPCommunityStatuses, len(rs.indexes))
+	for i, index := range rs.indexes {
+		communities[i] = rs.communities[index]
+	}
+
+	return communities
+}
+
+// Results are the communities that are left along with their scores and
+// what matched if they were searched. Otherwise the scores are all 0.
+func (rs ResultSet) Results() SearchResults {
+	results := make(SearchResults, len(rs.indexes))
+	st := newFoldedSearchTerm(rs.term, rs.folding)
+	for i, index := range rs.indexes {
+		c := rs.communities[index]
+		results[i].Community = c
+
+		if rs.scores != nil {
+			results[i].Score = rs.scores[i]
+		}
+		if len(rs.term) > 0 {
+			results[i].Matches = st.spans(c, c.foldedSearchKeys(rs.folding))
+		}
+	}
+
+	return results
+}
+
+// Page ends the refining with up to limit results starting at offset.
+// Only the communities on the page are copied.
+func (rs ResultSet) Page(offset, limit int) SearchPage {
+	start, end := pageBounds(len(rs.indexes), offset, limit)
+	page := ResultSet{
+		communities: rs.communities,
+		indexes:     rs.indexes[start:end],
+		term:        rs.term,
+		folding:     rs.folding,
+	}
+
+	if rs.scores != nil {
+		page.scores = rs.scores[start:end]
+	}
+
+	return SearchPage{Total: len(rs.indexes), Offset: start, Limit: limit, Results: page.Results()}
+}
+
+// byScore sorts a searched ResultSet with the highest scores first.
+type byScore ResultSet
+
+func (s byScore) Len() int           { return len(s.indexes) }
+func (s byScore) Less(i, j int) bool { return s.scores[i] > s.scores[j] }
+func (s byScore) Swap(i, j int) {
+	s.indexes[i], s.indexes[j] = s.indexes[j], s.indexes[i]
+	s.scores[i], s.scores[j] = s.scores[j], s.scores[i]
+}
+
+type byLess struct {
+	rs   ResultSet
+	less func(a, b NFIPCommunityStatus) bool
+}
+
+func (s byLess) Len() int { return len(s.rs.indexes) }
+func (s byLess) Less(i, j int) bool {
+	return s.less(s.rs.communities[s.rs.indexes[i]], s.rs.communities[s.rs.indexes[j]])
+}
+func (s byLess) Swap(i, j int) {
+	s.rs.indexes[i], s.rs.indexes[j] = s.rs.indexes[j], s.rs.indexes[i]
+	if s.rs.scores != nil {
+		s.rs.scores[i], s.rs.scores[j] = s.rs.scores[j], s.rs.scores[i]
+	}
+}
@@ -0,0 +1,51 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResultSet(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	all := communities.Refine()
+	if all.Len() != len(communities) {
+		t.Fatalf("expected every community, got %d", all.Len())
+	}
+
+	// Searching a refined set is the same as searching everything that's left
+	texas := all.Filter(State("TX"))
+	if texas.Len() != 3 {
+		t.Errorf("expected 3 communities in Texas, got %d", texas.Len())
+	}
+
+	harris := texas.Search("harris")
+	expected := texas.Communities().Search("harris")
+	if !reflect.DeepEqual(harris.Results(), expected) {
+		t.Errorf("expected the same results as searching the communities, got %v", harris.Results())
+	}
+
+	// Sorting keeps the scores with their communities
+	sorted := harris.SortByCID()
+	results := sorted.Results()
+	if len(results) != 2 || results[0].Community.CID != 480287 || results[0].Score != expected[0].Score {
+		t.Errorf("expected Harris County first with its score, got %v", results)
+	}
+
+	// None of the steps change the ones before them
+	if texas.Len() != 3 || harris.Results()[0].Community.CID != expected[0].Community.CID {
+		t.Errorf("expected the earlier steps to be left alone")
+	}
+
+	if all.Len() != len(communities) {
+		t.Errorf("expected the whole set to be left alone")
+	}
+
+	page := all.SortByName().Page(1, 2)
+	if page.Total != len(communities) || len(page.Results) != 2 || page.Results[0].Community.CommunityName != "BOULDER, CITY OF" {
+		t.Errorf("expected the second and third names in order, got %v", page.Results)
+	}
+}
@@ -0,0 +1,196 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A RefreshRule compares a freshly downloaded Community Status Book against
+// the one currently in use and returns an error if the new one looks broken.
+type RefreshRule func(current, next NFIPCommunityStatuses) error
+
+var ErrRefreshRejected = fmt.Errorf("refresh rejected")
+var ErrInvalidRefreshRule = fmt.Errorf("invalid refresh rule")
+
+// MinRecords rejects a refresh with fewer than n communities in it.
+func MinRecords(n int) RefreshRule {
+	return func(current, next NFIPCommunityStatuses) error {
+		if len(next) < n {
+			return fmt.Errorf("%d communities is less than the minimum of %d", len(next), n)
+		}
+
+		return nil
+	}
+}
+
+// MaxRecordLoss rejects a refresh that loses more than pct percent
+// of the communities in the current Community Status Book.
+func MaxRecordLoss(pct float64) RefreshRule {
+	return func(current, next NFIPCommunityStatuses) error {
+		if loss := percentLost(len(current), len(next)); loss > pct {
+			return fmt.Errorf("lost %.2f%% of all communities (%d to %d), more than the maximum of %.2f%%", loss, len(current), len(next), pct)
+		}
+
+		return nil
+	}
+}
+
+// MaxStateLoss rejects a refresh where any state loses more
+// than pct percent of its communities.
+func MaxStateLoss(pct float64) RefreshRule {
+	return func(current, next NFIPCommunityStatuses) error {
+		currentCounts := countByStateFIPS(current)
+		nextCounts := countByStateFIPS(next)
+
+		// Sort the states so that violations are always reported in the same order
+		var states []int
+		for state := range currentCounts {
+			states = append(states, state)
+		}
+		sort.Ints(states)
+
+		var violations []string
+		for _, state := range states {
+			if loss := percentLost(currentCounts[state], nextCounts[state]); loss > pct {
+				violations = append(violations, fmt.Sprintf("state %02d lost %.2f%% of its communities (%d to %d)", state, loss, currentCounts[state], nextCounts[state]))
+			}
+		}
+
+		if len(violations) > 0 {
+			return fmt.Errorf("%s, more than the maximum of %.2f%%", strings.Join(violations, ", "), pct)
+		}
+
+		return nil
+	}
+}
+
+// ParseRefreshRules builds refresh rules from a comma separated list of
+// "name=value" pairs so operators can configure them, for example:
+// "min-records=20000,max-record-loss=1,max-state-loss=2"
+func ParseRefreshRules(spec string) ([]RefreshRule, error) {
+	var rules []RefreshRule
+
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) == 0 {
+			continue
+		}
+
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidRefreshRule, pair)
+		}
+
+		name := strings.TrimSpace(parts[0])
+		value := strings.TrimSuffix(strings.TrimSpace(parts[1]), "%")
+
+		switch name {
+		case "min-records":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidRefreshRule, pair)
+			}
+			rules = append(rules, MinRecords(n))
+		case "max-record-loss", "max-state-loss":
+			pct, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidRefreshRule, pair)
+			}
+
+			if name == "max-record-loss" {
+				rules = append(rules, MaxRecordLoss(pct))
+			} else {
+				rules = append(rules, MaxStateLoss(pct))
+			}
+		default:
+			return nil, fmt.Errorf("%w: unknown rule %s", ErrInvalidRefreshRule, name)
+		}
+	}
+
+	return rules, nil
+}
+
+// ValidateRefresh runs every rule against the refresh and
+// returns all of the rules that were broken in a single error.
+func ValidateRefresh(current, next NFIPCommunityStatuses, rules ...RefreshRule) error {
+	var violations []string
+
+	for _, rule := range rules {
+		if err := rule(current, next); err != nil {
+			violations = append(violations, err.Error())
+		}
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("%w: %s", ErrRefreshRejected, strings.Join(violations, "; "))
+	}
+
+	return nil
+}
+
+// RefreshNFIPCommunityStatusBook downloads the latest Community Status Book and
+// swaps it in for the cached one, but only if it passes every rule. When the
+// refresh is rejected, the current communities are returned with the error.
+func RefreshNFIPCommunityStatusBook(l *log.Logger, current NFIPCommunityStatuses, rules ...RefreshRule) (NFIPCommunityStatuses, error) {
+	return RefreshNFIPCommunityStatusBookContext(context.Background(), l, current, rules...)
+}
+
+// RefreshNFIPCommunityStatusBookContext is RefreshNFIPCommunityStatusBook,
+// but the download is cancelled along with the context, and it's traced as
+// part of whatever the context's span is.
+func RefreshNFIPCommunityStatusBookContext(ctx context.Context, l *log.Logger, current NFIPCommunityStatuses, rules ...RefreshRule) (next NFIPCommunityStatuses, err error) {
+	ctx, span := tracer.Start(ctx, "refresh status book")
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	// The download is parsed and checked against the rules before it's
+	// swapped in for the cached file, so a rejected one is thrown away
+	verify := func(filename string) error {
+		result, err := readNFIPCommunityStatusBook(ctx, filename)
+		if err != nil {
+			return err
+		}
+
+		logSchemaChanges(l, result.SchemaChanges)
+		logDuplicates(l, result.Duplicates)
+
+		if err := ValidateRefresh(current, result.Communities, rules...); err != nil {
+			l.Println("** ALERT - NFIP Community book refresh rejected:", err)
+			return err
+		}
+
+		next = result.Communities
+		return nil
+	}
+
+	l.Println("Refreshing NFIP Community book...")
+	if err := downloadNFIPCommunityStatusBook(ctx, NFIPCommunityStatusBookFilename, verify); err != nil {
+		return current, err
+	}
+
+	l.Printf("Refreshed NFIP Community book: %d communities (was %d)\n", len(next), len(current))
+	return next, nil
+}
+
+// The first two digits of a six digit CID are the state's FIPS code.
+func countByStateFIPS(c NFIPCommunityStatuses) map[int]int {
+	counts := make(map[int]int)
+	for _, community := range c {
+		counts[community.CID/10000]++
+	}
+
+	return counts
+}
+
+func percentLost(before, after int) float64 {
+	if before == 0 || after >= before {
+		return 0
+	}
+
+	return float64(before-after) / float64(before) * 100
+}
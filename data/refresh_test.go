@@ -0,0 +1,59 @@
+package data
+
+import (
+	"errors"
+	"testing"
+)
+
+// Build a list of communities with n communities in each state
+func communitiesInStates(n int, states ...int) NFIPCommunityStatuses {
+	var c NFIPCommunityStatuses
+	for _, state := range states {
+		for i := 1; i <= n; i++ {
+			c = append(c, NFIPCommunityStatus{CID: state*10000 + i})
+		}
+	}
+
+	return c
+}
+
+func TestValidateRefresh(t *testing.T) {
+	current := communitiesInStates(100, 12, 48)
+
+	// The same data should always pass
+	err := ValidateRefresh(current, current, MinRecords(200), MaxRecordLoss(0), MaxStateLoss(0))
+	if err != nil {
+		t.Errorf("expected an unchanged refresh to pass, got %s", err)
+	}
+
+	// Losing 3 of Florida's 100 communities is 1.5% overall but 3% for the state
+	next := append(communitiesInStates(97, 12), communitiesInStates(100, 48)...)
+	if err := ValidateRefresh(current, next, MaxRecordLoss(2)); err != nil {
+		t.Errorf("expected a 1.5%% loss to pass, got %s", err)
+	}
+
+	err = ValidateRefresh(current, next, MaxRecordLoss(2), MaxStateLoss(2))
+	if !errors.Is(err, ErrRefreshRejected) {
+		t.Errorf("expected a 3%% loss in one state to be rejected")
+	}
+
+	// An empty file should never make it through
+	err = ValidateRefresh(current, nil, MinRecords(1))
+	if !errors.Is(err, ErrRefreshRejected) {
+		t.Errorf("expected an empty refresh to be rejected")
+	}
+}
+
+func TestParseRefreshRules(t *testing.T) {
+	rules, err := ParseRefreshRules("min-records=20000, max-record-loss=1,max-state-loss=2%")
+	if err != nil || len(rules) != 3 {
+		t.Errorf("expected 3 rules to be parsed, got %d (%v)", len(rules), err)
+	}
+
+	for _, testString := range []string{"min-records", "min-records=lots", "max-county-loss=1"} {
+		_, err = ParseRefreshRules(testString)
+		if !errors.Is(err, ErrInvalidRefreshRule) {
+			t.Errorf("%s should not be able to be parsed", testString)
+		}
+	}
+}
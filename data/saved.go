@@ -0,0 +1,116 @@
+package data
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// SavedQueriesFilename is where saved queries are loaded from by default.
+const SavedQueriesFilename = "queries.conf"
+
+var ErrInvalidSavedQuery = fmt.Errorf("invalid saved query")
+var ErrUnknownSavedQuery = fmt.Errorf("unknown saved query")
+
+// A SavedQuery is a query that's been given a name so that
+// the same slice of the status book can be looked at again.
+type SavedQuery struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+
+	filter Filter
+}
+
+type SavedQueries []SavedQuery
+
+// ParseSavedQueries reads one saved query per line, as its name and then
+// the query after an "=". Blank lines and lines starting with "#" are
+// skipped. Every query is parsed up front so that mistakes are found
+// when they're loaded rather than when they're run.
+//
+//	# CRS communities along the Gulf of Mexico
+//	gulf-coast-crs = state:TX,LA,MS,AL,FL class<=7
+func ParseSavedQueries(r io.Reader) (SavedQueries, error) {
+	var queries SavedQueries
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%w: missing \"=\" on line %d", ErrInvalidSavedQuery, lineNumber)
+		}
+
+		name, q := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if len(name) == 0 || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("%w: invalid name \"%s\" on line %d", ErrInvalidSavedQuery, name, lineNumber)
+		}
+
+		if seen[name] {
+			return nil, fmt.Errorf("%w: %s is saved more than once on line %d", ErrInvalidSavedQuery, name, lineNumber)
+		}
+
+		f, err := ParseQuery(q)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s on line %d", ErrInvalidSavedQuery, err, lineNumber)
+		}
+
+		seen[name] = true
+		queries = append(queries, SavedQuery{name, q, f})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return queries, nil
+}
+
+// LoadSavedQueries reads the saved queries in the file. See ParseSavedQueries.
+func LoadSavedQueries(filename string) (SavedQueries, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseSavedQueries(f)
+}
+
+// Get finds the saved query with the name.
+func (sq SavedQueries) Get(name string) (SavedQuery, bool) {
+	for _, q := range sq {
+		if q.Name == name {
+			return q, true
+		}
+	}
+
+	return SavedQuery{}, false
+}
+
+// Run runs the saved query with the name against the communities.
+func (sq SavedQueries) Run(name string, c NFIPCommunityStatuses) (*NFIPCommunityStatuses, error) {
+	q, ok := sq.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSavedQuery, name)
+	}
+
+	return q.Run(c)
+}
+
+// Run keeps the communities that match the query.
+func (q SavedQuery) Run(c NFIPCommunityStatuses) (*NFIPCommunityStatuses, error) {
+	if q.filter == nil {
+		// It was made by hand rather than parsed
+		return c.Query(q.Query)
+	}
+
+	return c.searchBy(q.filter), nil
+}
@@ -0,0 +1,70 @@
+package data
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseSavedQueries(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	config := `
+# CRS communities along the Gulf of Mexico
+gulf-coast-crs = state:TX,LA,MS,AL,FL class<=7
+
+emergency = program:emergency
+`
+
+	queries, err := ParseSavedQueries(strings.NewReader(config))
+	if err != nil {
+		t.Fatalf("expected the saved queries to parse, got %s", err.Error())
+	}
+
+	if len(queries) != 2 || queries[0].Name != "gulf-coast-crs" || queries[0].Query != "state:TX,LA,MS,AL,FL class<=7" {
+		t.Fatalf("expected 2 saved queries, got %v", queries)
+	}
+
+	matches, err := queries.Run("gulf-coast-crs", communities)
+	if err != nil {
+		t.Fatalf("expected gulf-coast-crs to run, got %s", err.Error())
+	}
+
+	for _, c := range *matches {
+		if !strings.Contains("TX LA MS AL FL", c.State) || *c.CurClass > 7 {
+			t.Errorf("expected only CRS class 7 or better on the gulf coast, got %s class %d", c.State, *c.CurClass)
+		}
+	}
+
+	if len(*matches) != 7 {
+		t.Errorf("expected 7 gulf coast CRS communities, got %d", len(*matches))
+	}
+
+	if _, err := queries.Run("atlantis", communities); !errors.Is(err, ErrUnknownSavedQuery) {
+		t.Errorf("expected an unknown saved query, got %v", err)
+	}
+
+	// Saved queries that are made by hand still run
+	q := SavedQuery{Name: "tribal", Query: "tribal"}
+	if matches, err := q.Run(communities); err != nil || len(*matches) != 1 {
+		t.Errorf("expected the one tribal community, got %v (%v)", matches, err)
+	}
+}
+
+func TestParseSavedQueriesErrors(t *testing.T) {
+	for _, config := range []string{
+		"no equals sign",
+		"= state:TX",
+		"two words = state:TX",
+		"texas = state:TX\ntexas = state:TX",
+		"broken = state:XX",
+		"broken = (tribal",
+	} {
+		if _, err := ParseSavedQueries(strings.NewReader(config)); !errors.Is(err, ErrInvalidSavedQuery) {
+			t.Errorf("expected \"%s\" to be invalid, got %v", config, err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+)
+
+// How many values from an unknown column are kept as samples.
+const schemaChangeSamples = 3
+
+type SchemaChangeKind string
+
+const (
+	// The column isn't one we know about. It's either new or one of
+	// our columns renamed to something we don't have an alias for.
+	SchemaColumnUnknown SchemaChangeKind = "unknown"
+
+	// The column was only found by one of its aliases,
+	// meaning it no longer has the name FEMA used to use.
+	SchemaColumnRenamed SchemaChangeKind = "renamed"
+)
+
+// A SchemaChange is a difference between the columns in the status book and
+// the ones we expect. Columns that are missing entirely aren't reported as
+// a change since they fail the parse instead.
+type SchemaChange struct {
+	Kind     SchemaChangeKind `json:"kind"`
+	Column   string           `json:"column"`
+	Expected string           `json:"expected,omitempty"`
+	Position int              `json:"position"`
+	Samples  []string         `json:"samples,omitempty"`
+}
+
+func (sc SchemaChange) String() string {
+	switch sc.Kind {
+	case SchemaColumnRenamed:
+		return fmt.Sprintf("column \"%s\" was renamed to \"%s\" (position %d)", sc.Expected, sc.Column, sc.Position)
+	default:
+		return fmt.Sprintf("unknown column \"%s\" (position %d) with values like %s", sc.Column, sc.Position, strings.Join(sc.Samples, ", "))
+	}
+}
+
+// detectSchemaChanges compares the header to the columns that were found in it.
+func detectSchemaChanges(header []string, columns [numStatusColumns]int) []SchemaChange {
+	var changes []SchemaChange
+
+	known := make(map[int]bool)
+	for column, i := range columns {
+		known[i] = true
+
+		expected := statusColumnNames[column][0]
+		if normalizeColumnName(header[i]) != normalizeColumnName(expected) {
+			changes = append(changes, SchemaChange{
+				Kind:     SchemaColumnRenamed,
+				Column:   header[i],
+				Expected: expected,
+				Position: i,
+			})
+		}
+	}
+
+	for i, name := range header {
+		if !known[i] {
+			changes = append(changes, SchemaChange{
+				Kind:     SchemaColumnUnknown,
+				Column:   name,
+				Position: i,
+			})
+		}
+	}
+
+	return changes
+}
+
+// sampleUnknownColumns keeps a few distinct values from each of the unknown
+// columns in the record so there's an idea of what the new column holds.
+func sampleUnknownColumns(changes []SchemaChange, record []string) {
+	for i := range changes {
+		sc := &changes[i]
+		if sc.Kind != SchemaColumnUnknown || len(sc.Samples) >= schemaChangeSamples {
+			continue
+		}
+
+		value := strings.TrimSpace(record[sc.Position])
+		if len(value) == 0 {
+			continue
+		}
+
+		duplicate := false
+		for _, sample := range sc.Samples {
+			if sample == value {
+				duplicate = true
+				break
+			}
+		}
+
+		if !duplicate {
+			sc.Samples = append(sc.Samples, value)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package data
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalSchemaChanges(t *testing.T) {
+	// An unchanged header shouldn't report anything
+	result, err := Unmarshal(strings.NewReader(testHeader + "\n"))
+	if err != nil || len(result.SchemaChanges) != 0 {
+		t.Errorf("expected no schema changes, got %v (%v)", result.SchemaChanges, err)
+	}
+
+	// Add a new column to the end and rename the class column to one of its aliases
+	header := strings.Replace(testHeader, "Curr Class", "CRS Class", 1) + ",Region"
+	records := []string{
+		`="170604","SPRINGFIELD, CITY OF",SANGAMON COUNTY,,,,,No,,,,,,R,Yes,5`,
+		`="170074","CHICAGO, CITY OF",COOK COUNTY,,,,,No,,,,,,R,Yes,5`,
+		`="480296","HOUSTON, CITY OF",HARRIS COUNTY,,,,,No,,,,,,R,Yes,6`,
+	}
+
+	result, err = Unmarshal(strings.NewReader(header + "\n" + strings.Join(records, "\n")))
+	if err != nil {
+		t.Fatalf("expected the status book to be parsed, got %s", err)
+	}
+
+	if len(result.Communities) != len(records) || len(result.SchemaChanges) != 2 {
+		t.Fatalf("expected %d communities and 2 schema changes, got %d and %v", len(records), len(result.Communities), result.SchemaChanges)
+	}
+
+	renamed := result.SchemaChanges[0]
+	if renamed.Kind != SchemaColumnRenamed || renamed.Column != "CRS Class" || renamed.Expected != "Curr Class" {
+		t.Errorf("expected the class column to be renamed, got %+v", renamed)
+	}
+
+	// Only distinct values are kept as samples
+	unknown := result.SchemaChanges[1]
+	if unknown.Kind != SchemaColumnUnknown || unknown.Column != "Region" || strings.Join(unknown.Samples, ",") != "5,6" {
+		t.Errorf("expected the region column to be unknown with samples, got %+v", unknown)
+	}
+}
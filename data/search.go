@@ -0,0 +1,288 @@
+package data
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A SearchResult is a community that matched a search, along with how
+// relevant it was from 0 to 1 so that the best results can be put first.
+type SearchResult struct {
+	Community NFIPCommunityStatus `json:"community"`
+	Score     float64             `json:"score"`
+	Matches   []MatchSpan         `json:"matches,omitempty"`
+}
+
+// A MatchSpan is where a search matched one of the community's fields so
+// that it can be highlighted. Fields are named the same as they are in JSON,
+// and Start and End are byte offsets into the field, with End exclusive.
+// The CID's offsets are into the CID as a number, without leading zeros.
+type MatchSpan struct {
+	Field string `json:"field"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+type SearchResults []SearchResult
+
+// Communities are the communities in the results, in the same order.
+func (sr SearchResults) Communities() NFIPCommunityStatuses {
+	communities := make(NFIPCommunityStatuses, len(sr))
+	for i, r := range sr {
+		communities[i] = r.Community
+	}
+
+	return communities
+}
+
+func (sr SearchResults) ToJSON(w io.Writer) error {
+	e := json.NewEncoder(w)
+	return e.Encode(sr)
+}
+
+// How well a term matched one of a community's fields, from best to worst
+const (
+	matchNone = iota
+	matchSubstring
+	matchWordBoundary
+	matchPrefix
+	matchExact
+)
+
+// Name matches are always ranked above county matches, and an exact CID
+// match is ranked above everything since there can only be one.
+const (
+	nameRelevance    = matchExact
+	exactCIDScore    = 2*matchExact + 1
+	maxRelevanceRank = exactCIDScore
+)
+
+// Search finds communities whose name, county or CID contains the term.
+// The most relevant communities come first: exact matches, then prefixes,
+// then matches at the start of a word, then anywhere else, with names
+// ranked above counties. Terms that are a state's name or abbreviation
+// also find the communities in that state, ranked last.
+//
+// Synonyms in the term are searched for too, so "St. Louis" finds
+// "SAINT LOUIS" and the other way around. See AddSynonyms.
+//
+// Terms with more than one word also find communities that have every word
+// somewhere in their name, county, state or CID, so "cook county il" finds
+// Cook County, Illinois. Each word has to match at the start of a word, and
+// "quoted phrases" have to match all together.
+//
+// Terms that look like a query are searched as one, unless it can't be
+// parsed. Query results aren't ranked or highlighted, so they all have a
+// score of 0 and are in the same order as the status book. See ParseQuery.
+func (c NFIPCommunityStatuses) Search(term string) SearchResults {
+	return c.search(term, folding{})
+}
+
+func (c NFIPCommunityStatuses) search(term string, f folding) SearchResults {
+	results := SearchResults{}
+
+	if IsQuery(term) {
+		if f, err := ParseQuery(term); err == nil {
+			for _, community := range *c.searchBy(f) {
+				results = append(results, SearchResult{Community: community})
+			}
+
+			return results
+		}
+	}
+
+	st := newFoldedSearchTerm(term, f)
+
+	for _, community := range c {
+		k := community.foldedSearchKeys(f)
+		if rank := st.relevance(k); rank > 0 {
+			results = append(results, SearchResult{community, float64(rank) / maxRelevanceRank, st.spans(community, k)})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	return results
+}
+
+// bestRelevance is the best that any of the variants of a term matched.
+func bestRelevance(k *searchKeys, variants []string) int {
+	best := matchNone
+	for _, term := range variants {
+		best = maxInt(best, relevance(k, term))
+	}
+
+	return best
+}
+
+// relevance ranks how well the community matched the lowercase term.
+func relevance(k *searchKeys, term string) int {
+	if k.cid == term {
+		return exactCIDScore
+	}
+
+	if m := maxInt(matchLevel(k.lowerName, term), matchLevel(k.lowerBaseName, term)); m > matchNone {
+		return nameRelevance + m
+	}
+
+	best := matchNone
+	for _, county := range k.lowerCounties {
+		best = maxInt(best, matchLevel(county, term))
+	}
+
+	if best == matchNone && (strings.Contains(k.cid, term) || k.inState(term)) {
+		best = matchSubstring
+	}
+
+	return best
+}
+
+// matchLevel is how well the term matched the field.
+func matchLevel(field, term string) int {
+	switch {
+	case len(term) == 0 || len(field) == 0:
+		return matchNone
+	case field == term:
+		return matchExact
+	case strings.HasPrefix(field, term):
+		return matchPrefix
+	}
+
+	level := matchNone
+	for offset := 0; ; {
+		i := strings.Index(field[offset:], term)
+		if i < 0 {
+			return level
+		}
+
+		i += offset
+		r, _ := utf8.DecodeLastRuneInString(field[:i])
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return matchWordBoundary
+		}
+
+		level = matchSubstring
+		offset = i + 1
+	}
+}
+
+// matchSpans finds everywhere any of the variants of a term are in the
+// community's name, county and CID, ignoring case (and accents, if they're
+// ignored). Where variants overlap,
+// like "st" and "st.", the one that comes first in the variants is kept.
+// The whole state is matched when the term names it.
+func matchSpans(c NFIPCommunityStatus, k *searchKeys, variants []string) []MatchSpan {
+	var spans []MatchSpan
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{"community_name", c.CommunityName},
+		{"county", c.County},
+		{"cid", k.cid},
+	}
+
+	for _, field := range fields {
+		var found []MatchSpan
+		for _, term := range variants {
+			for _, match := range k.folding.indexAllFold(field.value, term) {
+				span := MatchSpan{field.name, match[0], match[1]}
+				if !overlapsSpan(found, span) {
+					found = append(found, span)
+				}
+			}
+		}
+
+		sort.Slice(found, func(i, j int) bool {
+			return found[i].Start < found[j].Start
+		})
+		spans = append(spans, found...)
+	}
+
+	for _, term := range variants {
+		if k.inState(term) {
+			spans = append(spans, MatchSpan{"state", 0, len(c.State)})
+			break
+		}
+	}
+
+	return spans
+}
+
+func overlapsSpan(spans []MatchSpan, span MatchSpan) bool {
+	for _, s := range spans {
+		if span.Start < s.End && s.Start < span.End {
+			return true
+		}
+	}
+
+	return false
+}
+
+// indexAllFold finds the start and end of every match of the folded term in
+// s, once s is folded the same way. Matches don't overlap. Folding can change
+// how long s is, so the matches aren't always as long as the term.
+func (f folding) indexAllFold(s, term string) [][2]int {
+	var matches [][2]int
+	if len(term) == 0 {
+		return matches
+	}
+
+	folded, from := f.foldWithOffsets(s, true)
+	if from == nil {
+		return indexAll(folded, term)
+	}
+
+	for i := 0; i+len(term) <= len(folded); {
+		j := strings.Index(folded[i:], term)
+		if j < 0 {
+			break
+		}
+
+		start, end := i+j, i+j+len(term)
+
+		// A match that ends partway through what one letter was
+		// folded into still has to include all of that letter
+		for end < len(folded) && from[end] == from[end-1] {
+			end++
+		}
+
+		matches = append(matches, [2]int{from[start], from[end]})
+		i = end
+	}
+
+	return matches
+}
+
+// indexAll finds the start and end of every match of the term in s.
+func indexAll(s, term string) [][2]int {
+	var matches [][2]int
+	for i := 0; i+len(term) <= len(s); {
+		j := strings.Index(s[i:], term)
+		if j < 0 {
+			break
+		}
+
+		matches = append(matches, [2]int{i + j, i + j + len(term)})
+		i += j + len(term)
+	}
+
+	return matches
+}
+
+func maxInt(first int, rest ...int) int {
+	m := first
+	for _, n := range rest {
+		if n > m {
+			m = n
+		}
+	}
+
+	return m
+}
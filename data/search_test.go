@@ -0,0 +1,213 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchLevel(t *testing.T) {
+	tests := []struct {
+		field, term string
+		level       int
+	}{
+		{"springfield", "springfield", matchExact},
+		{"springfield, city of", "spring", matchPrefix},
+		{"new orleans/orleans parish, city of", "orleans", matchWordBoundary},
+		{"fairview park, city of", "view", matchSubstring},
+		{"cook county", "harris", matchNone},
+		{"cook county", "", matchNone},
+	}
+
+	for _, test := range tests {
+		if level := matchLevel(test.field, test.term); level != test.level {
+			t.Errorf("expected \"%s\" in \"%s\" to be %d, got %d", test.term, test.field, test.level, level)
+		}
+	}
+}
+
+func TestSearchRelevance(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	// Cook County itself comes before Chicago, which is just in it
+	results := communities.Search("cook")
+	if len(results) != 2 || results[0].Community.CID != 170054 || results[1].Community.CID != 170074 {
+		t.Fatalf("expected Cook County and then Chicago, got %v", results.Communities())
+	}
+
+	if results[0].Score <= results[1].Score {
+		t.Errorf("expected Cook County to score higher than Chicago, got %f and %f", results[0].Score, results[1].Score)
+	}
+
+	// An exact CID is as relevant as it gets
+	results = communities.Search("170074")
+	if len(results) != 1 || results[0].Score != 1 {
+		t.Errorf("expected an exact CID match to score 1, got %v", results)
+	}
+
+	// Harris County is an exact county match for Houston but a
+	// prefix match on the name of the county's own community
+	results = communities.Search("harris county")
+	if len(results) != 2 || results[0].Community.CID != 480287 || results[1].Community.CID != 480296 {
+		t.Errorf("expected Harris County and then Houston, got %v", results.Communities())
+	}
+
+	if results := communities.Search("atlantis"); results == nil || len(results) != 0 {
+		t.Errorf("expected no results, got %v", results)
+	}
+}
+
+func TestSearchMatchSpans(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	// New Orleans has "orleans" twice in its name and once in its parish
+	results := communities.Search("Orleans")
+	if len(results) != 1 {
+		t.Fatalf("expected only New Orleans, got %v", results.Communities())
+	}
+
+	expected := []MatchSpan{
+		{"community_name", 4, 11},
+		{"community_name", 12, 19},
+		{"county", 0, 7},
+	}
+
+	spans := results[0].Matches
+	if len(spans) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, spans)
+	}
+
+	for i := range spans {
+		if spans[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], spans[i])
+		}
+	}
+
+	// The span covers what was actually matched
+	name := results[0].Community.CommunityName
+	if name[spans[0].Start:spans[0].End] != "ORLEANS" {
+		t.Errorf("expected the span to cover \"ORLEANS\", got \"%s\"", name[spans[0].Start:spans[0].End])
+	}
+
+	// Fuzzy matches highlight the words that were close, which
+	// for New York are in its name and in one of its counties
+	results = communities.FuzzySearch("new yrok")
+	if len(results) != 1 || len(results[0].Matches) != 2 ||
+		results[0].Matches[0] != (MatchSpan{"community_name", 0, 8}) ||
+		results[0].Matches[1] != (MatchSpan{"county", 26, 34}) {
+		t.Errorf("expected \"NEW YORK\" to be highlighted, got %v", results)
+	}
+}
+
+func TestSearchKeys(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	// The keys are made when the communities are loaded
+	chicago := communities.SearchByCID(170074)
+	if (*chicago)[0].keys == nil || (*chicago)[0].keys.lowerName != "chicago, city of" {
+		t.Fatalf("expected Chicago's keys to be made when it was loaded")
+	}
+
+	// Changing a community means its keys are made again
+	c := (*chicago)[0]
+	c.CommunityName = "SECOND CITY, CITY OF"
+	if k := c.searchKeys(); k == c.keys || k.lowerName != "second city, city of" {
+		t.Errorf("expected new keys for the new name, got %s", k.lowerName)
+	}
+
+	if results := (NFIPCommunityStatuses{c}).Search("second city"); len(results) != 1 {
+		t.Errorf("expected to find Chicago by its new name")
+	}
+}
+
+// How fast searching was before the keys were made ahead of time, for comparison
+func BenchmarkSearchWithoutKeys(b *testing.B) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := range communities {
+		communities[i].keys = nil
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		communities.Search("springfield")
+	}
+}
+
+func TestSplitSearchTerm(t *testing.T) {
+	tests := []struct {
+		term   string
+		words  []string
+		quoted bool
+	}{
+		{"cook county il", []string{"cook", "county", "il"}, false},
+		{"  cook   county ", []string{"cook", "county"}, false},
+		{"\"miami beach\" fl", []string{"miami beach", "fl"}, true},
+		{"fl\"miami  beach\"", []string{"fl", "miami beach"}, true},
+		{"\"fort collins", []string{"fort collins"}, true},
+		{"\"\" cook", []string{"cook"}, true},
+	}
+
+	for _, test := range tests {
+		words, quoted := splitSearchTerm(test.term)
+		if !reflect.DeepEqual(words, test.words) || quoted != test.quoted {
+			t.Errorf("expected \"%s\" to split into %v (quoted %t), got %v (quoted %t)", test.term, test.words, test.quoted, words, quoted)
+		}
+	}
+}
+
+func TestMultiTermSearch(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	// Every word has to match somewhere, including the state
+	results := communities.Search("cook county il")
+	if len(results) != 2 || results[0].Community.CID != 170054 || results[1].Community.CID != 170074 {
+		t.Fatalf("expected Cook County and then Chicago, got %v", results.Communities())
+	}
+
+	if results := communities.Search("cook county tx"); len(results) != 0 {
+		t.Errorf("expected nothing in Cook County, Texas, got %v", results.Communities())
+	}
+
+	// The words don't have to be in the same field or in order
+	results = communities.Search("chicago cook")
+	if len(results) != 1 || results[0].Community.CID != 170074 {
+		t.Fatalf("expected only Chicago, got %v", results.Communities())
+	}
+
+	expected := []MatchSpan{{"community_name", 0, 7}, {"county", 0, 4}}
+	if !reflect.DeepEqual(results[0].Matches, expected) {
+		t.Errorf("expected %v, got %v", expected, results[0].Matches)
+	}
+
+	// Short words have to match the start of a word, so "il" doesn't find "MOBILE"
+	if results := communities.Search("mobile il"); len(results) != 0 {
+		t.Errorf("expected nothing for \"mobile il\", got %v", results.Communities())
+	}
+
+	// Quoted phrases have to match all together
+	results = communities.Search("\"miami beach\" fl")
+	if len(results) != 1 || results[0].Community.CID != 120651 {
+		t.Errorf("expected only Miami Beach, got %v", results.Communities())
+	}
+
+	if results := communities.Search("\"beach miami\""); len(results) != 0 {
+		t.Errorf("expected nothing for the phrase out of order, got %v", results.Communities())
+	}
+}
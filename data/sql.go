@@ -0,0 +1,225 @@
+package data
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var ErrUnsupportedDialect = fmt.Errorf("unsupported SQL dialect")
+
+// A SQLDialect is the flavor of SQL that ToSQL writes.
+type SQLDialect int
+
+const (
+	Postgres SQLDialect = iota
+	MySQL
+	SQLite
+)
+
+// DefaultSQLTable is the table that ToSQL creates if it isn't given one.
+const DefaultSQLTable = "nfip_communities"
+
+// How many communities are inserted by each INSERT statement.
+const sqlInsertBatchSize = 500
+
+// The SQL types of the fields that aren't text.
+var sqlFieldTypes = map[string]string{
+	"cid":                     "INTEGER",
+	"state_fips":              "INTEGER",
+	"fhbm_identified":         "DATE",
+	"firm_identified":         "DATE",
+	"curr_eff_map_date":       "DATE",
+	"reg_emer_date":           "DATE",
+	"tribal":                  "BOOLEAN",
+	"crs_entry_date":          "DATE",
+	"curr_eff_date":           "DATE",
+	"cur_class":               "INTEGER",
+	"participating_community": "BOOLEAN",
+}
+
+// WithSQLTable is the table that ToSQL creates and inserts into. It can
+// have a schema in front of it, like "warehouse.nfip_communities".
+func WithSQLTable(table string) EncodeOption {
+	return func(o *encodeOptions) {
+		o.sqlTable = table
+	}
+}
+
+// WithSQLDialect is the flavor of SQL that ToSQL writes. It's Postgres by default.
+func WithSQLDialect(dialect SQLDialect) EncodeOption {
+	return func(o *encodeOptions) {
+		o.sqlDialect = dialect
+	}
+}
+
+// AsCopy loads the communities with Postgres's COPY instead of INSERTs,
+// which is much faster for the whole status book. It can be run with psql.
+func AsCopy() EncodeOption {
+	return func(o *encodeOptions) {
+		o.sqlCopy = true
+	}
+}
+
+// ToSQL writes out a CREATE TABLE for the communities followed by the
+// statements to load them into it, in one transaction. The columns are
+// the same as the fields in JSON, or the ones picked by WithFields.
+func (c *NFIPCommunityStatuses) ToSQL(w io.Writer, opts ...EncodeOption) error {
+	o := newEncodeOptions(opts)
+	if o.sqlCopy && o.sqlDialect != Postgres {
+		return fmt.Errorf("%w: COPY is only supported by Postgres", ErrUnsupportedDialect)
+	}
+
+	if o.sqlDialect < Postgres || o.sqlDialect > SQLite {
+		return fmt.Errorf("%w: %d", ErrUnsupportedDialect, o.sqlDialect)
+	}
+
+	fields := statusFields
+	if len(o.fields) > 0 {
+		var err error
+		if fields, err = pickFields(o.fields); err != nil {
+			return err
+		}
+	}
+
+	table := o.sqlTable
+	if len(table) == 0 {
+		table = DefaultSQLTable
+	}
+	table = quoteSQLTable(o.sqlDialect, table)
+
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = quoteSQLIdentifier(o.sqlDialect, f.name)
+	}
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "CREATE TABLE IF NOT EXISTS %s (\n", table)
+	for i, f := range fields {
+		typ, ok := sqlFieldTypes[f.name]
+		if !ok {
+			typ = "TEXT"
+		}
+		if f.name == "cid" {
+			typ += " NOT NULL"
+		}
+
+		separator := ","
+		if i == len(fields)-1 {
+			separator = ""
+		}
+		fmt.Fprintf(bw, "  %s %s%s\n", columns[i], typ, separator)
+	}
+	bw.WriteString(");\n\n")
+
+	bw.WriteString("BEGIN;\n")
+	if o.sqlCopy {
+		writeSQLCopy(bw, table, columns, fields, *c)
+	} else {
+		writeSQLInserts(bw, o.sqlDialect, table, columns, fields, *c)
+	}
+	bw.WriteString("COMMIT;\n")
+
+	return bw.Flush()
+}
+
+func writeSQLInserts(w *bufio.Writer, dialect SQLDialect, table string, columns []string, fields []statusField, communities NFIPCommunityStatuses) {
+	for start := 0; start < len(communities); start += sqlInsertBatchSize {
+		end := minInt(start+sqlInsertBatchSize, len(communities))
+
+		fmt.Fprintf(w, "INSERT INTO %s (%s) VALUES\n", table, strings.Join(columns, ", "))
+		for i, community := range communities[start:end] {
+			values := make([]string, len(fields))
+			for j, f := range fields {
+				values[j] = sqlLiteral(dialect, f, community)
+			}
+
+			separator := ","
+			if start+i == end-1 {
+				separator = ";"
+			}
+			fmt.Fprintf(w, "  (%s)%s\n", strings.Join(values, ", "), separator)
+		}
+	}
+}
+
+// writeSQLCopy writes the communities in COPY's text format, with a tab
+// between each column and \N for nulls, ending with \. on its own line.
+func writeSQLCopy(w *bufio.Writer, table string, columns []string, fields []statusField, communities NFIPCommunityStatuses) {
+	fmt.Fprintf(w, "COPY %s (%s) FROM stdin;\n", table, strings.Join(columns, ", "))
+
+	copyEscaper := strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+	for _, community := range communities {
+		for i, f := range fields {
+			if i > 0 {
+				w.WriteByte('\t')
+			}
+
+			value := f.table.value(community)
+			switch {
+			case len(value) == 0:
+				w.WriteString("\\N")
+			case sqlFieldTypes[f.name] == "BOOLEAN":
+				w.WriteString(strconv.FormatBool(value == "Yes")[:1])
+			case sqlFieldTypes[f.name] == "INTEGER":
+				n, _ := strconv.Atoi(value)
+				w.WriteString(strconv.Itoa(n))
+			default:
+				w.WriteString(copyEscaper.Replace(value))
+			}
+		}
+		w.WriteByte('\n')
+	}
+
+	w.WriteString("\\.\n")
+}
+
+// sqlLiteral is the field's value as it's written in an INSERT. Values
+// come from the same place as they do for tables, so missing ones are NULL.
+func sqlLiteral(dialect SQLDialect, f statusField, c NFIPCommunityStatus) string {
+	value := f.table.value(c)
+	if len(value) == 0 {
+		return "NULL"
+	}
+
+	switch sqlFieldTypes[f.name] {
+	case "INTEGER":
+		// Without the leading zeros that CIDs and FIPS codes have
+		n, _ := strconv.Atoi(value)
+		return strconv.Itoa(n)
+	case "BOOLEAN":
+		if value == "Yes" {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+
+	if dialect == MySQL {
+		// MySQL treats backslashes in strings as escapes
+		value = strings.ReplaceAll(value, "\\", "\\\\")
+	}
+
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func quoteSQLIdentifier(dialect SQLDialect, name string) string {
+	quote := "\""
+	if dialect == MySQL {
+		quote = "`"
+	}
+
+	return quote + strings.ReplaceAll(name, quote, quote+quote) + quote
+}
+
+// quoteSQLTable quotes each part of a table name like "schema.table".
+func quoteSQLTable(dialect SQLDialect, table string) string {
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = quoteSQLIdentifier(dialect, part)
+	}
+
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,109 @@
+package data
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestToSQL(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+
+	var buf bytes.Buffer
+	if err := communities.ToSQL(&buf); err != nil {
+		t.Fatalf("expected the communities to be written as SQL, got %s", err)
+	}
+
+	sql := buf.String()
+	if !strings.HasPrefix(sql, "CREATE TABLE IF NOT EXISTS \"nfip_communities\" (\n  \"cid\" INTEGER NOT NULL,\n  \"community_name\" TEXT,") {
+		t.Errorf("expected the table to be created first, got %s", sql[:120])
+	}
+
+	if !strings.Contains(sql, "\"curr_eff_map_date\" DATE,") || !strings.Contains(sql, "\"participating_community\" BOOLEAN\n);") {
+		t.Errorf("expected the columns to have their types, got %s", sql[:800])
+	}
+
+	rows := 0
+	for _, line := range strings.Split(sql, "\n") {
+		if strings.HasPrefix(line, "  (") {
+			rows++
+		}
+	}
+	if rows != len(communities) {
+		t.Errorf("expected a row for each of the %d communities, got %d", len(communities), rows)
+	}
+
+	expected := (len(communities) + sqlInsertBatchSize - 1) / sqlInsertBatchSize
+	if inserts := strings.Count(sql, "INSERT INTO \"nfip_communities\""); inserts != expected {
+		t.Errorf("expected %d INSERTs, got %d", expected, inserts)
+	}
+
+	if !strings.HasSuffix(sql, ";\nCOMMIT;\n") {
+		t.Errorf("expected the last INSERT to be committed, got %s", sql[len(sql)-80:])
+	}
+}
+
+func TestToSQLDialects(t *testing.T) {
+	communities := NFIPCommunityStatuses{{
+		CID:           480287,
+		CommunityName: "O'BRIEN\\TOWN",
+		State:         "TX",
+		Tribal:        false,
+	}}
+
+	var buf bytes.Buffer
+	err := communities.ToSQL(&buf, WithSQLDialect(MySQL), WithSQLTable("warehouse.nfip"), WithFields("cid", "community_name", "cur_class", "tribal"))
+	if err != nil {
+		t.Fatalf("expected the community to be written as SQL, got %s", err)
+	}
+
+	sql := buf.String()
+	if !strings.Contains(sql, "CREATE TABLE IF NOT EXISTS `warehouse`.`nfip` (") {
+		t.Errorf("expected MySQL's quotes around each part of the table, got %s", sql)
+	}
+
+	if !strings.Contains(sql, "(`cid`, `community_name`, `cur_class`, `tribal`) VALUES\n  (480287, 'O''BRIEN\\\\TOWN', NULL, FALSE);") {
+		t.Errorf("expected the values to be escaped for MySQL, got %s", sql)
+	}
+
+	buf.Reset()
+	communities.ToSQL(&buf, WithSQLDialect(SQLite), WithFields("community_name"))
+	if !strings.Contains(buf.String(), "  ('O''BRIEN\\TOWN');") {
+		t.Errorf("expected backslashes to be left alone for SQLite, got %s", buf.String())
+	}
+}
+
+func TestToSQLCopy(t *testing.T) {
+	cls := 7
+	communities := NFIPCommunityStatuses{{
+		CID:                    480287,
+		CommunityName:          "HOUSTON,\tCITY OF",
+		StateFIPS:              48,
+		CurClass:               &cls,
+		ParticipatingCommunity: true,
+	}}
+
+	var buf bytes.Buffer
+	if err := communities.ToSQL(&buf, AsCopy(), WithFields("cid", "community_name", "state_fips", "cur_class", "county", "participating_community")); err != nil {
+		t.Fatalf("expected the community to be written with COPY, got %s", err)
+	}
+
+	expected := "BEGIN;\n" +
+		"COPY \"nfip_communities\" (\"cid\", \"community_name\", \"state_fips\", \"cur_class\", \"county\", \"participating_community\") FROM stdin;\n" +
+		"480287\tHOUSTON,\\tCITY OF\t48\t7\t\\N\tt\n" +
+		"\\.\n" +
+		"COMMIT;\n"
+	if !strings.HasSuffix(buf.String(), expected) {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+
+	err := communities.ToSQL(&buf, AsCopy(), WithSQLDialect(MySQL))
+	if !errors.Is(err, ErrUnsupportedDialect) {
+		t.Errorf("expected COPY to only work with Postgres, got %v", err)
+	}
+
+	if err := communities.ToSQL(&buf, WithFields("nope")); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("expected an unknown field to be an error, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
@@ -8,12 +9,25 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"nfip-community-book/states"
 )
 
+// Downloading and parsing the status book are traced, since they're most
+// of how long it takes to start up and to refresh.
+var tracer = otel.Tracer("nfip-community-book/data")
+
 const NFIPCommunityStatusBookFilename = "nation.csv"
 const NFIPCommunityStatusBookURL = "https://www.fema.gov/cis/nation.csv"
 
@@ -33,81 +47,215 @@ const (
 	StausPercentNonSFHA
 	StatusProgram
 	StatusParticipatingCommunity
+
+	numStatusColumns
 )
 
 type NFIPCommunityStatuses []NFIPCommunityStatus
 
 type NFIPCommunityStatus struct {
-	CID                    int        `json:"cid"`
-	CommunityName          string     `json:"community_name"`
-	County                 string     `json:"county"`
-	FHBMIdentified         *time.Time `json:"fhbm_identified"`
-	FIRMIdentified         *time.Time `json:"firm_identified"`
-	CurrEffMapDate         *time.Time `json:"curr_eff_map_date"`
-	RegEmerDate            *time.Time `json:"reg_emer_date"`
-	Tribal                 bool       `json:"tribal"`
-	CRSEntryDate           string     `json:"crs_entry_date"`
-	CurrEffDate            string     `json:"curr_eff_date"`
-	CurClass               string     `json:"cur_class"`
-	PercentDiscSFHA        string     `json:"percent_disc_sfha"`
-	PercentNonSFHA         string     `json:"percent_non_sfha"`
-	Program                string     `json:"program"`
-	ParticipatingCommunity bool       `json:"participating_community"`
+	CID                    int            `json:"cid"`
+	CommunityName          string         `json:"community_name"`
+	Flags                  CommunityFlags `json:"flags"`
+	Kind                   NameKind       `json:"kind"`
+	BaseName               string         `json:"base_name"`
+	Type                   CommunityType  `json:"type"`
+	County                 string         `json:"county"`
+	State                  string         `json:"state"`
+	StateFIPS              int            `json:"state_fips"`
+	FHBMIdentified         *time.Time     `json:"fhbm_identified"`
+	FIRMIdentified         *time.Time     `json:"firm_identified"`
+	CurrEffMapDate         *time.Time     `json:"curr_eff_map_date"`
+	RegEmerDate            *time.Time     `json:"reg_emer_date"`
+	Tribal                 bool           `json:"tribal"`
+	CRSEntryDate           *time.Time     `json:"crs_entry_date"`
+	CurrEffDate            *time.Time     `json:"curr_eff_date"`
+	CurClass               *int           `json:"cur_class"`
+	PercentDiscSFHA        string         `json:"percent_disc_sfha"`
+	PercentNonSFHA         string         `json:"percent_non_sfha"`
+	Program                ProgramKind    `json:"program"`
+	ParticipatingCommunity bool           `json:"participating_community"`
+
+	keys *searchKeys
 }
 
 var ErrEmptyString = fmt.Errorf("string is empty")
 var ErrInvalidDateString = fmt.Errorf("invalid date string")
+var ErrInvalidCRSClass = fmt.Errorf("invalid CRS class")
+var ErrStopIteration = fmt.Errorf("stop iteration")
+
+// The standard SFHA premium discount (in percent) for each CRS class.
+// Index 0 is unused since CRS classes start at 1.
+var sfhaDiscountByClass = [...]int{0, 45, 40, 35, 30, 25, 20, 15, 10, 5, 0}
+
+// EnsureNFIPCommunityStatusBook downloads the Community Status Book if it
+// isn't cached locally yet and returns the absolute path to the cached file.
+func EnsureNFIPCommunityStatusBook(l *log.Logger) (string, error) {
+	return ensureNFIPCommunityStatusBook(context.Background(), l)
+}
 
-func GetNFIPCommunityStatusBook(l *log.Logger) (NFIPCommunityStatuses, error) {
+func ensureNFIPCommunityStatusBook(ctx context.Context, l *log.Logger) (string, error) {
 	if _, err := os.Stat(NFIPCommunityStatusBookFilename); os.IsNotExist(err) {
 		l.Println("NFIP Community book does not exist. Downloading...")
-		resp, err := http.Get(NFIPCommunityStatusBookURL)
 
-		if err != nil {
-			l.Println("** Err -", err)
-			os.Exit(1)
+		if err := downloadNFIPCommunityStatusBook(ctx, NFIPCommunityStatusBookFilename, verifyNFIPCommunityStatusBook); err != nil {
+			return "", err
 		}
-		defer resp.Body.Close()
-
-		f, err := os.Create(NFIPCommunityStatusBookFilename)
+	}
 
-		if err != nil {
-			l.Println("** Err -", err)
-			os.Exit(1)
-		}
+	return filepath.Abs(NFIPCommunityStatusBookFilename)
+}
 
-		io.Copy(f, resp.Body)
-		defer f.Close()
+// NFIPCommunityStatusBookDownloaded is when the status book that's cached
+// was downloaded, which is the last time that it was written.
+func NFIPCommunityStatusBookDownloaded() (time.Time, error) {
+	fi, err := os.Stat(NFIPCommunityStatusBookFilename)
+	if err != nil {
+		return time.Time{}, err
 	}
 
-	f, err := os.Open(NFIPCommunityStatusBookFilename)
+	return fi.ModTime(), nil
+}
+
+func GetNFIPCommunityStatusBook(l *log.Logger, opts ...ParseOption) (NFIPCommunityStatuses, error) {
+	ctx, span := tracer.Start(context.Background(), "load status book")
+	defer span.End()
+
+	filename, err := ensureNFIPCommunityStatusBook(ctx, l)
 
 	if err != nil {
-		l.Println("Could not open NFIP Community book")
+		l.Println("** Err -", err)
 		os.Exit(1)
 	}
 
+	result, err := readNFIPCommunityStatusBook(ctx, filename, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	logSchemaChanges(l, result.SchemaChanges)
+	logDuplicates(l, result.Duplicates)
+	return result.Communities, nil
+}
+
+func downloadNFIPCommunityStatusBook(ctx context.Context, filename string, verify func(filename string) error) (err error) {
+	ctx, span := tracer.Start(ctx, "download status book",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPRequestMethodKey.String(http.MethodGet), semconv.URLFull(NFIPCommunityStatusBookURL)),
+	)
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	status, n, err := downloadFile(ctx, NFIPCommunityStatusBookURL, filename, verify)
+	if status != 0 {
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+	}
+	span.SetAttributes(semconv.HTTPResponseBodySize(int(n)))
+	return err
+}
+
+// verifyNFIPCommunityStatusBook checks that a download is a status book
+// by finding its columns and parsing the first community in it.
+func verifyNFIPCommunityStatusBook(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
 	defer f.Close()
 
-	csvReader := csv.NewReader(f)
-	csvReader.LazyQuotes = true
-	communities, err := unmarshal(csvReader)
+	found := false
+	_, err = UnmarshalEach(f, func(NFIPCommunityStatus) error {
+		found = true
+		return ErrStopIteration
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrDownloadFailed, err)
+	}
+	if !found {
+		return fmt.Errorf("%w: there aren't any communities in it", ErrDownloadFailed)
+	}
+
+	return nil
+}
+
+func readNFIPCommunityStatusBook(ctx context.Context, filename string, opts ...ParseOption) (result ParseResult, err error) {
+	_, span := tracer.Start(ctx, "parse status book")
+	defer func() {
+		span.SetAttributes(attribute.Int("nfip.communities", len(result.Communities)), attribute.Int("nfip.duplicates", len(result.Duplicates)))
+		endSpan(span, err)
+	}()
+
+	f, err := os.Open(filename)
 
 	if err != nil {
-		return nil, fmt.Errorf("could not parse NFIP Community book CSV File. Reason: %s", err.Error())
+		return ParseResult{}, fmt.Errorf("could not open NFIP Community book. Reason: %s", err.Error())
 	}
 
-	return communities, nil
+	defer f.Close()
+
+	result, err = Unmarshal(f, opts...)
+
+	if err != nil {
+		return ParseResult{}, fmt.Errorf("could not parse NFIP Community book CSV File. Reason: %s", err.Error())
+	}
+
+	return result, nil
+}
+
+// endSpan ends the span, marking it as failed if there's an error.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
 }
 
-func (c NFIPCommunityStatuses) Search(term string) *NFIPCommunityStatuses {
+func logDuplicates(l *log.Logger, duplicates []DuplicateCID) {
+	for _, d := range duplicates {
+		l.Printf("** WARN - NFIP Community book has CID %06d more than once (lines %s)\n", d.CID, joinLines(d.Indexes))
+	}
+}
+
+func logSchemaChanges(l *log.Logger, changes []SchemaChange) {
+	for _, sc := range changes {
+		l.Println("** WARN - NFIP Community book schema changed:", sc)
+	}
+}
+
+// SearchByName matches communities whose name contains the term.
+func (c NFIPCommunityStatuses) SearchByName(term string) *NFIPCommunityStatuses {
+	return c.searchBy(NameContains(term))
+}
+
+// SearchByCounty matches communities whose county contains the term.
+func (c NFIPCommunityStatuses) SearchByCounty(term string) *NFIPCommunityStatuses {
+	return c.searchBy(CountyContains(term))
+}
+
+// SearchByCID only matches the community with exactly this CID.
+func (c NFIPCommunityStatuses) SearchByCID(cid int) *NFIPCommunityStatuses {
+	return c.searchBy(func(community NFIPCommunityStatus) bool {
+		return community.CID == cid
+	})
+}
+
+// SearchByState matches communities in the state, which can be given
+// by its abbreviation ("IL"), its name ("Illinois") or its FIPS code ("17").
+func (c NFIPCommunityStatuses) SearchByState(state string) *NFIPCommunityStatuses {
+	if _, ok := states.Lookup(state); !ok {
+		return &NFIPCommunityStatuses{}
+	}
+
+	return c.searchBy(ByState(state))
+}
+
+func (c NFIPCommunityStatuses) searchBy(match func(NFIPCommunityStatus) bool) *NFIPCommunityStatuses {
 	var matchingCommunities NFIPCommunityStatuses
-	term = strings.ToLower(term)
 
 	for _, community := range c {
-		if strings.Contains(strings.ToLower(community.CommunityName), term) ||
-			strings.Contains(strings.ToLower(community.County), term) ||
-			strings.Contains(strconv.Itoa(community.CID), term) {
+		if match(community) {
 			matchingCommunities = append(matchingCommunities, community)
 		}
 	}
@@ -115,157 +263,361 @@ func (c NFIPCommunityStatuses) Search(term string) *NFIPCommunityStatuses {
 	return &matchingCommunities
 }
 
-func (c *NFIPCommunityStatuses) ToJSON(w io.Writer) error {
+// IsCRSParticipant reports whether the community has a CRS class,
+// which is only the case for communities in the Community Rating System.
+func (c NFIPCommunityStatus) IsCRSParticipant() bool {
+	return c.CurClass != nil
+}
+
+// DiscountForClass returns the standard SFHA premium discount percentage
+// for the community's CRS class. Non-CRS communities, and classes that
+// aren't from 1 to 10, get no discount.
+func (c NFIPCommunityStatus) DiscountForClass() int {
+	if !c.IsCRSParticipant() || *c.CurClass < 1 || *c.CurClass >= len(sfhaDiscountByClass) {
+		return 0
+	}
+
+	return sfhaDiscountByClass[*c.CurClass]
+}
+
+func (c *NFIPCommunityStatuses) ToJSON(w io.Writer, opts ...EncodeOption) error {
+	o := newEncodeOptions(opts)
+	v, err := c.project(o)
+	if err != nil {
+		return err
+	}
+
+	w, closeWriter, err := o.compress(w)
+	if err != nil {
+		return err
+	}
+
 	e := json.NewEncoder(w)
-	return e.Encode(c)
+	if err := e.Encode(v); err != nil {
+		return err
+	}
+
+	return closeWriter()
 }
 
 func (c *NFIPCommunityStatuses) addCommunity(comm *NFIPCommunityStatus) {
 	*c = append(*c, *comm)
 }
 
-func unmarshal(reader *csv.Reader) (NFIPCommunityStatuses, error) {
-	var communities NFIPCommunityStatuses
-	var lineNumber int = 1
-	var firstPass bool = true
+// ParseResult is everything that comes out of parsing the status book.
+type ParseResult struct {
+	Communities   NFIPCommunityStatuses `json:"communities"`
+	SchemaChanges []SchemaChange        `json:"schema_changes"`
 
-	for {
-		record, err := reader.Read()
+	// The CIDs that were in the status book more than once,
+	// before the duplicate policy was applied.
+	Duplicates []DuplicateCID `json:"duplicates"`
 
-		// Skip the header
-		if firstPass {
-			firstPass = false
-			lineNumber++
-			continue
-		}
+	// The values that couldn't be parsed and were left out.
+	Unparsable []UnparsableValue `json:"unparsable"`
+}
 
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
+// Unmarshal parses a Community Status Book CSV file. The file can be
+// in UTF-8 (with or without a byte order mark) or Windows-1252.
+func Unmarshal(r io.Reader, opts ...ParseOption) (ParseResult, error) {
+	csvReader := csv.NewReader(newTextReader(r))
+	csvReader.LazyQuotes = true
+	return parse(csvReader, opts...)
+}
+
+// UnmarshalEach parses a Community Status Book CSV file one community at a
+// time, calling fn with each community as soon as it's parsed instead of
+// holding onto all of them. If fn returns ErrStopIteration, parsing stops
+// early without an error. Any other error stops parsing and is returned.
+// Every community is passed to fn, even ones with duplicate CIDs, since
+// the duplicate policy can't be applied without seeing the whole file.
+func UnmarshalEach(r io.Reader, fn func(NFIPCommunityStatus) error, opts ...ParseOption) ([]SchemaChange, error) {
+	csvReader := csv.NewReader(newTextReader(r))
+	csvReader.LazyQuotes = true
+	return parseEach(csvReader, fn, opts...)
+}
 
-			// if we get an error other than EOF, then return it
-			return nil, fmt.Errorf("** ERR: %s on line %d", err.Error(), lineNumber)
+func unmarshal(reader *csv.Reader, opts ...ParseOption) (NFIPCommunityStatuses, error) {
+	result, err := parse(reader, opts...)
+	return result.Communities, err
+}
+
+func parse(reader *csv.Reader, opts ...ParseOption) (ParseResult, error) {
+	var communities NFIPCommunityStatuses
+	var unparsable []UnparsableValue
+
+	opts = append(opts, func(o *parseOptions) {
+		o.onUnparsable = func(uv UnparsableValue) {
+			unparsable = append(unparsable, uv)
 		}
+	})
+
+	schemaChanges, err := parseEach(reader, func(nc NFIPCommunityStatus) error {
+		communities.addCommunity(&nc)
+		return nil
+	}, opts...)
+
+	if err != nil {
+		return ParseResult{}, err
+	}
+
+	o := newParseOptions(opts)
+	duplicates := communities.Duplicates()
+	communities, err = applyDuplicatePolicy(communities, duplicates, o.duplicatePolicy)
+	if err != nil {
+		return ParseResult{}, fmt.Errorf("** ERR: %w", err)
+	}
+
+	return ParseResult{communities, schemaChanges, duplicates, unparsable}, nil
+}
 
-		var boolVal bool
+func parseEach(reader *csv.Reader, fn func(NFIPCommunityStatus) error, opts ...ParseOption) ([]SchemaChange, error) {
+	o := newParseOptions(opts)
 
-		// Clean all of the data by trimming off '=' and '"' characters
-		for i := 0; i < len(record); i++ {
-			record[i] = strings.Trim(record[i], "\"=")
+	// Find each of the columns by name in the header
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, nil
 		}
 
-		nc := NFIPCommunityStatus{}
+		return nil, fmt.Errorf("** ERR: %s on line 1", err.Error())
+	}
 
-		// Trim the leading "=" before each CID number
-		cidString := record[StatusCID]
+	columns, err := mapColumns(header, o.columnAliases)
+	if err != nil {
+		return nil, fmt.Errorf("** ERR: %s on line 1", err.Error())
+	}
 
-		if len(cidString) > 0 {
-			cid, err := strconv.Atoi(cidString)
+	schemaChanges := detectSchemaChanges(header, columns)
 
-			if err != nil {
-				return nil, fmt.Errorf("** ERR: %s on line %d", err.Error(), lineNumber)
-			}
+	done := make(chan struct{})
+	defer close(done)
+
+	// The workers can't share an interner, so the values
+	// are interned here as the communities come back.
+	in := newInterner()
 
-			nc.CID = cid
+	for result := range parseRecords(reader, columns, o, done) {
+		pr := <-result
+		if pr.err != nil {
+			return nil, pr.err
 		}
 
-		nc.CommunityName = record[StatusCommunityName]
-		nc.County = record[StatusCounty]
+		sampleUnknownColumns(schemaChanges, pr.record)
 
-		fhbmIdentifiedDate, err := parseDate(record[StatusFHBMIdentified])
-		if err == nil {
-			nc.FHBMIdentified = &fhbmIdentifiedDate
-		} else if err != nil && err != ErrEmptyString && err != ErrInvalidDateString {
-			return nil, fmt.Errorf("** ERR: %s on line %d", err.Error(), lineNumber)
+		if o.onUnparsable != nil {
+			for _, uv := range pr.unparsable {
+				o.onUnparsable(uv)
+			}
 		}
 
-		firmIdentifiedDate, err := parseDate(record[StatusFIRMIdentified])
-		if err == nil {
-			nc.FIRMIdentified = &firmIdentifiedDate
-		} else if err != nil && err != ErrEmptyString && err != ErrInvalidDateString {
-			return nil, fmt.Errorf("** ERR: %s on line %d", err.Error(), lineNumber)
+		if !o.skipIntern {
+			in.intern(&pr.community)
 		}
+		pr.community.keys = newSearchKeys(pr.community)
 
-		currEffMapDate, err := parseDate(record[StatusCurrEffMapDate])
-		if err == nil {
-			nc.CurrEffMapDate = &currEffMapDate
-		} else if err != nil && err != ErrEmptyString && err != ErrInvalidDateString {
-			return nil, fmt.Errorf("** ERR: %s on line %d", err.Error(), lineNumber)
+		if err := fn(pr.community); err == ErrStopIteration {
+			break
+		} else if err != nil {
+			return nil, err
 		}
+	}
+
+	return schemaChanges, nil
+}
+
+// parseRecord turns a record from the status book into a community.
+// It's called from the parsing workers, so it can't touch anything shared.
+func parseRecord(record []string, lineNumber int, columns [numStatusColumns]int, o parseOptions) (NFIPCommunityStatus, []UnparsableValue, error) {
+	var boolVal bool
+
+	// Clean all of the data by trimming off '=' and '"' characters
+	for i := 0; i < len(record); i++ {
+		record[i] = strings.Trim(record[i], "\"=")
+	}
+
+	nc := NFIPCommunityStatus{}
 
-		regEmerDate, err := parseDate(record[StatusRegEmerDate])
-		if err == nil {
-			nc.RegEmerDate = &regEmerDate
-		} else if err != nil && err != ErrEmptyString && err != ErrInvalidDateString {
-			return nil, fmt.Errorf("** ERR: %s on line %d", err.Error(), lineNumber)
+	// Keep track of the values that couldn't be parsed for the quality report
+	var unparsableValues []UnparsableValue
+	unparsable := func(column int) {
+		unparsableValues = append(unparsableValues, UnparsableValue{lineNumber, nc.CID, statusColumnNames[column][0], record[columns[column]]})
+	}
+
+	// Trim the leading "=" before each CID number
+	cidString := record[columns[StatusCID]]
+
+	if len(cidString) > 0 {
+		cid, err := strconv.Atoi(cidString)
+
+		if err != nil {
+			return nc, nil, fmt.Errorf("** ERR: %s on line %d", err.Error(), lineNumber)
 		}
 
-		boolVal, err = parseBoolFromYesNo(record[StatusTribal])
-		if err == nil {
-			nc.Tribal = boolVal
-		} else if err != nil && err != ErrEmptyString {
-			return nil, fmt.Errorf("** ERR: %s on line %d", err.Error(), lineNumber)
+		nc.CID = cid
+
+		// The first two digits of the CID are the state's FIPS code
+		if s, ok := states.ByFIPS(cid / 10000); ok {
+			nc.State = s.Abbreviation
+			nc.StateFIPS = s.FIPS
 		}
+	}
+
+	nc.CommunityName, nc.Flags = parseCommunityName(record[columns[StatusCommunityName]])
+	nc.Kind, nc.BaseName = splitCommunityName(nc.CommunityName)
+	nc.County = record[columns[StatusCounty]]
 
-		nc.CRSEntryDate = record[StatusCRSEntryDate]
-		nc.CurrEffDate = record[StatusCurrEffDate]
-		nc.CurClass = record[StatusCurClass]
-		nc.PercentDiscSFHA = record[StatusPercentDiscSFHA]
-		nc.PercentNonSFHA = record[StausPercentNonSFHA]
-		nc.Program = record[StatusProgram]
-
-		boolVal, err = parseBoolFromYesNo(record[StatusParticipatingCommunity])
-		if err == nil {
-			nc.ParticipatingCommunity = boolVal
-		} else if err != nil && err != ErrEmptyString {
-			return nil, fmt.Errorf("** ERR: %s on line %d", err.Error(), lineNumber)
+	// Some dates are markers instead, like "(NSFHA)", which are flags
+	invalidDate := func(column int) {
+		if !parseDateMarker(record[columns[column]], &nc.Flags) {
+			unparsable(column)
 		}
+	}
 
-		lineNumber++
-		communities.addCommunity(&nc)
+	fhbmIdentifiedDate, err := parseDateWithPivot(record[columns[StatusFHBMIdentified]], o.yearPivot)
+	if err == nil {
+		nc.FHBMIdentified = &fhbmIdentifiedDate
+	} else if err == ErrInvalidDateString {
+		invalidDate(StatusFHBMIdentified)
+	} else if err != nil && err != ErrEmptyString && err != ErrInvalidDateString {
+		return nc, nil, fmt.Errorf("** ERR: %s on line %d", err.Error(), lineNumber)
+	}
+
+	firmIdentifiedDate, err := parseDateWithPivot(record[columns[StatusFIRMIdentified]], o.yearPivot)
+	if err == nil {
+		nc.FIRMIdentified = &firmIdentifiedDate
+	} else if err == ErrInvalidDateString {
+		invalidDate(StatusFIRMIdentified)
+	} else if err != nil && err != ErrEmptyString && err != ErrInvalidDateString {
+		return nc, nil, fmt.Errorf("** ERR: %s on line %d", err.Error(), lineNumber)
 	}
 
-	return communities, nil
+	currEffMapDate, err := parseDateWithPivot(record[columns[StatusCurrEffMapDate]], o.yearPivot)
+	if err == nil {
+		nc.CurrEffMapDate = &currEffMapDate
+	} else if err == ErrInvalidDateString {
+		invalidDate(StatusCurrEffMapDate)
+	} else if err != nil && err != ErrEmptyString && err != ErrInvalidDateString {
+		return nc, nil, fmt.Errorf("** ERR: %s on line %d", err.Error(), lineNumber)
+	}
+
+	regEmerDate, err := parseDateWithPivot(record[columns[StatusRegEmerDate]], o.yearPivot)
+	if err == nil {
+		nc.RegEmerDate = &regEmerDate
+	} else if err == ErrInvalidDateString {
+		invalidDate(StatusRegEmerDate)
+	} else if err != nil && err != ErrEmptyString && err != ErrInvalidDateString {
+		return nc, nil, fmt.Errorf("** ERR: %s on line %d", err.Error(), lineNumber)
+	}
+
+	boolVal, err = parseBoolFromYesNo(record[columns[StatusTribal]])
+	if err == nil {
+		nc.Tribal = boolVal
+	} else if err != nil && err != ErrEmptyString {
+		return nc, nil, fmt.Errorf("** ERR: %s on line %d", err.Error(), lineNumber)
+	}
+
+	crsEntryDate, err := parseDateWithPivot(record[columns[StatusCRSEntryDate]], o.yearPivot)
+	if err == nil {
+		nc.CRSEntryDate = &crsEntryDate
+	} else if err == ErrInvalidDateString {
+		invalidDate(StatusCRSEntryDate)
+	} else if err != nil && err != ErrEmptyString && err != ErrInvalidDateString {
+		return nc, nil, fmt.Errorf("** ERR: %s on line %d", err.Error(), lineNumber)
+	}
+
+	currEffDate, err := parseDateWithPivot(record[columns[StatusCurrEffDate]], o.yearPivot)
+	if err == nil {
+		nc.CurrEffDate = &currEffDate
+	} else if err == ErrInvalidDateString {
+		invalidDate(StatusCurrEffDate)
+	} else if err != nil && err != ErrEmptyString && err != ErrInvalidDateString {
+		return nc, nil, fmt.Errorf("** ERR: %s on line %d", err.Error(), lineNumber)
+	}
+
+	curClass, err := parseCRSClass(record[columns[StatusCurClass]])
+	if err == nil {
+		nc.CurClass = &curClass
+	} else if err == ErrInvalidCRSClass {
+		unparsable(StatusCurClass)
+	} else if err != nil && err != ErrEmptyString && err != ErrInvalidCRSClass {
+		return nc, nil, fmt.Errorf("** ERR: %s on line %d", err.Error(), lineNumber)
+	}
+
+	nc.PercentDiscSFHA = record[columns[StatusPercentDiscSFHA]]
+	nc.PercentNonSFHA = record[columns[StausPercentNonSFHA]]
+
+	program, err := ParseProgramKind(record[columns[StatusProgram]])
+	if err == nil {
+		nc.Program = program
+	} else if err == ErrInvalidProgram {
+		unparsable(StatusProgram)
+	} else if err != nil && err != ErrEmptyString && err != ErrInvalidProgram {
+		return nc, nil, fmt.Errorf("** ERR: %s on line %d", err.Error(), lineNumber)
+	}
+
+	boolVal, err = parseBoolFromYesNo(record[columns[StatusParticipatingCommunity]])
+	if err == nil {
+		nc.ParticipatingCommunity = boolVal
+	} else if err != nil && err != ErrEmptyString {
+		return nc, nil, fmt.Errorf("** ERR: %s on line %d", err.Error(), lineNumber)
+	}
+
+	nc.Type = classifyCommunity(nc)
+
+	// Communities without a program listed that aren't participating in
+	// the NFIP aren't in any program, unless they're marked as having been
+	// suspended or withdrawn from it.
+	if nc.Program == ProgramUnknown && !nc.ParticipatingCommunity {
+		if nc.Flags.Suspended {
+			nc.Program = ProgramSuspended
+		} else if nc.Flags.Withdrawn {
+			nc.Program = ProgramWithdrawn
+		} else {
+			nc.Program = ProgramNotParticipating
+		}
+	}
+
+	return nc, unparsableValues, nil
 }
 
 func parseDate(s string) (time.Time, error) {
+	return parseDateWithPivot(s, DefaultYearPivot())
+}
+
+func parseDateWithPivot(s string, pivot int) (time.Time, error) {
 	if len(s) == 0 {
 		return time.Time{}, ErrEmptyString
 	}
 
-	reg := regexp.MustCompile("([0-9]+)")
-	matches := reg.FindAllString(s, 3)
+	numbers, n, ok := scanDateNumbers(s)
+	if !ok {
+		var err error
+		numbers, n, err = matchDateNumbers(s)
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
 
 	// If we don't have 3 sets of numbers,
 	// then it isn't a valid date string
 	// and we can stop trying to parse it
-	if len(matches) < 3 {
+	if n < 3 {
 		return time.Time{}, ErrInvalidDateString
 	}
 
-	m, err := strconv.Atoi(matches[0])
-	if err != nil {
-		return time.Time{}, fmt.Errorf("failed to parse month to integer")
-	}
-	month, err := iToMonth(m)
+	month, err := iToMonth(numbers[0])
 	if err != nil {
 		return time.Time{}, err
 	}
 
-	day, err := strconv.Atoi(matches[1])
-	if err != nil {
-		return time.Time{}, fmt.Errorf("failed to parse day to integer")
-	}
-
-	year, err := strconv.Atoi(matches[2])
-	if err != nil {
-		return time.Time{}, fmt.Errorf("failed to parse year to integer")
-	}
+	day := numbers[1]
+	year := numbers[2]
 
 	// The date is only stored in 2 digit format. So I'm taking a guess
 	// on whether it represents a year from the 20th or the 21st century.
-	if year <= 22 {
+	if year <= pivot {
 		year = 2000 + year
 	} else {
 		year = 1900 + year
@@ -274,6 +626,77 @@ func parseDate(s string) (time.Time, error) {
 	return time.Date(year, month, day, 0, 0, 0, 0, time.Local), nil
 }
 
+// The longest run of digits scanDateNumbers will handle
+// before it gives up and leaves it to the regex.
+const maxDateDigits = 9
+
+var dateNumbersRegexp = regexp.MustCompile("([0-9]+)")
+
+// scanDateNumbers pulls the first three numbers out of a date like
+// "05/31/74". Anything that isn't a digit separates the numbers, the
+// same as the regex does. It's not ok if a number is too long to scan.
+func scanDateNumbers(s string) ([3]int, int, bool) {
+	var numbers [3]int
+	n := 0
+	digits := 0
+
+	for i := 0; i < len(s) && n < 3; i++ {
+		c := s[i]
+		if c >= '0' && c <= '9' {
+			if digits == maxDateDigits {
+				return numbers, n, false
+			}
+
+			numbers[n] = numbers[n]*10 + int(c-'0')
+			digits++
+		} else if digits > 0 {
+			n++
+			digits = 0
+		}
+	}
+
+	if digits > 0 && n < 3 {
+		n++
+	}
+
+	return numbers, n, true
+}
+
+// matchDateNumbers is the slow way of getting the numbers out of
+// a date. It's only used when scanDateNumbers can't handle it.
+func matchDateNumbers(s string) ([3]int, int, error) {
+	var numbers [3]int
+	matches := dateNumbersRegexp.FindAllString(s, 3)
+
+	names := []string{"month", "day", "year"}
+	for i, match := range matches {
+		number, err := strconv.Atoi(match)
+		if err != nil {
+			return numbers, 0, fmt.Errorf("failed to parse %s to integer", names[i])
+		}
+
+		numbers[i] = number
+	}
+
+	return numbers, len(matches), nil
+}
+
+// CRS classes are whole numbers from 1 (best) to 10 (no discount).
+// Communities that aren't in the CRS have a blank class.
+func parseCRSClass(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if len(s) == 0 {
+		return 0, ErrEmptyString
+	}
+
+	class, err := strconv.Atoi(s)
+	if err != nil || class < 1 || class > 10 {
+		return 0, ErrInvalidCRSClass
+	}
+
+	return class, nil
+}
+
 func iToMonth(i int) (time.Month, error) {
 	switch i {
 	case 1:
@@ -1,6 +1,11 @@
 package data
 
 import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -86,3 +91,204 @@ func TestParseDate(t *testing.T) {
 		t.Errorf("expected an error parsing \"%s\" has a date", testString)
 	}
 }
+
+func TestParseCRSClass(t *testing.T) {
+	// Should be able to parse every valid class
+	for i := 1; i <= 10; i++ {
+		testString := strconv.Itoa(i)
+		class, err := parseCRSClass(testString)
+		if err != nil || class != i {
+			t.Errorf("expected \"%s\" to be parsed to %d", testString, i)
+		}
+	}
+
+	// Return an empty string error for non-CRS communities
+	testString := ""
+	_, err := parseCRSClass(testString)
+	if err != ErrEmptyString {
+		t.Errorf("expected an empty string error")
+	}
+
+	// Classes outside of 1-10 are invalid
+	for _, testString := range []string{"0", "11", "-1", "A"} {
+		_, err = parseCRSClass(testString)
+		if err != ErrInvalidCRSClass {
+			t.Errorf("expected \"%s\" to be an invalid CRS class", testString)
+		}
+	}
+}
+
+func TestDiscountForClass(t *testing.T) {
+	// Communities without a class aren't in the CRS and get no discount
+	var c NFIPCommunityStatus
+	if c.IsCRSParticipant() || c.DiscountForClass() != 0 {
+		t.Errorf("expected a community without a class to have no discount")
+	}
+
+	class := 5
+	c.CurClass = &class
+	if !c.IsCRSParticipant() || c.DiscountForClass() != 25 {
+		t.Errorf("expected a class %d community to have a 25%% discount", class)
+	}
+
+	class = 10
+	if c.DiscountForClass() != 0 {
+		t.Errorf("expected a class %d community to have no discount", class)
+	}
+
+	// Classes that can't be right, like from JSON, don't panic
+	for _, class = range []int{-1, 0, 11, 100} {
+		if c.DiscountForClass() != 0 {
+			t.Errorf("expected a class %d community to have no discount", class)
+		}
+	}
+}
+
+func TestParseDateWithPivot(t *testing.T) {
+	// Years after the pivot are in the 20th century
+	testString := "08/08/31"
+	tm, err := parseDateWithPivot(testString, 30)
+	if err != nil || tm.Year() != 1931 {
+		t.Errorf("expected \"%s\" to be parsed to 1931, got %d", testString, tm.Year())
+	}
+
+	// Years up to and including the pivot are in the 21st century
+	testString = "08/08/30"
+	tm, err = parseDateWithPivot(testString, 30)
+	if err != nil || tm.Year() != 2030 {
+		t.Errorf("expected \"%s\" to be parsed to 2030, got %d", testString, tm.Year())
+	}
+
+	// The default pivot should always treat this year as the 21st century
+	year := time.Now().Year()
+	testString = fmt.Sprintf("01/01/%02d", year%100)
+	tm, err = parseDate(testString)
+	if err != nil || tm.Year() != year {
+		t.Errorf("expected \"%s\" to be parsed to %d, got %d", testString, year, tm.Year())
+	}
+}
+
+func TestUnmarshalEach(t *testing.T) {
+	records := []string{
+		`="170604","SPRINGFIELD, CITY OF",SANGAMON COUNTY,,,,,No,,,,,,R,Yes`,
+		`="170074","CHICAGO, CITY OF",COOK COUNTY,,,,,No,,,,,,R,Yes`,
+		`="480296","HOUSTON, CITY OF",HARRIS COUNTY,,,,,No,,,,,,R,Yes`,
+	}
+	file := testHeader + "\n" + strings.Join(records, "\n")
+
+	// Every community should be passed to the callback in order
+	var cids []int
+	_, err := UnmarshalEach(strings.NewReader(file), func(c NFIPCommunityStatus) error {
+		cids = append(cids, c.CID)
+		return nil
+	})
+	if err != nil || len(cids) != 3 || cids[0] != 170604 || cids[2] != 480296 {
+		t.Errorf("expected every community to be parsed in order, got %v (%v)", cids, err)
+	}
+
+	// Stopping early isn't an error
+	cids = nil
+	_, err = UnmarshalEach(strings.NewReader(file), func(c NFIPCommunityStatus) error {
+		cids = append(cids, c.CID)
+		if c.State == "IL" {
+			return ErrStopIteration
+		}
+		return nil
+	})
+	if err != nil || len(cids) != 1 {
+		t.Errorf("expected parsing to stop after the first community, got %v (%v)", cids, err)
+	}
+
+	// Any other error from the callback is returned
+	_, err = UnmarshalEach(strings.NewReader(file), func(c NFIPCommunityStatus) error {
+		return ErrEmptyString
+	})
+	if err != ErrEmptyString {
+		t.Errorf("expected the callback's error to be returned, got %v", err)
+	}
+}
+
+func TestScanDateNumbers(t *testing.T) {
+	// The scanner should always agree with the regex
+	testStrings := []string{"08/08/99", "8-8-1", "09/11/09(M)", "(NSFHA)", "05/31", "  12.25.74  ", "1/2/3/4", ""}
+	for _, s := range testStrings {
+		scanned, n, ok := scanDateNumbers(s)
+		matched, m, err := matchDateNumbers(s)
+		if !ok || err != nil || n != m || scanned != matched {
+			t.Errorf("\"%s\" was scanned as %v (%d) but matched as %v (%d)", s, scanned, n, matched, m)
+		}
+	}
+
+	// Really long numbers are left to the regex
+	testString := "01/01/12345678901"
+	if _, _, ok := scanDateNumbers(testString); ok {
+		t.Errorf("expected \"%s\" to be too long to scan", testString)
+	}
+
+	if _, err := parseDate(testString); err != nil {
+		t.Errorf("expected \"%s\" to fall back to the regex, got %s", testString, err.Error())
+	}
+}
+
+var benchmarkDates = []string{"05/31/74", "03/16/81", "01/19/18", "(NSFHA)", "09/11/09(M)", ""}
+
+func BenchmarkParseDate(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		for _, s := range benchmarkDates {
+			parseDateWithPivot(s, 30)
+		}
+	}
+}
+
+// How fast parsing was before the scanner, for comparison
+func BenchmarkParseDateRegexp(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		for _, s := range benchmarkDates {
+			regexp.MustCompile("([0-9]+)").FindAllString(s, 3)
+		}
+	}
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := Unmarshal(bytes.NewReader(snapshot)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestFieldSearches(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	// The name search shouldn't pick up counties
+	if matches := *communities.SearchByName("mobile"); len(matches) != 2 {
+		t.Errorf("expected 2 communities named Mobile, got %d", len(matches))
+	}
+
+	if matches := *communities.SearchByCounty("cook"); len(matches) != 2 {
+		t.Errorf("expected 2 communities in Cook County, got %d", len(matches))
+	}
+
+	// CIDs are matched exactly, not as a substring
+	if matches := *communities.SearchByCID(170074); len(matches) != 1 || matches[0].CommunityName != "CHICAGO, CITY OF" {
+		t.Errorf("expected only Chicago, got %v", matches)
+	}
+
+	if matches := *communities.SearchByCID(1700); len(matches) != 0 {
+		t.Errorf("expected no communities for a partial CID, got %d", len(matches))
+	}
+
+	// States can be given by abbreviation or name
+	for _, state := range []string{"TX", "tx", "Texas"} {
+		if matches := *communities.SearchByState(state); len(matches) != 3 {
+			t.Errorf("expected 3 communities in \"%s\", got %d", state, len(matches))
+		}
+	}
+
+	if matches := *communities.SearchByState("Atlantis"); len(matches) != 0 {
+		t.Errorf("expected no communities in a state that doesn't exist, got %d", len(matches))
+	}
+}
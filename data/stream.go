@@ -0,0 +1,99 @@
+package data
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"iter"
+)
+
+// How many values are written before they're flushed to the writer.
+const streamFlushEvery = 256
+
+// A flusher is anything that can push what's been written out to the
+// client, like an http.ResponseWriter. Flushing one as the array is
+// written makes the response chunked instead of being held until the end.
+type flusher interface {
+	Flush()
+}
+
+// StreamJSON writes the communities out as a JSON array one at a time
+// rather than encoding the whole array in memory first like ToJSON.
+// It's the same JSON, except that no communities are written as [].
+func (c *NFIPCommunityStatuses) StreamJSON(w io.Writer, opts ...EncodeOption) error {
+	o := newEncodeOptions(opts)
+	if len(o.fields) == 0 {
+		return streamJSONArray(w, o, c.All())
+	}
+
+	fields, err := pickFields(o.fields)
+	if err != nil {
+		return err
+	}
+
+	return streamJSONArray(w, o, func(yield func(ProjectedStatus) bool) {
+		for community := range c.All() {
+			if !yield(ProjectedStatus{community, fields}) {
+				return
+			}
+		}
+	})
+}
+
+// StreamJSON writes the results out the same way NFIPCommunityStatuses.StreamJSON
+// does. Fields can't be picked for results, but they can be compressed.
+func (sr SearchResults) StreamJSON(w io.Writer, opts ...EncodeOption) error {
+	return streamJSONArray(w, newEncodeOptions(opts), sr.All())
+}
+
+// streamJSONArray writes each value as it comes, flushing every so often
+// if the writer can be flushed.
+func streamJSONArray[T any](w io.Writer, o encodeOptions, values iter.Seq[T]) error {
+	w, closeWriter, err := o.compress(w)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	f, canFlush := w.(flusher)
+
+	flush := func() error {
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+		if canFlush {
+			f.Flush()
+		}
+		return nil
+	}
+
+	bw.WriteByte('[')
+
+	n := 0
+	for v := range values {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+
+		if n > 0 {
+			bw.WriteByte(',')
+		}
+		bw.Write(b)
+
+		n++
+		if n%streamFlushEvery == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	// The same trailing newline as json.Encoder
+	bw.WriteString("]\n")
+	if err := flush(); err != nil {
+		return err
+	}
+
+	return closeWriter()
+}
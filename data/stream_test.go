@@ -0,0 +1,58 @@
+package data
+
+import (
+	"bytes"
+	"testing"
+)
+
+type flushRecorder struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (fr *flushRecorder) Flush() {
+	fr.flushes++
+}
+
+func TestStreamJSON(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+
+	var expected bytes.Buffer
+	if err := communities.ToJSON(&expected); err != nil {
+		t.Fatalf("expected the communities to be encoded, got %s", err)
+	}
+
+	var fr flushRecorder
+	if err := communities.StreamJSON(&fr); err != nil {
+		t.Fatalf("expected the communities to be streamed, got %s", err)
+	}
+
+	if !bytes.Equal(fr.Bytes(), expected.Bytes()) {
+		t.Errorf("expected the streamed JSON to be the same as ToJSON")
+	}
+
+	if want := len(communities)/streamFlushEvery + 1; fr.flushes != want {
+		t.Errorf("expected %d flushes, got %d", want, fr.flushes)
+	}
+}
+
+func TestStreamJSONSearchResults(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+	results := communities.Search("springfield")
+
+	var expected, streamed bytes.Buffer
+	results.ToJSON(&expected)
+	if err := results.StreamJSON(&streamed); err != nil {
+		t.Fatalf("expected the results to be streamed, got %s", err)
+	}
+
+	if !bytes.Equal(streamed.Bytes(), expected.Bytes()) {
+		t.Errorf("expected %s, got %s", expected.String(), streamed.String())
+	}
+
+	var empty bytes.Buffer
+	SearchResults(nil).StreamJSON(&empty)
+	if empty.String() != "[]\n" {
+		t.Errorf("expected no results to be [], got %s", empty.String())
+	}
+}
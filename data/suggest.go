@@ -0,0 +1,71 @@
+package data
+
+import (
+	"sort"
+	"strings"
+)
+
+// Names need to be at least this similar to the term to be suggested,
+// which is looser than a fuzzy search since they're only suggestions.
+const minSuggestionSimilarity = 0.6
+
+// DefaultSuggestions is how many names are suggested when nothing is found.
+const DefaultSuggestions = 5
+
+// DidYouMean suggests up to limit community and county names that are
+// close to the term, for when searching for it didn't find anything, e.g.
+// "PULASKI COUNTY" for "pulasky county". The closest names come first.
+func (c NFIPCommunityStatuses) DidYouMean(term string, limit int) []string {
+	return didYouMean(term, buildSuggestions(c, folding{}), limit)
+}
+
+// DidYouMean is the same as NFIPCommunityStatuses.DidYouMean,
+// but it uses the names that were collected for Autocomplete.
+func (idx *CommunityIndex) DidYouMean(term string, limit int) []string {
+	if idx.suggestions == nil {
+		return idx.communities.DidYouMean(term, limit)
+	}
+
+	return didYouMean(term, idx.suggestions, limit)
+}
+
+func didYouMean(term string, names []suggestion, limit int) []string {
+	suggestions := []string{}
+
+	termWords := fuzzyWords(term)
+	if len(termWords) == 0 || limit <= 0 {
+		return suggestions
+	}
+
+	term = strings.Join(termWords, " ")
+
+	type scored struct {
+		name  string
+		score float64
+		whole float64
+	}
+
+	var matches []scored
+	for _, n := range names {
+		// The term can be close to just part of the name, like "pulasky"
+		// is to "PULASKI COUNTY", but names that are close as a whole
+		// are suggested first when they're otherwise the same.
+		score, _, _ := fuzzyScore(term, len(termWords), fuzzyWordSpans(n.name))
+		if score >= minSuggestionSimilarity {
+			matches = append(matches, scored{n.name, score, similarity(term, strings.Join(fuzzyWords(n.name), " "))})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].whole > matches[j].whole
+	})
+
+	for i := 0; i < len(matches) && i < limit; i++ {
+		suggestions = append(suggestions, matches[i].name)
+	}
+
+	return suggestions
+}
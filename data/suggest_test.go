@@ -0,0 +1,44 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDidYouMean(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	idx := NewCommunityIndex(communities)
+
+	tests := []struct {
+		term  string
+		first string
+	}{
+		{"pulasky county", "PULASKI COUNTY"},
+		{"pulasky", "PULASKI COUNTY"},
+		{"Milwalkee", "MILWAUKEE COUNTY"},
+		{"sacremento", "SACRAMENTO COUNTY"},
+	}
+
+	for _, test := range tests {
+		suggestions := communities.DidYouMean(test.term, DefaultSuggestions)
+		if len(suggestions) == 0 || suggestions[0] != test.first {
+			t.Errorf("expected \"%s\" to suggest %s first, got %v", test.term, test.first, suggestions)
+		}
+
+		if indexed := idx.DidYouMean(test.term, DefaultSuggestions); !reflect.DeepEqual(indexed, suggestions) {
+			t.Errorf("expected the index to suggest %v for \"%s\", got %v", suggestions, test.term, indexed)
+		}
+	}
+
+	if suggestions := communities.DidYouMean("xyzzy", DefaultSuggestions); len(suggestions) != 0 {
+		t.Errorf("expected nothing to be suggested, got %v", suggestions)
+	}
+
+	if suggestions := communities.DidYouMean("county", 2); len(suggestions) != 2 {
+		t.Errorf("expected only 2 suggestions, got %v", suggestions)
+	}
+}
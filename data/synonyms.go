@@ -0,0 +1,182 @@
+package data
+
+import (
+	"strings"
+	"sync"
+
+	"nfip-community-book/states"
+)
+
+// The states' names and abbreviations. Some of the abbreviations are
+// words in plenty of names, like "la" in "LA GRANGE" and "de" in "DE SOTO",
+// so they're only swapped when they're marked as a state. See markedAsState.
+var stateSynonyms = func() map[string]bool {
+	words := make(map[string]bool)
+	for _, s := range states.All() {
+		words[foldString(s.Name)] = true
+		words[foldString(s.Abbreviation)] = true
+	}
+
+	return words
+}()
+
+// A term can turn into more than one search when it has synonyms in it,
+// so there's a limit on how many it can turn into.
+const maxSearchVariants = 16
+
+// synonyms are groups of words and phrases that mean the same thing in a
+// community's name, like "St." and "Saint". Searching for any of them finds
+// the others.
+var synonyms = struct {
+	sync.RWMutex
+	groups [][]string
+}{groups: defaultSynonyms()}
+
+func defaultSynonyms() [][]string {
+	groups := [][]string{
+		{"saint", "st.", "st"},
+		{"sainte", "ste.", "ste"},
+		{"mount", "mt.", "mt"},
+		{"fort", "ft.", "ft"},
+		{"township", "twp.", "twp"},
+		{"port", "pt.", "pt"},
+	}
+
+	// States can be searched for by their abbreviation or their name
+	for _, s := range states.All() {
+		groups = append(groups, []string{foldString(s.Name), foldString(s.Abbreviation)})
+	}
+
+	return groups
+}
+
+// AddSynonyms adds a group of words or phrases that searches should treat
+// as the same, on top of the defaults. If any of them are already in a
+// group, the groups are merged. Searches that were already cached by a
+// SearchCache won't find the new synonyms until it's purged.
+func AddSynonyms(words ...string) {
+	group := make([]string, 0, len(words))
+	for _, w := range words {
+		if w = foldString(strings.TrimSpace(w)); len(w) > 0 {
+			group = append(group, w)
+		}
+	}
+
+	if len(group) < 2 {
+		return
+	}
+
+	synonyms.Lock()
+	defer synonyms.Unlock()
+
+	for i, existing := range synonyms.groups {
+		if overlaps(existing, group) {
+			synonyms.groups[i] = mergeSynonyms(existing, group)
+			return
+		}
+	}
+
+	synonyms.groups = append(synonyms.groups, group)
+}
+
+// ResetSynonyms puts the synonyms back to the defaults.
+func ResetSynonyms() {
+	synonyms.Lock()
+	defer synonyms.Unlock()
+
+	synonyms.groups = defaultSynonyms()
+}
+
+func overlaps(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func mergeSynonyms(existing, group []string) []string {
+	merged := append([]string{}, existing...)
+	for _, w := range group {
+		if !overlaps(merged, []string{w}) {
+			merged = append(merged, w)
+		}
+	}
+
+	return merged
+}
+
+// searchVariants is the lowercase term along with every way it could be
+// written with its synonyms swapped in, so "st. charles" is also searched
+// as "saint charles" and "st charles". The term itself is always first.
+func searchVariants(term string) []string {
+	synonyms.RLock()
+	defer synonyms.RUnlock()
+
+	variants := []string{term}
+	seen := map[string]bool{term: true}
+
+	for _, group := range synonyms.groups {
+		for i := 0; i < len(variants); i++ {
+			for _, from := range group {
+				start, ok := indexWord(variants[i], from)
+				if !ok || (stateSynonyms[from] && !markedAsState(variants[i], start, start+len(from))) {
+					continue
+				}
+
+				for _, to := range group {
+					if to == from {
+						continue
+					}
+
+					v := variants[i][:start] + to + variants[i][start+len(from):]
+					if !seen[v] && len(variants) < maxSearchVariants {
+						seen[v] = true
+						variants = append(variants, v)
+					}
+				}
+			}
+		}
+	}
+
+	return variants
+}
+
+// indexWord finds the first place the word is in s as a whole
+// word, and not just part of a longer one.
+func indexWord(s, word string) (int, bool) {
+	for offset := 0; offset < len(s); {
+		i := strings.Index(s[offset:], word)
+		if i < 0 {
+			return 0, false
+		}
+
+		start, end := offset+i, offset+i+len(word)
+		if (start == 0 || isWordBreak(s[start-1])) && (end == len(s) || isWordBreak(s[end])) {
+			return start, true
+		}
+
+		offset = start + 1
+	}
+
+	return 0, false
+}
+
+// markedAsState is whether the word from start to end in s is a state,
+// which it is if it's all of s, or at the end of s after a comma, like
+// "lafayette, la".
+func markedAsState(s string, start, end int) bool {
+	if end != len(s) {
+		return false
+	}
+
+	return start == 0 || strings.HasSuffix(strings.TrimRight(s[:start], " "), ",")
+}
+
+func isWordBreak(b byte) bool {
+	return b == ' ' || b == ',' || b == '/' || b == '-' || b == '(' || b == ')'
+}
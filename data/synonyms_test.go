@@ -0,0 +1,77 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearchVariants(t *testing.T) {
+	tests := []struct {
+		term     string
+		variants []string
+	}{
+		{"st. louis", []string{"st. louis", "saint louis", "st louis"}},
+		{"saint louis", []string{"saint louis", "st. louis", "st louis"}},
+		{"stanton", []string{"stanton"}},
+		{"ft. worth", []string{"ft. worth", "fort worth", "ft worth"}},
+		{"illinois", []string{"illinois", "il"}},
+		{"new york", []string{"new york", "ny"}},
+		{"in", []string{"in", "indiana"}},
+		{"lafayette, la", []string{"lafayette, la", "lafayette, louisiana"}},
+
+		// State abbreviations in a name aren't states
+		{"la grange", []string{"la grange"}},
+		{"de soto", []string{"de soto"}},
+		{"grange la", []string{"grange la"}},
+		{"new york mills", []string{"new york mills"}},
+	}
+
+	for _, test := range tests {
+		if variants := searchVariants(test.term); !reflect.DeepEqual(variants, test.variants) {
+			t.Errorf("expected \"%s\" to be searched as %v, got %v", test.term, test.variants, variants)
+		}
+	}
+}
+
+func TestSearchSynonyms(t *testing.T) {
+	communities := NFIPCommunityStatuses{
+		{CID: 290385, CommunityName: "ST. LOUIS, CITY OF", County: "INDEPENDENT CITY", State: "MO"},
+		{CID: 80102, CommunityName: "FORT COLLINS, CITY OF", County: "LARIMER COUNTY", State: "CO"},
+		{CID: 170001, CommunityName: "SPRINGFIELD, CITY OF", County: "SANGAMON COUNTY", State: "IL"},
+	}
+
+	results := communities.Search("Saint Louis")
+	if len(results) != 1 || results[0].Community.CID != 290385 {
+		t.Fatalf("expected \"Saint Louis\" to find St. Louis, got %v", results.Communities())
+	}
+
+	expected := []MatchSpan{{"community_name", 0, 9}}
+	if !reflect.DeepEqual(results[0].Matches, expected) {
+		t.Errorf("expected %v, got %v", expected, results[0].Matches)
+	}
+
+	if results := communities.Search("ft collins"); len(results) != 1 || results[0].Community.CID != 80102 {
+		t.Errorf("expected \"ft collins\" to find Fort Collins, got %v", results.Communities())
+	}
+
+	// States are found by their name or their abbreviation
+	for _, term := range []string{"Illinois", "il"} {
+		results := communities.Search(term)
+		if len(results) != 1 || results[0].Community.CID != 170001 {
+			t.Errorf("expected \"%s\" to find Springfield, got %v", term, results.Communities())
+		}
+	}
+
+	// Callers can add their own
+	defer ResetSynonyms()
+	AddSynonyms("Independent City", "Ind. City")
+
+	if results := communities.Search("ind. city"); len(results) != 1 || results[0].Community.CID != 290385 {
+		t.Errorf("expected an added synonym to find St. Louis, got %v", results.Communities())
+	}
+
+	ResetSynonyms()
+	if results := communities.Search("ind. city"); len(results) != 0 {
+		t.Errorf("expected the added synonym to be gone after a reset, got %v", results.Communities())
+	}
+}
@@ -0,0 +1,217 @@
+package data
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+// How many spaces go between the columns of a table.
+const tablePadding = 2
+
+// Values that are too wide for their column are cut off and end with this.
+const tableEllipsis = "..."
+
+// A Column is one of the columns that ToTable can show.
+type Column struct {
+	Header string
+
+	// MaxWidth is the most characters that the column's values can take up
+	// before they're cut off. It's 0 by default, which means there's no limit.
+	MaxWidth int
+
+	// Align is how the column is lined up in Markdown.
+	Align Alignment
+
+	value func(c NFIPCommunityStatus) string
+}
+
+// An Alignment is which side of a column its values are lined up on.
+type Alignment int
+
+const (
+	// AlignDefault leaves it up to whatever shows the table.
+	AlignDefault Alignment = iota
+	AlignLeft
+	AlignCenter
+	AlignRight
+)
+
+// Truncate is the same column, but with values cut off after width characters.
+func (col Column) Truncate(width int) Column {
+	col.MaxWidth = width
+	return col
+}
+
+// Aligned is the same column, but lined up on the other side.
+func (col Column) Aligned(align Alignment) Column {
+	col.Align = align
+	return col
+}
+
+// Value is the community's value in the column, before it's cut off.
+func (col Column) Value(c NFIPCommunityStatus) string {
+	return col.value(c)
+}
+
+var (
+	CIDColumn = Column{Header: "CID", value: func(c NFIPCommunityStatus) string {
+		return fmt.Sprintf("%06d", c.CID)
+	}}
+	NameColumn = Column{Header: "COMMUNITY", value: func(c NFIPCommunityStatus) string {
+		return c.CommunityName
+	}}
+	CountyColumn = Column{Header: "COUNTY", value: func(c NFIPCommunityStatus) string {
+		return c.County
+	}}
+	StateColumn = Column{Header: "STATE", value: func(c NFIPCommunityStatus) string {
+		return c.State
+	}}
+	ProgramColumn = Column{Header: "PROGRAM", value: func(c NFIPCommunityStatus) string {
+		if c.Program == ProgramUnknown {
+			return ""
+		}
+		return c.Program.String()
+	}}
+	ClassColumn = Column{Header: "CRS CLASS", value: func(c NFIPCommunityStatus) string {
+		if c.CurClass == nil {
+			return ""
+		}
+		return strconv.Itoa(*c.CurClass)
+	}}
+	DiscountColumn = Column{Header: "SFHA DISCOUNT", value: func(c NFIPCommunityStatus) string {
+		return c.PercentDiscSFHA
+	}}
+	MapDateColumn = Column{Header: "MAP DATE", value: func(c NFIPCommunityStatus) string {
+		return formatJSONDate(c.CurrEffMapDate)
+	}}
+	ParticipatingColumn = Column{Header: "PARTICIPATING", value: func(c NFIPCommunityStatus) string {
+		return formatYesNo(c.ParticipatingCommunity)
+	}}
+	TribalColumn = Column{Header: "TRIBAL", value: func(c NFIPCommunityStatus) string {
+		return formatYesNo(c.Tribal)
+	}}
+)
+
+// DefaultTableColumns are the columns a table has when none are given.
+var DefaultTableColumns = []Column{CIDColumn, NameColumn, CountyColumn, StateColumn, ProgramColumn, ClassColumn}
+
+// ToTable writes the communities out as a table of text with the columns
+// lined up, for reading in a terminal.
+//
+//	CID     COMMUNITY             COUNTY           STATE  PROGRAM  CRS CLASS
+//	170604  SPRINGFIELD, CITY OF  SANGAMON COUNTY  IL     Regular
+func (c *NFIPCommunityStatuses) ToTable(w io.Writer, columns ...Column) error {
+	return c.ToTableWidth(w, 0, columns...)
+}
+
+// ToTableWidth is the same as ToTable, except that the table is kept to
+// width characters across by cutting off the widest columns first. A
+// width of 0 means the table can be as wide as it needs to be.
+func (c *NFIPCommunityStatuses) ToTableWidth(w io.Writer, width int, columns ...Column) error {
+	if len(columns) == 0 {
+		columns = DefaultTableColumns
+	}
+
+	rows := make([][]string, 0, len(*c)+1)
+
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Header
+	}
+	rows = append(rows, header)
+
+	for _, community := range *c {
+		row := make([]string, len(columns))
+		for i, col := range columns {
+			row[i] = col.value(community)
+		}
+		rows = append(rows, row)
+	}
+
+	widths := tableWidths(rows, columns, width)
+	for _, row := range rows {
+		for i, value := range row {
+			row[i] = truncateTableValue(value, widths[i])
+		}
+	}
+
+	return WriteTable(w, rows)
+}
+
+// WriteTable writes the rows out with the columns lined up the same way
+// as ToTable, for tables of things that aren't communities. The first row
+// is the header, and nothing is cut off.
+func WriteTable(w io.Writer, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, tablePadding, ' ', 0)
+	for _, row := range rows {
+		if _, err := fmt.Fprintln(tw, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
+
+// tableWidths is how wide each column can be, which is as wide as its
+// widest value unless the column has a limit. Then, if the table would be
+// wider than width, the widest column is narrowed until it fits, but never
+// down to less than the header or the ellipsis.
+func tableWidths(rows [][]string, columns []Column, width int) []int {
+	widths := make([]int, len(columns))
+	for _, row := range rows {
+		for i, value := range row {
+			widths[i] = maxInt(widths[i], utf8.RuneCountInString(value))
+		}
+	}
+
+	for i, col := range columns {
+		if col.MaxWidth > 0 {
+			widths[i] = minInt(widths[i], col.MaxWidth)
+		}
+	}
+
+	if width <= 0 {
+		return widths
+	}
+
+	total := tablePadding * (len(widths) - 1)
+	for _, w := range widths {
+		total += w
+	}
+
+	for total > width {
+		widest := -1
+		for i, w := range widths {
+			narrowest := maxInt(utf8.RuneCountInString(columns[i].Header), len(tableEllipsis)+1)
+			if w > narrowest && (widest < 0 || w > widths[widest]) {
+				widest = i
+			}
+		}
+
+		if widest < 0 {
+			// Nothing else can be narrowed
+			break
+		}
+
+		widths[widest]--
+		total--
+	}
+
+	return widths
+}
+
+func truncateTableValue(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+
+	if width <= len(tableEllipsis) {
+		return string([]rune(s)[:width])
+	}
+
+	return string([]rune(s)[:width-len(tableEllipsis)]) + tableEllipsis
+}
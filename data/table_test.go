@@ -0,0 +1,102 @@
+package data
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestToTable(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+	springfield := communities.Search("springfield").Communities()
+
+	var buf bytes.Buffer
+	if err := springfield.ToTable(&buf, CIDColumn, NameColumn, StateColumn); err != nil {
+		t.Fatalf("expected a table, got %s", err)
+	}
+
+	expected := "CID     COMMUNITY             STATE\n" +
+		"170604  SPRINGFIELD, CITY OF  IL\n"
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	rows := [][]string{
+		{"STATE", "COUNT"},
+		{"TX", "3"},
+		{"PA", "12"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTable(&buf, rows); err != nil {
+		t.Fatalf("expected a table, got %s", err)
+	}
+
+	expected := "STATE  COUNT\n" +
+		"TX     3\n" +
+		"PA     12\n"
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+}
+
+func TestToTableDefaultColumns(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+
+	var buf bytes.Buffer
+	communities.ToTable(&buf)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(communities)+1 {
+		t.Fatalf("expected a header and %d rows, got %d lines", len(communities), len(lines))
+	}
+
+	if fields := strings.Fields(lines[0]); len(fields) != 7 || fields[0] != "CID" || fields[6] != "CLASS" {
+		t.Errorf("expected the default columns in the header, got %s", lines[0])
+	}
+}
+
+func TestToTableTruncate(t *testing.T) {
+	communities := NFIPCommunityStatuses{
+		{CID: 480287, CommunityName: "HOUSTON, CITY OF", County: "HARRIS COUNTY/FORT BEND COUNTY/MONTGOMERY COUNTY", State: "TX"},
+	}
+
+	var buf bytes.Buffer
+	communities.ToTable(&buf, CIDColumn, CountyColumn.Truncate(20))
+	if !strings.Contains(buf.String(), "480287  HARRIS COUNTY/FOR...\n") {
+		t.Errorf("expected the county to be cut off at 20 characters, got\n%s", buf.String())
+	}
+
+	buf.Reset()
+	communities.ToTableWidth(&buf, 50, CIDColumn, NameColumn, CountyColumn, StateColumn)
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if n := utf8.RuneCountInString(strings.TrimRight(line, " ")); n > 50 {
+			t.Errorf("expected the table to fit in 50 characters, got %d: %s", n, line)
+		}
+	}
+
+	if !strings.Contains(buf.String(), "HOUSTON, CITY OF") {
+		t.Errorf("expected the widest column to be cut off first, got\n%s", buf.String())
+	}
+}
+
+func TestColumnValue(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+	c, _ := NewCommunityIndex(communities).ByCID(480296)
+
+	columns, err := FieldColumns("cid", "community_name", "cur_class")
+	if err != nil {
+		t.Fatalf("expected the columns, got %s", err)
+	}
+
+	var values []string
+	for _, col := range columns {
+		values = append(values, col.Truncate(5).Value(*c))
+	}
+	if strings.Join(values, "|") != "480296|HOUSTON, CITY OF|7" {
+		t.Errorf("expected Houston's whole values, got %v", values)
+	}
+}
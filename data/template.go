@@ -0,0 +1,52 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+var ErrInvalidTemplate = fmt.Errorf("invalid template")
+
+// The functions that templates can use on top of text/template's own.
+var templateFuncs = template.FuncMap{
+	"date":  formatJSONDate,
+	"yesno": formatYesNo,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// ToTemplate executes the text/template once for each community, so that
+// communities can be written out however they're needed. The template is
+// given the community, so its fields are named the same as they are in Go.
+// Dates can be written with date, and anything as JSON with json. Each
+// community ends with a newline if the template doesn't end with one.
+//
+//	{{.CID}} {{.CommunityName}} ({{.State}}) mapped {{date .CurrEffMapDate}}
+func (c *NFIPCommunityStatuses) ToTemplate(w io.Writer, tmpl string) error {
+	t, err := template.New("community").Funcs(templateFuncs).Parse(tmpl)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidTemplate, err)
+	}
+
+	newline := !strings.HasSuffix(tmpl, "\n")
+	for _, community := range *c {
+		if err := t.Execute(w, community); err != nil {
+			return err
+		}
+
+		if newline {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
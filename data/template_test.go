@@ -0,0 +1,48 @@
+package data
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestToTemplate(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+	springfield := communities.Search("springfield").Communities()
+
+	tests := []struct {
+		tmpl     string
+		expected string
+	}{
+		{"{{.CID}} {{.CommunityName}} ({{.State}})", "170604 SPRINGFIELD, CITY OF (IL)\n"},
+		{"{{.State}}: {{date .CurrEffMapDate}}\n", "IL: 2018-01-19\n"},
+		{"{{lower .County}},{{yesno .ParticipatingCommunity}},{{.Program}}", "sangamon county,Yes,Regular\n"},
+		{"{{json .CommunityName}}", "\"SPRINGFIELD, CITY OF\"\n"},
+		{"{{if .IsCRSParticipant}}{{.CurClass}}{{else}}-{{end}}", "-\n"},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := springfield.ToTemplate(&buf, test.tmpl); err != nil {
+			t.Errorf("expected \"%s\" to be executed, got %s", test.tmpl, err)
+			continue
+		}
+
+		if buf.String() != test.expected {
+			t.Errorf("expected \"%s\" to write %q, got %q", test.tmpl, test.expected, buf.String())
+		}
+	}
+}
+
+func TestToTemplateErrors(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+
+	var buf bytes.Buffer
+	if err := communities.ToTemplate(&buf, "{{.CID"); !errors.Is(err, ErrInvalidTemplate) {
+		t.Errorf("expected an unclosed action to be an invalid template, got %v", err)
+	}
+
+	if err := communities.ToTemplate(&buf, "{{.Population}}"); err == nil {
+		t.Errorf("expected a field that doesn't exist to be an error")
+	}
+}
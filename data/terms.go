@@ -0,0 +1,160 @@
+package data
+
+import "strings"
+
+// A searchTerm is a lowercase search term along with the words and
+// "quoted phrases" in it, and the synonyms of each.
+type searchTerm struct {
+	// The whole term and its synonyms, which is how a term is matched
+	// unless it has quoted phrases, since they have to match on their own
+	whole []string
+
+	// Each word or phrase and its synonyms, for terms that have more
+	// than one or have a quoted phrase
+	tokens [][]string
+
+	// How the term was folded, which the keys it's matched against
+	// have to be folded the same way as
+	folding folding
+}
+
+func newSearchTerm(term string) searchTerm {
+	return newFoldedSearchTerm(term, folding{})
+}
+
+func newFoldedSearchTerm(term string, f folding) searchTerm {
+	term = f.fold(term)
+	words, quoted := splitSearchTerm(term)
+
+	st := searchTerm{folding: f}
+	if !quoted {
+		st.whole = searchVariants(term)
+	}
+
+	if quoted || len(words) > 1 {
+		for _, word := range words {
+			st.tokens = append(st.tokens, searchVariants(word))
+		}
+	}
+
+	return st
+}
+
+// splitSearchTerm breaks the term up into words, keeping "quoted phrases"
+// together without their quotes. A quote that isn't closed runs to the end.
+// It also reports whether there were any quotes.
+func splitSearchTerm(term string) ([]string, bool) {
+	var words []string
+	quoted := false
+
+	for len(term) > 0 {
+		term = strings.TrimLeft(term, " \t")
+		if len(term) == 0 {
+			break
+		}
+
+		if term[0] == '"' {
+			quoted = true
+			end := strings.IndexByte(term[1:], '"')
+			if end < 0 {
+				end = len(term) - 1
+			}
+
+			if phrase := strings.Join(strings.Fields(term[1:end+1]), " "); len(phrase) > 0 {
+				words = append(words, phrase)
+			}
+
+			term = term[minInt(end+2, len(term)):]
+			continue
+		}
+
+		end := strings.IndexAny(term, " \t\"")
+		if end < 0 {
+			end = len(term)
+		}
+
+		words = append(words, term[:end])
+		term = term[end:]
+	}
+
+	return words, quoted
+}
+
+// relevance is how well the community matched the whole term, or every
+// word and phrase in it, whichever is better.
+func (st searchTerm) relevance(k *searchKeys) int {
+	rank := bestRelevance(k, st.whole)
+	if len(st.tokens) > 0 {
+		rank = maxInt(rank, tokensRelevance(k, st.tokens))
+	}
+
+	return rank
+}
+
+// spans are where the whole term matched, or every word and phrase
+// in it if the whole term didn't.
+func (st searchTerm) spans(c NFIPCommunityStatus, k *searchKeys) []MatchSpan {
+	if bestRelevance(k, st.whole) > matchNone {
+		return matchSpans(c, k, st.whole)
+	}
+
+	var variants []string
+	for _, token := range st.tokens {
+		variants = append(variants, token...)
+	}
+
+	return matchSpans(c, k, variants)
+}
+
+// tokensRelevance ranks a community that has to match every word and
+// phrase. It's only as relevant as the worst of them, not counting states,
+// since "cook county il" shouldn't rank Cook County lower for saying where
+// it is.
+func tokensRelevance(k *searchKeys, tokens [][]string) int {
+	worst := 0
+	for _, variants := range tokens {
+		best, inState := matchNone, false
+		for _, token := range variants {
+			best = maxInt(best, tokenRelevance(k, token))
+			inState = inState || k.inState(token)
+		}
+
+		switch {
+		case best > matchNone:
+			if worst == 0 || best < worst {
+				worst = best
+			}
+		case !inState:
+			return matchNone
+		}
+	}
+
+	if worst == 0 {
+		// Every token was a state
+		return matchSubstring
+	}
+
+	return worst
+}
+
+// tokenRelevance is the same as relevance, except that a word or phrase in
+// a longer term has to match at the start of a word. Otherwise short words
+// like "il" would match the middle of names like "MOBILE".
+func tokenRelevance(k *searchKeys, token string) int {
+	if k.cid == token {
+		return exactCIDScore
+	}
+
+	if m := maxInt(matchLevel(k.lowerName, token), matchLevel(k.lowerBaseName, token)); m >= matchWordBoundary {
+		return nameRelevance + m
+	}
+
+	best := matchNone
+	for _, county := range k.lowerCounties {
+		if m := matchLevel(county, token); m >= matchWordBoundary {
+			best = maxInt(best, m)
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,63 @@
+package data
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Each CRS class better than 10 takes another 5% off of
+// premiums for policies in the Special Flood Hazard Area.
+const crsDiscountPerClass = 5
+
+// SFHADiscount is the CRS discount on premiums for policies in the Special
+// Flood Hazard Area, as a percent. If the status book didn't have one, it's
+// worked out from the community's CRS class. It's false for communities
+// that aren't in the CRS.
+func (c NFIPCommunityStatus) SFHADiscount() (float64, bool) {
+	if pct, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(c.PercentDiscSFHA), "%"), 64); err == nil {
+		return pct, true
+	}
+
+	if !c.IsCRSParticipant() {
+		return 0, false
+	}
+
+	return float64((10 - *c.CurClass) * crsDiscountPerClass), true
+}
+
+// TopCRSCommunities are the n CRS communities with the best classes,
+// best first. Communities with the same class are in the same order
+// as they were in. Class 10 gets no discount, so it's left out.
+func (c NFIPCommunityStatuses) TopCRSCommunities(n int) NFIPCommunityStatuses {
+	top := c.FilterFunc(func(community NFIPCommunityStatus) bool {
+		return community.IsCRSParticipant() && *community.CurClass >= 1 && *community.CurClass < 10
+	})
+
+	sort.SliceStable(top, func(i, j int) bool {
+		return *top[i].CurClass < *top[j].CurClass
+	})
+
+	if n < len(top) {
+		top = top[:maxInt(n, 0)]
+	}
+
+	return top
+}
+
+// CommunitiesWithDiscountAtLeast are the communities whose CRS
+// discount in the Special Flood Hazard Area is at least pct percent.
+func (c NFIPCommunityStatuses) CommunitiesWithDiscountAtLeast(pct float64) NFIPCommunityStatuses {
+	return c.FilterFunc(func(community NFIPCommunityStatus) bool {
+		discount, ok := community.SFHADiscount()
+		return ok && discount >= pct
+	})
+}
+
+// NonParticipatingWithFIRM are the communities that have been mapped, so
+// they know their flood risk, but still aren't in the NFIP.
+func (c NFIPCommunityStatuses) NonParticipatingWithFIRM() NFIPCommunityStatuses {
+	return c.FilterFunc(func(community NFIPCommunityStatus) bool {
+		return !community.ParticipatingCommunity && community.FIRMIdentified != nil
+	})
+}
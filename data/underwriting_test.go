@@ -0,0 +1,102 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSFHADiscount(t *testing.T) {
+	class := 6
+
+	tests := []struct {
+		community NFIPCommunityStatus
+		discount  float64
+		ok        bool
+	}{
+		{NFIPCommunityStatus{PercentDiscSFHA: "25", CurClass: &class}, 25, true},
+		{NFIPCommunityStatus{PercentDiscSFHA: "15%"}, 15, true},
+		{NFIPCommunityStatus{CurClass: &class}, 20, true},
+		{NFIPCommunityStatus{}, 0, false},
+	}
+
+	for _, test := range tests {
+		if discount, ok := test.community.SFHADiscount(); discount != test.discount || ok != test.ok {
+			t.Errorf("expected a discount of %.0f (%t), got %.0f (%t)", test.discount, test.ok, discount, ok)
+		}
+	}
+}
+
+func TestTopCRSCommunities(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	top := communities.TopCRSCommunities(3)
+	if len(top) != 3 {
+		t.Fatalf("expected 3 communities, got %d", len(top))
+	}
+
+	for i := 1; i < len(top); i++ {
+		if *top[i-1].CurClass > *top[i].CurClass {
+			t.Errorf("expected the best classes first, got %d before %d", *top[i-1].CurClass, *top[i].CurClass)
+		}
+	}
+
+	discounted := communities.FilterFunc(func(c NFIPCommunityStatus) bool {
+		return c.IsCRSParticipant() && *c.CurClass < 10
+	})
+
+	all := communities.TopCRSCommunities(len(communities))
+	if len(all) != len(discounted) {
+		t.Errorf("expected every CRS community with a discount, got %d", len(all))
+	}
+
+	// Class 10 doesn't get a discount, and communities without a class aren't in the CRS
+	ten, seven := 10, 7
+	classes := NFIPCommunityStatuses{{CID: 1, CurClass: &ten}, {CID: 2}, {CID: 3, CurClass: &seven}}
+	if top := classes.TopCRSCommunities(3); len(top) != 1 || top[0].CID != 3 {
+		t.Errorf("expected only the class 7 community, got %v", top)
+	}
+
+	if none := communities.TopCRSCommunities(-1); len(none) != 0 {
+		t.Errorf("expected no communities, got %d", len(none))
+	}
+}
+
+func TestCommunitiesWithDiscountAtLeast(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("failed to load the snapshot: %s", err.Error())
+	}
+
+	matches := communities.CommunitiesWithDiscountAtLeast(25)
+	if len(matches) == 0 {
+		t.Fatalf("expected some communities with at least a 25%% discount")
+	}
+
+	for _, c := range matches {
+		if discount, _ := c.SFHADiscount(); discount < 25 {
+			t.Errorf("expected at least a 25%% discount, got %.0f%% for %d", discount, c.CID)
+		}
+	}
+
+	// It's the same as CRS class 5 or better
+	if expected := len(*communities.Apply(CRSClassAtMost(5))); len(matches) != expected {
+		t.Errorf("expected %d communities, got %d", expected, len(matches))
+	}
+}
+
+func TestNonParticipatingWithFIRM(t *testing.T) {
+	firm := time.Date(1985, time.March, 1, 0, 0, 0, 0, time.Local)
+	communities := NFIPCommunityStatuses{
+		{CID: 1, ParticipatingCommunity: true, FIRMIdentified: &firm},
+		{CID: 2, ParticipatingCommunity: false, FIRMIdentified: &firm},
+		{CID: 3, ParticipatingCommunity: false},
+	}
+
+	matches := communities.NonParticipatingWithFIRM()
+	if len(matches) != 1 || matches[0].CID != 2 {
+		t.Errorf("expected only the community with a FIRM that isn't participating, got %v", matches)
+	}
+}
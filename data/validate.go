@@ -0,0 +1,96 @@
+package data
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"nfip-community-book/states"
+)
+
+// A ValidationIssue is something wrong with one of a community's fields.
+// Fields are named the same as they are in JSON.
+type ValidationIssue struct {
+	CID     int    `json:"cid"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (vi ValidationIssue) String() string {
+	return fmt.Sprintf("CID %06d: %s: %s", vi.CID, vi.Field, vi.Message)
+}
+
+type ValidationReport struct {
+	Checked int               `json:"checked"`
+	Invalid int               `json:"invalid"`
+	Issues  []ValidationIssue `json:"issues"`
+}
+
+func (vr ValidationReport) Valid() bool {
+	return len(vr.Issues) == 0
+}
+
+// Validate checks the community for values that can't be right,
+// like a CID outside of any state or a FIRM before its FHBM.
+func (c NFIPCommunityStatus) Validate() []ValidationIssue {
+	var issues []ValidationIssue
+	issue := func(field, format string, args ...interface{}) {
+		issues = append(issues, ValidationIssue{c.CID, field, fmt.Sprintf(format, args...)})
+	}
+
+	// CIDs are six digits and start with the state's FIPS code
+	if c.CID <= 0 || c.CID > 999999 {
+		issue("cid", "%d is not a six digit CID", c.CID)
+	} else if _, ok := states.ByFIPS(c.CID / 10000); !ok {
+		issue("cid", "%02d is not a state FIPS code", c.CID/10000)
+	}
+
+	if c.FHBMIdentified != nil && c.FIRMIdentified != nil && c.FIRMIdentified.Before(*c.FHBMIdentified) {
+		issue("firm_identified", "FIRM identified on %s before the FHBM was identified on %s",
+			c.FIRMIdentified.Format(jsonDateLayout), c.FHBMIdentified.Format(jsonDateLayout))
+	}
+
+	if c.CurClass != nil && (*c.CurClass < 1 || *c.CurClass > 10) {
+		issue("cur_class", "%d is not a CRS class between 1 and 10", *c.CurClass)
+	}
+
+	percentages := []struct {
+		field string
+		value string
+	}{
+		{"percent_disc_sfha", c.PercentDiscSFHA},
+		{"percent_non_sfha", c.PercentNonSFHA},
+	}
+
+	for _, p := range percentages {
+		value := strings.TrimSpace(strings.TrimSuffix(p.value, "%"))
+		if len(value) == 0 {
+			continue
+		}
+
+		pct, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			issue(p.field, "\"%s\" is not a percentage", p.value)
+		} else if pct < 0 || pct > 100 {
+			issue(p.field, "%s is not between 0 and 100 percent", p.value)
+		}
+	}
+
+	return issues
+}
+
+// Validate checks every community and reports all of the issues found.
+func (c NFIPCommunityStatuses) Validate() ValidationReport {
+	var report ValidationReport
+
+	for _, community := range c {
+		issues := community.Validate()
+		if len(issues) > 0 {
+			report.Invalid++
+			report.Issues = append(report.Issues, issues...)
+		}
+		report.Checked++
+	}
+
+	return report
+}
@@ -0,0 +1,47 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	communities, err := GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("expected the demo status book to be parsed, got %s", err)
+	}
+
+	report := communities.Validate()
+	if !report.Valid() || report.Checked != len(communities) {
+		t.Errorf("expected the demo status book to be valid, got %v", report.Issues)
+	}
+
+	fhbm := time.Date(1980, time.January, 1, 0, 0, 0, 0, time.Local)
+	firm := time.Date(1979, time.January, 1, 0, 0, 0, 0, time.Local)
+	class := 11
+	bad := NFIPCommunityStatus{
+		CID:             30001,
+		FHBMIdentified:  &fhbm,
+		FIRMIdentified:  &firm,
+		CurClass:        &class,
+		PercentDiscSFHA: "150",
+		PercentNonSFHA:  "ten",
+	}
+
+	// Every field should have an issue
+	fields := make(map[string]bool)
+	for _, issue := range bad.Validate() {
+		fields[issue.Field] = true
+	}
+
+	for _, field := range []string{"cid", "firm_identified", "cur_class", "percent_disc_sfha", "percent_non_sfha"} {
+		if !fields[field] {
+			t.Errorf("expected an issue with %s", field)
+		}
+	}
+
+	report = append(communities, bad).Validate()
+	if report.Valid() || report.Invalid != 1 {
+		t.Errorf("expected one invalid community, got %d", report.Invalid)
+	}
+}
@@ -0,0 +1,75 @@
+package data
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+type parsedRecord struct {
+	record     []string
+	community  NFIPCommunityStatus
+	unparsable []UnparsableValue
+	err        error
+}
+
+type recordJob struct {
+	record     []string
+	lineNumber int
+	result     chan parsedRecord
+}
+
+// parseRecords reads the records in one goroutine and hands them off to a
+// pool of workers to be parsed. Each record gets its own result channel,
+// and those are sent out in the order the records were read so that the
+// communities come out in the same order they're in the file.
+//
+// Closing done stops the reader, and the workers stop once they've
+// finished whatever records they were already given.
+func parseRecords(reader *csv.Reader, columns [numStatusColumns]int, o parseOptions, done <-chan struct{}) <-chan chan parsedRecord {
+	jobs := make(chan recordJob, o.workers)
+	results := make(chan chan parsedRecord, o.workers*4)
+
+	go func() {
+		defer close(jobs)
+		defer close(results)
+
+		for lineNumber := 2; ; lineNumber++ {
+			record, err := reader.Read()
+			if err == io.EOF {
+				return
+			}
+
+			result := make(chan parsedRecord, 1)
+			select {
+			case results <- result:
+			case <-done:
+				return
+			}
+
+			// if we get an error other than EOF, then
+			// it's the last thing that gets sent out
+			if err != nil {
+				result <- parsedRecord{err: fmt.Errorf("** ERR: %s on line %d", err.Error(), lineNumber)}
+				return
+			}
+
+			select {
+			case jobs <- recordJob{record, lineNumber, result}:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < o.workers; i++ {
+		go func() {
+			for job := range jobs {
+				nc, unparsable, err := parseRecord(job.record, job.lineNumber, columns, o)
+				job.result <- parsedRecord{job.record, nc, unparsable, err}
+			}
+		}()
+	}
+
+	return results
+}
@@ -0,0 +1,47 @@
+package data
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseWorkers(t *testing.T) {
+	// The order should be the same no matter how many workers there are
+	one, err := Unmarshal(bytes.NewReader(snapshot), WithWorkers(1))
+	if err != nil {
+		t.Fatalf("failed to unmarshal the snapshot: %s", err.Error())
+	}
+
+	many, err := Unmarshal(bytes.NewReader(snapshot), WithWorkers(8))
+	if err != nil {
+		t.Fatalf("failed to unmarshal the snapshot: %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(one, many) {
+		t.Errorf("expected the same communities with 1 and 8 workers")
+	}
+
+	// Errors still have the right line number
+	records := []string{
+		`="170604","SPRINGFIELD, CITY OF",SANGAMON COUNTY,,,,,No,,,,,,R,Yes`,
+		`="170074","CHICAGO, CITY OF",COOK COUNTY,,,,,No,,,,,,R,Yes`,
+		`="ABC","NOWHERE",,,,,,No,,,,,,R,Yes`,
+		`="170001","ADDISON, VILLAGE OF",DUPAGE COUNTY,,,,,No,,,,,,R,Yes`,
+	}
+
+	file := testHeader + "\n" + strings.Join(records, "\n")
+	_, err = Unmarshal(strings.NewReader(file), WithWorkers(4))
+	if err == nil || !strings.Contains(err.Error(), "on line 4") {
+		t.Errorf("expected an error on line 4, got %v", err)
+	}
+}
+
+func BenchmarkUnmarshalOneWorker(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := Unmarshal(bytes.NewReader(snapshot), WithWorkers(1)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package data
+
+import (
+	"io"
+	"sort"
+	"time"
+
+	"github.com/tealeg/xlsx/v3"
+)
+
+// How dates are shown in workbooks. They're still dates
+// underneath, so Excel can sort and filter them as dates.
+const xlsxDateFormat = "yyyy-mm-dd"
+
+// The widest that a column is made to fit what's in it, in characters.
+const maxXLSXColumnWidth = 50
+
+// The sheets that communities go on when they aren't split up
+// by state, or when they don't have a state to be split up by.
+const (
+	allCommunitiesSheet = "Communities"
+	noStateSheet        = "No State"
+)
+
+// SheetPerState puts each state's communities on their own sheet in the
+// workbook, named by the state's abbreviation, instead of all on one sheet.
+func SheetPerState() EncodeOption {
+	return func(o *encodeOptions) {
+		o.sheetPerState = true
+	}
+}
+
+// ToXLSX writes the communities out as an Excel workbook with the same
+// columns as ToCSV. Unlike the CSV, the dates are typed as dates and the
+// CRS class is a number. CIDs are text so they keep their leading zeros
+// without the ="..." convention. The header row is frozen and filterable.
+func (c *NFIPCommunityStatuses) ToXLSX(w io.Writer, opts ...EncodeOption) error {
+	wb, err := c.toWorkbook(newEncodeOptions(opts))
+	if err != nil {
+		return err
+	}
+
+	return wb.Write(w)
+}
+
+func (c *NFIPCommunityStatuses) toWorkbook(o encodeOptions) (*xlsx.File, error) {
+	wb := xlsx.NewFile()
+	fields, err := o.bookFields()
+	if err != nil {
+		return nil, err
+	}
+
+	if !o.sheetPerState || len(*c) == 0 {
+		return wb, addStatusSheet(wb, allCommunitiesSheet, *c, fields)
+	}
+
+	var states []string
+	byState := make(map[string]NFIPCommunityStatuses)
+	for _, community := range *c {
+		state := community.State
+		if len(state) == 0 {
+			state = noStateSheet
+		}
+
+		if _, ok := byState[state]; !ok {
+			states = append(states, state)
+		}
+		byState[state] = append(byState[state], community)
+	}
+
+	sort.Strings(states)
+	for _, state := range states {
+		if err := addStatusSheet(wb, state, byState[state], fields); err != nil {
+			return nil, err
+		}
+	}
+
+	return wb, nil
+}
+
+func addStatusSheet(wb *xlsx.File, name string, communities NFIPCommunityStatuses, fields []statusField) error {
+	sheet, err := wb.AddSheet(name)
+	if err != nil {
+		return err
+	}
+
+	bold := xlsx.NewStyle()
+	bold.Font.Bold = true
+	bold.ApplyFont = true
+
+	widths := make([]int, len(fields))
+	header := sheet.AddRow()
+	for i, f := range fields {
+		cell := header.AddCell()
+		cell.SetString(f.header())
+		cell.SetStyle(bold)
+		widths[i] = len(f.header())
+	}
+
+	// The CSV's values are used for everything that isn't typed
+	o := encodeOptions{stripEquals: true}
+
+	for _, community := range communities {
+		record, err := community.csvRecord(o)
+		if err != nil {
+			return err
+		}
+
+		row := sheet.AddRow()
+		for i, f := range fields {
+			cell := row.AddCell()
+
+			if t, isDate := community.statusDate(f.column); isDate {
+				if t != nil {
+					cell.SetDateWithOptions(*t, xlsx.DateTimeOptions{
+						Location:        t.Location(),
+						ExcelTimeFormat: xlsxDateFormat,
+					})
+				}
+				widths[i] = maxInt(widths[i], len(xlsxDateFormat))
+				continue
+			}
+
+			value := f.csvValue(community, record)
+			if f.column == StatusCurClass && community.CurClass != nil {
+				cell.SetInt(*community.CurClass)
+			} else {
+				cell.SetString(value)
+			}
+			widths[i] = maxInt(widths[i], len(value))
+		}
+	}
+
+	sheet.SheetViews = []xlsx.SheetView{{
+		Pane: &xlsx.Pane{
+			YSplit:      1,
+			TopLeftCell: "A2",
+			ActivePane:  "bottomLeft",
+			State:       "frozen",
+		},
+	}}
+
+	sheet.AutoFilter = &xlsx.AutoFilter{
+		TopLeftCell:     "A1",
+		BottomRightCell: xlsx.GetCellIDStringFromCoords(len(fields)-1, sheet.MaxRow-1),
+	}
+
+	// Columns are numbered from 1 here
+	for column, width := range widths {
+		sheet.SetColWidth(column+1, column+1, float64(minInt(width+2, maxXLSXColumnWidth)))
+	}
+
+	return nil
+}
+
+// statusDate is the date in the column, and whether the column is a date.
+func (c NFIPCommunityStatus) statusDate(column int) (*time.Time, bool) {
+	switch column {
+	case StatusFHBMIdentified:
+		return c.FHBMIdentified, true
+	case StatusFIRMIdentified:
+		return c.FIRMIdentified, true
+	case StatusCurrEffMapDate:
+		return c.CurrEffMapDate, true
+	case StatusRegEmerDate:
+		return c.RegEmerDate, true
+	case StatusCRSEntryDate:
+		return c.CRSEntryDate, true
+	case StatusCurrEffDate:
+		return c.CurrEffDate, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,54 @@
+package data
+
+import "testing"
+
+func TestToWorkbook(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+
+	wb, err := communities.toWorkbook(encodeOptions{})
+	if err != nil {
+		t.Fatalf("expected a workbook, got %s", err)
+	}
+
+	if len(wb.Sheets) != 1 || wb.Sheets[0].Name != allCommunitiesSheet {
+		t.Fatalf("expected one sheet of communities, got %d", len(wb.Sheets))
+	}
+
+	sheet := wb.Sheets[0]
+	if sheet.MaxRow != len(communities)+1 {
+		t.Errorf("expected a header and %d communities, got %d rows", len(communities), sheet.MaxRow)
+	}
+
+	if len(sheet.SheetViews) != 1 || sheet.SheetViews[0].Pane == nil || sheet.SheetViews[0].Pane.State != "frozen" {
+		t.Errorf("expected the header row to be frozen")
+	}
+
+	if sheet.AutoFilter == nil || sheet.AutoFilter.TopLeftCell != "A1" {
+		t.Errorf("expected the header row to have a filter")
+	}
+}
+
+func TestToWorkbookSheetPerState(t *testing.T) {
+	communities := NFIPCommunityStatuses{
+		{CID: 480287, CommunityName: "HOUSTON, CITY OF", State: "TX"},
+		{CID: 170604, CommunityName: "SPRINGFIELD, CITY OF", State: "IL"},
+		{CID: 480596, CommunityName: "DALLAS, CITY OF", State: "TX"},
+		{CID: 999999, CommunityName: "SOMEWHERE"},
+	}
+
+	wb, err := communities.toWorkbook(newEncodeOptions([]EncodeOption{SheetPerState()}))
+	if err != nil {
+		t.Fatalf("expected a workbook, got %s", err)
+	}
+
+	expected := map[string]int{"IL": 2, "TX": 3, noStateSheet: 2}
+	if len(wb.Sheets) != len(expected) {
+		t.Fatalf("expected %d sheets, got %d", len(expected), len(wb.Sheets))
+	}
+
+	for i, name := range []string{"IL", noStateSheet, "TX"} {
+		if sheet := wb.Sheets[i]; sheet.Name != name || sheet.MaxRow != expected[name] {
+			t.Errorf("expected sheet %d to be %s with %d rows, got %s with %d", i, name, expected[name], sheet.Name, sheet.MaxRow)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package data
+
+import (
+	_ "embed"
+	"encoding/xml"
+	"io"
+)
+
+// XMLSchema is the XML Schema (XSD) for what ToXML writes.
+//
+//go:embed communities.xsd
+var XMLSchema string
+
+// statusesXML is the root of the XML. Its layout is kept the same from one
+// version to the next so that anything reading it doesn't break, and it's
+// described by XMLSchema. Elements are named the same as the fields in JSON,
+// are in the same order, and are left out the same way when they're empty:
+//
+//	<?xml version="1.0" encoding="UTF-8"?>
+//	<communities count="1">
+//	  <community>
+//	    <cid>170604</cid>
+//	    <community_name>SPRINGFIELD, CITY OF</community_name>
+//	    <flags>
+//	      <minimally_flood_prone>true</minimally_flood_prone>
+//	    </flags>
+//	    <county>SANGAMON COUNTY</county>
+//	    <state>IL</state>
+//	    <firm_identified>1981-03-16</firm_identified>
+//	    <tribal>false</tribal>
+//	    <cur_class>7</cur_class>
+//	    <program>Regular</program>
+//	    <participating_community>true</participating_community>
+//	  </community>
+//	</communities>
+type statusesXML struct {
+	XMLName     xml.Name    `xml:"communities"`
+	Count       int         `xml:"count,attr"`
+	Communities []statusXML `xml:"community"`
+}
+
+type statusXML struct {
+	CID                    int             `xml:"cid"`
+	CommunityName          string          `xml:"community_name,omitempty"`
+	Flags                  *CommunityFlags `xml:"flags,omitempty"`
+	Kind                   NameKind        `xml:"kind,omitempty"`
+	BaseName               string          `xml:"base_name,omitempty"`
+	Type                   CommunityType   `xml:"type,omitempty"`
+	County                 string          `xml:"county,omitempty"`
+	State                  string          `xml:"state,omitempty"`
+	StateFIPS              int             `xml:"state_fips,omitempty"`
+	FHBMIdentified         string          `xml:"fhbm_identified,omitempty"`
+	FIRMIdentified         string          `xml:"firm_identified,omitempty"`
+	CurrEffMapDate         string          `xml:"curr_eff_map_date,omitempty"`
+	RegEmerDate            string          `xml:"reg_emer_date,omitempty"`
+	Tribal                 bool            `xml:"tribal"`
+	CRSEntryDate           string          `xml:"crs_entry_date,omitempty"`
+	CurrEffDate            string          `xml:"curr_eff_date,omitempty"`
+	CurClass               *int            `xml:"cur_class,omitempty"`
+	PercentDiscSFHA        string          `xml:"percent_disc_sfha,omitempty"`
+	PercentNonSFHA         string          `xml:"percent_non_sfha,omitempty"`
+	Program                string          `xml:"program,omitempty"`
+	ParticipatingCommunity bool            `xml:"participating_community"`
+}
+
+// ToXML writes the communities out as XML. See XMLSchema for the layout.
+func (c *NFIPCommunityStatuses) ToXML(w io.Writer) error {
+	root := statusesXML{
+		Count:       len(*c),
+		Communities: make([]statusXML, len(*c)),
+	}
+
+	for i, community := range *c {
+		root.Communities[i] = community.toStatusXML()
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(root); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// toStatusXML lays the community out the same way as it is in JSON.
+func (c NFIPCommunityStatus) toStatusXML() statusXML {
+	sj := c.toStatusJSON()
+	sx := statusXML{
+		CID:                    sj.CID,
+		CommunityName:          sj.CommunityName,
+		Flags:                  sj.Flags,
+		Kind:                   sj.Kind,
+		BaseName:               sj.BaseName,
+		Type:                   sj.Type,
+		County:                 sj.County,
+		State:                  sj.State,
+		StateFIPS:              sj.StateFIPS,
+		FHBMIdentified:         sj.FHBMIdentified,
+		FIRMIdentified:         sj.FIRMIdentified,
+		CurrEffMapDate:         sj.CurrEffMapDate,
+		RegEmerDate:            sj.RegEmerDate,
+		Tribal:                 sj.Tribal,
+		CRSEntryDate:           sj.CRSEntryDate,
+		CurrEffDate:            sj.CurrEffDate,
+		CurClass:               sj.CurClass,
+		PercentDiscSFHA:        sj.PercentDiscSFHA,
+		PercentNonSFHA:         sj.PercentNonSFHA,
+		ParticipatingCommunity: sj.ParticipatingCommunity,
+	}
+
+	if sj.Program != ProgramUnknown {
+		sx.Program = sj.Program.String()
+	}
+
+	return sx
+}
@@ -0,0 +1,49 @@
+package data
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestToXML(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+
+	var buf bytes.Buffer
+	if err := communities.ToXML(&buf); err != nil {
+		t.Fatalf("expected the communities to be written as XML, got %s", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), xml.Header+"<communities count=") {
+		t.Errorf("expected the XML to start with its header and the communities, got %s", buf.String()[:80])
+	}
+
+	var root statusesXML
+	if err := xml.Unmarshal(buf.Bytes(), &root); err != nil {
+		t.Fatalf("expected the XML to be read back, got %s", err)
+	}
+
+	if root.Count != len(communities) || len(root.Communities) != len(communities) {
+		t.Fatalf("expected %d communities, got %d", len(communities), len(root.Communities))
+	}
+
+	for i, community := range communities {
+		if expected := community.toStatusXML(); !reflect.DeepEqual(root.Communities[i], expected) {
+			t.Fatalf("expected %v, got %v", expected, root.Communities[i])
+		}
+	}
+}
+
+// Every element that's written has to be in the schema.
+func TestXMLSchema(t *testing.T) {
+	for _, typ := range []reflect.Type{reflect.TypeOf(statusXML{}), reflect.TypeOf(CommunityFlags{})} {
+		for i := 0; i < typ.NumField(); i++ {
+			name := strings.Split(typ.Field(i).Tag.Get("xml"), ",")[0]
+			if !strings.Contains(XMLSchema, "<xs:element name=\""+name+"\"") {
+				t.Errorf("expected %s to be in the schema", name)
+			}
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package data
+
+import (
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ToYAML writes the communities out as a YAML list, with the
+// same field names and formatting that they have in JSON. WithFields
+// picks the fields, the same as for JSON.
+func (c *NFIPCommunityStatuses) ToYAML(w io.Writer, opts ...EncodeOption) error {
+	v, err := c.project(newEncodeOptions(opts))
+	if err != nil {
+		return err
+	}
+
+	e := yaml.NewEncoder(w)
+	e.SetIndent(2)
+
+	if err := e.Encode(v); err != nil {
+		return err
+	}
+
+	return e.Close()
+}
+
+func (c NFIPCommunityStatus) MarshalYAML() (interface{}, error) {
+	return c.toStatusJSON(), nil
+}
+
+func (p ProgramKind) MarshalYAML() (interface{}, error) {
+	return p.String(), nil
+}
@@ -0,0 +1,68 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestToYAML(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+
+	var buf bytes.Buffer
+	if err := communities.ToYAML(&buf); err != nil {
+		t.Fatalf("expected the communities to be written as YAML, got %s", err)
+	}
+
+	var fromYAML []map[string]interface{}
+	if err := yaml.Unmarshal(buf.Bytes(), &fromYAML); err != nil {
+		t.Fatalf("expected the YAML to be read back, got %s", err)
+	}
+
+	b, _ := json.Marshal(communities)
+	var fromJSON []map[string]interface{}
+	json.Unmarshal(b, &fromJSON)
+
+	if len(fromYAML) != len(fromJSON) {
+		t.Fatalf("expected %d communities, got %d", len(fromJSON), len(fromYAML))
+	}
+
+	for i := range fromJSON {
+		if !reflect.DeepEqual(fieldNames(fromYAML[i]), fieldNames(fromJSON[i])) {
+			t.Fatalf("expected the fields %v, got %v", fieldNames(fromJSON[i]), fieldNames(fromYAML[i]))
+		}
+	}
+
+	first := fromYAML[0]
+	if first["community_name"] != communities[0].CommunityName || first["program"] != communities[0].Program.String() {
+		t.Errorf("expected the first community to be %s, got %v", communities[0].CommunityName, first)
+	}
+}
+
+func TestToYAMLDates(t *testing.T) {
+	communities, _ := GetDemoNFIPCommunityStatusBook()
+	springfield := communities.Search("springfield").Communities()
+
+	var buf bytes.Buffer
+	springfield.ToYAML(&buf)
+
+	expected := "curr_eff_map_date: \"" + springfield[0].CurrEffMapDate.Format(jsonDateLayout) + "\""
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("expected the YAML to have %s, got\n%s", expected, buf.String())
+	}
+}
+
+func fieldNames(m map[string]interface{}) []string {
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
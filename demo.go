@@ -0,0 +1,53 @@
+package main
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+// Queries that show off the demo's sample communities.
+var demoQueries = []string{
+	"/status?search=miami",
+	"/status?search=county",
+	"/status?search=480296",
+	"/rating?search=tulsa",
+}
+
+func (c *cli) demoCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "demo",
+		Short: "Serve the sample communities that are built into the binary",
+		Long: `Serve the sample communities that are built into the binary, so that
+nothing has to be downloaded or configured to try it out. It's the same as
+serve --demo.`,
+		Args: checkArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDemo(c.l)
+		},
+	}
+}
+
+// runDemo serves the sample of the Community Status Book that's built into
+// the binary, so nothing has to be downloaded or configured to try it out.
+func runDemo(l *log.Logger) error {
+	cb, err := data.GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		return err
+	}
+
+	crs, err := data.GetDemoNFIPCommunityRatingSystem()
+	if err != nil {
+		return err
+	}
+
+	l.Printf("Demo mode: serving %d sample communities. Try these queries:\n", len(cb))
+	for _, q := range demoQueries {
+		l.Printf("  http://localhost:9001%s\n", q)
+	}
+
+	return serve(l, store.NewMemory(cb, indexOptions()...), crs)
+}
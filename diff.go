@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"nfip-community-book/data"
+)
+
+// A communityDiff is what changed between two versions of the communities,
+// by CID.
+type communityDiff struct {
+	Added   []int           `json:"added"`
+	Updated []updatedFields `json:"updated"`
+	Removed []int           `json:"removed"`
+}
+
+// updatedFields are the fields that changed in a community, named the same
+// as they are in JSON.
+type updatedFields struct {
+	CID    int      `json:"cid"`
+	Fields []string `json:"fields"`
+}
+
+func (d communityDiff) changed() bool {
+	return len(d.Added) > 0 || len(d.Updated) > 0 || len(d.Removed) > 0
+}
+
+// rows are the changes as a table, a community to a row in order of their
+// CIDs.
+func (d communityDiff) rows() [][]string {
+	rows := [][]string{{"CID", "CHANGE", "FIELDS"}}
+	for _, cid := range d.Added {
+		rows = append(rows, []string{fmt.Sprintf("%06d", cid), "added", ""})
+	}
+	for _, u := range d.Updated {
+		rows = append(rows, []string{fmt.Sprintf("%06d", u.CID), "updated", strings.Join(u.Fields, " ")})
+	}
+	for _, cid := range d.Removed {
+		rows = append(rows, []string{fmt.Sprintf("%06d", cid), "removed", ""})
+	}
+
+	slices.SortStableFunc(rows[1:], func(a, b []string) int { return strings.Compare(a[0], b[0]) })
+	return rows
+}
+
+func (c *cli) diffCommand() *cobra.Command {
+	var exitCode bool
+
+	cmd := &cobra.Command{
+		Use:   "diff <old> [new]",
+		Short: "Show what changed between two status books",
+		Long: `Show the communities that were added, updated and removed between two
+status books, and which of their fields changed. Without the new
+one, the old one is compared to the communities as they are now.
+
+The status books can be CSV files like nation.csv, or JSON from export.`,
+		Example: `  nfip diff nation-2024-01.csv nation.csv
+  nfip diff --exit-code last-week.json`,
+		Args: checkArgs(cobra.RangeArgs(1, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.runDiff(args, exitCode)
+		},
+	}
+
+	cmd.Flags().BoolVar(&exitCode, "exit-code", false, "exit with 4 if anything changed")
+	return cmd
+}
+
+func (c *cli) runDiff(args []string, exitCode bool) error {
+	old, err := readStatusBookFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	var next data.NFIPCommunityStatuses
+	if len(args) > 1 {
+		var result data.ParseResult
+		result, err = readStatusBookFile(args[1])
+		next = result.Communities
+	} else {
+		next, err = c.communities()
+	}
+	if err != nil {
+		return err
+	}
+
+	d, err := diffCommunities(old.Communities, next)
+	if err != nil {
+		return err
+	}
+
+	if err := c.write(d, d.rows()); err != nil {
+		return err
+	}
+
+	if exitCode && d.changed() {
+		return commandError{exitInvalid, fmt.Errorf("the communities changed: %d added, %d updated, %d removed", len(d.Added), len(d.Updated), len(d.Removed))}
+	}
+	return nil
+}
+
+// diffCommunities matches the communities up by CID, and compares their
+// fields' JSON. The CIDs are in order.
+func diffCommunities(old, next data.NFIPCommunityStatuses) (communityDiff, error) {
+	d := communityDiff{Added: []int{}, Updated: []updatedFields{}, Removed: []int{}}
+
+	before, err := fieldsByCID(old)
+	if err != nil {
+		return d, err
+	}
+
+	after, err := fieldsByCID(next)
+	if err != nil {
+		return d, err
+	}
+
+	for cid, fields := range after {
+		oldFields, ok := before[cid]
+		if !ok {
+			d.Added = append(d.Added, cid)
+			continue
+		}
+
+		var changed []string
+		for _, name := range data.FieldNames() {
+			if !bytes.Equal(oldFields[name], fields[name]) {
+				changed = append(changed, name)
+			}
+		}
+		if len(changed) > 0 {
+			d.Updated = append(d.Updated, updatedFields{cid, changed})
+		}
+	}
+
+	for cid := range before {
+		if _, ok := after[cid]; !ok {
+			d.Removed = append(d.Removed, cid)
+		}
+	}
+
+	slices.Sort(d.Added)
+	slices.Sort(d.Removed)
+	slices.SortFunc(d.Updated, func(a, b updatedFields) int { return a.CID - b.CID })
+	return d, nil
+}
+
+func fieldsByCID(communities data.NFIPCommunityStatuses) (map[int]map[string]json.RawMessage, error) {
+	fields := make(map[int]map[string]json.RawMessage, len(communities))
+	for _, c := range communities {
+		b, err := json.Marshal(c)
+		if err != nil {
+			return nil, err
+		}
+
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal(b, &m); err != nil {
+			return nil, err
+		}
+		fields[c.CID] = m
+	}
+
+	return fields, nil
+}
+
+// readStatusBookFile reads a status book from a CSV file like nation.csv,
+// or a JSON file that was exported. JSON doesn't have anything but the
+// communities.
+func readStatusBookFile(filename string) (data.ParseResult, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return data.ParseResult{}, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		var result data.ParseResult
+		if err := json.NewDecoder(f).Decode(&result.Communities); err != nil {
+			return data.ParseResult{}, fmt.Errorf("could not read %s: %w", filename, err)
+		}
+		return result, nil
+	}
+
+	result, err := data.Unmarshal(f)
+	if err != nil {
+		return data.ParseResult{}, fmt.Errorf("could not read %s: %w", filename, err)
+	}
+
+	return result, nil
+}
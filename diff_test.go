@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"nfip-community-book/data"
+)
+
+// writeCommunitiesFile writes the communities to a JSON file, like export does.
+func writeCommunitiesFile(t *testing.T, name string, communities data.NFIPCommunityStatuses) string {
+	t.Helper()
+
+	filename := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatalf("expected a file, got %s", err)
+	}
+	defer f.Close()
+
+	if err := communities.ToJSON(f); err != nil {
+		t.Fatalf("expected the communities to be written, got %s", err)
+	}
+	return filename
+}
+
+func TestDiffCommand(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+
+	// Houston is removed, and Harris County's class is changed
+	changed := data.NFIPCommunityStatuses{}
+	for _, c := range communities {
+		switch c.CID {
+		case 480296:
+			continue
+		case 480287:
+			class := 4
+			c.CurClass = &class
+		}
+		changed = append(changed, c)
+	}
+
+	old := writeCommunitiesFile(t, "old.json", communities)
+	next := writeCommunitiesFile(t, "new.json", changed)
+
+	for _, tc := range []commandTest{
+		{name: "changes", args: []string{"diff", old, next}, code: exitOK, contains: []string{`"removed": [`, "480296", `"cur_class"`}},
+		{name: "table", args: []string{"diff", "-o", "csv", old, next}, code: exitOK, contains: []string{
+			"CID,CHANGE,FIELDS\n",
+			"480287,updated,cur_class\n",
+			"480296,removed,\n",
+		}},
+		{name: "added", args: []string{"diff", "-o", "csv", next, old}, code: exitOK, contains: []string{"480296,added,\n"}},
+		{name: "exit code", args: []string{"diff", "--exit-code", old, next}, code: exitInvalid},
+		{name: "exit code without changes", args: []string{"diff", "--exit-code", old, old}, code: exitOK, contains: []string{`"added": []`}},
+		{name: "against now", args: []string{"--demo", "diff", "--exit-code", old}, code: exitOK},
+		{name: "missing file", args: []string{"diff", filepath.Join(t.TempDir(), "missing.json"), old}, code: exitError},
+		{name: "no files", args: []string{"diff"}, code: exitUsage},
+		{name: "three files", args: []string{"diff", old, next, old}, code: exitUsage},
+	} {
+		t.Run(tc.name, tc.run)
+	}
+}
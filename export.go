@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"nfip-community-book/data"
+)
+
+// An exportFormat is one of the formats that the communities can be
+// exported in. It's picked by its name, or by a file's extension, which is
+// its name too unless there are others.
+type exportFormat struct {
+	name       string
+	extensions []string
+	write      func(w io.Writer, communities data.NFIPCommunityStatuses) error
+}
+
+var exportFormats = []exportFormat{
+	{"json", nil, func(w io.Writer, c data.NFIPCommunityStatuses) error { return c.ToJSON(w) }},
+	{"ndjson", []string{"jsonl"}, func(w io.Writer, c data.NFIPCommunityStatuses) error { return c.ToNDJSON(w) }},
+	{"csv", nil, func(w io.Writer, c data.NFIPCommunityStatuses) error { return c.ToCSV(w) }},
+	{"yaml", []string{"yml"}, func(w io.Writer, c data.NFIPCommunityStatuses) error { return c.ToYAML(w) }},
+	{"xml", nil, func(w io.Writer, c data.NFIPCommunityStatuses) error { return c.ToXML(w) }},
+	{"markdown", []string{"md"}, func(w io.Writer, c data.NFIPCommunityStatuses) error { return c.ToMarkdown(w) }},
+	{"table", []string{"txt"}, func(w io.Writer, c data.NFIPCommunityStatuses) error { return c.ToTable(w) }},
+	{"sql", nil, func(w io.Writer, c data.NFIPCommunityStatuses) error { return c.ToSQL(w) }},
+	{"xlsx", nil, func(w io.Writer, c data.NFIPCommunityStatuses) error { return c.ToXLSX(w) }},
+	{"arrow", nil, func(w io.Writer, c data.NFIPCommunityStatuses) error { return c.ToArrow(w) }},
+}
+
+func exportFormatNames() []string {
+	names := make([]string, len(exportFormats))
+	for i, f := range exportFormats {
+		names[i] = f.name
+	}
+
+	return names
+}
+
+func (c *cli) exportCommand() *cobra.Command {
+	var (
+		filters  filterFlags
+		format   string
+		filename string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the communities to a file",
+		Long: `Export the communities, or the ones that pass the filters, to stdout or
+the file. The format is picked by the file's extension if it isn't given,
+then by --output, and is JSON otherwise.
+
+The formats are ` + strings.Join(exportFormatNames(), ", ") + `.`,
+		Example: `  nfip export --state TX --file texas.csv
+  nfip export --query 'crs AND class<=5' --format xlsx --file crs.xlsx`,
+		Args: checkArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// --output is only for what's written to stdout, and the
+			// extension says better what the file should be
+			if len(format) == 0 && len(filepath.Ext(filename)) == 0 {
+				format = c.output
+			}
+
+			f, err := pickExportFormat(format, filename)
+			if err != nil {
+				return err
+			}
+
+			return c.runExport(&filters, f, filename)
+		},
+	}
+
+	filters.add(cmd)
+	cmd.Flags().StringVar(&format, "format", "", "the format to export, one of "+strings.Join(exportFormatNames(), ", "))
+	cmd.Flags().StringVar(&filename, "file", "", "the file to export to, instead of stdout")
+	return cmd
+}
+
+// pickExportFormat is the format with the name, or the one for the file's
+// extension without one.
+func pickExportFormat(name, filename string) (exportFormat, error) {
+	if len(name) == 0 {
+		ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+		if len(ext) == 0 {
+			return exportFormats[0], nil
+		}
+
+		for _, f := range exportFormats {
+			if f.name == ext || slices.Contains(f.extensions, ext) {
+				return f, nil
+			}
+		}
+		return exportFormat{}, usageError(fmt.Errorf("can't tell what format %s is, give it with --format", filename))
+	}
+
+	for _, f := range exportFormats {
+		if f.name == strings.ToLower(name) {
+			return f, nil
+		}
+	}
+
+	return exportFormat{}, usageError(fmt.Errorf("invalid format %s, it has to be one of %s", strconv.Quote(name), strings.Join(exportFormatNames(), ", ")))
+}
+
+func (c *cli) runExport(filters *filterFlags, f exportFormat, filename string) error {
+	communities, err := c.communities()
+	if err != nil {
+		return err
+	}
+
+	communities, err = filters.apply(communities)
+	if err != nil {
+		return err
+	}
+
+	if len(filename) == 0 {
+		return f.write(os.Stdout, communities)
+	}
+
+	if err := exportFile(filename, f, communities); err != nil {
+		return err
+	}
+
+	c.stderr().Printf("Exported %d communities to %s\n", len(communities), filename)
+	return nil
+}
+
+// exportFile writes the communities to the file in the format.
+func exportFile(filename string, f exportFormat, communities data.NFIPCommunityStatuses) (err error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	return f.write(file, communities)
+}
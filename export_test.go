@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"nfip-community-book/data"
+)
+
+func TestExportCommand(t *testing.T) {
+	for _, tc := range []commandTest{
+		{name: "json", args: []string{"--demo", "export", "--state", "TX"}, code: exitOK, contains: []string{`"cid":480296`}},
+		{name: "format", args: []string{"--demo", "export", "--state", "TX", "--format", "csv"}, code: exitOK, contains: []string{"CID,Community Name", `="481658","RIO BRAVO, CITY OF"`}},
+		{name: "output", args: []string{"--demo", "-o", "markdown", "export", "--state", "TX"}, code: exitOK, contains: []string{"| CID "}},
+		{name: "query", args: []string{"--demo", "export", "-q", "crs AND class<=5", "--format", "csv"}, code: exitOK, contains: []string{`="480287"`}, excludes: []string{`="480296"`}},
+		{name: "invalid format", args: []string{"--demo", "export", "--format", "bogus"}, code: exitUsage},
+		{name: "unknown extension", args: []string{"--demo", "export", "--file", "communities.bogus"}, code: exitUsage},
+		{name: "unknown state", args: []string{"--demo", "export", "--state", "XX"}, code: exitUsage},
+		{name: "arguments", args: []string{"--demo", "export", "TX"}, code: exitUsage},
+	} {
+		t.Run(tc.name, tc.run)
+	}
+}
+
+func TestExportFile(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, tc := range []struct {
+		args     []string
+		filename string
+	}{
+		{[]string{"--file", filepath.Join(dir, "texas.json")}, "texas.json"},
+		{[]string{"--file", filepath.Join(dir, "texas.txt"), "--format", "json"}, "texas.txt"},
+		{[]string{"--file", filepath.Join(dir, "texas"), "-o", "csv"}, "texas"},
+	} {
+		out, code := runCommand(t, append([]string{"--demo", "export", "--state", "TX"}, tc.args...)...)
+		if code != exitOK || len(out) > 0 {
+			t.Fatalf("expected %s to be exported without writing to stdout, got %d %s", tc.filename, code, out)
+		}
+	}
+
+	f, err := os.Open(filepath.Join(dir, "texas.json"))
+	if err != nil {
+		t.Fatalf("expected the file to be exported, got %s", err)
+	}
+	defer f.Close()
+
+	var communities data.NFIPCommunityStatuses
+	if err := json.NewDecoder(f).Decode(&communities); err != nil || len(communities) != 3 {
+		t.Errorf("expected the 3 communities in Texas, got %d %v", len(communities), err)
+	}
+
+	if b, err := os.ReadFile(filepath.Join(dir, "texas.txt")); err != nil || b[0] != '[' {
+		t.Errorf("expected --format to win over the extension, got %s %v", b, err)
+	}
+
+	if b, err := os.ReadFile(filepath.Join(dir, "texas")); err != nil || string(b[:3]) != "CID" {
+		t.Errorf("expected --output to be used without an extension, got %s %v", b, err)
+	}
+}
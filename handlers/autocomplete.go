@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"nfip-community-book/problem"
+)
+
+// The most suggestions that are returned if a limit isn't given
+const defaultAutocompleteLimit = 10
+
+type Autocomplete struct {
+	l  *log.Logger
+	st Indexer
+}
+
+func NewAutocomplete(l *log.Logger, st Indexer) Autocomplete {
+	return Autocomplete{l, st}
+}
+
+func (a Autocomplete) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		a.getSuggestions(rw, r)
+		return
+	}
+
+	methodNotAllowed(rw, r)
+}
+
+func (a Autocomplete) getSuggestions(rw http.ResponseWriter, r *http.Request) {
+	queries := r.URL.Query()
+	prefix := queries.Get("prefix")
+
+	if len(prefix) == 0 {
+		problem.Write(rw, r, http.StatusBadRequest, problem.MissingParameter, "missing the prefix")
+		return
+	}
+
+	limit := defaultAutocompleteLimit
+	if limitString := queries.Get("limit"); len(limitString) > 0 {
+		var err error
+		limit, err = strconv.Atoi(limitString)
+		if err != nil || limit < 1 {
+			problem.Write(rw, r, http.StatusBadRequest, problem.InvalidParameter, "invalid limit "+strconv.Quote(limitString)+", it has to be at least 1")
+			return
+		}
+	}
+
+	// These come in as someone types, so they aren't logged
+	err := json.NewEncoder(rw).Encode(a.st.Index().Autocomplete(prefix, limit))
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}
+}
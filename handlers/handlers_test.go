@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+func TestRefreshed(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	memory := store.NewMemory(communities)
+	l := log.New(io.Discard, "", 0)
+	status, autocomplete := NewStatus(l, memory), NewAutocomplete(l, memory)
+
+	for _, h := range []struct {
+		target  string
+		handler http.Handler
+	}{
+		{"/status?search=houston", status},
+		{"/status?cid=480296", status},
+		{"/autocomplete?prefix=hous", autocomplete},
+	} {
+		rw := httptest.NewRecorder()
+		h.handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, h.target, nil))
+		if rw.Code != http.StatusOK || !strings.Contains(strings.ToUpper(rw.Body.String()), "HOUSTON") {
+			t.Errorf("expected Houston from %s, got %d %s", h.target, rw.Code, rw.Body)
+		}
+	}
+
+	withoutHouston := data.NFIPCommunityStatuses{}
+	for _, c := range communities {
+		if c.CID != 480296 {
+			withoutHouston = append(withoutHouston, c)
+		}
+	}
+	if _, err := memory.Refresh(context.Background(), withoutHouston); err != nil {
+		t.Fatalf("expected the store to be refreshed, got %s", err)
+	}
+
+	for _, h := range []struct {
+		target  string
+		handler http.Handler
+	}{
+		{"/status?cid=480296", status},
+		{"/autocomplete?prefix=hous", autocomplete},
+	} {
+		rw := httptest.NewRecorder()
+		h.handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, h.target, nil))
+		if strings.Contains(strings.ToUpper(rw.Body.String()), "HOUSTON") {
+			t.Errorf("expected Houston to be gone from %s once it's refreshed, got %s", h.target, rw.Body)
+		}
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	memory := store.NewMemory(communities)
+	l := log.New(io.Discard, "", 0)
+
+	for _, h := range []http.Handler{NewStatus(l, memory), NewAutocomplete(l, memory), NewRating(l, nil)} {
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/", nil))
+		if rw.Code != http.StatusBadRequest || rw.Header().Get("Allow") != http.MethodGet || !strings.Contains(rw.Body.String(), `"method_not_allowed"`) {
+			t.Errorf("expected a POST to still be a 400, got %d %s", rw.Code, rw.Body)
+		}
+	}
+}
+
+func TestDidYouMean(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	status := NewStatus(log.New(io.Discard, "", 0), store.NewMemory(communities))
+
+	rw := httptest.NewRecorder()
+	status.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/status?search=pulasky", nil))
+	if body := strings.TrimSpace(rw.Body.String()); body != "[]" {
+		t.Errorf("expected an empty list, got %s", body)
+	}
+	if suggestions := rw.Header().Get("Did-You-Mean"); !strings.HasPrefix(suggestions, `"PULASKI COUNTY"`) {
+		t.Errorf("expected Pulaski County to be suggested, got %s", suggestions)
+	}
+
+	rw = httptest.NewRecorder()
+	status.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/status?search=pulasky&limit=10", nil))
+	if !strings.Contains(rw.Body.String(), `"did_you_mean":["PULASKI COUNTY"`) || len(rw.Header().Get("Did-You-Mean")) > 0 {
+		t.Errorf("expected Pulaski County to be suggested in the page, got %s", rw.Body)
+	}
+
+	rw = httptest.NewRecorder()
+	status.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/status?search=houston", nil))
+	if len(rw.Header().Get("Did-You-Mean")) > 0 {
+		t.Errorf("expected nothing to be suggested when something's found, got %s", rw.Header().Get("Did-You-Mean"))
+	}
+}
+
+func TestQuoteList(t *testing.T) {
+	if quoted := quoteList([]string{"HOUSTON, CITY OF", `SAY "HI"`}); quoted != `"HOUSTON, CITY OF", "SAY \"HI\""` {
+		t.Errorf("expected the names to be quoted, got %s", quoted)
+	}
+}
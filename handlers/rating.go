@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"nfip-community-book/data"
+	"nfip-community-book/problem"
 )
 
 type Rating struct {
@@ -22,7 +23,7 @@ func (rh Rating) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rw.WriteHeader(http.StatusBadRequest)
+	methodNotAllowed(rw, r)
 }
 
 func (rh Rating) getStatus(rw http.ResponseWriter, r *http.Request) {
@@ -30,7 +31,7 @@ func (rh Rating) getStatus(rw http.ResponseWriter, r *http.Request) {
 	search := queries.Get("search")
 
 	if len(search) == 0 {
-		rw.WriteHeader(http.StatusBadRequest)
+		problem.Write(rw, r, http.StatusBadRequest, problem.MissingParameter, "missing the search term")
 		return
 	}
 
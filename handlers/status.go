@@ -1,19 +1,32 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"nfip-community-book/data"
+	"nfip-community-book/problem"
 )
 
+// An Indexer has the index of the communities, like a store.Snapshot.
+// It's asked for the index again for every request, so that a refresh
+// is seen as soon as it's done.
+type Indexer interface {
+	Index() *data.CommunityIndex
+}
+
 type Status struct {
 	l  *log.Logger
-	cb data.NFIPCommunityStatuses
+	st Indexer
 }
 
-func NewStatus(l *log.Logger, cb data.NFIPCommunityStatuses) Status {
-	return Status{l, cb}
+func NewStatus(l *log.Logger, st Indexer) Status {
+	return Status{l, st}
 }
 
 func (s Status) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
@@ -22,22 +35,246 @@ func (s Status) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rw.WriteHeader(http.StatusBadRequest)
+	methodNotAllowed(rw, r)
 }
 
 func (s Status) getStatus(rw http.ResponseWriter, r *http.Request) {
 	queries := r.URL.Query()
 	search := queries.Get("search")
+	idx := s.st.Index()
+
+	p, err := parsePaging(queries)
+	if err != nil {
+		problem.Write(rw, r, http.StatusBadRequest, problem.InvalidParameter, err.Error())
+		return
+	}
+
+	if len(search) > 0 {
+		s.l.Printf("[STATUS] Requested search for term \"%s\"\n", search)
+		s.writeResults(rw, r, idx, idx.Search(search), p, search)
+		return
+	}
+
+	// Fuzzy searches tolerate typos and include how close each match was
+	if fuzzy := queries.Get("fuzzy"); len(fuzzy) > 0 {
+		var opts []data.FuzzyOption
+		if similarity := queries.Get("similarity"); len(similarity) > 0 {
+			minSimilarity, err := strconv.ParseFloat(similarity, 64)
+			if err != nil {
+				problem.Write(rw, r, http.StatusBadRequest, problem.InvalidParameter, "invalid similarity "+strconv.Quote(similarity)+", it has to be a number")
+				return
+			}
+
+			opts = append(opts, data.WithMinSimilarity(minSimilarity))
+		}
+
+		s.l.Printf("[STATUS] Requested fuzzy search for term \"%s\"\n", fuzzy)
+		s.writeResults(rw, r, idx, idx.FuzzySearch(fuzzy, opts...), p, fuzzy)
+		return
+	}
+
+	// Phonetic searches find names that sound like the term
+	if phonetic := queries.Get("phonetic"); len(phonetic) > 0 {
+		s.l.Printf("[STATUS] Requested phonetic search for term \"%s\"\n", phonetic)
+		s.writeResults(rw, r, idx, idx.PhoneticSearch(phonetic), p, phonetic)
+		return
+	}
+
+	// Queries that can't be parsed are a bad request rather
+	// than falling back to a plain search like search does
+	if q := queries.Get("q"); len(q) > 0 {
+		s.l.Printf("[STATUS] Requested query \"%s\"\n", q)
+		communityStatuses, err := idx.Communities().Query(q)
+		if err != nil {
+			s.l.Printf("[STATUS] %s\n", err.Error())
+			problem.Write(rw, r, http.StatusBadRequest, problem.InvalidQuery, err.Error())
+			return
+		}
 
-	if len(search) == 0 {
-		rw.WriteHeader(http.StatusBadRequest)
+		s.writeStatuses(rw, r, communityStatuses, p)
 		return
 	}
 
-	s.l.Printf("[STATUS] Requested search for term \"%s\"\n", search)
-	communityStatuses := s.cb.Search(search)
-	err := communityStatuses.ToJSON(rw)
+	// Otherwise search by specific fields, narrowing the
+	// results down with each one that's been given
+	start := idx.Communities()
+	q := data.NewQuery()
+	searched := false
+
+	if cidString := queries.Get("cid"); len(cidString) > 0 {
+		cid, err := strconv.Atoi(cidString)
+		if err != nil {
+			problem.Write(rw, r, http.StatusBadRequest, problem.InvalidParameter, "invalid cid "+strconv.Quote(cidString)+", it has to be a number")
+			return
+		}
+
+		// The index can find the community without looking through them all
+		start = data.NFIPCommunityStatuses{}
+		if c, ok := idx.ByCID(cid); ok {
+			start = data.NFIPCommunityStatuses{*c}
+		}
+		searched = true
+	}
+
+	if name := queries.Get("name"); len(name) > 0 {
+		q.NameContains(name)
+		searched = true
+	}
+
+	if county := queries.Get("county"); len(county) > 0 {
+		q.CountyContains(county)
+		searched = true
+	}
+
+	if state := queries.Get("state"); len(state) > 0 {
+		q.State(state)
+		searched = true
+	}
+
+	if !searched {
+		problem.Write(rw, r, http.StatusBadRequest, problem.MissingParameter, "missing a search, one of search, fuzzy, phonetic, q, cid, name, county or state")
+		return
+	}
+
+	s.l.Printf("[STATUS] Requested search for %s\n", r.URL.RawQuery)
+	communityStatuses := q.Refine(start).Communities()
+	s.writeStatuses(rw, r, &communityStatuses, p)
+}
+
+// Results are only paged when a limit or offset is given. Otherwise
+// they're all written out on their own, without a total.
+type paging struct {
+	paged  bool
+	offset int
+	limit  int
+}
+
+func parsePaging(queries url.Values) (paging, error) {
+	var p paging
+
+	for _, param := range []struct {
+		name  string
+		value *int
+	}{
+		{"offset", &p.offset},
+		{"limit", &p.limit},
+	} {
+		s := queries.Get(param.name)
+		if len(s) == 0 {
+			continue
+		}
+
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return p, fmt.Errorf("invalid %s %s", param.name, s)
+		}
+
+		*param.value = n
+		p.paged = true
+	}
+
+	return p, nil
+}
+
+// Searches that don't find anything have the names that were probably
+// meant in this header, since an unpaged search is always a list.
+const didYouMeanHeader = "Did-You-Mean"
+
+func (s Status) writeResults(rw http.ResponseWriter, r *http.Request, idx *data.CommunityIndex, results data.SearchResults, p paging, term string) {
+	var suggestions []string
+	if len(results) == 0 {
+		suggestions = idx.DidYouMean(term, data.DefaultSuggestions)
+		if len(suggestions) > 0 {
+			s.l.Printf("[STATUS] Nothing found for \"%s\", suggesting %v\n", term, suggestions)
+		}
+	}
+
+	var err error
+	switch {
+	case p.paged:
+		page := results.Page(p.offset, p.limit)
+		page.DidYouMean = suggestions
+		err = json.NewEncoder(rw).Encode(page)
+	default:
+		if len(suggestions) > 0 {
+			rw.Header().Set(didYouMeanHeader, quoteList(suggestions))
+		}
+		err = results.StreamJSON(rw, compress(rw, r)...)
+	}
+
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// quoteList is the strings as a header's list of quoted strings, since
+// names like "HOUSTON, CITY OF" have commas in them.
+func quoteList(ss []string) string {
+	quoted := make([]string, len(ss))
+	for i, s := range ss {
+		quoted[i] = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func (s Status) writeStatuses(rw http.ResponseWriter, r *http.Request, communityStatuses *data.NFIPCommunityStatuses, p paging) {
+	var err error
+	if p.paged {
+		err = json.NewEncoder(rw).Encode(communityStatuses.Page(p.offset, p.limit))
+	} else {
+		err = communityStatuses.StreamJSON(rw, compress(rw, r)...)
+	}
+
 	if err != nil {
 		rw.WriteHeader(http.StatusInternalServerError)
 	}
 }
+
+// methodNotAllowed is the problem for anything but a GET, which is all
+// that the handlers take. It's a 400 rather than a 405, since that's what
+// these have always been.
+func methodNotAllowed(rw http.ResponseWriter, r *http.Request) {
+	rw.Header().Set("Allow", http.MethodGet)
+	problem.Write(rw, r, http.StatusBadRequest, problem.MethodNotAllowed, r.Method+" isn't allowed, only GET")
+}
+
+// compress gzips everything that's written when the client can take it,
+// which is only done for the responses without paging, since pages are
+// small and everything else could be the whole status book.
+func compress(rw http.ResponseWriter, r *http.Request) []data.EncodeOption {
+	rw.Header().Add("Vary", "Accept-Encoding")
+	if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+		return nil
+	}
+
+	rw.Header().Set("Content-Encoding", "gzip")
+	return []data.EncodeOption{data.WithGzip()}
+}
+
+// acceptsGzip is whether gzip is one of the encodings in an Accept-Encoding
+// header, either by name or by "*", and it wasn't turned down with q=0.
+func acceptsGzip(acceptEncoding string) bool {
+	accepted := false
+	for _, encoding := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(encoding, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "gzip" && name != "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				q, _ = strconv.ParseFloat(value, 64)
+			}
+		}
+
+		// gzip by name wins over *
+		if name == "gzip" {
+			return q > 0
+		}
+		accepted = q > 0
+	}
+
+	return accepted
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+	"nfip-community-book/store/history"
+)
+
+// Every version of the status book that's loaded is kept in
+// this file if it's set, so that it can be looked back on.
+const HistoryEnv = "NFIP_HISTORY"
+
+// The layout of the dates that --as-of takes.
+const asOfLayout = "2006-01-02"
+
+// recordHistory adds the communities that a refresh got to the history if
+// it's kept, and they're a different version than the ones they replace.
+// They take effect when the status book was downloaded. A status book that
+// was downloaded before the last one in the history isn't recorded, rather
+// than failing the refresh.
+func recordHistory(l *log.Logger, current, next data.NFIPCommunityStatuses) error {
+	filename := os.Getenv(HistoryEnv)
+	if len(filename) == 0 || store.Version(current) == store.Version(next) {
+		return nil
+	}
+
+	effective, err := data.NFIPCommunityStatusBookDownloaded()
+	if err != nil {
+		return err
+	}
+
+	h, err := history.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	changes, err := h.Record(next, effective)
+	if errors.Is(err, history.ErrOutOfOrder) {
+		l.Printf("Not recording the communities in the history: %s\n", err)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if changes.Added > 0 || changes.Updated > 0 || changes.Removed > 0 {
+		l.Printf("Recorded in the history: %d added, %d updated, %d removed\n", changes.Added, changes.Updated, changes.Removed)
+	}
+
+	return nil
+}
+
+func (c *cli) historyCommand() *cobra.Command {
+	var asOf string
+
+	cmd := &cobra.Command{
+		Use:   "history <cid>",
+		Short: "Write every revision of a community, or how it stood on a day",
+		Long: `Write every revision of the community with the CID from the history, or
+how it stood at the end of the day with --as-of.
+
+The history is kept in the file in NFIP_HISTORY.`,
+		Example: "  NFIP_HISTORY=history.db nfip history --as-of 2024-01-31 480296",
+		Args:    checkArgs(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.runHistory(args[0], asOf)
+		},
+	}
+
+	cmd.Flags().StringVar(&asOf, "as-of", "", "the day to write the community as it stood at the end of, like 2024-01-31")
+	return cmd
+}
+
+// runHistory writes out the community with the CID as it stood at the end
+// of the day, or every revision of it without one.
+func (c *cli) runHistory(cidString, asOf string) error {
+	filename := os.Getenv(HistoryEnv)
+	if len(filename) == 0 {
+		return usageError(fmt.Errorf("%s has to be set to the history's file", HistoryEnv))
+	}
+
+	cid, err := parseCID(cidString)
+	if err != nil {
+		return err
+	}
+
+	var day time.Time
+	if len(asOf) > 0 {
+		day, err = time.ParseInLocation(asOfLayout, asOf, time.Local)
+		if err != nil {
+			return usageError(fmt.Errorf("invalid date %s, it has to be like 2024-01-31", strconv.Quote(asOf)))
+		}
+	}
+
+	h, err := history.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	if len(asOf) == 0 {
+		revisions := h.Revisions(cid)
+		return c.write(revisions, revisionRows(revisions))
+	}
+
+	// Anything that took effect that day counts
+	community, ok := h.CommunityAsOf(cid, day.AddDate(0, 0, 1).Add(-time.Nanosecond))
+	if !ok {
+		return notFound("%d wasn't in the status book on %s", cid, asOf)
+	}
+
+	return c.writeCommunities(community, data.NFIPCommunityStatuses{community})
+}
+
+// revisionRows are the revisions as a table, with when each one took effect
+// and the community's name, or that it was removed.
+func revisionRows(revisions []history.Revision) [][]string {
+	rows := [][]string{{"EFFECTIVE", "CID", "COMMUNITY"}}
+	for _, r := range revisions {
+		name := "(removed)"
+		if r.Community != nil {
+			name = r.Community.CommunityName
+		}
+		rows = append(rows, []string{r.Effective.Format(asOfLayout), fmt.Sprintf("%06d", r.CID), name})
+	}
+
+	return rows
+}
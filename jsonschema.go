@@ -0,0 +1,31 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"nfip-community-book/data"
+)
+
+func (c *cli) schemaCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema",
+		Short: "Write the JSON Schema for a community",
+		Args:  checkArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runJSONSchema()
+		},
+	}
+}
+
+// runJSONSchema writes the JSON Schema for a community to stdout.
+func runJSONSchema() error {
+	schema, err := data.GenerateJSONSchema()
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stdout.Write(append(schema, '\n'))
+	return err
+}
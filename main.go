@@ -2,41 +2,153 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
 	"nfip-community-book/data"
 	"nfip-community-book/handlers"
+	"nfip-community-book/server"
+	"nfip-community-book/server/rpc"
+	"nfip-community-book/store"
+	"nfip-community-book/web"
 )
 
 func main() {
 	l := log.New(os.Stdout, "NFIP Community Book: ", log.LstdFlags)
-	cb, err := data.GetNFIPCommunityStatusBook(l)
+	root := newRootCommand(l)
+
+	// Anything that isn't one of the commands is a subcommand provided
+	// by an external "nfip-<name>" executable found on the PATH.
+	// Serving is what's done without one.
+	if args, ok := pluginArgs(root, os.Args[1:]); ok {
+		os.Exit(runPlugin(l, args[0], args[1:]))
+	}
+
+	cmd, err := root.ExecuteC()
+	if err != nil {
+		l = log.New(os.Stderr, l.Prefix(), l.Flags())
+		l.Println("** Err -", err)
+		if exitCode(err) == exitUsage {
+			l.Printf("Run \"%s --help\" for usage\n", cmd.CommandPath())
+		}
+	}
+
+	os.Exit(exitCode(err))
+}
+
+func (c *cli) serveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the REST API on port 9001 and gRPC on port 9002",
+		Long: `Serve the REST API on port 9001 and gRPC on port 9002, until it's
+stopped with SIGINT or SIGTERM. This is what's run without a command.
+
+The server is configured with the NFIP_ environment variables in the README.`,
+		Args: checkArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.serve()
+		},
+	}
+}
+
+// serve serves the API until it's stopped, with the sample communities
+// if it's the demo.
+func (c *cli) serve() error {
+	if c.demo {
+		return runDemo(c.l)
+	}
+
+	shutdownTracing, err := setupTracing(c.l)
+	if err != nil {
+		return err
+	}
+
+	// The store stays open for as long as it's served from
+	st, err := openSnapshot(context.Background(), c.l, indexOptions()...)
+	if err != nil {
+		return err
+	}
+	defer closeStore(st)
+
+	crs, err := data.GetNFIPCommunityRatingSystem(c.l)
+	if err != nil {
+		return err
+	}
+
+	opts, err := serverOptions(c.l)
+	if err != nil {
+		return err
+	}
+
+	if err := serve(c.l, st, crs, opts...); err != nil {
+		return err
+	}
+
+	// Send the traces that haven't been yet, which are the last ones
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		c.l.Printf("Error sending the last traces: %s\n", err)
+	}
+
+	return nil
+}
+
+// How many recent searches are cached. Search boxes that search as
+// they're typed in tend to search for the same things over and over.
+const searchCacheSize = 1024
 
+// indexOptions are what the index that's served from is built with.
+func indexOptions() []data.IndexOption {
+	return []data.IndexOption{data.WithSearchCache(data.NewSearchCache(searchCacheSize))}
+}
+
+func serve(l *log.Logger, st store.Snapshot, crs data.NFIPCommunityRatings, opts ...server.Option) error {
+	timeout, err := readShutdownTimeout()
 	if err != nil {
-		l.Println(err.Error())
-		os.Exit(1)
+		return err
 	}
 
-	crs, err := data.GetNFIPCommunityRatingSystem(l)
+	tlsConfig, err := readTLS(l)
 	if err != nil {
-		l.Println(err.Error())
-		os.Exit(1)
+		return err
 	}
 
-	sh := handlers.NewStatus(l, cb)
-	rh := handlers.NewRating(l, crs)
-	sm := http.NewServeMux()
+	// Stores that are shared with other instances, like Redis, are
+	// kept up to date with their refreshes while this one's serving
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	if w, ok := st.(watcher); ok {
+		go func() {
+			if err := w.Watch(watchCtx, l); err != nil && !errors.Is(err, context.Canceled) {
+				l.Printf("Stopped watching for refreshes: %s\n", err)
+			}
+		}()
+	}
 
-	sm.Handle("/status", sh)
-	sm.Handle("/rating", rh)
+	// The endpoints from before the API are served by it, so that
+	// they're held to the same limits
+	api := server.New(st, append(opts,
+		server.WithUI(web.Handler()),
+		server.WithHandler("/status", handlers.NewStatus(l, st)),
+		server.WithHandler("/autocomplete", handlers.NewAutocomplete(l, st)),
+		server.WithHandler("/rating", handlers.NewRating(l, crs)),
+	)...)
 
 	s := http.Server{
 		Addr:         ":9001",
-		Handler:      sm,
+		Handler:      api,
+		TLSConfig:    tlsConfig,
 		ErrorLog:     l,
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
@@ -44,23 +156,78 @@ func main() {
 	}
 
 	go func() {
-		l.Println("Starting server on port 9001")
+		var err error
+		if tlsConfig != nil {
+			l.Println("Starting server with TLS on port 9001")
+			err = s.ListenAndServeTLS("", "")
+		} else {
+			l.Println("Starting server on port 9001")
+			err = s.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			l.Printf("Error starting server: %s\n", err)
+			os.Exit(1)
+		}
+	}()
+
+	// gRPC is served on its own port alongside the REST API
+	grpcOpts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(api.UnaryInterceptor()),
+		grpc.ChainStreamInterceptor(api.StreamInterceptor()),
+	}
+	if tlsConfig != nil {
+		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	gs := rpc.NewServer(st, grpcOpts...)
+	go func() {
+		l.Println("Starting gRPC server on port 9002")
 
-		err := s.ListenAndServe()
+		lis, err := net.Listen("tcp", ":9002")
+		if err == nil {
+			err = gs.Serve(lis)
+		}
 		if err != nil {
-			l.Printf("Error starting server: %s\n", err)
+			l.Printf("Error starting gRPC server: %s\n", err)
 			os.Exit(1)
 		}
 	}()
 
 	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
-	signal.Notify(c, os.Kill)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
 	sig := <-c
 	l.Println("Received signal:", sig)
 
-	// wait 30 seconds for existing requests to be completed before shutting down
-	ctx, _ := context.WithTimeout(context.Background(), 30*time.Second)
-	s.Shutdown(ctx)
+	// Stop taking new connections and wait for existing requests to be
+	// completed before shutting down. The API ends its event streams and
+	// waits for a refresh that's saving to the store at the same time.
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	apiDone := make(chan error, 1)
+	go func() {
+		apiDone <- api.Shutdown(ctx)
+	}()
+
+	if err := s.Shutdown(ctx); err != nil {
+		l.Printf("Error shutting down the server: %s\n", err)
+	}
+	if err := <-apiDone; err != nil {
+		l.Printf("Gave up waiting for the refresh to finish: %s\n", err)
+	}
+
+	grpcDone := make(chan struct{})
+	go func() {
+		gs.GracefulStop()
+		close(grpcDone)
+	}()
+
+	select {
+	case <-grpcDone:
+	case <-ctx.Done():
+		gs.Stop()
+	}
+
+	l.Println("Shut down")
+	return nil
 }
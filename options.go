@@ -0,0 +1,186 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"nfip-community-book/data"
+	"nfip-community-book/server"
+)
+
+// The communities are out of date in /readyz once they're older than
+// this, like 720h. They're never out of date if it isn't set.
+const MaxAgeEnv = "NFIP_MAX_AGE"
+
+// How many requests a second each client IP, and every client together,
+// can make to the API, like 10. A burst can be given after a comma, like
+// 10,50, and it's a second's worth of requests otherwise. There's no
+// limit if they aren't set.
+const (
+	RateLimitEnv       = "NFIP_RATE_LIMIT"
+	GlobalRateLimitEnv = "NFIP_GLOBAL_RATE_LIMIT"
+)
+
+// The API only answers requests with one of the keys in this file if it's
+// set. See server.ParseAPIKeys.
+const APIKeysEnv = "NFIP_API_KEYS"
+
+// Browser apps on these origins can call the API, like
+// https://example.com,https://app.example.com, or * for any origin. What
+// they can do in a preflight can be changed from the defaults with the
+// methods and headers, which are also lists, and the max age, like 1h.
+const (
+	CORSOriginsEnv = "NFIP_CORS_ORIGINS"
+	CORSMethodsEnv = "NFIP_CORS_METHODS"
+	CORSHeadersEnv = "NFIP_CORS_HEADERS"
+	CORSMaxAgeEnv  = "NFIP_CORS_MAX_AGE"
+)
+
+// How long the server waits for the requests that it's answering, and a
+// refresh that's running, to finish when it's told to stop, like 1m. It's
+// 30s if it isn't set. Anything still going after that is cut off.
+const ShutdownTimeoutEnv = "NFIP_SHUTDOWN_TIMEOUT"
+
+const defaultShutdownTimeout = 30 * time.Second
+
+// The REST API and gRPC are served over TLS with the certificate and key
+// in these files if they're set. Clients need a certificate that's signed
+// by one of the CAs in the client CA file too if it's set. The files are
+// read again when they change, so certificates can be rotated in place.
+const (
+	TLSCertEnv     = "NFIP_TLS_CERT"
+	TLSKeyEnv      = "NFIP_TLS_KEY"
+	TLSClientCAEnv = "NFIP_TLS_CLIENT_CA"
+)
+
+// serverOptions are the API's options from the environment.
+func serverOptions(l *log.Logger) ([]server.Option, error) {
+	rules, err := data.ParseRefreshRules(os.Getenv(RefreshRulesEnv))
+	if err != nil {
+		return nil, err
+	}
+	opts := []server.Option{server.WithRefresher(downloadCommunities(l, rules))}
+
+	maxAge, err := readMaxAge()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, server.WithMaxAge(maxAge))
+
+	for _, limit := range []struct {
+		env    string
+		option func(float64, int) server.Option
+	}{
+		{RateLimitEnv, server.WithIPRateLimit},
+		{GlobalRateLimitEnv, server.WithGlobalRateLimit},
+	} {
+		perSecond, burst, ok, err := readRateLimit(limit.env)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			opts = append(opts, limit.option(perSecond, burst))
+		}
+	}
+
+	if origins := readList(CORSOriginsEnv); len(origins) > 0 {
+		cors := server.CORS{
+			Origins: origins,
+			Methods: readList(CORSMethodsEnv),
+			Headers: readList(CORSHeadersEnv),
+		}
+
+		if s := os.Getenv(CORSMaxAgeEnv); len(s) > 0 {
+			cors.MaxAge, err = time.ParseDuration(s)
+			if err != nil || cors.MaxAge < 0 {
+				return nil, fmt.Errorf("invalid %s %s, it has to be a duration like 1h", CORSMaxAgeEnv, strconv.Quote(s))
+			}
+		}
+
+		opts = append(opts, server.WithCORS(cors))
+	}
+
+	if filename := os.Getenv(APIKeysEnv); len(filename) > 0 {
+		keys, err := server.LoadAPIKeys(filename)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, server.WithAPIKeys(keys))
+	}
+
+	return opts, nil
+}
+
+func readMaxAge() (time.Duration, error) {
+	s := os.Getenv(MaxAgeEnv)
+	if len(s) == 0 {
+		return 0, nil
+	}
+
+	maxAge, err := time.ParseDuration(s)
+	if err != nil || maxAge < 0 {
+		return 0, fmt.Errorf("invalid %s %s, it has to be a duration like 720h", MaxAgeEnv, strconv.Quote(s))
+	}
+
+	return maxAge, nil
+}
+
+func readShutdownTimeout() (time.Duration, error) {
+	s := os.Getenv(ShutdownTimeoutEnv)
+	if len(s) == 0 {
+		return defaultShutdownTimeout, nil
+	}
+
+	timeout, err := time.ParseDuration(s)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid %s %s, it has to be a duration like 1m", ShutdownTimeoutEnv, strconv.Quote(s))
+	}
+
+	return timeout, nil
+}
+
+// readTLS is the TLS config to serve with, or nil if it isn't set.
+func readTLS(l *log.Logger) (*tls.Config, error) {
+	t := server.TLS{
+		CertFile:     os.Getenv(TLSCertEnv),
+		KeyFile:      os.Getenv(TLSKeyEnv),
+		ClientCAFile: os.Getenv(TLSClientCAEnv),
+		ErrorLog:     l,
+	}
+	if len(t.CertFile) == 0 && len(t.KeyFile) == 0 && len(t.ClientCAFile) == 0 {
+		return nil, nil
+	}
+
+	return t.Config()
+}
+
+func readRateLimit(env string) (float64, int, bool, error) {
+	s := os.Getenv(env)
+	if len(s) == 0 {
+		return 0, 0, false, nil
+	}
+
+	perSecond, burst, err := server.ParseRateLimit(s)
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("%s: %w", env, err)
+	}
+
+	return perSecond, burst, true, nil
+}
+
+// readList is the comma separated values in the environment variable.
+func readList(env string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(env), ",") {
+		if value = strings.TrimSpace(value); len(value) > 0 {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"nfip-community-book/data"
+)
+
+// The formats that --output can write what the commands find in.
+const (
+	outputJSON     = "json"
+	outputNDJSON   = "ndjson"
+	outputCSV      = "csv"
+	outputTable    = "table"
+	outputYAML     = "yaml"
+	outputMarkdown = "markdown"
+)
+
+var outputFormats = []string{outputJSON, outputNDJSON, outputCSV, outputTable, outputYAML, outputMarkdown}
+
+// checkOutput checks that --output is one of the formats.
+func (c *cli) checkOutput() error {
+	if len(c.output) == 0 {
+		return nil
+	}
+
+	for _, f := range outputFormats {
+		if strings.EqualFold(c.output, f) {
+			c.output = f
+			return nil
+		}
+	}
+
+	return usageError(fmt.Errorf("invalid output %s, it has to be one of %s", strconv.Quote(c.output), strings.Join(outputFormats, ", ")))
+}
+
+// outputFormat is the format that's written, which is --output, or a table
+// if stdout is a terminal and JSON if it's piped somewhere.
+func (c *cli) outputFormat() string {
+	if len(c.output) > 0 {
+		return c.output
+	}
+
+	fi, err := os.Stdout.Stat()
+	if err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		return outputTable
+	}
+
+	return outputJSON
+}
+
+// write writes what a command found in the output format. JSON and YAML
+// are the value as it is, NDJSON is a line for each of its elements if
+// it's a list, and a table, CSV and Markdown are the rows, the first of
+// which is the header.
+func (c *cli) write(v any, rows [][]string) error {
+	switch c.outputFormat() {
+	case outputNDJSON:
+		return writeNDJSON(v)
+	case outputYAML:
+		return writeYAML(v)
+	case outputTable:
+		return data.WriteTable(os.Stdout, rows)
+	case outputCSV:
+		w := csv.NewWriter(os.Stdout)
+		if err := w.WriteAll(rows); err != nil {
+			return err
+		}
+		return w.Error()
+	case outputMarkdown:
+		return data.WriteMarkdown(os.Stdout, rows)
+	}
+
+	return writeJSON(v)
+}
+
+// writeCommunities writes communities with the same encoders as export.
+// The value is written instead as JSON, NDJSON and YAML if it isn't nil,
+// for things like search results that are more than the communities.
+func (c *cli) writeCommunities(v any, communities data.NFIPCommunityStatuses) error {
+	switch c.outputFormat() {
+	case outputTable:
+		return communities.ToTable(os.Stdout)
+	case outputCSV:
+		return communities.ToCSV(os.Stdout)
+	case outputMarkdown:
+		return communities.ToMarkdown(os.Stdout)
+	}
+
+	if v != nil {
+		return c.write(v, nil)
+	}
+
+	switch c.outputFormat() {
+	case outputNDJSON:
+		return communities.ToNDJSON(os.Stdout)
+	case outputYAML:
+		return communities.ToYAML(os.Stdout)
+	}
+
+	return communities.ToJSON(os.Stdout)
+}
+
+// writeNDJSON writes each of the value's elements as JSON on its own line,
+// or the value on one line if it isn't a list.
+func writeNDJSON(v any) error {
+	e := json.NewEncoder(os.Stdout)
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return e.Encode(v)
+	}
+
+	for i := 0; i < rv.Len(); i++ {
+		if err := e.Encode(rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeYAML writes the value as YAML with the same field names, and in the
+// same order, as it has in JSON. JSON is YAML already, so it's read back
+// as YAML and written out again in YAML's block style.
+func writeYAML(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.NewDecoder(bytes.NewReader(b)).Decode(&doc); err != nil {
+		return err
+	}
+	blockStyle(&doc)
+
+	e := yaml.NewEncoder(os.Stdout)
+	e.SetIndent(2)
+	if err := e.Encode(&doc); err != nil {
+		return err
+	}
+
+	return e.Close()
+}
+
+// blockStyle takes out the brackets and quotes that the node was read with,
+// so that YAML only quotes the strings that need it.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, child := range n.Content {
+		blockStyle(child)
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"nfip-community-book/data"
+)
+
+// captureStdout is what the function writes to stdout.
+func captureStdout(t *testing.T, f func() error) string {
+	t.Helper()
+
+	filename := filepath.Join(t.TempDir(), "stdout")
+	stdout, err := os.Create(filename)
+	if err != nil {
+		t.Fatalf("expected a file for stdout, got %s", err)
+	}
+
+	realStdout := os.Stdout
+	os.Stdout = stdout
+	err = f()
+	os.Stdout = realStdout
+	stdout.Close()
+
+	if err != nil {
+		t.Fatalf("expected it to be written, got %s", err)
+	}
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("expected to read stdout, got %s", err)
+	}
+	return string(b)
+}
+
+type outputRow struct {
+	CID      int    `json:"cid"`
+	CRSClass *int   `json:"crs_class,omitempty"`
+	Name     string `json:"name"`
+}
+
+func TestWrite(t *testing.T) {
+	class := 7
+	v := []outputRow{{480296, &class, "HOUSTON, CITY OF"}, {481658, nil, "RIO BRAVO, CITY OF"}}
+	rows := [][]string{{"CID", "CRS CLASS", "NAME"}, {"480296", "7", "HOUSTON, CITY OF"}, {"481658", "", "RIO BRAVO, CITY OF"}}
+
+	for _, tc := range []struct {
+		output string
+		golden string
+	}{
+		{outputJSON, `[
+  {
+    "cid": 480296,
+    "crs_class": 7,
+    "name": "HOUSTON, CITY OF"
+  },
+  {
+    "cid": 481658,
+    "name": "RIO BRAVO, CITY OF"
+  }
+]
+`},
+		{outputNDJSON, `{"cid":480296,"crs_class":7,"name":"HOUSTON, CITY OF"}
+{"cid":481658,"name":"RIO BRAVO, CITY OF"}
+`},
+		{outputCSV, `CID,CRS CLASS,NAME
+480296,7,"HOUSTON, CITY OF"
+481658,,"RIO BRAVO, CITY OF"
+`},
+		{outputTable, `CID     CRS CLASS  NAME
+480296  7          HOUSTON, CITY OF
+481658             RIO BRAVO, CITY OF
+`},
+		{outputYAML, `- cid: 480296
+  crs_class: 7
+  name: HOUSTON, CITY OF
+- cid: 481658
+  name: RIO BRAVO, CITY OF
+`},
+		{outputMarkdown, `| CID    | CRS CLASS | NAME               |
+| ------ | --------- | ------------------ |
+| 480296 | 7         | HOUSTON, CITY OF   |
+| 481658 |           | RIO BRAVO, CITY OF |
+`},
+	} {
+		c := &cli{output: tc.output}
+		if out := captureStdout(t, func() error { return c.write(v, rows) }); out != tc.golden {
+			t.Errorf("expected %s to be\n%s\ngot\n%s", tc.output, tc.golden, out)
+		}
+	}
+}
+
+func TestWriteCommunities(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	var houston data.NFIPCommunityStatuses
+	for _, c := range communities {
+		if c.CID == 480296 {
+			houston = append(houston, c)
+		}
+	}
+
+	for _, tc := range []struct {
+		output string
+		golden string
+	}{
+		{outputJSON, `[{"cid":480296,"community_name":"HOUSTON, CITY OF","kind":"City","base_name":"HOUSTON","type":"city","county":"HARRIS COUNTY/FORT BEND COUNTY/MONTGOMERY COUNTY","state":"TX","state_fips":48,"fhbm_identified":"1974-01-10","firm_identified":"1979-12-11","curr_eff_map_date":"2013-10-16","reg_emer_date":"1979-12-11","tribal":false,"crs_entry_date":"1991-10-01","curr_eff_date":"2019-05-01","cur_class":7,"percent_disc_sfha":"15","percent_non_sfha":"5","program":"Regular","participating_community":true}]
+`},
+		{outputNDJSON, `{"cid":480296,"community_name":"HOUSTON, CITY OF","kind":"City","base_name":"HOUSTON","type":"city","county":"HARRIS COUNTY/FORT BEND COUNTY/MONTGOMERY COUNTY","state":"TX","state_fips":48,"fhbm_identified":"1974-01-10","firm_identified":"1979-12-11","curr_eff_map_date":"2013-10-16","reg_emer_date":"1979-12-11","tribal":false,"crs_entry_date":"1991-10-01","curr_eff_date":"2019-05-01","cur_class":7,"percent_disc_sfha":"15","percent_non_sfha":"5","program":"Regular","participating_community":true}
+`},
+		// The communities' CSV ends its lines with CRLF, unlike the rows'
+		{outputCSV, "CID,Community Name,County,Init FHBM Identified,Init FIRM Identified,Curr Eff Map Date,Reg-Emer Date,Tribal,CRS Entry Date,Curr Eff Date,Curr Class,% Disc SFHA,% Disc Non SFHA,Program,Participating in NFIP\r\n" +
+			`="480296","HOUSTON, CITY OF",HARRIS COUNTY/FORT BEND COUNTY/MONTGOMERY COUNTY,01/10/74,12/11/79,10/16/13,12/11/79,No,10/01/91,05/01/19,7,15,5,R,Yes` + "\r\n"},
+		{outputTable, `CID     COMMUNITY         COUNTY                                            STATE  PROGRAM  CRS CLASS
+480296  HOUSTON, CITY OF  HARRIS COUNTY/FORT BEND COUNTY/MONTGOMERY COUNTY  TX     Regular  7
+`},
+		{outputYAML, `- cid: 480296
+  community_name: HOUSTON, CITY OF
+  kind: City
+  base_name: HOUSTON
+  type: city
+  county: HARRIS COUNTY/FORT BEND COUNTY/MONTGOMERY COUNTY
+  state: TX
+  state_fips: 48
+  fhbm_identified: "1974-01-10"
+  firm_identified: "1979-12-11"
+  curr_eff_map_date: "2013-10-16"
+  reg_emer_date: "1979-12-11"
+  tribal: false
+  crs_entry_date: "1991-10-01"
+  curr_eff_date: "2019-05-01"
+  cur_class: 7
+  percent_disc_sfha: "15"
+  percent_non_sfha: "5"
+  program: Regular
+  participating_community: true
+`},
+		{outputMarkdown, `| CID    | COMMUNITY        | COUNTY                                           | STATE | PROGRAM | CRS CLASS |
+| ------ | ---------------- | ------------------------------------------------ | ----- | ------- | --------- |
+| 480296 | HOUSTON, CITY OF | HARRIS COUNTY/FORT BEND COUNTY/MONTGOMERY COUNTY | TX    | Regular | 7         |
+`},
+	} {
+		c := &cli{output: tc.output}
+		if out := captureStdout(t, func() error { return c.writeCommunities(nil, houston) }); out != tc.golden {
+			t.Errorf("expected %s to be\n%s\ngot\n%s", tc.output, tc.golden, out)
+		}
+	}
+}
+
+func TestOutputFormat(t *testing.T) {
+	// Devices like /dev/null are what a terminal looks like
+	terminal, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("expected to open %s, got %s", os.DevNull, err)
+	}
+	defer terminal.Close()
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatalf("expected a file, got %s", err)
+	}
+	defer file.Close()
+
+	realStdout := os.Stdout
+	defer func() {
+		os.Stdout = realStdout
+	}()
+
+	for _, tc := range []struct {
+		name   string
+		stdout *os.File
+		output string
+		format string
+	}{
+		{"terminal", terminal, "", outputTable},
+		{"piped", file, "", outputJSON},
+		{"terminal with --output", terminal, outputYAML, outputYAML},
+		{"piped with --output", file, outputCSV, outputCSV},
+	} {
+		os.Stdout = tc.stdout
+		c := &cli{output: tc.output}
+		if format := c.outputFormat(); format != tc.format {
+			t.Errorf("expected %s to be written as %s, got %s", tc.name, tc.format, format)
+		}
+	}
+}
+
+func TestCheckOutput(t *testing.T) {
+	for _, tc := range []struct {
+		output string
+		want   string
+		code   int
+	}{
+		{"", "", exitOK},
+		{"json", outputJSON, exitOK},
+		{"Markdown", outputMarkdown, exitOK},
+		{"NDJSON", outputNDJSON, exitOK},
+		{"xml", "xml", exitUsage},
+	} {
+		c := &cli{output: tc.output}
+		if code := exitCode(c.checkOutput()); code != tc.code || c.output != tc.want {
+			t.Errorf("expected %q to be %q and exit with %d, got %q and %d", tc.output, tc.want, tc.code, c.output, code)
+		}
+	}
+}
@@ -0,0 +1,579 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: nfip.proto
+
+// The communities in the NFIP Community Status Book. Fields are named and
+// numbered in the same order as they are in the JSON.
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// The NFIP program a community is in.
+type Program int32
+
+const (
+	Program_PROGRAM_UNKNOWN           Program = 0
+	Program_PROGRAM_EMERGENCY         Program = 1
+	Program_PROGRAM_REGULAR           Program = 2
+	Program_PROGRAM_NOT_PARTICIPATING Program = 3
+	Program_PROGRAM_SUSPENDED         Program = 4
+	Program_PROGRAM_WITHDRAWN         Program = 5
+)
+
+// Enum value maps for Program.
+var (
+	Program_name = map[int32]string{
+		0: "PROGRAM_UNKNOWN",
+		1: "PROGRAM_EMERGENCY",
+		2: "PROGRAM_REGULAR",
+		3: "PROGRAM_NOT_PARTICIPATING",
+		4: "PROGRAM_SUSPENDED",
+		5: "PROGRAM_WITHDRAWN",
+	}
+	Program_value = map[string]int32{
+		"PROGRAM_UNKNOWN":           0,
+		"PROGRAM_EMERGENCY":         1,
+		"PROGRAM_REGULAR":           2,
+		"PROGRAM_NOT_PARTICIPATING": 3,
+		"PROGRAM_SUSPENDED":         4,
+		"PROGRAM_WITHDRAWN":         5,
+	}
+)
+
+func (x Program) Enum() *Program {
+	p := new(Program)
+	*p = x
+	return p
+}
+
+func (x Program) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Program) Descriptor() protoreflect.EnumDescriptor {
+	return file_nfip_proto_enumTypes[0].Descriptor()
+}
+
+func (Program) Type() protoreflect.EnumType {
+	return &file_nfip_proto_enumTypes[0]
+}
+
+func (x Program) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Program.Descriptor instead.
+func (Program) EnumDescriptor() ([]byte, []int) {
+	return file_nfip_proto_rawDescGZIP(), []int{0}
+}
+
+// A day on the calendar, without a time or a time zone.
+type Date struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Year          int32                  `protobuf:"varint,1,opt,name=year,proto3" json:"year,omitempty"`
+	Month         int32                  `protobuf:"varint,2,opt,name=month,proto3" json:"month,omitempty"`
+	Day           int32                  `protobuf:"varint,3,opt,name=day,proto3" json:"day,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Date) Reset() {
+	*x = Date{}
+	mi := &file_nfip_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Date) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Date) ProtoMessage() {}
+
+func (x *Date) ProtoReflect() protoreflect.Message {
+	mi := &file_nfip_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Date.ProtoReflect.Descriptor instead.
+func (*Date) Descriptor() ([]byte, []int) {
+	return file_nfip_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Date) GetYear() int32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+func (x *Date) GetMonth() int32 {
+	if x != nil {
+		return x.Month
+	}
+	return 0
+}
+
+func (x *Date) GetDay() int32 {
+	if x != nil {
+		return x.Day
+	}
+	return 0
+}
+
+// The statuses that the status book marks after a community's name.
+type CommunityFlags struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Suspended           bool                   `protobuf:"varint,1,opt,name=suspended,proto3" json:"suspended,omitempty"`
+	Withdrawn           bool                   `protobuf:"varint,2,opt,name=withdrawn,proto3" json:"withdrawn,omitempty"`
+	MinimallyFloodProne bool                   `protobuf:"varint,3,opt,name=minimally_flood_prone,json=minimallyFloodProne,proto3" json:"minimally_flood_prone,omitempty"`
+	NonFloodProne       bool                   `protobuf:"varint,4,opt,name=non_flood_prone,json=nonFloodProne,proto3" json:"non_flood_prone,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *CommunityFlags) Reset() {
+	*x = CommunityFlags{}
+	mi := &file_nfip_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommunityFlags) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommunityFlags) ProtoMessage() {}
+
+func (x *CommunityFlags) ProtoReflect() protoreflect.Message {
+	mi := &file_nfip_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommunityFlags.ProtoReflect.Descriptor instead.
+func (*CommunityFlags) Descriptor() ([]byte, []int) {
+	return file_nfip_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CommunityFlags) GetSuspended() bool {
+	if x != nil {
+		return x.Suspended
+	}
+	return false
+}
+
+func (x *CommunityFlags) GetWithdrawn() bool {
+	if x != nil {
+		return x.Withdrawn
+	}
+	return false
+}
+
+func (x *CommunityFlags) GetMinimallyFloodProne() bool {
+	if x != nil {
+		return x.MinimallyFloodProne
+	}
+	return false
+}
+
+func (x *CommunityFlags) GetNonFloodProne() bool {
+	if x != nil {
+		return x.NonFloodProne
+	}
+	return false
+}
+
+type CommunityStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cid           int32                  `protobuf:"varint,1,opt,name=cid,proto3" json:"cid,omitempty"`
+	CommunityName string                 `protobuf:"bytes,2,opt,name=community_name,json=communityName,proto3" json:"community_name,omitempty"`
+	Flags         *CommunityFlags        `protobuf:"bytes,3,opt,name=flags,proto3" json:"flags,omitempty"`
+	Kind          string                 `protobuf:"bytes,4,opt,name=kind,proto3" json:"kind,omitempty"`
+	BaseName      string                 `protobuf:"bytes,5,opt,name=base_name,json=baseName,proto3" json:"base_name,omitempty"`
+	Type          string                 `protobuf:"bytes,6,opt,name=type,proto3" json:"type,omitempty"`
+	County        string                 `protobuf:"bytes,7,opt,name=county,proto3" json:"county,omitempty"`
+	State         string                 `protobuf:"bytes,8,opt,name=state,proto3" json:"state,omitempty"`
+	StateFips     int32                  `protobuf:"varint,9,opt,name=state_fips,json=stateFips,proto3" json:"state_fips,omitempty"`
+	// Dates that aren't in the status book are left unset.
+	FhbmIdentified *Date `protobuf:"bytes,10,opt,name=fhbm_identified,json=fhbmIdentified,proto3" json:"fhbm_identified,omitempty"`
+	FirmIdentified *Date `protobuf:"bytes,11,opt,name=firm_identified,json=firmIdentified,proto3" json:"firm_identified,omitempty"`
+	CurrEffMapDate *Date `protobuf:"bytes,12,opt,name=curr_eff_map_date,json=currEffMapDate,proto3" json:"curr_eff_map_date,omitempty"`
+	RegEmerDate    *Date `protobuf:"bytes,13,opt,name=reg_emer_date,json=regEmerDate,proto3" json:"reg_emer_date,omitempty"`
+	Tribal         bool  `protobuf:"varint,14,opt,name=tribal,proto3" json:"tribal,omitempty"`
+	CrsEntryDate   *Date `protobuf:"bytes,15,opt,name=crs_entry_date,json=crsEntryDate,proto3" json:"crs_entry_date,omitempty"`
+	CurrEffDate    *Date `protobuf:"bytes,16,opt,name=curr_eff_date,json=currEffDate,proto3" json:"curr_eff_date,omitempty"`
+	// Only set for communities in the Community Rating System.
+	CurClass               *int32  `protobuf:"varint,17,opt,name=cur_class,json=curClass,proto3,oneof" json:"cur_class,omitempty"`
+	PercentDiscSfha        string  `protobuf:"bytes,18,opt,name=percent_disc_sfha,json=percentDiscSfha,proto3" json:"percent_disc_sfha,omitempty"`
+	PercentNonSfha         string  `protobuf:"bytes,19,opt,name=percent_non_sfha,json=percentNonSfha,proto3" json:"percent_non_sfha,omitempty"`
+	Program                Program `protobuf:"varint,20,opt,name=program,proto3,enum=nfip.v1.Program" json:"program,omitempty"`
+	ParticipatingCommunity bool    `protobuf:"varint,21,opt,name=participating_community,json=participatingCommunity,proto3" json:"participating_community,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *CommunityStatus) Reset() {
+	*x = CommunityStatus{}
+	mi := &file_nfip_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommunityStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommunityStatus) ProtoMessage() {}
+
+func (x *CommunityStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_nfip_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommunityStatus.ProtoReflect.Descriptor instead.
+func (*CommunityStatus) Descriptor() ([]byte, []int) {
+	return file_nfip_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CommunityStatus) GetCid() int32 {
+	if x != nil {
+		return x.Cid
+	}
+	return 0
+}
+
+func (x *CommunityStatus) GetCommunityName() string {
+	if x != nil {
+		return x.CommunityName
+	}
+	return ""
+}
+
+func (x *CommunityStatus) GetFlags() *CommunityFlags {
+	if x != nil {
+		return x.Flags
+	}
+	return nil
+}
+
+func (x *CommunityStatus) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *CommunityStatus) GetBaseName() string {
+	if x != nil {
+		return x.BaseName
+	}
+	return ""
+}
+
+func (x *CommunityStatus) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *CommunityStatus) GetCounty() string {
+	if x != nil {
+		return x.County
+	}
+	return ""
+}
+
+func (x *CommunityStatus) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *CommunityStatus) GetStateFips() int32 {
+	if x != nil {
+		return x.StateFips
+	}
+	return 0
+}
+
+func (x *CommunityStatus) GetFhbmIdentified() *Date {
+	if x != nil {
+		return x.FhbmIdentified
+	}
+	return nil
+}
+
+func (x *CommunityStatus) GetFirmIdentified() *Date {
+	if x != nil {
+		return x.FirmIdentified
+	}
+	return nil
+}
+
+func (x *CommunityStatus) GetCurrEffMapDate() *Date {
+	if x != nil {
+		return x.CurrEffMapDate
+	}
+	return nil
+}
+
+func (x *CommunityStatus) GetRegEmerDate() *Date {
+	if x != nil {
+		return x.RegEmerDate
+	}
+	return nil
+}
+
+func (x *CommunityStatus) GetTribal() bool {
+	if x != nil {
+		return x.Tribal
+	}
+	return false
+}
+
+func (x *CommunityStatus) GetCrsEntryDate() *Date {
+	if x != nil {
+		return x.CrsEntryDate
+	}
+	return nil
+}
+
+func (x *CommunityStatus) GetCurrEffDate() *Date {
+	if x != nil {
+		return x.CurrEffDate
+	}
+	return nil
+}
+
+func (x *CommunityStatus) GetCurClass() int32 {
+	if x != nil && x.CurClass != nil {
+		return *x.CurClass
+	}
+	return 0
+}
+
+func (x *CommunityStatus) GetPercentDiscSfha() string {
+	if x != nil {
+		return x.PercentDiscSfha
+	}
+	return ""
+}
+
+func (x *CommunityStatus) GetPercentNonSfha() string {
+	if x != nil {
+		return x.PercentNonSfha
+	}
+	return ""
+}
+
+func (x *CommunityStatus) GetProgram() Program {
+	if x != nil {
+		return x.Program
+	}
+	return Program_PROGRAM_UNKNOWN
+}
+
+func (x *CommunityStatus) GetParticipatingCommunity() bool {
+	if x != nil {
+		return x.ParticipatingCommunity
+	}
+	return false
+}
+
+type CommunityStatuses struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Communities   []*CommunityStatus     `protobuf:"bytes,1,rep,name=communities,proto3" json:"communities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommunityStatuses) Reset() {
+	*x = CommunityStatuses{}
+	mi := &file_nfip_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommunityStatuses) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommunityStatuses) ProtoMessage() {}
+
+func (x *CommunityStatuses) ProtoReflect() protoreflect.Message {
+	mi := &file_nfip_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommunityStatuses.ProtoReflect.Descriptor instead.
+func (*CommunityStatuses) Descriptor() ([]byte, []int) {
+	return file_nfip_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CommunityStatuses) GetCommunities() []*CommunityStatus {
+	if x != nil {
+		return x.Communities
+	}
+	return nil
+}
+
+var File_nfip_proto protoreflect.FileDescriptor
+
+const file_nfip_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"nfip.proto\x12\anfip.v1\"B\n" +
+	"\x04Date\x12\x12\n" +
+	"\x04year\x18\x01 \x01(\x05R\x04year\x12\x14\n" +
+	"\x05month\x18\x02 \x01(\x05R\x05month\x12\x10\n" +
+	"\x03day\x18\x03 \x01(\x05R\x03day\"\xa8\x01\n" +
+	"\x0eCommunityFlags\x12\x1c\n" +
+	"\tsuspended\x18\x01 \x01(\bR\tsuspended\x12\x1c\n" +
+	"\twithdrawn\x18\x02 \x01(\bR\twithdrawn\x122\n" +
+	"\x15minimally_flood_prone\x18\x03 \x01(\bR\x13minimallyFloodProne\x12&\n" +
+	"\x0fnon_flood_prone\x18\x04 \x01(\bR\rnonFloodProne\"\xd3\x06\n" +
+	"\x0fCommunityStatus\x12\x10\n" +
+	"\x03cid\x18\x01 \x01(\x05R\x03cid\x12%\n" +
+	"\x0ecommunity_name\x18\x02 \x01(\tR\rcommunityName\x12-\n" +
+	"\x05flags\x18\x03 \x01(\v2\x17.nfip.v1.CommunityFlagsR\x05flags\x12\x12\n" +
+	"\x04kind\x18\x04 \x01(\tR\x04kind\x12\x1b\n" +
+	"\tbase_name\x18\x05 \x01(\tR\bbaseName\x12\x12\n" +
+	"\x04type\x18\x06 \x01(\tR\x04type\x12\x16\n" +
+	"\x06county\x18\a \x01(\tR\x06county\x12\x14\n" +
+	"\x05state\x18\b \x01(\tR\x05state\x12\x1d\n" +
+	"\n" +
+	"state_fips\x18\t \x01(\x05R\tstateFips\x126\n" +
+	"\x0ffhbm_identified\x18\n" +
+	" \x01(\v2\r.nfip.v1.DateR\x0efhbmIdentified\x126\n" +
+	"\x0ffirm_identified\x18\v \x01(\v2\r.nfip.v1.DateR\x0efirmIdentified\x128\n" +
+	"\x11curr_eff_map_date\x18\f \x01(\v2\r.nfip.v1.DateR\x0ecurrEffMapDate\x121\n" +
+	"\rreg_emer_date\x18\r \x01(\v2\r.nfip.v1.DateR\vregEmerDate\x12\x16\n" +
+	"\x06tribal\x18\x0e \x01(\bR\x06tribal\x123\n" +
+	"\x0ecrs_entry_date\x18\x0f \x01(\v2\r.nfip.v1.DateR\fcrsEntryDate\x121\n" +
+	"\rcurr_eff_date\x18\x10 \x01(\v2\r.nfip.v1.DateR\vcurrEffDate\x12 \n" +
+	"\tcur_class\x18\x11 \x01(\x05H\x00R\bcurClass\x88\x01\x01\x12*\n" +
+	"\x11percent_disc_sfha\x18\x12 \x01(\tR\x0fpercentDiscSfha\x12(\n" +
+	"\x10percent_non_sfha\x18\x13 \x01(\tR\x0epercentNonSfha\x12*\n" +
+	"\aprogram\x18\x14 \x01(\x0e2\x10.nfip.v1.ProgramR\aprogram\x127\n" +
+	"\x17participating_community\x18\x15 \x01(\bR\x16participatingCommunityB\f\n" +
+	"\n" +
+	"_cur_class\"O\n" +
+	"\x11CommunityStatuses\x12:\n" +
+	"\vcommunities\x18\x01 \x03(\v2\x18.nfip.v1.CommunityStatusR\vcommunities*\x97\x01\n" +
+	"\aProgram\x12\x13\n" +
+	"\x0fPROGRAM_UNKNOWN\x10\x00\x12\x15\n" +
+	"\x11PROGRAM_EMERGENCY\x10\x01\x12\x13\n" +
+	"\x0fPROGRAM_REGULAR\x10\x02\x12\x1d\n" +
+	"\x19PROGRAM_NOT_PARTICIPATING\x10\x03\x12\x15\n" +
+	"\x11PROGRAM_SUSPENDED\x10\x04\x12\x15\n" +
+	"\x11PROGRAM_WITHDRAWN\x10\x05B\x18Z\x16nfip-community-book/pbb\x06proto3"
+
+var (
+	file_nfip_proto_rawDescOnce sync.Once
+	file_nfip_proto_rawDescData []byte
+)
+
+func file_nfip_proto_rawDescGZIP() []byte {
+	file_nfip_proto_rawDescOnce.Do(func() {
+		file_nfip_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_nfip_proto_rawDesc), len(file_nfip_proto_rawDesc)))
+	})
+	return file_nfip_proto_rawDescData
+}
+
+var file_nfip_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_nfip_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_nfip_proto_goTypes = []any{
+	(Program)(0),              // 0: nfip.v1.Program
+	(*Date)(nil),              // 1: nfip.v1.Date
+	(*CommunityFlags)(nil),    // 2: nfip.v1.CommunityFlags
+	(*CommunityStatus)(nil),   // 3: nfip.v1.CommunityStatus
+	(*CommunityStatuses)(nil), // 4: nfip.v1.CommunityStatuses
+}
+var file_nfip_proto_depIdxs = []int32{
+	2, // 0: nfip.v1.CommunityStatus.flags:type_name -> nfip.v1.CommunityFlags
+	1, // 1: nfip.v1.CommunityStatus.fhbm_identified:type_name -> nfip.v1.Date
+	1, // 2: nfip.v1.CommunityStatus.firm_identified:type_name -> nfip.v1.Date
+	1, // 3: nfip.v1.CommunityStatus.curr_eff_map_date:type_name -> nfip.v1.Date
+	1, // 4: nfip.v1.CommunityStatus.reg_emer_date:type_name -> nfip.v1.Date
+	1, // 5: nfip.v1.CommunityStatus.crs_entry_date:type_name -> nfip.v1.Date
+	1, // 6: nfip.v1.CommunityStatus.curr_eff_date:type_name -> nfip.v1.Date
+	0, // 7: nfip.v1.CommunityStatus.program:type_name -> nfip.v1.Program
+	3, // 8: nfip.v1.CommunityStatuses.communities:type_name -> nfip.v1.CommunityStatus
+	9, // [9:9] is the sub-list for method output_type
+	9, // [9:9] is the sub-list for method input_type
+	9, // [9:9] is the sub-list for extension type_name
+	9, // [9:9] is the sub-list for extension extendee
+	0, // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_nfip_proto_init() }
+func file_nfip_proto_init() {
+	if File_nfip_proto != nil {
+		return
+	}
+	file_nfip_proto_msgTypes[2].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_nfip_proto_rawDesc), len(file_nfip_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_nfip_proto_goTypes,
+		DependencyIndexes: file_nfip_proto_depIdxs,
+		EnumInfos:         file_nfip_proto_enumTypes,
+		MessageInfos:      file_nfip_proto_msgTypes,
+	}.Build()
+	File_nfip_proto = out.File
+	file_nfip_proto_goTypes = nil
+	file_nfip_proto_depIdxs = nil
+}
@@ -0,0 +1,7 @@
+// Package pb has the Protocol Buffers messages for the status book, and
+// the gRPC service for looking them up. The .pb.go files are generated
+// from the .proto files, so edit those and regenerate.
+package pb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative nfip.proto
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative service.proto
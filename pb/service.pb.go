@@ -0,0 +1,353 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: service.proto
+
+// A service for looking up and searching the communities, for services
+// that would rather use gRPC than the REST API.
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetCommunityRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cid           int32                  `protobuf:"varint,1,opt,name=cid,proto3" json:"cid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCommunityRequest) Reset() {
+	*x = GetCommunityRequest{}
+	mi := &file_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCommunityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCommunityRequest) ProtoMessage() {}
+
+func (x *GetCommunityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCommunityRequest.ProtoReflect.Descriptor instead.
+func (*GetCommunityRequest) Descriptor() ([]byte, []int) {
+	return file_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetCommunityRequest) GetCid() int32 {
+	if x != nil {
+		return x.Cid
+	}
+	return 0
+}
+
+type SearchCommunitiesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// What to search for, in the community's name, county, state or CID.
+	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// The most results to send. All of them are sent if it's 0.
+	Limit         int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchCommunitiesRequest) Reset() {
+	*x = SearchCommunitiesRequest{}
+	mi := &file_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchCommunitiesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchCommunitiesRequest) ProtoMessage() {}
+
+func (x *SearchCommunitiesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchCommunitiesRequest.ProtoReflect.Descriptor instead.
+func (*SearchCommunitiesRequest) Descriptor() ([]byte, []int) {
+	return file_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SearchCommunitiesRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchCommunitiesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type SearchResult struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Community *CommunityStatus       `protobuf:"bytes,1,opt,name=community,proto3" json:"community,omitempty"`
+	// How well the community matched, higher being better.
+	Score         float64 `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResult) ProtoMessage() {}
+
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SearchResult) GetCommunity() *CommunityStatus {
+	if x != nil {
+		return x.Community
+	}
+	return nil
+}
+
+func (x *SearchResult) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+type GetDatasetInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDatasetInfoRequest) Reset() {
+	*x = GetDatasetInfoRequest{}
+	mi := &file_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDatasetInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDatasetInfoRequest) ProtoMessage() {}
+
+func (x *GetDatasetInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDatasetInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetDatasetInfoRequest) Descriptor() ([]byte, []int) {
+	return file_service_proto_rawDescGZIP(), []int{3}
+}
+
+type DatasetInfo struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// How many communities there are.
+	Communities int32 `protobuf:"varint,1,opt,name=communities,proto3" json:"communities,omitempty"`
+	// When the communities were loaded or last refreshed.
+	LoadedAt      *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=loaded_at,json=loadedAt,proto3" json:"loaded_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DatasetInfo) Reset() {
+	*x = DatasetInfo{}
+	mi := &file_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DatasetInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DatasetInfo) ProtoMessage() {}
+
+func (x *DatasetInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DatasetInfo.ProtoReflect.Descriptor instead.
+func (*DatasetInfo) Descriptor() ([]byte, []int) {
+	return file_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DatasetInfo) GetCommunities() int32 {
+	if x != nil {
+		return x.Communities
+	}
+	return 0
+}
+
+func (x *DatasetInfo) GetLoadedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LoadedAt
+	}
+	return nil
+}
+
+var File_service_proto protoreflect.FileDescriptor
+
+const file_service_proto_rawDesc = "" +
+	"\n" +
+	"\rservice.proto\x12\anfip.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\n" +
+	"nfip.proto\"'\n" +
+	"\x13GetCommunityRequest\x12\x10\n" +
+	"\x03cid\x18\x01 \x01(\x05R\x03cid\"F\n" +
+	"\x18SearchCommunitiesRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"\\\n" +
+	"\fSearchResult\x126\n" +
+	"\tcommunity\x18\x01 \x01(\v2\x18.nfip.v1.CommunityStatusR\tcommunity\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x01R\x05score\"\x17\n" +
+	"\x15GetDatasetInfoRequest\"h\n" +
+	"\vDatasetInfo\x12 \n" +
+	"\vcommunities\x18\x01 \x01(\x05R\vcommunities\x127\n" +
+	"\tloaded_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\bloadedAt2\xf9\x01\n" +
+	"\x16CommunityStatusService\x12F\n" +
+	"\fGetCommunity\x12\x1c.nfip.v1.GetCommunityRequest\x1a\x18.nfip.v1.CommunityStatus\x12O\n" +
+	"\x11SearchCommunities\x12!.nfip.v1.SearchCommunitiesRequest\x1a\x15.nfip.v1.SearchResult0\x01\x12F\n" +
+	"\x0eGetDatasetInfo\x12\x1e.nfip.v1.GetDatasetInfoRequest\x1a\x14.nfip.v1.DatasetInfoB\x18Z\x16nfip-community-book/pbb\x06proto3"
+
+var (
+	file_service_proto_rawDescOnce sync.Once
+	file_service_proto_rawDescData []byte
+)
+
+func file_service_proto_rawDescGZIP() []byte {
+	file_service_proto_rawDescOnce.Do(func() {
+		file_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_service_proto_rawDesc), len(file_service_proto_rawDesc)))
+	})
+	return file_service_proto_rawDescData
+}
+
+var file_service_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_service_proto_goTypes = []any{
+	(*GetCommunityRequest)(nil),      // 0: nfip.v1.GetCommunityRequest
+	(*SearchCommunitiesRequest)(nil), // 1: nfip.v1.SearchCommunitiesRequest
+	(*SearchResult)(nil),             // 2: nfip.v1.SearchResult
+	(*GetDatasetInfoRequest)(nil),    // 3: nfip.v1.GetDatasetInfoRequest
+	(*DatasetInfo)(nil),              // 4: nfip.v1.DatasetInfo
+	(*CommunityStatus)(nil),          // 5: nfip.v1.CommunityStatus
+	(*timestamppb.Timestamp)(nil),    // 6: google.protobuf.Timestamp
+}
+var file_service_proto_depIdxs = []int32{
+	5, // 0: nfip.v1.SearchResult.community:type_name -> nfip.v1.CommunityStatus
+	6, // 1: nfip.v1.DatasetInfo.loaded_at:type_name -> google.protobuf.Timestamp
+	0, // 2: nfip.v1.CommunityStatusService.GetCommunity:input_type -> nfip.v1.GetCommunityRequest
+	1, // 3: nfip.v1.CommunityStatusService.SearchCommunities:input_type -> nfip.v1.SearchCommunitiesRequest
+	3, // 4: nfip.v1.CommunityStatusService.GetDatasetInfo:input_type -> nfip.v1.GetDatasetInfoRequest
+	5, // 5: nfip.v1.CommunityStatusService.GetCommunity:output_type -> nfip.v1.CommunityStatus
+	2, // 6: nfip.v1.CommunityStatusService.SearchCommunities:output_type -> nfip.v1.SearchResult
+	4, // 7: nfip.v1.CommunityStatusService.GetDatasetInfo:output_type -> nfip.v1.DatasetInfo
+	5, // [5:8] is the sub-list for method output_type
+	2, // [2:5] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_service_proto_init() }
+func file_service_proto_init() {
+	if File_service_proto != nil {
+		return
+	}
+	file_nfip_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_service_proto_rawDesc), len(file_service_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_service_proto_goTypes,
+		DependencyIndexes: file_service_proto_depIdxs,
+		MessageInfos:      file_service_proto_msgTypes,
+	}.Build()
+	File_service_proto = out.File
+	file_service_proto_goTypes = nil
+	file_service_proto_depIdxs = nil
+}
@@ -0,0 +1,213 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: service.proto
+
+// A service for looking up and searching the communities, for services
+// that would rather use gRPC than the REST API.
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CommunityStatusService_GetCommunity_FullMethodName      = "/nfip.v1.CommunityStatusService/GetCommunity"
+	CommunityStatusService_SearchCommunities_FullMethodName = "/nfip.v1.CommunityStatusService/SearchCommunities"
+	CommunityStatusService_GetDatasetInfo_FullMethodName    = "/nfip.v1.CommunityStatusService/GetDatasetInfo"
+)
+
+// CommunityStatusServiceClient is the client API for CommunityStatusService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CommunityStatusServiceClient interface {
+	// GetCommunity finds the community with the CID, or returns NOT_FOUND.
+	GetCommunity(ctx context.Context, in *GetCommunityRequest, opts ...grpc.CallOption) (*CommunityStatus, error)
+	// SearchCommunities sends the communities that match the query one at
+	// a time, with the best matches first.
+	SearchCommunities(ctx context.Context, in *SearchCommunitiesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SearchResult], error)
+	// GetDatasetInfo describes the status book that's being served.
+	GetDatasetInfo(ctx context.Context, in *GetDatasetInfoRequest, opts ...grpc.CallOption) (*DatasetInfo, error)
+}
+
+type communityStatusServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCommunityStatusServiceClient(cc grpc.ClientConnInterface) CommunityStatusServiceClient {
+	return &communityStatusServiceClient{cc}
+}
+
+func (c *communityStatusServiceClient) GetCommunity(ctx context.Context, in *GetCommunityRequest, opts ...grpc.CallOption) (*CommunityStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CommunityStatus)
+	err := c.cc.Invoke(ctx, CommunityStatusService_GetCommunity_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *communityStatusServiceClient) SearchCommunities(ctx context.Context, in *SearchCommunitiesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SearchResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CommunityStatusService_ServiceDesc.Streams[0], CommunityStatusService_SearchCommunities_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SearchCommunitiesRequest, SearchResult]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CommunityStatusService_SearchCommunitiesClient = grpc.ServerStreamingClient[SearchResult]
+
+func (c *communityStatusServiceClient) GetDatasetInfo(ctx context.Context, in *GetDatasetInfoRequest, opts ...grpc.CallOption) (*DatasetInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DatasetInfo)
+	err := c.cc.Invoke(ctx, CommunityStatusService_GetDatasetInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CommunityStatusServiceServer is the server API for CommunityStatusService service.
+// All implementations must embed UnimplementedCommunityStatusServiceServer
+// for forward compatibility.
+type CommunityStatusServiceServer interface {
+	// GetCommunity finds the community with the CID, or returns NOT_FOUND.
+	GetCommunity(context.Context, *GetCommunityRequest) (*CommunityStatus, error)
+	// SearchCommunities sends the communities that match the query one at
+	// a time, with the best matches first.
+	SearchCommunities(*SearchCommunitiesRequest, grpc.ServerStreamingServer[SearchResult]) error
+	// GetDatasetInfo describes the status book that's being served.
+	GetDatasetInfo(context.Context, *GetDatasetInfoRequest) (*DatasetInfo, error)
+	mustEmbedUnimplementedCommunityStatusServiceServer()
+}
+
+// UnimplementedCommunityStatusServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCommunityStatusServiceServer struct{}
+
+func (UnimplementedCommunityStatusServiceServer) GetCommunity(context.Context, *GetCommunityRequest) (*CommunityStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCommunity not implemented")
+}
+func (UnimplementedCommunityStatusServiceServer) SearchCommunities(*SearchCommunitiesRequest, grpc.ServerStreamingServer[SearchResult]) error {
+	return status.Errorf(codes.Unimplemented, "method SearchCommunities not implemented")
+}
+func (UnimplementedCommunityStatusServiceServer) GetDatasetInfo(context.Context, *GetDatasetInfoRequest) (*DatasetInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDatasetInfo not implemented")
+}
+func (UnimplementedCommunityStatusServiceServer) mustEmbedUnimplementedCommunityStatusServiceServer() {
+}
+func (UnimplementedCommunityStatusServiceServer) testEmbeddedByValue() {}
+
+// UnsafeCommunityStatusServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CommunityStatusServiceServer will
+// result in compilation errors.
+type UnsafeCommunityStatusServiceServer interface {
+	mustEmbedUnimplementedCommunityStatusServiceServer()
+}
+
+func RegisterCommunityStatusServiceServer(s grpc.ServiceRegistrar, srv CommunityStatusServiceServer) {
+	// If the following call pancis, it indicates UnimplementedCommunityStatusServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CommunityStatusService_ServiceDesc, srv)
+}
+
+func _CommunityStatusService_GetCommunity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCommunityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommunityStatusServiceServer).GetCommunity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CommunityStatusService_GetCommunity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommunityStatusServiceServer).GetCommunity(ctx, req.(*GetCommunityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CommunityStatusService_SearchCommunities_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SearchCommunitiesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CommunityStatusServiceServer).SearchCommunities(m, &grpc.GenericServerStream[SearchCommunitiesRequest, SearchResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CommunityStatusService_SearchCommunitiesServer = grpc.ServerStreamingServer[SearchResult]
+
+func _CommunityStatusService_GetDatasetInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDatasetInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommunityStatusServiceServer).GetDatasetInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CommunityStatusService_GetDatasetInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommunityStatusServiceServer).GetDatasetInfo(ctx, req.(*GetDatasetInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CommunityStatusService_ServiceDesc is the grpc.ServiceDesc for CommunityStatusService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CommunityStatusService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "nfip.v1.CommunityStatusService",
+	HandlerType: (*CommunityStatusServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetCommunity",
+			Handler:    _CommunityStatusService_GetCommunity_Handler,
+		},
+		{
+			MethodName: "GetDatasetInfo",
+			Handler:    _CommunityStatusService_GetDatasetInfo_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SearchCommunities",
+			Handler:       _CommunityStatusService_SearchCommunities_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "service.proto",
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+	"os/exec"
+
+	"nfip-community-book/data"
+)
+
+// PluginPrefix is prepended to a subcommand's name to find the
+// executable that implements it (e.g. "nfip territory" runs "nfip-territory").
+const PluginPrefix = "nfip-"
+
+// Plugins find the cached Community Status Book through this
+// environment variable so they don't have to download it themselves.
+const PluginStatusBookEnv = "NFIP_STATUS_BOOK"
+
+// runPlugin looks up the executable for the subcommand on the PATH and runs it
+// with the remaining arguments, returning the exit code it should exit with.
+// A command without one is a usage error, the same as an unknown flag.
+func runPlugin(l *log.Logger, name string, args []string) int {
+	path, err := exec.LookPath(PluginPrefix + name)
+	if err != nil {
+		l.Printf("Unknown command \"%s\": no %s%s executable found on the PATH\n", name, PluginPrefix, name)
+		return exitUsage
+	}
+
+	statusBook, err := data.EnsureNFIPCommunityStatusBook(l)
+	if err != nil {
+		l.Println("** Err -", err)
+		return exitError
+	}
+
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), PluginStatusBookEnv+"="+statusBook)
+
+	err = cmd.Run()
+
+	// Pass the plugin's exit code through as our own
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	} else if err != nil {
+		l.Println("** Err -", err)
+		return exitError
+	}
+
+	return exitOK
+}
@@ -0,0 +1,112 @@
+// Package problem writes the API's errors as problem details, from RFC 7807,
+// so that programs can tell what went wrong from a code rather than by
+// reading the message, which is only for people.
+//
+//	{
+//	  "type": "about:blank",
+//	  "title": "Not Found",
+//	  "status": 404,
+//	  "detail": "no community with the CID 999999",
+//	  "instance": "/v1/communities/999999",
+//	  "code": "community_not_found",
+//	  "request_id": "4f9c2a1e7b3d5c60",
+//	  "error": "no community with the CID 999999"
+//	}
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const ContentType = "application/problem+json"
+
+// A Code is what went wrong. Messages can change, but codes can't, so
+// they're what programs should look at.
+type Code string
+
+const (
+	// A parameter couldn't be parsed, like a limit that isn't a number
+	InvalidParameter Code = "invalid_parameter"
+	// A parameter that's needed wasn't given, like a search's term
+	MissingParameter Code = "missing_parameter"
+	// A query in the status book's query language couldn't be parsed
+	InvalidQuery Code = "invalid_query"
+	// The request's body couldn't be parsed, or there's too much of it
+	InvalidBody Code = "invalid_body"
+	// None of the formats in Accept can be returned
+	NotAcceptable Code = "not_acceptable"
+	// The endpoint doesn't take the request's method
+	MethodNotAllowed Code = "method_not_allowed"
+	// There isn't anything at the path
+	NotFound Code = "not_found"
+	// There isn't a community with the CID
+	CommunityNotFound Code = "community_not_found"
+	// The API key is missing or isn't one of the server's
+	Unauthorized Code = "unauthorized"
+	// The API key can't be used for the endpoint
+	Forbidden Code = "forbidden"
+	// The client has made too many requests, see Retry-After
+	RateLimited Code = "rate_limited"
+	// A refresh is already running
+	RefreshRunning Code = "refresh_running"
+	// The server can't refresh the communities
+	RefreshUnavailable Code = "refresh_unavailable"
+	// No communities have been loaded yet
+	NotReady Code = "not_ready"
+	// The server is shutting down
+	ShuttingDown Code = "shutting_down"
+	// Something went wrong on the server
+	Internal Code = "internal_error"
+)
+
+// Codes is every code, for describing them.
+func Codes() []Code {
+	return []Code{
+		InvalidParameter, MissingParameter, InvalidQuery, InvalidBody, NotAcceptable, MethodNotAllowed,
+		NotFound, CommunityNotFound, Unauthorized, Forbidden, RateLimited, RefreshRunning,
+		RefreshUnavailable, NotReady, ShuttingDown, Internal,
+	}
+}
+
+// A Problem is the details of an error. Its type is always about:blank,
+// which means that its title is just the status's, and the code is what
+// tells problems apart.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      Code   `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+
+	// Error is the same as the detail, for the clients from before errors
+	// were problems, which only had this.
+	Error string `json:"error,omitempty"`
+}
+
+// New is the problem with the status for the request. Its request ID is
+// the one in the response's X-Request-ID header, if it's been set.
+func New(rw http.ResponseWriter, r *http.Request, status int, code Code, detail string) Problem {
+	return Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Code:      code,
+		RequestID: rw.Header().Get("X-Request-ID"),
+		Error:     detail,
+	}
+}
+
+// Write writes the problem out with its status.
+func Write(rw http.ResponseWriter, r *http.Request, status int, code Code, detail string) error {
+	p := New(rw, r, status, code, detail)
+
+	rw.Header().Set("Content-Type", ContentType)
+	rw.WriteHeader(status)
+
+	return json.NewEncoder(rw).Encode(p)
+}
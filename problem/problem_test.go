@@ -0,0 +1,41 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/v1/communities/999999", nil)
+	rw := httptest.NewRecorder()
+	rw.Header().Set("X-Request-ID", "support-ticket-42")
+
+	if err := Write(rw, r, http.StatusNotFound, CommunityNotFound, "no community with the CID 999999"); err != nil {
+		t.Fatalf("expected the problem to be written, got %s", err)
+	}
+
+	if rw.Code != http.StatusNotFound || rw.Header().Get("Content-Type") != ContentType {
+		t.Errorf("expected a 404 problem, got %d %s", rw.Code, rw.Header().Get("Content-Type"))
+	}
+
+	var p Problem
+	if err := json.Unmarshal(rw.Body.Bytes(), &p); err != nil {
+		t.Fatalf("expected the problem as JSON, got %s", rw.Body)
+	}
+
+	expected := Problem{
+		Type:      "about:blank",
+		Title:     "Not Found",
+		Status:    http.StatusNotFound,
+		Detail:    "no community with the CID 999999",
+		Instance:  "/v1/communities/999999",
+		Code:      CommunityNotFound,
+		RequestID: "support-ticket-42",
+		Error:     "no community with the CID 999999",
+	}
+	if p != expected {
+		t.Errorf("expected %+v, got %+v", expected, p)
+	}
+}
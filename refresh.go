@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+func (c *cli) refreshCommand() *cobra.Command {
+	var rules string
+
+	cmd := &cobra.Command{
+		Use:   "refresh",
+		Short: "Download the status book again and save it",
+		Long: `Download the status book again, and swap it in for the cached one and save
+it to the store and the history if they're set, the same as the API's
+POST /admin/refresh. It has to pass the rules first, or nothing is changed
+and it exits with 4.
+
+The rules are read from NFIP_REFRESH_RULES if they aren't given.`,
+		Example: "  nfip refresh --rules min-records=20000,max-record-loss=1",
+		Args:    checkArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("rules") {
+				rules = os.Getenv(RefreshRulesEnv)
+			}
+
+			return c.runRefresh(cmd.Context(), rules)
+		},
+	}
+
+	cmd.Flags().StringVar(&rules, "rules", "", "the rules that the status book has to pass, like min-records=20000,max-state-loss=2")
+	return cmd
+}
+
+func (c *cli) runRefresh(ctx context.Context, spec string) error {
+	if c.demo {
+		return usageError(errors.New("the demo's communities can't be refreshed"))
+	}
+
+	rules, err := data.ParseRefreshRules(spec)
+	if err != nil {
+		return usageError(err)
+	}
+
+	current, err := loadCommunities(c.l)
+	if err != nil {
+		return err
+	}
+
+	next, err := refreshCommunities(c.l, rules)(ctx, current)
+	if errors.Is(err, data.ErrRefreshRejected) {
+		return commandError{exitInvalid, err}
+	} else if err != nil {
+		return err
+	}
+
+	changes, err := store.Diff(current, next)
+	if err != nil {
+		return err
+	}
+
+	c.l.Printf("Refreshed the communities: %d added, %d updated, %d removed\n", changes.Added, changes.Updated, changes.Removed)
+	return nil
+}
@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+// Refreshes that get as far as downloading the status book can't be run
+// here, so these are the ones that are turned away before then.
+func TestRefreshCommand(t *testing.T) {
+	for _, tc := range []commandTest{
+		{name: "demo", args: []string{"--demo", "refresh"}, code: exitUsage},
+		{name: "invalid rules", args: []string{"refresh", "--rules", "min-records=lots"}, code: exitUsage},
+		{name: "unknown rule", args: []string{"refresh", "--rules", "bogus=1"}, code: exitUsage},
+		{name: "arguments", args: []string{"refresh", "now"}, code: exitUsage},
+	} {
+		t.Run(tc.name, tc.run)
+	}
+}
+
+func TestRefreshRulesEnv(t *testing.T) {
+	t.Setenv(RefreshRulesEnv, "min-records=lots")
+
+	if _, code := runCommand(t, "refresh"); code != exitUsage {
+		t.Errorf("expected the rules in %s to be checked, got %d", RefreshRulesEnv, code)
+	}
+}
@@ -0,0 +1,131 @@
+// Package report renders the communities as an HTML page that can be
+// shared with people who'd rather not read JSON or open a spreadsheet.
+package report
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+	"math"
+	"time"
+
+	"nfip-community-book/data"
+)
+
+//go:embed report.html
+var reportHTML string
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"date": formatDate,
+}).Parse(reportHTML))
+
+// How big the CRS chart is drawn, in pixels.
+const (
+	chartBarWidth  = 40
+	chartBarGap    = 12
+	chartHeight    = 160
+	chartLabelSize = 20
+)
+
+type report struct {
+	Title     string
+	Generated string
+
+	Communities   data.NFIPCommunityStatuses
+	Participating int
+	Rate          float64
+	CRS           int
+
+	States []stateSummary
+	Chart  chart
+}
+
+type stateSummary struct {
+	data.StateParticipation
+	CRS int
+}
+
+type chart struct {
+	Width  int
+	Height int
+	Bars   []bar
+}
+
+// A bar is one CRS class in the chart, already laid out.
+type bar struct {
+	data.CRSClassCount
+	X, Y, Height int
+}
+
+// WriteHTML writes a page with a summary of each state, a chart of how
+// many communities are in each CRS class, and a table of the communities
+// that can be sorted by clicking on its headers. Everything it needs is
+// in the page, so it can be emailed or opened without a connection.
+func WriteHTML(w io.Writer, title string, c data.NFIPCommunityStatuses) error {
+	r := report{
+		Title:       title,
+		Generated:   time.Now().Format("January 2, 2006"),
+		Communities: c,
+		Chart:       newChart(c.CountByCRSClass()),
+	}
+
+	crsByState := make(map[string]int)
+	for _, community := range c {
+		if community.ParticipatingCommunity {
+			r.Participating++
+		}
+		if community.IsCRSParticipant() {
+			r.CRS++
+			crsByState[community.State]++
+		}
+	}
+
+	if len(c) > 0 {
+		r.Rate = math.Round(float64(r.Participating)/float64(len(c))*1000) / 10
+	}
+
+	for _, sp := range c.ParticipationRateByState() {
+		r.States = append(r.States, stateSummary{sp, crsByState[sp.State]})
+	}
+
+	return reportTemplate.Execute(w, r)
+}
+
+// newChart lays out a bar for each class, scaled to the biggest one.
+func newChart(counts []data.CRSClassCount) chart {
+	most := 0
+	for _, count := range counts {
+		if count.Count > most {
+			most = count.Count
+		}
+	}
+
+	ch := chart{
+		Width:  len(counts)*(chartBarWidth+chartBarGap) + chartBarGap,
+		Height: chartHeight + 2*chartLabelSize,
+	}
+
+	for i, count := range counts {
+		height := 0
+		if most > 0 {
+			height = count.Count * chartHeight / most
+		}
+
+		ch.Bars = append(ch.Bars, bar{
+			CRSClassCount: count,
+			X:             chartBarGap + i*(chartBarWidth+chartBarGap),
+			Y:             chartLabelSize + chartHeight - height,
+			Height:        height,
+		})
+	}
+
+	return ch
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format("2006-01-02")
+}
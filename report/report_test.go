@@ -0,0 +1,58 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"nfip-community-book/data"
+)
+
+func TestWriteHTML(t *testing.T) {
+	communities, err := data.GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("expected the demo status book to be parsed, got %s", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, "Demo Status Book", communities); err != nil {
+		t.Fatalf("expected a report, got %s", err)
+	}
+
+	page := buf.String()
+	for _, expected := range []string{"<title>Demo Status Book</title>", "<svg", "SPRINGFIELD, CITY OF", "<td>IL</td>"} {
+		if !strings.Contains(page, expected) {
+			t.Errorf("expected the report to have %s", expected)
+		}
+	}
+
+	if rows := strings.Count(page, "<td>Yes</td>") + strings.Count(page, "<td>No</td>"); rows != len(communities) {
+		t.Errorf("expected a row for each of the %d communities, got %d", len(communities), rows)
+	}
+}
+
+func TestWriteHTMLEscapes(t *testing.T) {
+	communities := data.NFIPCommunityStatuses{
+		{CID: 1, CommunityName: "<script>alert(1)</script>", State: "TX"},
+	}
+
+	var buf bytes.Buffer
+	WriteHTML(&buf, "Escaped", communities)
+
+	if strings.Contains(buf.String(), "<script>alert(1)") {
+		t.Errorf("expected the community's name to be escaped")
+	}
+}
+
+func TestNewChart(t *testing.T) {
+	counts := []data.CRSClassCount{{Class: 1, Count: 0}, {Class: 2, Count: 5}, {Class: 3, Count: 10}}
+
+	ch := newChart(counts)
+	if len(ch.Bars) != 3 {
+		t.Fatalf("expected a bar for each class, got %d", len(ch.Bars))
+	}
+
+	if ch.Bars[2].Height != chartHeight || ch.Bars[1].Height != chartHeight/2 || ch.Bars[0].Height != 0 {
+		t.Errorf("expected the bars to be scaled to the biggest class, got %v", ch.Bars)
+	}
+}
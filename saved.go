@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"nfip-community-book/data"
+)
+
+// Saved queries are loaded from this file if it's set,
+// and from data.SavedQueriesFilename if it isn't.
+const SavedQueriesEnv = "NFIP_QUERIES"
+
+func (c *cli) queryCommand() *cobra.Command {
+	var tmpl string
+
+	cmd := &cobra.Command{
+		Use:   "query [name]",
+		Short: "Run a saved query, or list them",
+		Long: `Run the saved query with the name and write the communities it finds, or
+with the template. Without a name, it lists the saved queries.
+
+Saved queries are read from NFIP_QUERIES, or queries.conf if it isn't set.`,
+		Example: `  nfip query gulf-coast-crs
+  nfip query --template '{{.CID}} {{.CommunityName}}' gulf-coast-crs`,
+		Args: checkArgs(cobra.MaximumNArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.runSavedQuery(args, tmpl)
+		},
+	}
+
+	cmd.Flags().StringVar(&tmpl, "template", "", "a Go template to write each community with, instead of JSON")
+	return cmd
+}
+
+// runSavedQuery runs the saved query that's named in the arguments and
+// writes the communities it finds to stdout, or with the template.
+// Without a name, it lists the saved queries.
+func (c *cli) runSavedQuery(args []string, tmpl string) error {
+	filename := os.Getenv(SavedQueriesEnv)
+	if len(filename) == 0 {
+		filename = data.SavedQueriesFilename
+	}
+
+	queries, err := data.LoadSavedQueries(filename)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		rows := [][]string{{"NAME", "QUERY"}}
+		for _, q := range queries {
+			rows = append(rows, []string{q.Name, q.Query})
+		}
+		return c.write(queries, rows)
+	}
+
+	if _, ok := queries.Get(args[0]); !ok {
+		return notFound("no saved query named %s in %s", strconv.Quote(args[0]), filename)
+	}
+
+	cb, err := c.communities()
+	if err != nil {
+		return err
+	}
+
+	communityStatuses, err := queries.Run(args[0], cb)
+	if err != nil {
+		return err
+	}
+
+	if len(tmpl) > 0 {
+		return communityStatuses.ToTemplate(os.Stdout, tmpl)
+	}
+	return c.writeCommunities(nil, *communityStatuses)
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+// How many results search writes if a limit isn't given.
+const defaultSearchLimit = 20
+
+func (c *cli) searchCommand() *cobra.Command {
+	var (
+		filters filterFlags
+		limit   int
+		fuzzy   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "search <term>...",
+		Short: "Search the communities by name, county or CID",
+		Long: `Search the communities by name, county or CID, and write the best matches
+first. The words in the term can be given as separate arguments, and the
+scores and what matched are written too in JSON, NDJSON and YAML.
+
+With --fuzzy, names that are spelled a little differently match too, and
+how close they were is in the score.`,
+		Example: `  nfip search houston
+  nfip search --state TX --limit 5 harris county
+  nfip search --fuzzy pittsburg`,
+		Args: checkArgs(cobra.MinimumNArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if limit < 0 {
+				return usageError(fmt.Errorf("invalid limit %d, it can't be negative", limit))
+			}
+
+			return c.runSearch(strings.Join(args, " "), &filters, limit, fuzzy)
+		},
+	}
+
+	filters.add(cmd)
+	cmd.Flags().IntVarP(&limit, "limit", "n", defaultSearchLimit, "how many results to write at most, or 0 for all of them")
+	cmd.Flags().BoolVar(&fuzzy, "fuzzy", false, "match names that are spelled a little differently")
+	return cmd
+}
+
+// runSearch writes the communities that best match the term. They're
+// searched in the store, except for fuzzy searches, which only work on
+// the communities themselves.
+func (c *cli) runSearch(term string, filters *filterFlags, limit int, fuzzy bool) error {
+	fs, err := filters.filters()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	s, err := c.communityStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeStore(s)
+
+	var results data.SearchResults
+	if fuzzy {
+		communities, err := s.Load(ctx)
+		if err != nil {
+			return err
+		}
+		results = communities.FuzzySearch(term)
+	} else {
+		results, err = s.Search(ctx, term)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(results) == 0 {
+		c.suggest(ctx, s, term)
+	}
+
+	// A community's score doesn't depend on the others, so filtering
+	// the results is the same as searching what passes the filters
+	results = filterResults(results, fs)
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return c.writeCommunities(results, results.Communities())
+}
+
+// suggest logs the names that were probably meant when searching for the
+// term didn't find anything, rather than only writing nothing.
+func (c *cli) suggest(ctx context.Context, s store.Store, term string) {
+	var suggestions []string
+	if snapshot, ok := s.(store.Snapshot); ok {
+		suggestions = snapshot.Index().DidYouMean(term, data.DefaultSuggestions)
+	} else if communities, err := s.Load(ctx); err == nil {
+		suggestions = communities.DidYouMean(term, data.DefaultSuggestions)
+	}
+
+	if len(suggestions) > 0 {
+		c.stderr().Printf("Nothing found for \"%s\", did you mean %s?\n", term, strings.Join(suggestions, "; "))
+	}
+}
+
+func (c *cli) getCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "get <cid>",
+		Short:   "Write the community with the CID",
+		Long:    "Write the community with the CID. It exits with 3 if there isn't one.",
+		Example: "  nfip get 480296",
+		Args:    checkArgs(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.runGet(args[0])
+		},
+	}
+}
+
+func (c *cli) runGet(cidString string) error {
+	cid, err := parseCID(cidString)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	s, err := c.communityStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeStore(s)
+
+	community, ok, err := s.Get(ctx, cid)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return notFound("no community with the CID %d", cid)
+	}
+
+	return c.writeCommunities(community, data.NFIPCommunityStatuses{community})
+}
+
+// filterResults is the results with communities that pass every filter.
+func filterResults(results data.SearchResults, filters []data.Filter) data.SearchResults {
+	if len(filters) == 0 {
+		return results
+	}
+
+	keep := data.All(filters...)
+	kept := data.SearchResults{}
+	for _, r := range results {
+		if keep(r.Community) {
+			kept = append(kept, r)
+		}
+	}
+
+	return kept
+}
+
+// parseCID reads a CID from the arguments.
+func parseCID(s string) (int, error) {
+	cid, err := strconv.Atoi(s)
+	if err != nil || cid <= 0 {
+		return 0, usageError(fmt.Errorf("invalid CID %s, it has to be a number like 480296", strconv.Quote(s)))
+	}
+
+	return cid, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSearchCommand(t *testing.T) {
+	for _, tc := range []commandTest{
+		{name: "search", args: []string{"--demo", "search", "houston"}, code: exitOK, contains: []string{`"community_name": "HOUSTON, CITY OF"`, `"score"`}},
+		{name: "words", args: []string{"--demo", "search", "harris", "county", "-o", "csv"}, code: exitOK, contains: []string{`="480287",HARRIS COUNTY *`}},
+		{name: "state", args: []string{"--demo", "search", "--state", "LA", "houston", "-o", "csv"}, code: exitOK, excludes: []string{"480296"}},
+		{name: "fuzzy", args: []string{"--demo", "search", "--fuzzy", "huston", "-o", "table"}, code: exitOK, contains: []string{"HOUSTON, CITY OF"}},
+		{name: "unknown state", args: []string{"--demo", "search", "--state", "XX", "houston"}, code: exitUsage},
+		{name: "invalid query", args: []string{"--demo", "search", "-q", "county:", "houston"}, code: exitUsage},
+		{name: "negative limit", args: []string{"--demo", "search", "-n", "-1", "houston"}, code: exitUsage},
+		{name: "no term", args: []string{"--demo", "search"}, code: exitUsage},
+	} {
+		t.Run(tc.name, tc.run)
+	}
+}
+
+func TestSearchLimit(t *testing.T) {
+	for _, tc := range []struct {
+		limit string
+		lines int
+	}{
+		{"1", 1},
+		{"2", 2},
+		{"0", 29},
+	} {
+		out, code := runCommand(t, "--demo", "search", "-n", tc.limit, "-o", "ndjson", "county")
+		if lines := strings.Count(out, "\n"); code != exitOK || lines != tc.lines {
+			t.Errorf("expected %d results with a limit of %s, got %d", tc.lines, tc.limit, lines)
+		}
+	}
+}
+
+func TestSearchDidYouMean(t *testing.T) {
+	out, errOut, code := runCommandWithStderr(t, "--demo", "search", "-o", "ndjson", "pulasky")
+	if code != exitOK || len(out) > 0 || !strings.Contains(errOut, "did you mean PULASKI COUNTY") {
+		t.Errorf("expected Pulaski County to be suggested, got %d %q %q", code, out, errOut)
+	}
+
+	_, errOut, _ = runCommandWithStderr(t, "--demo", "search", "houston")
+	if strings.Contains(errOut, "did you mean") {
+		t.Errorf("expected nothing to be suggested when something's found, got %q", errOut)
+	}
+}
+
+func TestGetCommand(t *testing.T) {
+	for _, tc := range []commandTest{
+		{name: "get", args: []string{"--demo", "get", "480296"}, code: exitOK, contains: []string{`"cid": 480296`}},
+		{name: "csv", args: []string{"--demo", "get", "-o", "csv", "480296"}, code: exitOK, contains: []string{`="480296","HOUSTON, CITY OF"`}},
+		{name: "invalid CID", args: []string{"--demo", "get", "abc"}, code: exitUsage},
+		{name: "unknown CID", args: []string{"--demo", "get", "1"}, code: exitNotFound},
+		{name: "no CID", args: []string{"--demo", "get"}, code: exitUsage},
+		{name: "two CIDs", args: []string{"--demo", "get", "480296", "480287"}, code: exitUsage},
+	} {
+		t.Run(tc.name, tc.run)
+	}
+}
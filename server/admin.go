@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"nfip-community-book/data"
+	"nfip-community-book/problem"
+	"nfip-community-book/store"
+)
+
+// A Refresher gets the newest communities, like by downloading the status
+// book again. It's given the ones that are in the store now, so that it can
+// turn down a status book that looks broken.
+type Refresher func(ctx context.Context, current data.NFIPCommunityStatuses) (data.NFIPCommunityStatuses, error)
+
+// WithRefresher lets admins refresh the communities from the API, with
+// POST /admin/refresh, rather than restarting the server.
+func WithRefresher(r Refresher) Option {
+	return func(s *Server) {
+		s.refresher = r
+	}
+}
+
+// The stages of a refresh that's running.
+const (
+	stageDownloading = "downloading"
+	stageIndexing    = "indexing"
+)
+
+// refreshStatus is how the last refresh went, and how far along the one
+// that's running is, if there is one.
+type refreshStatus struct {
+	Running     bool           `json:"running"`
+	Stage       string         `json:"stage,omitempty"`
+	StartedAt   *time.Time     `json:"started_at,omitempty"`
+	LastSuccess *time.Time     `json:"last_success,omitempty"`
+	LastChanges *store.Changes `json:"last_changes,omitempty"`
+	LastError   string         `json:"last_error,omitempty"`
+	LastErrorAt *time.Time     `json:"last_error_at,omitempty"`
+}
+
+// refreshState is the status of the refreshes, which only run one at a time.
+// Finished is closed once the refresh that's running is done, and no more
+// can be started once it's stopped.
+type refreshState struct {
+	mu       sync.Mutex
+	status   refreshStatus
+	finished chan struct{}
+	stopped  bool
+}
+
+func (rs *refreshState) get() refreshStatus {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	return rs.status
+}
+
+func (rs *refreshState) update(f func(*refreshStatus)) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	f(&rs.status)
+}
+
+// start marks a refresh as running, unless one already is or they've
+// been stopped.
+func (rs *refreshState) start() bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.status.Running || rs.stopped {
+		return false
+	}
+
+	now := time.Now()
+	rs.status.Running, rs.status.Stage, rs.status.StartedAt = true, stageDownloading, &now
+	rs.finished = make(chan struct{})
+	return true
+}
+
+// finish marks the refresh that's running as done.
+func (rs *refreshState) finish(f func(*refreshStatus)) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.status.Running, rs.status.Stage = false, ""
+	f(&rs.status)
+	close(rs.finished)
+}
+
+// stop keeps any more refreshes from starting, and returns a channel that's
+// closed when the one that's running is done, or nil if none is.
+func (rs *refreshState) stop() <-chan struct{} {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.stopped = true
+	if !rs.status.Running {
+		return nil
+	}
+	return rs.finished
+}
+
+// postRefresh starts refreshing the communities and returns right away,
+// since downloading the status book takes a while. How it's going is at
+// /admin/refresh/status.
+func (s *Server) postRefresh(rw http.ResponseWriter, r *http.Request) {
+	if s.refresher == nil {
+		s.writeError(rw, r, http.StatusNotImplemented, problem.RefreshUnavailable, "refreshing isn't set up on this server")
+		return
+	}
+
+	if !s.refreshes.start() {
+		if s.isShuttingDown() {
+			s.writeError(rw, r, http.StatusServiceUnavailable, problem.ShuttingDown, "the server is shutting down")
+			return
+		}
+		s.writeError(rw, r, http.StatusConflict, problem.RefreshRunning, "a refresh is already running, see /admin/refresh/status")
+		return
+	}
+
+	s.log.InfoContext(r.Context(), "refresh started", "request_id", requestID(r.Context()))
+	// The refresh outlives the request, but it's still in its trace
+	go s.refresh(trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(r.Context())))
+
+	rw.Header().Set("Location", "/admin/refresh/status")
+	s.writeJSON(rw, http.StatusAccepted, s.refreshes.get())
+}
+
+func (s *Server) refresh(ctx context.Context) {
+	ctx, span := s.startSpan(ctx, "refresh")
+	defer span.End()
+
+	changes, err := s.runRefresh(ctx)
+	now := time.Now()
+
+	s.refreshes.finish(func(status *refreshStatus) {
+		if err != nil {
+			status.LastError, status.LastErrorAt = err.Error(), &now
+			return
+		}
+		status.LastSuccess, status.LastChanges = &now, &changes
+	})
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		s.log.Error("refresh failed", "err", err)
+		return
+	}
+	span.SetAttributes(
+		attribute.Int("nfip.added", changes.Added),
+		attribute.Int("nfip.updated", changes.Updated),
+		attribute.Int("nfip.removed", changes.Removed),
+	)
+	s.log.Info("refresh finished", "added", changes.Added, "updated", changes.Updated, "removed", changes.Removed)
+	s.events.publish(refreshEvent{Version: s.store.Version(), LoadedAt: s.store.Loaded(), Changes: changes})
+}
+
+func (s *Server) runRefresh(ctx context.Context) (store.Changes, error) {
+	next, err := s.refresher(ctx, s.store.Index().Communities())
+	if err != nil {
+		return store.Changes{}, err
+	}
+
+	s.refreshes.update(func(status *refreshStatus) {
+		status.Stage = stageIndexing
+	})
+
+	return s.store.Refresh(ctx, next)
+}
+
+func (s *Server) getRefreshStatus(rw http.ResponseWriter, r *http.Request) {
+	s.writeJSON(rw, http.StatusOK, s.refreshes.get())
+}
+
+// isAdmin is whether the path is one that only admins can use.
+func isAdmin(path string) bool {
+	return strings.HasPrefix(path, "/admin/")
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+func TestRefresh(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	memory := store.NewMemory(communities)
+
+	release := make(chan error)
+	s := New(memory, discardLogs,
+		WithAPIKeys([]APIKey{{Name: "ops", Key: "ops-key", Admin: true}, {Name: "acme", Key: "acme-key"}}),
+		WithRefresher(func(ctx context.Context, current data.NFIPCommunityStatuses) (data.NFIPCommunityStatuses, error) {
+			if err := <-release; err != nil {
+				return nil, err
+			}
+			return current[1:], nil
+		}),
+	)
+
+	admin := func(method, target, key string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, nil)
+		r.Header.Set("X-API-Key", key)
+
+		rw := httptest.NewRecorder()
+		s.ServeHTTP(rw, r)
+		return rw
+	}
+
+	status := func() refreshStatus {
+		var status refreshStatus
+		json.Unmarshal(admin(http.MethodGet, "/admin/refresh/status", "ops-key").Body.Bytes(), &status)
+		return status
+	}
+
+	// Waits for the refresh that's running to finish
+	finished := func() refreshStatus {
+		for i := 0; i < 500; i++ {
+			if status := status(); !status.Running {
+				return status
+			}
+			time.Sleep(time.Millisecond)
+		}
+		t.Fatalf("expected the refresh to finish")
+		return refreshStatus{}
+	}
+
+	if rw := admin(http.MethodPost, "/admin/refresh", "acme-key"); rw.Code != http.StatusForbidden {
+		t.Errorf("expected a 403 for a key that isn't an admin's, got %d", rw.Code)
+	}
+
+	rw := admin(http.MethodPost, "/admin/refresh", "ops-key")
+	var started refreshStatus
+	if err := json.Unmarshal(rw.Body.Bytes(), &started); rw.Code != http.StatusAccepted || err != nil || !started.Running || started.Stage != stageDownloading {
+		t.Fatalf("expected the refresh to start, got %d %s", rw.Code, rw.Body)
+	}
+
+	if rw := admin(http.MethodPost, "/admin/refresh", "ops-key"); rw.Code != http.StatusConflict {
+		t.Errorf("expected a 409 while it's running, got %d", rw.Code)
+	}
+
+	release <- nil
+	done := finished()
+	if done.LastSuccess == nil || done.LastChanges == nil || done.LastChanges.Removed != 1 || len(memory.Index().Communities()) != len(communities)-1 {
+		t.Errorf("expected a community to be removed, got %+v", done)
+	}
+
+	// A refresh that fails keeps the communities, and says why
+	admin(http.MethodPost, "/admin/refresh", "ops-key")
+	release <- errors.New("download failed")
+	failed := finished()
+	if failed.LastError != "download failed" || failed.LastErrorAt == nil || failed.LastSuccess == nil || len(memory.Index().Communities()) != len(communities)-1 {
+		t.Errorf("expected the error with the last success, got %+v", failed)
+	}
+}
+
+func TestRefreshWithoutKeys(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/admin/refresh", nil))
+	if rw.Code != http.StatusForbidden {
+		t.Errorf("expected the admin endpoints to be closed without keys, got %d", rw.Code)
+	}
+}
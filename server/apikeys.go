@@ -0,0 +1,212 @@
+package server
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"nfip-community-book/problem"
+)
+
+var ErrInvalidAPIKey = fmt.Errorf("invalid API key")
+
+// An APIKey lets a client use the API. Its name is what it's logged and
+// counted as, so that the key itself never has to be. Keys without a
+// rate limit can make as many requests as the other limits let them.
+// Only admin keys can use the /admin endpoints.
+type APIKey struct {
+	Name      string
+	Key       string
+	PerSecond float64
+	Burst     int
+	Admin     bool
+}
+
+// ParseAPIKeys reads one API key per line, as its name and then the key
+// after an "=", with a rate limit after it if it has one, and "admin" if
+// it's an admin's. Blank lines and lines starting with "#" are skipped.
+//
+//	# Acme Insurance's nightly policy batch
+//	acme = 3f9a0c4e6b1d8f27a5c3e9b0d4f6a812 100,500
+//	website = 7b2e5d1a9c8f4e3b6a0d2c7f1e9b5a48
+//	ops = 0d8c2f6a4b9e1735c0a8f2d6b4e91c37 admin
+func ParseAPIKeys(r io.Reader) ([]APIKey, error) {
+	var keys []APIKey
+	names := make(map[string]bool)
+	secrets := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, rest, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: missing \"=\" on line %d", ErrInvalidAPIKey, lineNumber)
+		}
+
+		name = strings.TrimSpace(name)
+		if len(name) == 0 || strings.ContainsAny(name, " \t\"") {
+			return nil, fmt.Errorf("%w: invalid name \"%s\" on line %d", ErrInvalidAPIKey, name, lineNumber)
+		}
+
+		fields := strings.Fields(rest)
+		if len(fields) == 0 || len(fields) > 3 {
+			return nil, fmt.Errorf("%w: %s has to be a key and maybe a rate limit and admin on line %d", ErrInvalidAPIKey, name, lineNumber)
+		}
+
+		key := APIKey{Name: name, Key: fields[0]}
+		for _, field := range fields[1:] {
+			if field == "admin" && !key.Admin {
+				key.Admin = true
+				continue
+			}
+
+			if key.PerSecond > 0 {
+				return nil, fmt.Errorf("%w: %s has more than one rate limit on line %d", ErrInvalidAPIKey, name, lineNumber)
+			}
+
+			var err error
+			key.PerSecond, key.Burst, err = ParseRateLimit(field)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s on line %d", ErrInvalidAPIKey, err, lineNumber)
+			}
+		}
+
+		if names[key.Name] {
+			return nil, fmt.Errorf("%w: %s is there more than once on line %d", ErrInvalidAPIKey, key.Name, lineNumber)
+		}
+		if secrets[key.Key] {
+			return nil, fmt.Errorf("%w: %s has the same key as another one on line %d", ErrInvalidAPIKey, key.Name, lineNumber)
+		}
+
+		names[key.Name], secrets[key.Key] = true, true
+		keys = append(keys, key)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// LoadAPIKeys reads the API keys in the file. See ParseAPIKeys.
+func LoadAPIKeys(filename string) ([]APIKey, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseAPIKeys(f)
+}
+
+// WithAPIKeys only answers requests with one of the keys, in an
+// "Authorization: Bearer <key>" or "X-API-Key: <key>" header, or a token
+// from POST /v1/tokens for /v1/events and /v1/search/live. Health
+// checks and metrics don't need one, and neither does the OpenAPI
+// document, so that clients can be made before there's a key, or the
+// page from WithUI, which asks for one.
+func WithAPIKeys(keys []APIKey) Option {
+	return func(s *Server) {
+		s.apiKeys = make(map[[sha256.Size]byte]*apiKey, len(keys))
+		for _, key := range keys {
+			k := &apiKey{name: key.Name, hash: sha256.Sum256([]byte(key.Key)), admin: key.Admin}
+			if key.PerSecond > 0 {
+				k.limiter = rate.NewLimiter(rate.Limit(key.PerSecond), key.Burst)
+			}
+			s.apiKeys[k.hash] = k
+			s.apiKeyNames = append(s.apiKeyNames, key.Name)
+		}
+	}
+}
+
+// An apiKey is a key's limit and how much it's been used. Keys are looked
+// up by their hashes, so that how long it takes to look one up doesn't
+// give away anything about the ones that are there.
+type apiKey struct {
+	name     string
+	hash     [sha256.Size]byte
+	admin    bool
+	limiter  *rate.Limiter
+	requests atomic.Int64
+	limited  atomic.Int64
+}
+
+// authenticate turns away requests without a key with a 401, and
+// requests over their key's limit with a 429. The admin endpoints need an
+// admin's key, so without any keys no one can use them.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	if s.apiKeys == nil {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			if isAdmin(r.URL.Path) {
+				s.writeError(rw, r, http.StatusForbidden, problem.Forbidden, "the admin endpoints need API keys to be set up")
+				return
+			}
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if isProbe(r.URL.Path) || isUI(r.URL.Path) || r.URL.Path == "/openapi.json" {
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		secret := r.Header.Get("X-API-Key")
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			secret = strings.TrimSpace(bearer)
+		}
+
+		key := s.keyFor(secret)
+		if len(secret) == 0 && s.tokenPaths[r.URL.Path] {
+			key = s.keyForToken(requestToken(r), time.Now())
+		}
+		if key == nil {
+			s.unauthorized.Add(1)
+			rw.Header().Set("WWW-Authenticate", `Bearer realm="nfip"`)
+			s.writeError(rw, r, http.StatusUnauthorized, problem.Unauthorized, "missing or unknown API key")
+			return
+		}
+
+		if rl, ok := r.Context().Value(requestLogKey{}).(*requestLog); ok {
+			rl.apiKey = key.name
+		}
+		key.requests.Add(1)
+
+		if isAdmin(r.URL.Path) && !key.admin {
+			s.writeError(rw, r, http.StatusForbidden, problem.Forbidden, "only admins can use "+r.URL.Path)
+			return
+		}
+
+		if key.limiter != nil {
+			now := time.Now()
+			if s.tooManyRequests(rw, r, now, key.limiter.ReserveN(now, 1)) {
+				key.limited.Add(1)
+				return
+			}
+		}
+
+		next.ServeHTTP(rw, r.WithContext(withAPIKey(r.Context(), key)))
+	})
+}
+
+// keyFor is the API key with the secret, or nil if there isn't one.
+func (s *Server) keyFor(secret string) *apiKey {
+	if len(secret) == 0 {
+		return nil
+	}
+	return s.apiKeys[sha256.Sum256([]byte(secret))]
+}
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+func TestParseAPIKeys(t *testing.T) {
+	keys, err := ParseAPIKeys(strings.NewReader(`
+# Acme's nightly batch
+acme = acme-key 100,500
+
+website=website-key
+ops = ops-key admin 5
+`))
+	if err != nil {
+		t.Fatalf("expected the keys to be parsed, got %s", err)
+	}
+
+	expected := []APIKey{{"acme", "acme-key", 100, 500, false}, {"website", "website-key", 0, 0, false}, {"ops", "ops-key", 5, 5, true}}
+	if len(keys) != len(expected) || keys[0] != expected[0] || keys[1] != expected[1] || keys[2] != expected[2] {
+		t.Errorf("expected %v, got %v", expected, keys)
+	}
+
+	for _, invalid := range []string{
+		"acme acme-key",
+		"= acme-key",
+		"acme =",
+		"acme = acme-key 10 20",
+		"acme = acme-key admin admin",
+		"acme = acme-key 10 admin 20",
+		"acme = acme-key fast",
+		"acme = one\nacme = two",
+		"acme = same\nwebsite = same",
+	} {
+		if _, err := ParseAPIKeys(strings.NewReader(invalid)); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Errorf("expected %q to be invalid, got %v", invalid, err)
+		}
+	}
+}
+
+func getWithKey(s http.Handler, target, header, value string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	if len(header) > 0 {
+		r.Header.Set(header, value)
+	}
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, r)
+	return rw
+}
+
+func TestAPIKeys(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	s := New(store.NewMemory(communities), discardLogs, WithAPIKeys([]APIKey{
+		{Name: "acme", Key: "acme-key", PerSecond: 0.01, Burst: 2},
+		{Name: "website", Key: "website-key"},
+	}))
+
+	rw := getWithKey(s, "/communities", "", "")
+	if rw.Code != http.StatusUnauthorized || len(rw.Header().Get("WWW-Authenticate")) == 0 {
+		t.Errorf("expected a 401 without a key, got %d", rw.Code)
+	}
+
+	if rw := getWithKey(s, "/communities", "Authorization", "Bearer nope"); rw.Code != http.StatusUnauthorized {
+		t.Errorf("expected a 401 for an unknown key, got %d", rw.Code)
+	}
+
+	for _, header := range []struct{ name, value string }{
+		{"Authorization", "Bearer acme-key"},
+		{"X-API-Key", "acme-key"},
+	} {
+		if rw := getWithKey(s, "/communities", header.name, header.value); rw.Code != http.StatusOK {
+			t.Errorf("expected the key in %s to be let through, got %d", header.name, rw.Code)
+		}
+	}
+
+	// Acme's burst is used up, but the website doesn't have a limit
+	if rw := getWithKey(s, "/communities", "X-API-Key", "acme-key"); rw.Code != http.StatusTooManyRequests || len(rw.Header().Get("Retry-After")) == 0 {
+		t.Errorf("expected a 429 over the key's limit, got %d", rw.Code)
+	}
+	for i := 0; i < 5; i++ {
+		if rw := getWithKey(s, "/search?q=houston", "X-API-Key", "website-key"); rw.Code != http.StatusOK {
+			t.Fatalf("expected the website to be let through, got %d", rw.Code)
+		}
+	}
+
+	for _, target := range []string{"/healthz", "/readyz", "/openapi.json"} {
+		if rw := get(s, target); rw.Code != http.StatusOK {
+			t.Errorf("expected %s without a key, got %d", target, rw.Code)
+		}
+	}
+
+	rw = get(s, "/metrics")
+	if rw.Code != http.StatusOK || rw.Header().Get("Content-Type") != metricsContentType {
+		t.Fatalf("expected the metrics, got %d %s", rw.Code, rw.Header().Get("Content-Type"))
+	}
+
+	for _, line := range []string{
+		`nfip_api_key_requests_total{key="acme"} 3`,
+		`nfip_api_key_requests_total{key="website"} 5`,
+		`nfip_api_key_rate_limited_total{key="acme"} 1`,
+		`nfip_api_key_rate_limited_total{key="website"} 0`,
+		`nfip_unauthorized_requests_total 2`,
+	} {
+		if !strings.Contains(rw.Body.String(), line+"\n") {
+			t.Errorf("expected %s in the metrics, got\n%s", line, rw.Body)
+		}
+	}
+
+	if strings.Contains(rw.Body.String(), "acme-key") {
+		t.Errorf("expected the keys themselves to never be shown")
+	}
+}
+
+func TestAPIKeysHandlers(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	legacy := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {})
+	s := New(store.NewMemory(communities), discardLogs, WithAPIKeys([]APIKey{{Name: "website", Key: "website-key"}}), WithHandler("/status", legacy))
+
+	if rw := get(s, "/status?search=houston"); rw.Code != http.StatusUnauthorized {
+		t.Errorf("expected a 401 without a key, got %d", rw.Code)
+	}
+
+	if rw := getWithKey(s, "/status?search=houston", "X-API-Key", "website-key"); rw.Code != http.StatusOK {
+		t.Errorf("expected the key to be let through, got %d", rw.Code)
+	}
+}
+
+func TestAPIKeysGRPC(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	s := New(store.NewMemory(communities), discardLogs, WithAPIKeys([]APIKey{
+		{Name: "acme", Key: "acme-key", PerSecond: 0.01, Burst: 1},
+		{Name: "website", Key: "website-key"},
+	}))
+
+	unary := s.UnaryInterceptor()
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	withKey := func(pairs ...string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(pairs...))
+	}
+
+	for _, ctx := range []context.Context{context.Background(), withKey("x-api-key", "nope")} {
+		if _, err := unary(ctx, nil, &grpc.UnaryServerInfo{}, handler); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("expected a call without a key to be unauthenticated, got %v", err)
+		}
+	}
+
+	if _, err := unary(withKey("authorization", "Bearer acme-key"), nil, &grpc.UnaryServerInfo{}, handler); err != nil {
+		t.Errorf("expected the key in authorization to be let through, got %s", err)
+	}
+
+	if _, err := unary(withKey("x-api-key", "acme-key"), nil, &grpc.UnaryServerInfo{}, handler); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected a call over the key's limit to be resource exhausted, got %v", err)
+	}
+
+	stream := s.StreamInterceptor()
+	err := stream(nil, &testStream{ctx: withKey("x-api-key", "website-key")}, &grpc.StreamServerInfo{}, func(any, grpc.ServerStream) error { return nil })
+	if err != nil {
+		t.Errorf("expected the website's stream to be let through, got %s", err)
+	}
+
+	if s.unauthorized.Load() != 2 || s.keyFor("acme-key").limited.Load() != 1 || s.keyFor("website-key").requests.Load() != 1 {
+		t.Errorf("expected the calls to be counted in the metrics")
+	}
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// The encodings that responses can be compressed with, in order of
+// preference when a client takes both equally. HTTP's deflate is zlib's
+// format rather than raw deflate.
+var encodings = []string{"gzip", "deflate"}
+
+// acceptedEncoding is the encoding with the highest q in an Accept-Encoding
+// header, or nothing if the response shouldn't be compressed.
+func acceptedEncoding(acceptEncoding string) string {
+	qs := make(map[string]float64)
+	for _, coding := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(coding, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if len(name) == 0 {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				q, _ = strconv.ParseFloat(value, 64)
+			}
+		}
+		qs[name] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, encoding := range encodings {
+		q, ok := qs[encoding]
+		if !ok {
+			// Encodings by name win over *
+			q, ok = qs["*"]
+		}
+
+		if ok && q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+
+	return best
+}
+
+// compressible is whether a response of the content type is text that's
+// worth compressing, which everything the API returns is.
+func compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	// Events are sent one at a time as they happen, which
+	// is too little at once to be worth compressing
+	if mediaType == eventStreamContentType {
+		return false
+	}
+
+	return strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "json") ||
+		strings.HasSuffix(mediaType, "yaml") ||
+		mediaType == "application/x-ndjson"
+}
+
+// compress compresses the responses to clients that can take it. Lists
+// of a whole state are big and repeat the same field names over and
+// over, so they're a tenth of the size.
+func (s *Server) compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := acceptedEncoding(r.Header.Get("Accept-Encoding"))
+		if len(encoding) == 0 || r.Method == http.MethodHead {
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: rw, encoding: encoding}
+		defer func() {
+			if err := cw.Close(); err != nil {
+				s.log.ErrorContext(r.Context(), "could not compress the response", "err", err, "request_id", requestID(r.Context()))
+			}
+		}()
+
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// A compressWriter decides whether to compress the response once its
+// headers are written, since that's when its content type is known.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	decided  bool
+	w        interface {
+		io.WriteCloser
+		Flush() error
+	}
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if !cw.decided {
+		cw.decided = true
+
+		h := cw.Header()
+		if status != http.StatusNoContent && status != http.StatusNotModified && len(h.Get("Content-Encoding")) == 0 && compressible(h.Get("Content-Type")) {
+			h.Set("Content-Encoding", cw.encoding)
+			h.Del("Content-Length")
+
+			// The compressed bytes aren't the same as the ones the
+			// ETag is for, but they mean the same thing
+			if etag := h.Get("ETag"); len(etag) > 0 && !strings.HasPrefix(etag, "W/") {
+				h.Set("ETag", "W/"+etag)
+			}
+
+			if cw.encoding == "gzip" {
+				cw.w = gzip.NewWriter(cw.ResponseWriter)
+			} else {
+				cw.w = zlib.NewWriter(cw.ResponseWriter)
+			}
+		}
+	}
+
+	cw.ResponseWriter.WriteHeader(status)
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if !cw.decided {
+		cw.WriteHeader(http.StatusOK)
+	}
+
+	if cw.w == nil {
+		return cw.ResponseWriter.Write(b)
+	}
+	return cw.w.Write(b)
+}
+
+// FlushError sends what's been compressed so far, for the responses that
+// are streamed. It's what http.ResponseController's Flush calls, so the
+// handlers that flush with one find out when it fails.
+func (cw *compressWriter) FlushError() error {
+	if cw.w != nil {
+		if err := cw.w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// Flush is FlushError for the handlers that flush with http.Flusher,
+// which has no way to say that it failed.
+func (cw *compressWriter) Flush() {
+	cw.FlushError()
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+func (cw *compressWriter) Close() error {
+	if cw.w == nil {
+		return nil
+	}
+	return cw.w.Close()
+}
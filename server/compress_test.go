@@ -0,0 +1,130 @@
+package server
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAcceptedEncoding(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		expected       string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"gzip, deflate, br", "gzip"},
+		{"deflate, gzip;q=0.5", "deflate"},
+		{"GZIP", "gzip"},
+		{"*", "gzip"},
+		{"gzip;q=0, *", "deflate"},
+		{"gzip;q=0, deflate;q=0", ""},
+		{"br", ""},
+	}
+
+	for _, test := range tests {
+		if encoding := acceptedEncoding(test.acceptEncoding); encoding != test.expected {
+			t.Errorf("expected %q for %q, got %q", test.expected, test.acceptEncoding, encoding)
+		}
+	}
+}
+
+func getEncoded(s http.Handler, target, acceptEncoding string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	r.Header.Set("Accept-Encoding", acceptEncoding)
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, r)
+	return rw
+}
+
+func TestCompress(t *testing.T) {
+	s, _ := newTestServer(t)
+	plain := get(s, "/communities?format=csv").Body.String()
+
+	tests := []struct {
+		encoding string
+		reader   func(io.Reader) (io.Reader, error)
+	}{
+		{"gzip", func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }},
+		{"deflate", func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) }},
+	}
+
+	for _, test := range tests {
+		rw := getEncoded(s, "/communities?format=csv", test.encoding)
+		if rw.Header().Get("Content-Encoding") != test.encoding || rw.Header().Get("Vary") == "" {
+			t.Fatalf("expected it to be compressed with %s, got %v", test.encoding, rw.Header())
+		}
+
+		if etag := rw.Header().Get("ETag"); !strings.HasPrefix(etag, "W/") {
+			t.Errorf("expected a weak ETag for %s, got %s", test.encoding, etag)
+		}
+
+		if rw.Body.Len() >= len(plain) {
+			t.Errorf("expected %s to be smaller, got %d bytes from %d", test.encoding, rw.Body.Len(), len(plain))
+		}
+
+		r, err := test.reader(rw.Body)
+		if err != nil {
+			t.Fatalf("expected %s, got %s", test.encoding, err)
+		}
+
+		b, err := io.ReadAll(r)
+		if err != nil || string(b) != plain {
+			t.Errorf("expected the same CSV once it's decompressed with %s, got %v", test.encoding, err)
+		}
+	}
+
+	if rw := get(s, "/communities"); len(rw.Header().Get("Content-Encoding")) > 0 {
+		t.Errorf("expected nothing to be compressed without Accept-Encoding, got %s", rw.Header().Get("Content-Encoding"))
+	}
+
+	etag := get(s, "/communities").Header().Get("ETag")
+	r := httptest.NewRequest(http.MethodGet, "/communities", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	r.Header.Set("If-None-Match", etag)
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, r)
+	if rw.Code != http.StatusNotModified || rw.Body.Len() > 0 || len(rw.Header().Get("Content-Encoding")) > 0 {
+		t.Errorf("expected a 304 to be left empty, got %d with %d bytes", rw.Code, rw.Body.Len())
+	}
+}
+
+// A brokenWriter is a connection that goes away once it's broken.
+type brokenWriter struct {
+	*httptest.ResponseRecorder
+	broken bool
+}
+
+var errBroken = errors.New("broken pipe")
+
+func (bw *brokenWriter) Write(b []byte) (int, error) {
+	if bw.broken {
+		return 0, errBroken
+	}
+	return bw.ResponseRecorder.Write(b)
+}
+
+func TestCompressFlushError(t *testing.T) {
+	rw := &brokenWriter{ResponseRecorder: httptest.NewRecorder()}
+	cw := &compressWriter{ResponseWriter: rw, encoding: "gzip"}
+	cw.Header().Set("Content-Type", "application/x-ndjson")
+
+	// The compressed bytes are buffered until they're flushed
+	if _, err := cw.Write([]byte(`{"cid":480296}`)); err != nil {
+		t.Fatalf("expected the write to be buffered, got %s", err)
+	}
+
+	rw.broken = true
+
+	if err := http.NewResponseController(cw).Flush(); !errors.Is(err, errBroken) {
+		t.Errorf("expected the flush to fail, got %v", err)
+	}
+}
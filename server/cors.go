@@ -0,0 +1,116 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS is which browser apps on other origins can call the API. Origins
+// are like https://example.com, or * for any of them. The methods, headers
+// and max age are what's allowed in a preflight, and they're the defaults
+// below when they aren't given.
+type CORS struct {
+	Origins []string
+	Methods []string
+	Headers []string
+	MaxAge  time.Duration
+}
+
+var (
+	defaultCORSMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+	defaultCORSHeaders = []string{"Authorization", "Content-Type", "If-None-Match", "X-API-Key", requestIDHeader}
+	defaultCORSMaxAge  = 10 * time.Minute
+)
+
+// The headers that scripts can read from the responses, besides the ones
+// that they always can.
+var exposedHeaders = []string{"ETag", "Link", "Retry-After", "X-Total-Count", requestIDHeader}
+
+// WithCORS lets browser apps on the origins call the API directly, without
+// a proxy in front of it to add the headers.
+func WithCORS(c CORS) Option {
+	return func(s *Server) {
+		if len(c.Methods) == 0 {
+			c.Methods = defaultCORSMethods
+		}
+		if len(c.Headers) == 0 {
+			c.Headers = defaultCORSHeaders
+		}
+		if c.MaxAge == 0 {
+			c.MaxAge = defaultCORSMaxAge
+		}
+		s.cors = &c
+	}
+}
+
+func (c *CORS) allowsOrigin(origin string) bool {
+	for _, allowed := range c.Origins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CORS) allowsMethod(method string) bool {
+	for _, allowed := range c.Methods {
+		if strings.EqualFold(allowed, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowCORS adds the CORS headers to responses to the origins that are
+// allowed, and answers their preflights. Preflights are answered before
+// the API key is looked for, since browsers never send one with them.
+// Other origins get the same responses without the headers, which the
+// browser won't let their scripts read.
+func (s *Server) allowCORS(next http.Handler) http.Handler {
+	if s.cors == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if len(origin) == 0 {
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		h := rw.Header()
+		h.Add("Vary", "Origin")
+
+		preflight := r.Method == http.MethodOptions && len(r.Header.Get("Access-Control-Request-Method")) > 0
+		if preflight {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		if !s.cors.allowsOrigin(origin) {
+			if preflight {
+				rw.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		h.Set("Access-Control-Allow-Origin", origin)
+
+		if !preflight {
+			h.Set("Access-Control-Expose-Headers", strings.Join(exposedHeaders, ", "))
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		if s.cors.allowsMethod(r.Header.Get("Access-Control-Request-Method")) {
+			h.Set("Access-Control-Allow-Methods", strings.Join(s.cors.Methods, ", "))
+			h.Set("Access-Control-Allow-Headers", strings.Join(s.cors.Headers, ", "))
+			h.Set("Access-Control-Max-Age", strconv.Itoa(int(s.cors.MaxAge.Seconds())))
+		}
+		rw.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+func TestCORS(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	s := New(store.NewMemory(communities), discardLogs,
+		WithCORS(CORS{Origins: []string{"https://app.example.com"}, MaxAge: time.Hour}),
+		WithAPIKeys([]APIKey{{Name: "app", Key: "app-key"}}),
+	)
+
+	request := func(method, origin string, headers map[string]string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/search?q=houston", nil)
+		if len(origin) > 0 {
+			r.Header.Set("Origin", origin)
+		}
+		for name, value := range headers {
+			r.Header.Set(name, value)
+		}
+
+		rw := httptest.NewRecorder()
+		s.ServeHTTP(rw, r)
+		return rw
+	}
+
+	// Preflights are answered without a key
+	rw := request(http.MethodOptions, "https://app.example.com", map[string]string{
+		"Access-Control-Request-Method":  "GET",
+		"Access-Control-Request-Headers": "x-api-key",
+	})
+	h := rw.Header()
+	if rw.Code != http.StatusNoContent || h.Get("Access-Control-Allow-Origin") != "https://app.example.com" || h.Get("Access-Control-Allow-Methods") != "GET, HEAD, POST" || h.Get("Access-Control-Max-Age") != "3600" {
+		t.Errorf("expected the preflight to be allowed, got %d %v", rw.Code, h)
+	}
+
+	rw = request(http.MethodGet, "https://app.example.com", map[string]string{"X-API-Key": "app-key"})
+	if rw.Code != http.StatusOK || rw.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" || len(rw.Header().Get("Access-Control-Expose-Headers")) == 0 {
+		t.Errorf("expected the search to be allowed, got %d %v", rw.Code, rw.Header())
+	}
+
+	// Errors have the headers too, so that the app can read them
+	if rw := request(http.MethodGet, "https://app.example.com", nil); rw.Code != http.StatusUnauthorized || len(rw.Header().Get("Access-Control-Allow-Origin")) == 0 {
+		t.Errorf("expected a 401 that the app can read, got %d %v", rw.Code, rw.Header())
+	}
+
+	rw = request(http.MethodOptions, "https://evil.example.com", map[string]string{"Access-Control-Request-Method": "GET"})
+	if rw.Code != http.StatusNoContent || len(rw.Header().Get("Access-Control-Allow-Origin")) > 0 {
+		t.Errorf("expected another origin to not be allowed, got %v", rw.Header())
+	}
+
+	rw = request(http.MethodOptions, "https://app.example.com", map[string]string{"Access-Control-Request-Method": "DELETE"})
+	if len(rw.Header().Get("Access-Control-Allow-Methods")) > 0 {
+		t.Errorf("expected DELETE to not be allowed, got %v", rw.Header())
+	}
+
+	if rw := request(http.MethodGet, "", map[string]string{"X-API-Key": "app-key"}); rw.Code != http.StatusOK || len(rw.Header().Get("Access-Control-Allow-Origin")) > 0 {
+		t.Errorf("expected requests that aren't from a browser to be left alone, got %v", rw.Header())
+	}
+}
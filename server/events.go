@@ -0,0 +1,116 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"nfip-community-book/store"
+)
+
+const eventStreamContentType = "text/event-stream"
+
+// How often a comment is sent to the clients that are listening for
+// events, so that proxies don't close the connection for being idle.
+const keepAliveEvery = 30 * time.Second
+
+// How many events can be waiting to be sent to a client. Clients that
+// fall further behind than that miss them.
+const eventBuffer = 16
+
+// A refreshEvent is sent whenever the communities are refreshed, with
+// what changed and the version of the communities they are now, which
+// is the same as the one in the lists' ETags.
+type refreshEvent struct {
+	Version  string    `json:"version"`
+	LoadedAt time.Time `json:"loaded_at"`
+	store.Changes
+}
+
+// events hands out the events to each client that's listening.
+type events struct {
+	mu      sync.Mutex
+	clients map[chan refreshEvent]bool
+}
+
+func (e *events) subscribe() chan refreshEvent {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.clients == nil {
+		e.clients = make(map[chan refreshEvent]bool)
+	}
+
+	c := make(chan refreshEvent, eventBuffer)
+	e.clients[c] = true
+	return c
+}
+
+func (e *events) unsubscribe(c chan refreshEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.clients, c)
+}
+
+// publish sends the event to every client without waiting on any of them.
+func (e *events) publish(event refreshEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for c := range e.clients {
+		select {
+		case c <- event:
+		default:
+		}
+	}
+}
+
+// getEvents streams server-sent events for as long as the client listens,
+// or until the server shuts down. The write timeout doesn't apply, since
+// the response never ends on its own.
+func (s *Server) getEvents(rw http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(rw)
+	rc.SetWriteDeadline(time.Time{})
+
+	c := s.events.subscribe()
+	defer s.events.unsubscribe(c)
+
+	h := rw.Header()
+	h.Set("Content-Type", eventStreamContentType)
+	h.Set("Cache-Control", "no-cache")
+	rw.WriteHeader(http.StatusOK)
+
+	fmt.Fprint(rw, ": listening for refreshes\n\n")
+	if err := rc.Flush(); err != nil {
+		s.log.ErrorContext(r.Context(), "could not stream events", "err", err, "request_id", requestID(r.Context()))
+		return
+	}
+
+	keepAlive := time.NewTicker(keepAliveEvery)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.shuttingDown:
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(rw, ": keep-alive\n\n")
+		case event := <-c:
+			b, err := json.Marshal(event)
+			if err != nil {
+				s.log.ErrorContext(r.Context(), "could not write the event", "err", err)
+				continue
+			}
+			fmt.Fprintf(rw, "id: %s\nevent: refresh\ndata: %s\n\n", event.Version, b)
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
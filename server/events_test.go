@@ -0,0 +1,71 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+func TestGetEvents(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	memory := store.NewMemory(communities)
+	s := New(memory, discardLogs, WithRefresher(func(ctx context.Context, current data.NFIPCommunityStatuses) (data.NFIPCommunityStatuses, error) {
+		return current[2:], nil
+	}))
+
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	// Compression is asked for, but events are sent as they are
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/events", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("expected to listen for events, got %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.Header.Get("Content-Type") != eventStreamContentType || len(resp.Header.Get("Content-Encoding")) > 0 {
+		t.Fatalf("expected an uncompressed event stream, got %v", resp.Header)
+	}
+
+	r := bufio.NewReader(resp.Body)
+	readEvent := func() []string {
+		var lines []string
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				t.Fatalf("expected an event, got %s", err)
+			}
+			if line == "\n" {
+				return lines
+			}
+			lines = append(lines, strings.TrimSuffix(line, "\n"))
+		}
+	}
+
+	// The comment says it's listening, so the refresh can't be missed
+	if first := readEvent(); len(first) != 1 || !strings.HasPrefix(first[0], ":") {
+		t.Fatalf("expected a comment first, got %v", first)
+	}
+
+	s.refreshes.start()
+	s.refresh(context.Background())
+
+	event := readEvent()
+	if len(event) != 3 || event[0] != "id: "+memory.Version() || event[1] != "event: refresh" {
+		t.Fatalf("expected a refresh event with the new version, got %v", event)
+	}
+
+	var refreshed refreshEvent
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(event[2], "data: ")), &refreshed); err != nil || refreshed.Removed != 2 || refreshed.Version != memory.Version() {
+		t.Errorf("expected 2 communities to be removed, got %+v %v", refreshed, err)
+	}
+}
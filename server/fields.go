@@ -0,0 +1,93 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"nfip-community-book/data"
+)
+
+// parseFields is the fields that the communities on a page are trimmed to,
+// from a list like fields=cid,community_name, so that clients that only
+// show a name don't have to download everything else. None is all of them.
+func parseFields(values url.Values) ([]string, error) {
+	s := values.Get("fields")
+	if len(s) == 0 {
+		return nil, nil
+	}
+
+	var fields []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if len(name) == 0 {
+			continue
+		}
+
+		if !slices.Contains(data.FieldNames(), name) {
+			return nil, fmt.Errorf("invalid field %s, it has to be one of %s", strconv.Quote(name), strings.Join(data.FieldNames(), ", "))
+		}
+		fields = append(fields, name)
+	}
+
+	return fields, nil
+}
+
+// A projectedPage is a page with only some of the communities' fields.
+type projectedPage interface {
+	project(fields []string) (any, error)
+}
+
+// The communities shadow the ones in the StatusPage, since they're
+// shallower, so they're the ones that are written.
+type projectedCommunitiesPage struct {
+	communitiesPage
+	Communities []data.ProjectedStatus `json:"communities"`
+}
+
+func (p communitiesPage) project(fields []string) (any, error) {
+	communities, err := p.Communities.Project(fields...)
+	if err != nil {
+		return nil, err
+	}
+
+	return projectedCommunitiesPage{p, communities}, nil
+}
+
+// A projectedResult is a search result with only some of the community's
+// fields. Matches in the fields that were left out are left out too.
+type projectedResult struct {
+	Community data.ProjectedStatus `json:"community"`
+	Score     float64              `json:"score"`
+	Matches   []data.MatchSpan     `json:"matches,omitempty"`
+}
+
+type projectedSearchPage struct {
+	searchPage
+	Results []projectedResult `json:"results"`
+}
+
+func (p searchPage) project(fields []string) (any, error) {
+	return p.projected(fields)
+}
+
+func (p searchPage) projected(fields []string) (projectedSearchPage, error) {
+	communities, err := p.Results.Communities().Project(fields...)
+	if err != nil {
+		return projectedSearchPage{}, err
+	}
+
+	results := make([]projectedResult, len(p.Results))
+	for i, r := range p.Results {
+		results[i] = projectedResult{Community: communities[i], Score: r.Score}
+		for _, m := range r.Matches {
+			if slices.Contains(fields, m.Field) {
+				results[i].Matches = append(results[i].Matches, m)
+			}
+		}
+	}
+
+	return projectedSearchPage{p, results}, nil
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestParseFields(t *testing.T) {
+	fields, err := parseFields(url.Values{"fields": {"cid, community_name,,state"}})
+	if err != nil || strings.Join(fields, ",") != "cid,community_name,state" {
+		t.Errorf("expected cid, community_name and state, got %v %v", fields, err)
+	}
+
+	if fields, err := parseFields(url.Values{}); fields != nil || err != nil {
+		t.Errorf("expected all of the fields without the parameter, got %v %v", fields, err)
+	}
+
+	if _, err := parseFields(url.Values{"fields": {"cid,population"}}); err == nil {
+		t.Errorf("expected an unknown field to be invalid")
+	}
+}
+
+func TestFields(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	rw := get(s, "/v1/communities?state=TX&limit=1&fields=cid,community_name,state,cur_class")
+	var page struct {
+		Total       int                          `json:"total"`
+		Communities []map[string]json.RawMessage `json:"communities"`
+		Next        string                       `json:"next"`
+	}
+	if err := json.Unmarshal(rw.Body.Bytes(), &page); rw.Code != http.StatusOK || err != nil || len(page.Communities) != 1 {
+		t.Fatalf("expected a page of communities, got %d %s", rw.Code, rw.Body)
+	}
+
+	for name := range page.Communities[0] {
+		if name != "cid" && name != "community_name" && name != "state" && name != "cur_class" {
+			t.Errorf("expected only the fields that were picked, got %s", name)
+		}
+	}
+	if page.Total != 3 || !strings.Contains(page.Next, "fields=") {
+		t.Errorf("expected the rest of the page and the fields in the next link, got %d %s", page.Total, page.Next)
+	}
+
+	rw = get(s, "/v1/search?q=houston&fields=cid,community_name")
+	var search searchPage
+	if err := json.Unmarshal(rw.Body.Bytes(), &search); rw.Code != http.StatusOK || err != nil || len(search.Results) == 0 {
+		t.Fatalf("expected search results, got %d %s", rw.Code, rw.Body)
+	}
+	if c := search.Results[0].Community; c.CID != 480296 || len(c.County) > 0 {
+		t.Errorf("expected Houston with only its CID and name, got %+v", c)
+	}
+	for _, m := range search.Results[0].Matches {
+		if m.Field != "cid" && m.Field != "community_name" {
+			t.Errorf("expected only matches in the fields that were picked, got %s", m.Field)
+		}
+	}
+
+	csv := get(s, "/v1/communities?state=TX&format=csv&fields=cid,state").Body.String()
+	if !strings.HasPrefix(csv, "CID,State\r\n") {
+		t.Errorf("expected only the CID and state in the CSV, got %s", csv)
+	}
+
+	ndjson := get(s, "/v1/communities?state=TX&limit=1&format=ndjson&fields=state").Body.String()
+	if ndjson != `{"state":"TX"}`+"\n" {
+		t.Errorf("expected only the state in the NDJSON, got %s", ndjson)
+	}
+
+	if rw := get(s, "/v1/communities?fields=population"); rw.Code != http.StatusBadRequest {
+		t.Errorf("expected an unknown field to be a bad request, got %d", rw.Code)
+	}
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"nfip-community-book/data"
+	"nfip-community-book/problem"
+)
+
+// A format is one of the ways that lists of communities can be written
+// out. Everything but JSON is just the communities on the page, since the
+// rest of the page doesn't fit in a CSV. How many there are and the link to
+// the next page are in the X-Total-Count and Link headers instead.
+type format struct {
+	name        string
+	mediaType   string
+	contentType string
+
+	// Other media types that mean the same thing
+	aliases []string
+
+	// JSON is written with the rest of the page by writeJSON
+	write func(w io.Writer, communities data.NFIPCommunityStatuses, opts ...data.EncodeOption) error
+}
+
+// formats are in order of preference, for when a client takes more than
+// one of them equally.
+var formats = []format{
+	{
+		name:        "json",
+		mediaType:   "application/json",
+		contentType: jsonContentType,
+	},
+	{
+		name:        "ndjson",
+		mediaType:   "application/x-ndjson",
+		contentType: "application/x-ndjson",
+		aliases:     []string{"application/ndjson", "application/jsonl"},
+		write: func(w io.Writer, communities data.NFIPCommunityStatuses, opts ...data.EncodeOption) error {
+			return communities.ToNDJSON(w, opts...)
+		},
+	},
+	{
+		name:        "csv",
+		mediaType:   "text/csv",
+		contentType: "text/csv; charset=utf-8",
+		write: func(w io.Writer, communities data.NFIPCommunityStatuses, opts ...data.EncodeOption) error {
+			return communities.ToCSV(w, opts...)
+		},
+	},
+	{
+		name:        "yaml",
+		mediaType:   "application/yaml",
+		contentType: "application/yaml",
+		aliases:     []string{"application/x-yaml", "text/yaml"},
+		write: func(w io.Writer, communities data.NFIPCommunityStatuses, opts ...data.EncodeOption) error {
+			return communities.ToYAML(w, opts...)
+		},
+	},
+}
+
+// negotiate picks the format that a list is written in. The format
+// parameter wins over the Accept header, so that a link can be to a CSV.
+// If the client doesn't take any of them, it's written back and it
+// isn't ok.
+func (s *Server) negotiate(rw http.ResponseWriter, r *http.Request) (format, bool) {
+	rw.Header().Add("Vary", "Accept")
+
+	if name := r.URL.Query().Get("format"); len(name) > 0 {
+		for _, f := range formats {
+			if f.name == name {
+				return f, true
+			}
+		}
+
+		s.writeError(rw, r, http.StatusBadRequest, problem.InvalidParameter, "invalid format "+strconv.Quote(name)+", it has to be json, ndjson, csv or yaml")
+		return format{}, false
+	}
+
+	f, ok := acceptedFormat(r.Header.Get("Accept"))
+	if !ok {
+		s.writeError(rw, r, http.StatusNotAcceptable, problem.NotAcceptable, "none of the types in Accept can be returned, it has to take application/json, application/x-ndjson, text/csv or application/yaml")
+		return format{}, false
+	}
+
+	return f, true
+}
+
+// acceptedFormat is the format with the highest q in an Accept header.
+// No header at all takes anything, and wildcards are the first format
+// of that type.
+func acceptedFormat(accept string) (format, bool) {
+	if len(strings.TrimSpace(accept)) == 0 {
+		return formats[0], true
+	}
+
+	var best format
+	bestQ := 0.0
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(mediaRange, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				q, _ = strconv.ParseFloat(value, 64)
+			}
+		}
+
+		// The first one wins a tie, since they're in the order they're preferred
+		if q <= bestQ {
+			continue
+		}
+
+		if f, ok := formatFor(mediaType); ok {
+			best, bestQ = f, q
+		}
+	}
+
+	return best, bestQ > 0
+}
+
+func formatFor(mediaType string) (format, bool) {
+	for _, f := range formats {
+		if mediaType == "*/*" || mediaType == f.mediaType {
+			return f, true
+		}
+		if prefix, ok := strings.CutSuffix(mediaType, "*"); ok && strings.HasPrefix(f.mediaType, prefix) {
+			return f, true
+		}
+		for _, alias := range f.aliases {
+			if mediaType == alias {
+				return f, true
+			}
+		}
+	}
+
+	return format{}, false
+}
+
+// writePage writes the page as JSON, or the communities on it in any
+// other format, with only the fields that were picked if any were.
+func (s *Server) writePage(rw http.ResponseWriter, r *http.Request, f format, page projectedPage, communities data.NFIPCommunityStatuses, total int, next string, fields []string) {
+	if f.write == nil {
+		var v any = page
+		if len(fields) > 0 {
+			projected, err := page.project(fields)
+			if err != nil {
+				s.writeError(rw, r, http.StatusBadRequest, problem.InvalidParameter, err.Error())
+				return
+			}
+			v = projected
+		}
+
+		s.writeJSON(rw, http.StatusOK, v)
+		return
+	}
+
+	var opts []data.EncodeOption
+	if len(fields) > 0 {
+		opts = append(opts, data.WithFields(fields...))
+	}
+
+	rw.Header().Set("Content-Type", f.contentType)
+	rw.Header().Set("X-Total-Count", strconv.Itoa(total))
+	if len(next) > 0 {
+		rw.Header().Set("Link", "<"+next+">; rel=\"next\"")
+	}
+
+	if err := f.write(rw, communities, opts...); err != nil {
+		s.log.Error("could not write the response", "format", f.name, "err", err)
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"nfip-community-book/problem"
+)
+
+func TestAcceptedFormat(t *testing.T) {
+	tests := []struct {
+		accept   string
+		expected string
+	}{
+		{"", "json"},
+		{"*/*", "json"},
+		{"application/json", "json"},
+		{"text/csv", "csv"},
+		{"text/*", "csv"},
+		{"application/x-ndjson", "ndjson"},
+		{"application/ndjson", "ndjson"},
+		{"application/x-yaml", "yaml"},
+		{"TEXT/CSV; charset=utf-8", "csv"},
+		{"text/html, text/csv;q=0.5, */*;q=0.1", "csv"},
+		{"application/yaml;q=0.9, text/csv", "csv"},
+		{"text/csv, application/yaml", "csv"},
+		{"application/json;q=0, */*", "json"},
+		{"text/csv;q=0, application/yaml;q=0.2", "yaml"},
+		{"text/html", ""},
+		{"text/csv;q=0", ""},
+	}
+
+	for _, test := range tests {
+		f, ok := acceptedFormat(test.accept)
+		if f.name != test.expected || ok != (len(test.expected) > 0) {
+			t.Errorf("expected %q for %q, got %q %t", test.expected, test.accept, f.name, ok)
+		}
+	}
+}
+
+func getAccepting(s http.Handler, target, accept string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	r.Header.Set("Accept", accept)
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, r)
+	return rw
+}
+
+func TestListFormats(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	rw := getAccepting(s, "/communities?state=TX&limit=2", "text/csv")
+	if rw.Code != http.StatusOK || rw.Header().Get("Content-Type") != "text/csv; charset=utf-8" || !slices.Contains(rw.Header().Values("Vary"), "Accept") {
+		t.Fatalf("expected CSV, got %d %s", rw.Code, rw.Header())
+	}
+
+	// CIDs are written like ="480296", which isn't quoted the way encoding/csv wants
+	cr := csv.NewReader(rw.Body)
+	cr.LazyQuotes = true
+	records, err := cr.ReadAll()
+	if err != nil || len(records) != 3 {
+		t.Fatalf("expected a header and 2 communities, got %d %v", len(records), err)
+	}
+
+	if total, link := rw.Header().Get("X-Total-Count"), rw.Header().Get("Link"); total != "3" || link != `</communities?limit=2&offset=2&state=TX>; rel="next"` {
+		t.Errorf("expected 3 in total and a link to the next page, got %s %s", total, link)
+	}
+
+	// The format parameter wins over Accept, and stays in the next link
+	rw = getAccepting(s, "/search?q=houston&format=ndjson&limit=1", "text/csv")
+	lines := strings.Split(strings.TrimSpace(rw.Body.String()), "\n")
+	if rw.Header().Get("Content-Type") != "application/x-ndjson" || len(lines) != 1 || !strings.Contains(lines[0], `"cid":480296`) {
+		t.Errorf("expected Houston as NDJSON, got %s %s", rw.Header().Get("Content-Type"), rw.Body)
+	}
+	if link := rw.Header().Get("Link"); len(link) > 0 && !strings.Contains(link, "format=ndjson") {
+		t.Errorf("expected the next page to be NDJSON too, got %s", link)
+	}
+
+	rw = get(s, "/communities?format=yaml&state=TX")
+	var communities []map[string]any
+	if err := yaml.Unmarshal(rw.Body.Bytes(), &communities); err != nil || len(communities) != 3 || communities[0]["state"] != "TX" {
+		t.Errorf("expected the communities in TX as YAML, got %d %v", len(communities), err)
+	}
+
+	rw = getAccepting(s, "/communities?limit=1", "application/json")
+	if rw.Header().Get("Content-Type") != jsonContentType || !bytes.HasPrefix(rw.Body.Bytes(), []byte(`{"api_version":"v1","total":`)) {
+		t.Errorf("expected a JSON page, got %s", rw.Body)
+	}
+}
+
+func TestListFormatErrors(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	if rw := getAccepting(s, "/communities", "text/html"); rw.Code != http.StatusNotAcceptable || rw.Header().Get("Content-Type") != problem.ContentType {
+		t.Errorf("expected a 406 for HTML, got %d", rw.Code)
+	}
+
+	if rw := get(s, "/search?q=houston&format=xml"); rw.Code != http.StatusBadRequest {
+		t.Errorf("expected a 400 for XML, got %d", rw.Code)
+	}
+}
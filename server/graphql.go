@@ -0,0 +1,274 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	"nfip-community-book/data"
+	"nfip-community-book/problem"
+)
+
+// communityField is a field of a community in the GraphQL schema. The
+// fields are named the same as they are in the community's JSON, and
+// the ones that are left out of the JSON are null.
+func communityField(typ graphql.Output, description string, value func(c data.NFIPCommunityStatus) any) *graphql.Field {
+	return &graphql.Field{
+		Type:        typ,
+		Description: description,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			switch c := p.Source.(type) {
+			case data.NFIPCommunityStatus:
+				return value(c), nil
+			case *data.NFIPCommunityStatus:
+				return value(*c), nil
+			}
+			return nil, nil
+		},
+	}
+}
+
+func optionalString(s string) any {
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
+
+func optionalDate(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.Format(dateLayout)
+}
+
+var flagsType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "CommunityFlags",
+	Description: "The statuses that the status book marks after a community's name.",
+	Fields: graphql.Fields{
+		"suspended":             &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Resolve: flag(func(f data.CommunityFlags) bool { return f.Suspended })},
+		"withdrawn":             &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Resolve: flag(func(f data.CommunityFlags) bool { return f.Withdrawn })},
+		"minimally_flood_prone": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Resolve: flag(func(f data.CommunityFlags) bool { return f.MinimallyFloodProne })},
+		"non_flood_prone":       &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Resolve: flag(func(f data.CommunityFlags) bool { return f.NonFloodProne })},
+	},
+})
+
+func flag(value func(f data.CommunityFlags) bool) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		return value(p.Source.(data.CommunityFlags)), nil
+	}
+}
+
+var communityType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Community",
+	Description: "A community in FEMA's NFIP Community Status Book.",
+	Fields: graphql.Fields{
+		"cid":            communityField(graphql.NewNonNull(graphql.Int), "The community's ID.", func(c data.NFIPCommunityStatus) any { return c.CID }),
+		"community_name": communityField(graphql.String, "The community's name as it's written in the status book, without its flags.", func(c data.NFIPCommunityStatus) any { return optionalString(c.CommunityName) }),
+		"flags": communityField(flagsType, "The markers after the community's name. Null when it doesn't have any.", func(c data.NFIPCommunityStatus) any {
+			if c.Flags == (data.CommunityFlags{}) {
+				return nil
+			}
+			return c.Flags
+		}),
+		"kind":                    communityField(graphql.String, "The kind of jurisdiction the community's name says it is.", func(c data.NFIPCommunityStatus) any { return optionalString(string(c.Kind)) }),
+		"base_name":               communityField(graphql.String, "The community's name without its kind.", func(c data.NFIPCommunityStatus) any { return optionalString(c.BaseName) }),
+		"type":                    communityField(graphql.String, "The type of jurisdiction the community is.", func(c data.NFIPCommunityStatus) any { return optionalString(string(c.Type)) }),
+		"county":                  communityField(graphql.String, "The county or counties the community is in.", func(c data.NFIPCommunityStatus) any { return optionalString(c.County) }),
+		"state":                   communityField(graphql.String, "The state's abbreviation.", func(c data.NFIPCommunityStatus) any { return optionalString(c.State) }),
+		"state_fips":              communityField(graphql.Int, "The state's FIPS code.", func(c data.NFIPCommunityStatus) any { return c.StateFIPS }),
+		"fhbm_identified":         communityField(graphql.String, "When the Flood Hazard Boundary Map was identified.", func(c data.NFIPCommunityStatus) any { return optionalDate(c.FHBMIdentified) }),
+		"firm_identified":         communityField(graphql.String, "When the Flood Insurance Rate Map was identified.", func(c data.NFIPCommunityStatus) any { return optionalDate(c.FIRMIdentified) }),
+		"curr_eff_map_date":       communityField(graphql.String, "When the current map took effect.", func(c data.NFIPCommunityStatus) any { return optionalDate(c.CurrEffMapDate) }),
+		"reg_emer_date":           communityField(graphql.String, "When the community joined the regular or emergency program.", func(c data.NFIPCommunityStatus) any { return optionalDate(c.RegEmerDate) }),
+		"tribal":                  communityField(graphql.NewNonNull(graphql.Boolean), "Whether it's a tribal community.", func(c data.NFIPCommunityStatus) any { return c.Tribal }),
+		"crs_entry_date":          communityField(graphql.String, "When the community entered the Community Rating System.", func(c data.NFIPCommunityStatus) any { return optionalDate(c.CRSEntryDate) }),
+		"curr_eff_date":           communityField(graphql.String, "When the community's current CRS class took effect.", func(c data.NFIPCommunityStatus) any { return optionalDate(c.CurrEffDate) }),
+		"cur_class":               communityField(graphql.Int, "The community's CRS class, from 1 to 10. Null when it isn't in the CRS.", func(c data.NFIPCommunityStatus) any { return c.CurClass }),
+		"percent_disc_sfha":       communityField(graphql.String, "The discount inside the Special Flood Hazard Area.", func(c data.NFIPCommunityStatus) any { return optionalString(c.PercentDiscSFHA) }),
+		"percent_non_sfha":        communityField(graphql.String, "The discount outside the Special Flood Hazard Area.", func(c data.NFIPCommunityStatus) any { return optionalString(c.PercentNonSFHA) }),
+		"program":                 communityField(graphql.String, "The NFIP program the community is in.", func(c data.NFIPCommunityStatus) any { return optionalString(jsonProgram(c.Program)) }),
+		"participating_community": communityField(graphql.NewNonNull(graphql.Boolean), "Whether the community participates in the NFIP.", func(c data.NFIPCommunityStatus) any { return c.ParticipatingCommunity }),
+	},
+})
+
+// jsonProgram is the program the way it's written in the JSON, which
+// leaves out communities whose program isn't known.
+func jsonProgram(p data.ProgramKind) string {
+	if p == data.ProgramUnknown {
+		return ""
+	}
+	return p.String()
+}
+
+var searchResultType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "SearchResult",
+	Fields: graphql.Fields{
+		"community": &graphql.Field{Type: graphql.NewNonNull(communityType)},
+		"score":     &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Description: "How well the community matched, higher being better."},
+	},
+})
+
+func pageType(name, items string, item graphql.Output) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: name,
+		Fields: graphql.Fields{
+			"total":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "How many there are on every page."},
+			"offset": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"limit":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			items:    &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(item)))},
+		},
+	})
+}
+
+// listArguments are the same as the REST API's list parameters, so that
+// they're parsed the same way by parseQuery.
+func listArguments() graphql.FieldConfigArgument {
+	args := graphql.FieldConfigArgument{}
+	for _, param := range listParameters() {
+		var typ graphql.Input = graphql.String
+		switch param.Schema["type"] {
+		case "integer":
+			typ = graphql.Int
+		case "boolean":
+			typ = graphql.Boolean
+		}
+
+		args[param.Name] = &graphql.ArgumentConfig{Type: typ, Description: param.Description}
+	}
+
+	return args
+}
+
+// listValues are the arguments as the REST API's query parameters.
+func listValues(args map[string]any) url.Values {
+	values := url.Values{}
+	for name, arg := range args {
+		switch arg := arg.(type) {
+		case string:
+			values.Set(name, arg)
+		case int:
+			values.Set(name, strconv.Itoa(arg))
+		case bool:
+			values.Set(name, strconv.FormatBool(arg))
+		}
+	}
+
+	return values
+}
+
+// graphQLSchema lets clients pick exactly the fields of the communities
+// that they need. Communities are looked up in the server's store.
+func (s *Server) graphQLSchema() (graphql.Schema, error) {
+	searchArgs := listArguments()
+	searchArgs["q"] = &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String), Description: "What to search for."}
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"community": &graphql.Field{
+					Type:        communityType,
+					Description: "The community with the CID, or null if there isn't one.",
+					Args: graphql.FieldConfigArgument{
+						"cid": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					},
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						c, ok, err := s.store.Get(p.Context, p.Args["cid"].(int))
+						if err != nil || !ok {
+							return nil, err
+						}
+						return &c, nil
+					},
+				},
+				"communities": &graphql.Field{
+					Type:        pageType("CommunitiesPage", "communities", communityType),
+					Description: "Every community, a page at a time.",
+					Args:        listArguments(),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						q, err := parseQuery(listValues(p.Args))
+						if err != nil {
+							return nil, err
+						}
+
+						page := q.Run(s.store.Index().Communities())
+						return data.StatusPage{Total: page.Total, Offset: page.Offset, Limit: page.Limit, Communities: page.Results.Communities()}, nil
+					},
+				},
+				"search": &graphql.Field{
+					Type:        pageType("SearchPage", "results", searchResultType),
+					Description: "The communities that match the term, best first.",
+					Args:        searchArgs,
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						q, err := parseQuery(listValues(p.Args))
+						if err != nil {
+							return nil, err
+						}
+
+						results, err := s.store.Search(p.Context, p.Args["q"].(string))
+						if err != nil {
+							return nil, err
+						}
+						return q.RunResults(results), nil
+					},
+				},
+			},
+		}),
+	})
+}
+
+// A graphQLRequest is a query and its variables, which are posted as JSON
+// or given as the query, variables and operationName parameters of a GET.
+type graphQLRequest struct {
+	Query         string         `json:"query"`
+	Variables     map[string]any `json:"variables"`
+	OperationName string         `json:"operationName"`
+}
+
+func (s *Server) graphQL(rw http.ResponseWriter, r *http.Request) {
+	var req graphQLRequest
+	if r.Method == http.MethodGet {
+		values := r.URL.Query()
+		req.Query, req.OperationName = values.Get("query"), values.Get("operationName")
+
+		if variables := values.Get("variables"); len(variables) > 0 {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				s.writeError(rw, r, http.StatusBadRequest, problem.InvalidParameter, "invalid variables: "+err.Error())
+				return
+			}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(rw, r, http.StatusBadRequest, problem.InvalidBody, "invalid GraphQL request: "+err.Error())
+		return
+	}
+
+	if len(req.Query) == 0 {
+		s.writeError(rw, r, http.StatusBadRequest, problem.MissingParameter, "missing the GraphQL query")
+		return
+	}
+
+	s.graphqlOnce.Do(func() {
+		s.graphql, s.graphqlErr = s.graphQLSchema()
+	})
+	if s.graphqlErr != nil {
+		s.log.ErrorContext(r.Context(), "could not make the GraphQL schema", "err", s.graphqlErr, "request_id", requestID(r.Context()))
+		s.writeError(rw, r, http.StatusInternalServerError, problem.Internal, "could not make the GraphQL schema")
+		return
+	}
+
+	// Errors in the query are in the result, the same as GraphQL
+	// servers always return them
+	result := graphql.Do(graphql.Params{
+		Schema:         s.graphql,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        r.Context(),
+	})
+
+	s.writeJSON(rw, http.StatusOK, result)
+}
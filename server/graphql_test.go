@@ -0,0 +1,130 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type graphQLResult struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func postGraphQL(t *testing.T, s http.Handler, body string) graphQLResult {
+	t.Helper()
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body)))
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected a GraphQL result, got %d %s", rw.Code, rw.Body)
+	}
+
+	var result graphQLResult
+	if err := json.Unmarshal(rw.Body.Bytes(), &result); err != nil {
+		t.Fatalf("expected JSON, got %s", err)
+	}
+
+	return result
+}
+
+func TestGraphQLCommunity(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	// Only the fields that are asked for come back
+	result := postGraphQL(t, s, `{"query": "{ community(cid: 480296) { cid base_name state cur_class } }"}`)
+	if len(result.Errors) > 0 {
+		t.Fatalf("expected no errors, got %v", result.Errors)
+	}
+
+	var community map[string]any
+	json.Unmarshal(result.Data["community"], &community)
+	if len(community) != 4 || community["cid"] != float64(480296) || community["base_name"] != "HOUSTON" || community["state"] != "TX" {
+		t.Errorf("expected Houston's CID, name, state and class, got %v", community)
+	}
+
+	result = postGraphQL(t, s, `{"query": "query Get($cid: Int!) { community(cid: $cid) { cid } }", "variables": {"cid": 1}}`)
+	if len(result.Errors) > 0 || string(result.Data["community"]) != "null" {
+		t.Errorf("expected null for a CID that isn't there, got %s %v", result.Data["community"], result.Errors)
+	}
+}
+
+func TestGraphQLCommunities(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	result := postGraphQL(t, s, `{"query": "{ communities(state: \"TX\", sort: \"cid\", limit: 2) { total limit communities { cid state } } }"}`)
+	if len(result.Errors) > 0 {
+		t.Fatalf("expected no errors, got %v", result.Errors)
+	}
+
+	var page struct {
+		Total       int
+		Limit       int
+		Communities []map[string]any
+	}
+	json.Unmarshal(result.Data["communities"], &page)
+	if page.Total != 3 || page.Limit != 2 || len(page.Communities) != 2 {
+		t.Fatalf("expected the first 2 of 3 communities in TX, got %d of %d", len(page.Communities), page.Total)
+	}
+
+	for _, c := range page.Communities {
+		if c["state"] != "TX" || len(c) != 2 {
+			t.Errorf("expected a CID and state in TX, got %v", c)
+		}
+	}
+
+	// Bad arguments are errors in the result, the same as the REST API's 400s
+	result = postGraphQL(t, s, `{"query": "{ communities(limit: 0) { total } }"}`)
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Message, "invalid limit") {
+		t.Errorf("expected an invalid limit, got %v", result.Errors)
+	}
+}
+
+func TestGraphQLSearch(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	query := url.Values{"query": {"{ search(q: \"houston\", limit: 1) { total results { score community { cid } } } }"}}
+	rw := get(s, "/graphql?"+query.Encode())
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected a GraphQL result, got %d", rw.Code)
+	}
+
+	var result struct {
+		Data struct {
+			Search struct {
+				Total   int
+				Results []struct {
+					Score     float64
+					Community struct{ CID int }
+				}
+			}
+		}
+	}
+	json.Unmarshal(rw.Body.Bytes(), &result)
+
+	search := result.Data.Search
+	if search.Total == 0 || len(search.Results) != 1 || search.Results[0].Community.CID != 480296 || search.Results[0].Score <= 0 {
+		t.Errorf("expected Houston first, got %+v", search)
+	}
+}
+
+func TestGraphQLBadRequest(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	for _, target := range []string{"/graphql", "/graphql?query=%7B%7D&variables=nope"} {
+		if rw := get(s, target); rw.Code != http.StatusBadRequest {
+			t.Errorf("expected a 400 for %s, got %d", target, rw.Code)
+		}
+	}
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader("not json")))
+	if rw.Code != http.StatusBadRequest {
+		t.Errorf("expected a 400 for a body that isn't JSON, got %d", rw.Code)
+	}
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryInterceptor holds gRPC calls to the same rate limits and API keys
+// as the API's requests, so that clients can't get around them by using
+// gRPC instead. Keys are sent in the "authorization" metadata as "Bearer
+// <key>", or in "x-api-key". Calls without one fail with Unauthenticated,
+// and calls over the limits with ResourceExhausted.
+func (s *Server) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := s.checkCall(ctx); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor is UnaryInterceptor for the calls that stream.
+func (s *Server) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := s.checkCall(ss.Context()); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// checkCall is the error for a call over the limits or without a key,
+// the same as limitRate and authenticate.
+func (s *Server) checkCall(ctx context.Context) error {
+	now := time.Now()
+	if seconds := retryAfter(now, s.reserve(peerIP(ctx), now)...); seconds > 0 {
+		return status.Errorf(codes.ResourceExhausted, "too many requests, try again in %ds", seconds)
+	}
+
+	if s.apiKeys == nil {
+		return nil
+	}
+
+	key := s.keyFor(callSecret(ctx))
+	if key == nil {
+		s.unauthorized.Add(1)
+		return status.Error(codes.Unauthenticated, "missing or unknown API key")
+	}
+	key.requests.Add(1)
+
+	if key.limiter != nil {
+		if seconds := retryAfter(now, key.limiter.ReserveN(now, 1)); seconds > 0 {
+			key.limited.Add(1)
+			return status.Errorf(codes.ResourceExhausted, "too many requests, try again in %ds", seconds)
+		}
+	}
+
+	return nil
+}
+
+// callSecret is the API key that the call was made with, if any.
+func callSecret(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var secret string
+	if values := md.Get("x-api-key"); len(values) > 0 {
+		secret = values[0]
+	}
+	if values := md.Get("authorization"); len(values) > 0 {
+		if bearer, ok := strings.CutPrefix(values[0], "Bearer "); ok {
+			secret = strings.TrimSpace(bearer)
+		}
+	}
+
+	return secret
+}
+
+// peerIP is the IP that the call came from, like clientIP.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
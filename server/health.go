@@ -0,0 +1,109 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"nfip-community-book/problem"
+)
+
+// An Option changes how a Server is set up.
+type Option func(*Server)
+
+// WithMaxAge is how old the communities can be before the server says
+// they're out of date, from when they were downloaded or their store was
+// last refreshed. They're never out of date without it.
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(s *Server) {
+		s.maxAge = maxAge
+	}
+}
+
+// The statuses in the health and readiness checks.
+const (
+	statusOK          = "ok"
+	statusDegraded    = "degraded"
+	statusUnavailable = "unavailable"
+)
+
+type health struct {
+	Status string `json:"status"`
+}
+
+// readiness is whether the communities are there to be looked up, and
+// how fresh they are. Error is why it isn't ready when it isn't, so that
+// it can be read the same way as any other error.
+type readiness struct {
+	Status      string     `json:"status"`
+	Communities int        `json:"communities"`
+	LoadedAt    *time.Time `json:"loaded_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	Age         string     `json:"age,omitempty"`
+	MaxAge      string     `json:"max_age,omitempty"`
+	Version     string     `json:"version,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// getHealth is whether the server is up at all, for a liveness probe.
+// It doesn't look at the communities, since restarting the server
+// won't make them any fresher.
+func (s *Server) getHealth(rw http.ResponseWriter, r *http.Request) {
+	s.writeJSON(rw, http.StatusOK, health{statusOK})
+}
+
+// getReadiness is whether there are communities to look up, for a
+// readiness probe. Communities that are older than the max age are
+// degraded rather than unavailable, since old communities are better
+// than none, but it's there for monitoring to see.
+func (s *Server) getReadiness(rw http.ResponseWriter, r *http.Request) {
+	if s.isShuttingDown() {
+		s.writeJSON(rw, http.StatusServiceUnavailable, readiness{
+			Status: statusUnavailable,
+			Error:  "the server is shutting down",
+		})
+		return
+	}
+
+	communities := len(s.store.Index().Communities())
+	loaded, updated := s.store.Loaded(), s.store.Updated()
+
+	if communities == 0 || loaded.IsZero() {
+		s.writeJSON(rw, http.StatusServiceUnavailable, readiness{
+			Status: statusUnavailable,
+			Error:  "no communities have been loaded",
+		})
+		return
+	}
+
+	// Communities that were loaded from a store that hasn't been refreshed
+	// in a while are as old as the store is
+	age := time.Since(updated)
+	ready := readiness{
+		Status:      statusOK,
+		Communities: communities,
+		LoadedAt:    &loaded,
+		UpdatedAt:   &updated,
+		Age:         age.Round(time.Second).String(),
+		Version:     s.store.Version(),
+	}
+
+	if s.maxAge > 0 {
+		ready.MaxAge = s.maxAge.String()
+		if age > s.maxAge {
+			ready.Status = statusDegraded
+		}
+	}
+
+	s.writeJSON(rw, http.StatusOK, ready)
+}
+
+// notReady writes back a 503 if no communities have been loaded yet, rather
+// than answering as if none of them matched. It's the same as /readyz.
+func (s *Server) notReady(rw http.ResponseWriter, r *http.Request) bool {
+	if len(s.store.Index().Communities()) > 0 {
+		return false
+	}
+
+	s.writeError(rw, r, http.StatusServiceUnavailable, problem.NotReady, "no communities have been loaded yet")
+	return true
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+func TestGetHealth(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	rw := get(s, "/healthz")
+	if rw.Code != http.StatusOK || rw.Body.String() != `{"status":"ok"}`+"\n" {
+		t.Errorf("expected ok, got %d %s", rw.Code, rw.Body)
+	}
+}
+
+func TestGetReadiness(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	memory := store.NewMemory(communities)
+
+	tests := []struct {
+		maxAge time.Duration
+		status string
+	}{
+		{0, statusOK},
+		{time.Hour, statusOK},
+		{time.Nanosecond, statusDegraded},
+	}
+
+	for _, test := range tests {
+		s := New(memory, discardLogs, WithMaxAge(test.maxAge))
+		if test.maxAge == time.Nanosecond {
+			time.Sleep(time.Millisecond)
+		}
+
+		rw := get(s, "/readyz")
+
+		var ready readiness
+		if err := json.Unmarshal(rw.Body.Bytes(), &ready); rw.Code != http.StatusOK || err != nil {
+			t.Fatalf("expected to be ready with a max age of %s, got %d %s", test.maxAge, rw.Code, rw.Body)
+		}
+
+		if ready.Status != test.status || ready.Communities != len(communities) || ready.LoadedAt == nil || ready.Version != memory.Version() {
+			t.Errorf("expected %s with %d communities with a max age of %s, got %+v", test.status, len(communities), test.maxAge, ready)
+		}
+	}
+
+	// Communities that were loaded just now from a store that was refreshed
+	// a while ago are as old as the store
+	memory.SetUpdated(time.Now().Add(-48 * time.Hour))
+	var ready readiness
+	json.Unmarshal(get(New(memory, discardLogs, WithMaxAge(24*time.Hour)), "/readyz").Body.Bytes(), &ready)
+	if ready.Status != statusDegraded || ready.UpdatedAt == nil || ready.LoadedAt == nil || !ready.UpdatedAt.Before(*ready.LoadedAt) {
+		t.Errorf("expected communities from 48 hours ago to be degraded, got %+v", ready)
+	}
+
+	// Nothing to look up isn't ready at all
+	s := New(store.NewMemory(nil), discardLogs)
+	rw := get(s, "/readyz")
+
+	ready = readiness{}
+	if err := json.Unmarshal(rw.Body.Bytes(), &ready); rw.Code != http.StatusServiceUnavailable || err != nil || ready.Status != statusUnavailable || len(ready.Error) == 0 {
+		t.Errorf("expected to be unavailable, got %d %s", rw.Code, rw.Body)
+	}
+}
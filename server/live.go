@@ -0,0 +1,193 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"go.opentelemetry.io/otel/attribute"
+
+	"nfip-community-book/data"
+	"nfip-community-book/problem"
+)
+
+// How long the live search waits after the last term it was sent before
+// it searches, so that typing a word is one search rather than one for
+// every key.
+const liveSearchDebounce = 150 * time.Millisecond
+
+// The longest term that can be sent, which is a lot longer than any name.
+const liveSearchReadLimit = 1024
+
+// How long a client has to take the results before it's given up on.
+const liveSearchWriteTimeout = 10 * time.Second
+
+// A liveSearchPage is the results for the last term that was sent, along
+// with the term, so that the client can tell which one they're for.
+type liveSearchPage struct {
+	Term string `json:"q"`
+	searchPage
+}
+
+type projectedLiveSearchPage struct {
+	Term string `json:"q"`
+	projectedSearchPage
+}
+
+// liveSearch searches as the term is typed, over a WebSocket rather than a
+// request for every key. The client sends the whole term as text every
+// time it changes, and it's sent back a page of results for the last one
+// once it stops changing. The list parameters are given once, when it
+// connects, like /v1/search/live?state=TX&limit=10&fields=cid,community_name.
+func (s *Server) liveSearch(rw http.ResponseWriter, r *http.Request) {
+	if s.notReady(rw, r) {
+		return
+	}
+
+	q, err := parseQuery(r.URL.Query())
+	if err != nil {
+		s.writeError(rw, r, http.StatusBadRequest, problem.InvalidParameter, err.Error())
+		return
+	}
+
+	fields, err := parseFields(r.URL.Query())
+	if err != nil {
+		s.writeError(rw, r, http.StatusBadRequest, problem.InvalidParameter, err.Error())
+		return
+	}
+
+	// The timeouts don't apply, since the connection stays open for as
+	// long as the client is typing
+	rc := http.NewResponseController(rw)
+	rc.SetReadDeadline(time.Time{})
+	rc.SetWriteDeadline(time.Time{})
+
+	opts := &websocket.AcceptOptions{}
+	if s.cors != nil {
+		opts.OriginPatterns = s.cors.Origins
+	}
+
+	conn, err := websocket.Accept(rw, r, opts)
+	if err != nil {
+		// Accept has already written back why
+		return
+	}
+	defer conn.CloseNow()
+	conn.SetReadLimit(liveSearchReadLimit)
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	terms := make(chan string)
+	closed := make(chan error, 1)
+	go func() {
+		for {
+			typ, b, err := conn.Read(ctx)
+			if err != nil {
+				closed <- err
+				return
+			}
+			if typ != websocket.MessageText {
+				conn.Close(websocket.StatusUnsupportedData, "the term has to be sent as text")
+				closed <- nil
+				return
+			}
+
+			select {
+			case terms <- string(b):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		term     string
+		debounce <-chan time.Time
+		sent     int
+	)
+	for {
+		select {
+		case <-s.shuttingDown:
+			conn.Close(websocket.StatusGoingAway, "the server is shutting down")
+			return
+		case err := <-closed:
+			if err != nil && !isClosed(err) {
+				s.log.DebugContext(r.Context(), "the live search was closed", "err", err, "request_id", requestID(r.Context()))
+			}
+			return
+		case term = <-terms:
+			debounce = time.After(liveSearchDebounce)
+		case <-debounce:
+			debounce = nil
+
+			page := s.runLiveSearch(ctx, q, term)
+			b, err := liveSearchJSON(term, page, fields)
+			if err != nil {
+				s.log.ErrorContext(r.Context(), "could not write the live search", "err", err, "request_id", requestID(r.Context()))
+				conn.Close(websocket.StatusInternalError, "could not write the results")
+				return
+			}
+
+			writeCtx, cancelWrite := context.WithTimeout(ctx, liveSearchWriteTimeout)
+			err = conn.Write(writeCtx, websocket.MessageText, b)
+			cancelWrite()
+			if err != nil {
+				return
+			}
+
+			sent += len(page.Results)
+			countResults(r, sent)
+		}
+	}
+}
+
+// runLiveSearch searches for the term, which finds nothing if it's blank,
+// so that the results are cleared when the box is. A search that fails
+// finds nothing too.
+func (s *Server) runLiveSearch(ctx context.Context, q *data.Query, term string) data.SearchPage {
+	if len(strings.TrimSpace(term)) == 0 {
+		page := q.Run(nil)
+		page.Results = data.SearchResults{}
+		return page
+	}
+
+	ctx, span := s.startSpan(ctx, "live search", attribute.String("nfip.search.term", term))
+	defer span.End()
+
+	results, err := s.store.Search(ctx, term)
+	if err != nil {
+		span.RecordError(err)
+		s.log.ErrorContext(ctx, "could not search the communities", "err", err)
+	}
+
+	page := q.RunResults(results)
+	span.SetAttributes(attribute.Int("nfip.total", page.Total))
+
+	return page
+}
+
+func liveSearchJSON(term string, page data.SearchPage, fields []string) ([]byte, error) {
+	p := searchPage{APIVersion: v1, SearchPage: page}
+	if len(fields) == 0 {
+		return json.Marshal(liveSearchPage{term, p})
+	}
+
+	projected, err := p.projected(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(projectedLiveSearchPage{term, projected})
+}
+
+// isClosed is whether the client closed the connection rather than it
+// breaking.
+func isClosed(err error) bool {
+	status := websocket.CloseStatus(err)
+	return status == websocket.StatusNormalClosure || status == websocket.StatusGoingAway || errors.Is(err, context.Canceled)
+}
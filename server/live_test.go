@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+func dialLiveSearch(t *testing.T, ts *httptest.Server, query string) *websocket.Conn {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(ts.URL, "http")+"/v1/search/live"+query, nil)
+	if err != nil {
+		t.Fatalf("expected to connect to the live search, got %s", err)
+	}
+	t.Cleanup(func() { conn.CloseNow() })
+
+	return conn
+}
+
+func readLiveSearch(t *testing.T, conn *websocket.Conn) map[string]json.RawMessage {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, b, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatalf("expected results, got %s", err)
+	}
+
+	var page map[string]json.RawMessage
+	if err := json.Unmarshal(b, &page); err != nil {
+		t.Fatalf("expected the results as JSON, got %s", b)
+	}
+	return page
+}
+
+func TestLiveSearch(t *testing.T) {
+	s, _ := newTestServer(t)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	conn := dialLiveSearch(t, ts, "?limit=5&fields=cid,community_name")
+	ctx := context.Background()
+
+	// Typing quickly is only searched once it stops
+	for _, term := range []string{"h", "ho", "hou", "hous", "houston"} {
+		if err := conn.Write(ctx, websocket.MessageText, []byte(term)); err != nil {
+			t.Fatalf("expected to send %s, got %s", term, err)
+		}
+	}
+
+	page := readLiveSearch(t, conn)
+	if string(page["q"]) != `"houston"` || string(page["api_version"]) != `"v1"` {
+		t.Fatalf("expected the results for the last term, got %s %s", page["q"], page["api_version"])
+	}
+
+	var results []struct {
+		Community map[string]json.RawMessage `json:"community"`
+	}
+	json.Unmarshal(page["results"], &results)
+	if len(results) == 0 || len(results) > 5 || string(results[0].Community["cid"]) != "480296" || len(results[0].Community) != 2 {
+		t.Errorf("expected up to 5 results with Houston first and only its CID and name, got %s", page["results"])
+	}
+
+	// The results are cleared with the box
+	conn.Write(ctx, websocket.MessageText, []byte(" "))
+	if page := readLiveSearch(t, conn); string(page["total"]) != "0" || string(page["results"]) != "[]" {
+		t.Errorf("expected no results for a blank term, got %s %s", page["total"], page["results"])
+	}
+
+	conn.Write(ctx, websocket.MessageBinary, []byte("houston"))
+	if _, _, err := conn.Read(ctx); websocket.CloseStatus(err) != websocket.StatusUnsupportedData {
+		t.Errorf("expected binary messages to close the connection, got %v", err)
+	}
+}
+
+func TestLiveSearchParameters(t *testing.T) {
+	s, _ := newTestServer(t)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	_, resp, err := websocket.Dial(context.Background(), "ws"+strings.TrimPrefix(ts.URL, "http")+"/v1/search/live?fields=population", nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected an unknown field to be a bad request before connecting, got %v", err)
+	}
+
+	conn := dialLiveSearch(t, ts, "?state=LA")
+	conn.Write(context.Background(), websocket.MessageText, []byte("houston"))
+	if page := readLiveSearch(t, conn); string(page["total"]) != "0" {
+		t.Errorf("expected nothing in Louisiana, got %s", page["total"])
+	}
+}
+
+func TestLiveSearchShutdown(t *testing.T) {
+	s, _ := newTestServer(t)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	conn := dialLiveSearch(t, ts, "")
+	s.Shutdown(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, _, err := conn.Read(ctx); websocket.CloseStatus(err) != websocket.StatusGoingAway {
+		t.Errorf("expected the connection to be closed when the server shuts down, got %v", err)
+	}
+}
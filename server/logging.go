@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The header that a request's ID is taken from, if it has one, and that
+// it's sent back in.
+const requestIDHeader = "X-Request-ID"
+
+// The longest request ID that's taken from a request. IDs from a proxy,
+// like a UUID or a trace ID, are a lot shorter than this.
+const maxRequestIDLength = 128
+
+// WithLogHandler logs through the handler instead of as JSON lines to
+// stdout, like to send them somewhere else or to log them as text.
+func WithLogHandler(h slog.Handler) Option {
+	return func(s *Server) {
+		s.log = slog.New(h)
+	}
+}
+
+// A requestLog is what the handlers add to the line that's logged for
+// their request.
+type requestLog struct {
+	id      string
+	apiKey  string
+	results int
+	counted bool
+}
+
+type requestLogKey struct{}
+
+// countResults is how many communities were returned for the request.
+func countResults(r *http.Request, n int) {
+	if rl, ok := r.Context().Value(requestLogKey{}).(*requestLog); ok {
+		rl.results, rl.counted = n, true
+	}
+}
+
+// requestID is the ID of the request that's being handled, which is the
+// one it came with or one that was made up for it.
+func requestID(ctx context.Context) string {
+	if rl, ok := ctx.Value(requestLogKey{}).(*requestLog); ok {
+		return rl.id
+	}
+	return ""
+}
+
+// validRequestID is whether the ID that a request came with can be used as
+// it is. Anything that's too long, or that isn't letters, digits and a few
+// separators, is replaced, so that it can't break the logs or the header.
+func validRequestID(id string) bool {
+	if len(id) == 0 || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range id {
+		isLetterOrDigit := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+		if !isLetterOrDigit && !strings.ContainsRune("-_.:", r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// logRequests logs a line for every request once it's been answered,
+// with how long it took and how many communities it returned. Every
+// request has an ID, which is the one it came with in X-Request-ID, like
+// from a proxy or another service, or a new one. It's sent back in the
+// same header, and it's in errors and the request's span too, so that a
+// request someone has trouble with can be found in the logs and traces.
+func (s *Server) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		rl := &requestLog{id: r.Header.Get(requestIDHeader)}
+		if !validRequestID(rl.id) {
+			rl.id = newRequestID()
+		}
+		rw.Header().Set(requestIDHeader, rl.id)
+		trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("nfip.request_id", rl.id))
+
+		sw := &statusWriter{ResponseWriter: rw}
+		next.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), requestLogKey{}, rl)))
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", sw.Status()),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("request_id", rl.id),
+		}
+		if len(rl.apiKey) > 0 {
+			attrs = append(attrs, slog.String("api_key", rl.apiKey))
+		}
+		if rl.counted {
+			attrs = append(attrs, slog.Int("results", rl.results))
+		}
+		if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+			attrs = append(attrs, slog.String("trace_id", sc.TraceID().String()))
+		}
+
+		level := slog.LevelInfo
+		if sw.Status() >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		s.log.LogAttrs(r.Context(), level, "request", attrs...)
+	})
+}
+
+// A statusWriter keeps the status that was written, so that it can be
+// logged. It can still be flushed, for the responses that are streamed.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	if sw.status == 0 {
+		sw.status = status
+	}
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+func (sw *statusWriter) Write(b []byte) (int, error) {
+	if sw.status == 0 {
+		sw.status = http.StatusOK
+	}
+	return sw.ResponseWriter.Write(b)
+}
+
+func (sw *statusWriter) FlushError() error {
+	return http.NewResponseController(sw.ResponseWriter).Flush()
+}
+
+func (sw *statusWriter) Flush() {
+	sw.FlushError()
+}
+
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+// Status is what was written, which is a 200 if nothing was.
+func (sw *statusWriter) Status() int {
+	if sw.status == 0 {
+		return http.StatusOK
+	}
+	return sw.status
+}
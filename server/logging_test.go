@@ -0,0 +1,86 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"nfip-community-book/data"
+	"nfip-community-book/problem"
+	"nfip-community-book/store"
+)
+
+func TestLogRequests(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+
+	var buf bytes.Buffer
+	s := New(store.NewMemory(communities), WithLogHandler(slog.NewJSONHandler(&buf, nil)))
+
+	r := httptest.NewRequest(http.MethodGet, "/communities?state=TX", nil)
+	r.Header.Set(requestIDHeader, "abc123")
+	s.ServeHTTP(httptest.NewRecorder(), r)
+	get(s, "/communities/1")
+
+	var lines []map[string]any
+	d := json.NewDecoder(&buf)
+	for d.More() {
+		var line map[string]any
+		if err := d.Decode(&line); err != nil {
+			t.Fatalf("expected JSON lines, got %s", err)
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) != 2 {
+		t.Fatalf("expected a line for each request, got %d", len(lines))
+	}
+
+	first := lines[0]
+	if first["msg"] != "request" || first["method"] != "GET" || first["path"] != "/communities" || first["status"] != float64(200) || first["results"] != float64(3) || first["request_id"] != "abc123" {
+		t.Errorf("expected the request for the communities in TX, got %v", first)
+	}
+	if _, ok := first["latency_ms"].(float64); !ok {
+		t.Errorf("expected how long it took, got %v", first["latency_ms"])
+	}
+
+	// Requests without an ID get one, and nothing found isn't counted
+	second := lines[1]
+	if second["status"] != float64(404) || len(second["request_id"].(string)) != 16 || second["results"] != nil {
+		t.Errorf("expected a 404 with a new ID, got %v", second)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/communities/999999", nil)
+	r.Header.Set(requestIDHeader, "support-ticket-42")
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, r)
+
+	var body problem.Problem
+	json.Unmarshal(rw.Body.Bytes(), &body)
+	if rw.Header().Get(requestIDHeader) != "support-ticket-42" || body.RequestID != "support-ticket-42" {
+		t.Errorf("expected the ID to be sent back in the header and the error, got %s %s", rw.Header().Get(requestIDHeader), rw.Body)
+	}
+
+	// IDs that could break the logs are replaced
+	for _, id := range []string{"bad id\nlevel=ERROR", strings.Repeat("a", maxRequestIDLength+1)} {
+		r := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+		r.Header.Set(requestIDHeader, id)
+		rw := httptest.NewRecorder()
+		s.ServeHTTP(rw, r)
+
+		if got := rw.Header().Get(requestIDHeader); got == id || len(got) != 16 {
+			t.Errorf("expected %q to be replaced, got %q", id, got)
+		}
+	}
+
+	if rw := get(s, "/healthz"); len(rw.Header().Get(requestIDHeader)) != 16 {
+		t.Errorf("expected a new ID without one, got %q", rw.Header().Get(requestIDHeader))
+	}
+}
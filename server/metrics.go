@@ -0,0 +1,43 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// The content type of Prometheus' text format.
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// getMetrics is how much each API key has been used, in Prometheus' text
+// format so that it can be scraped. Keys are only ever named, never shown.
+func (s *Server) getMetrics(rw http.ResponseWriter, r *http.Request) {
+	names := append([]string(nil), s.apiKeyNames...)
+	sort.Strings(names)
+
+	byName := make(map[string]*apiKey, len(s.apiKeys))
+	for _, key := range s.apiKeys {
+		byName[key.name] = key
+	}
+
+	var b strings.Builder
+	for _, metric := range []struct {
+		name, help string
+		value      func(*apiKey) int64
+	}{
+		{"nfip_api_key_requests_total", "Requests made with each API key.", func(k *apiKey) int64 { return k.requests.Load() }},
+		{"nfip_api_key_rate_limited_total", "Requests with each API key that were over its rate limit.", func(k *apiKey) int64 { return k.limited.Load() }},
+	} {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n", metric.name, metric.help, metric.name)
+		for _, name := range names {
+			fmt.Fprintf(&b, "%s{key=%q} %d\n", metric.name, name, metric.value(byName[name]))
+		}
+	}
+
+	fmt.Fprintf(&b, "# HELP nfip_unauthorized_requests_total Requests without a known API key.\n# TYPE nfip_unauthorized_requests_total counter\n")
+	fmt.Fprintf(&b, "nfip_unauthorized_requests_total %d\n", s.unauthorized.Load())
+
+	rw.Header().Set("Content-Type", metricsContentType)
+	rw.Write([]byte(b.String()))
+}
@@ -0,0 +1,439 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"nfip-community-book/data"
+	"nfip-community-book/problem"
+)
+
+// OpenAPI 3.1 is used since its schemas are JSON Schema, so the
+// community's schema from data.GenerateJSONSchema can be used as it is.
+const openAPIVersion = "3.1.0"
+
+// The version of the API in the OpenAPI document.
+const apiVersion = "1.2.0"
+
+// A schema is a JSON Schema, written out the same way it's written here.
+type schema map[string]any
+
+// A parameter is one of an endpoint's path or query parameters.
+type parameter struct {
+	Name        string `json:"name"`
+	In          string `json:"in"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Schema      schema `json:"schema"`
+}
+
+// listParameters are the parameters that parseQuery takes.
+func listParameters() []parameter {
+	var sorts []string
+	for name := range sortFields {
+		sorts = append(sorts, name)
+	}
+	sort.Strings(sorts)
+
+	params := []parameter{
+		{Name: "limit", In: "query", Description: "How many to return at most.", Schema: schema{"type": "integer", "minimum": 1, "maximum": maxLimit, "default": defaultLimit}},
+		{Name: "offset", In: "query", Description: "How many to skip.", Schema: schema{"type": "integer", "minimum": 0, "default": 0}},
+		{Name: "sort", In: "query", Description: "What they're sorted by. Relevance is the best matches first for searches, and the status book's order otherwise.", Schema: schema{"type": "string", "enum": sorts, "default": "relevance"}},
+		{Name: "state", In: "query", Description: "Only the communities in the state, as an abbreviation, a name or a FIPS code.", Schema: schema{"type": "string"}},
+		{Name: "participating", In: "query", Description: "Only the communities that are participating in the NFIP, or that aren't.", Schema: schema{"type": "boolean"}},
+		{Name: "tribal", In: "query", Description: "Only the tribal communities, or the ones that aren't.", Schema: schema{"type": "boolean"}},
+		{Name: "crs_class_max", In: "query", Description: "Only the communities in the CRS with this class or better.", Schema: schema{"type": "integer", "minimum": 1, "maximum": 10}},
+	}
+
+	for _, param := range dateParams {
+		params = append(params,
+			parameter{Name: param.name + "_from", In: "query", Description: "Only the communities with a " + param.name + " on or after this day.", Schema: schema{"type": "string", "format": "date"}},
+			parameter{Name: param.name + "_to", In: "query", Description: "Only the communities with a " + param.name + " on or before this day.", Schema: schema{"type": "string", "format": "date"}},
+		)
+	}
+
+	return params
+}
+
+// openAPIDocument describes every route.
+func openAPIDocument() ([]byte, error) {
+	schemas, err := openAPISchemas()
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]map[string]any)
+	for _, rt := range routes() {
+		params := rt.params
+		ok := response("OK", rt.response)
+		if len(rt.contentType) > 0 {
+			ok["content"] = map[string]any{rt.contentType: map[string]any{"schema": ref(rt.response)}}
+		}
+		if rt.list {
+			params = append(append([]parameter{}, params...), listParameters()...)
+			params = append(params, fieldsParameter(), formatParameter(), ifNoneMatchParameter)
+			addFormats(ok)
+		}
+
+		status := http.StatusOK
+		if rt.status != 0 {
+			status = rt.status
+		}
+		ok["description"] = http.StatusText(status)
+
+		responses := map[string]any{
+			strconv.Itoa(status): ok,
+		}
+		if rt.list {
+			responses["304"] = map[string]any{"description": "The communities haven't changed since the ETag in If-None-Match."}
+		}
+		// The probes answer with what they always do, even when it's not ok
+		for _, status := range rt.errors {
+			if isProbe(rt.path) {
+				responses[strconv.Itoa(status)] = response(http.StatusText(status), rt.response)
+			} else {
+				responses[strconv.Itoa(status)] = problemResponse(http.StatusText(status))
+			}
+		}
+
+		operation := map[string]any{
+			"summary":   rt.summary,
+			"responses": responses,
+		}
+		if len(params) > 0 {
+			operation["parameters"] = params
+		}
+		if len(rt.request) > 0 {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": ref(rt.request)},
+				},
+			}
+		}
+
+		// Keys are only needed when the server has them
+		if !isProbe(rt.path) && rt.path != "/openapi.json" {
+			security := []map[string][]string{{"bearer": {}}, {"apiKey": {}}}
+			if rt.tokens {
+				security = append(security, map[string][]string{"token": {}}, map[string][]string{"tokenCookie": {}})
+			}
+			operation["security"] = append(security, map[string][]string{})
+		}
+
+		path := rt.fullPath()
+		if paths[path] == nil {
+			paths[path] = make(map[string]any)
+		}
+		paths[path][strings.ToLower(rt.method)] = operation
+	}
+
+	return json.MarshalIndent(map[string]any{
+		"openapi": openAPIVersion,
+		"info": map[string]any{
+			"title":       "NFIP Community Status Book",
+			"description": "Look up and search the communities in FEMA's NFIP Community Status Book.",
+			"version":     apiVersion,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"bearer":      map[string]any{"type": "http", "scheme": "bearer", "description": "An API key, when the server needs one."},
+				"apiKey":      map[string]any{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+				"token":       map[string]any{"type": "apiKey", "in": "query", "name": tokenParameter, "description": "A token from POST /v1/tokens."},
+				"tokenCookie": map[string]any{"type": "apiKey", "in": "cookie", "name": tokenCookie, "description": "The cookie that POST /v1/tokens sets."},
+			},
+		},
+	}, "", "  ")
+}
+
+func response(description, name string) map[string]any {
+	return map[string]any{
+		"description": description,
+		"content": map[string]any{
+			"application/json": map[string]any{"schema": ref(name)},
+		},
+	}
+}
+
+// problemResponse is an error, which is written as a problem.
+func problemResponse(description string) map[string]any {
+	return map[string]any{
+		"description": description,
+		"content": map[string]any{
+			problem.ContentType: map[string]any{"schema": ref("Problem")},
+		},
+	}
+}
+
+// formatParameter picks the format instead of the Accept header.
+func formatParameter() parameter {
+	var names []string
+	for _, f := range formats {
+		names = append(names, f.name)
+	}
+
+	return parameter{Name: "format", In: "query", Description: "The format to return, instead of the one in the Accept header.", Schema: schema{"type": "string", "enum": names, "default": "json"}}
+}
+
+// fieldsParameter picks the communities' fields that are returned.
+func fieldsParameter() parameter {
+	return parameter{Name: "fields", In: "query", Description: "Only these fields of the communities, separated by commas, like cid,community_name. The communities only have the ones that were picked, even if they're required otherwise.", Schema: schema{"type": "string", "examples": []string{"cid,community_name,state,cur_class"}}}
+}
+
+var ifNoneMatchParameter = parameter{Name: "If-None-Match", In: "header", Description: "The ETag of the last response, to get a 304 if the communities haven't changed since.", Schema: schema{"type": "string"}}
+
+// addFormats adds the formats other than JSON to a list's response,
+// which are only the communities on the page.
+func addFormats(resp map[string]any) {
+	content := resp["content"].(map[string]any)
+	for _, f := range formats {
+		if f.write == nil {
+			continue
+		}
+
+		s := schema{"type": "array", "items": ref("Community")}
+		if f.name == "csv" {
+			s = schema{"type": "string", "description": "The communities in the same columns as the status book's nation.csv."}
+		}
+		content[f.mediaType] = map[string]any{"schema": s}
+	}
+
+	resp["headers"] = map[string]any{
+		"ETag":          map[string]any{"description": "The version of the communities, for If-None-Match.", "schema": schema{"type": "string"}},
+		"X-Total-Count": map[string]any{"description": "How many there are on every page, when it isn't JSON.", "schema": schema{"type": "integer"}},
+		"Link":          map[string]any{"description": "The next page, when it isn't JSON.", "schema": schema{"type": "string"}},
+	}
+}
+
+func ref(name string) schema {
+	return schema{"$ref": "#/components/schemas/" + name}
+}
+
+// openAPISchemas are the schemas of everything the API returns. The
+// community's $defs are moved in with them, since a reference to them
+// from the community would be to the top of the OpenAPI document.
+func openAPISchemas() (map[string]any, error) {
+	b, err := data.GenerateJSONSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	var community schema
+	if err := json.Unmarshal(b, &community); err != nil {
+		return nil, err
+	}
+
+	schemas := map[string]any{
+		"Community": community,
+		"SearchResult": schema{
+			"type": "object",
+			"properties": schema{
+				"community": ref("Community"),
+				"score":     schema{"type": "number", "description": "How well the community matched, higher being better."},
+				"matches": schema{
+					"type":        "array",
+					"description": "Where the term matched in the community's fields.",
+					"items": schema{
+						"type": "object",
+						"properties": schema{
+							"field": schema{"type": "string"},
+							"start": schema{"type": "integer"},
+							"end":   schema{"type": "integer"},
+						},
+					},
+				},
+			},
+			"required": []string{"community", "score"},
+		},
+		"CommunitiesPage": page("communities", ref("Community")),
+		"SearchPage":      searchPageSchema(),
+		"Problem": schema{
+			"type":        "object",
+			"description": "An error, as problem details from RFC 7807.",
+			"properties": schema{
+				"type":       schema{"type": "string", "const": "about:blank"},
+				"title":      schema{"type": "string", "description": "The status's text."},
+				"status":     schema{"type": "integer"},
+				"detail":     schema{"type": "string", "description": "What went wrong, for people."},
+				"instance":   schema{"type": "string", "description": "The path that was requested."},
+				"code":       schema{"type": "string", "enum": problem.Codes(), "description": "What went wrong, for programs."},
+				"request_id": schema{"type": "string", "description": "The ID of the request, which is the same as its X-Request-ID header."},
+				"error":      schema{"type": "string", "description": "The same as the detail, for clients from before errors were problems.", "deprecated": true},
+			},
+			"required": []string{"type", "title", "status", "code"},
+		},
+		"LookupRequest": schema{
+			"type":        "array",
+			"description": "The CIDs to look up.",
+			"items":       schema{"type": "integer"},
+			"maxItems":    maxLookup,
+		},
+		"LookupResult": schema{
+			"type": "object",
+			"properties": schema{
+				"api_version": apiVersionSchema,
+				"communities": schema{"type": "array", "items": ref("Community"), "description": "The communities that were found, in the order their CIDs were given."},
+				"unknown":     schema{"type": "array", "items": schema{"type": "integer"}, "description": "The CIDs that weren't found."},
+			},
+			"required": []string{"api_version", "communities", "unknown"},
+		},
+		"GraphQLRequest": schema{
+			"type": "object",
+			"properties": schema{
+				"query":         schema{"type": "string"},
+				"variables":     schema{"type": "object"},
+				"operationName": schema{"type": "string"},
+			},
+			"required": []string{"query"},
+		},
+		"GraphQLResponse": schema{
+			"type":        "object",
+			"description": "The fields that were asked for in data, and what went wrong in errors. Errors in the query are here rather than being a 400.",
+			"properties": schema{
+				"data":   schema{"type": "object"},
+				"errors": schema{"type": "array", "items": schema{"type": "object"}},
+			},
+		},
+		"Health": schema{
+			"type":       "object",
+			"properties": schema{"status": schema{"type": "string", "enum": []string{statusOK}}},
+			"required":   []string{"status"},
+		},
+		"Readiness": schema{
+			"type":        "object",
+			"description": "Degraded when the communities are older than the server's max age, which is still ready.",
+			"properties": schema{
+				"status":      schema{"type": "string", "enum": []string{statusOK, statusDegraded, statusUnavailable}},
+				"communities": schema{"type": "integer"},
+				"loaded_at":   schema{"type": "string", "format": "date-time"},
+				"updated_at":  schema{"type": "string", "format": "date-time", "description": "When they were downloaded, or their store was last refreshed."},
+				"age":         schema{"type": "string", "description": "How long ago they were updated, like 36h0m0s."},
+				"max_age":     schema{"type": "string"},
+				"version":     schema{"type": "string", "description": "The same version that's in the lists' ETags."},
+			},
+			"required": []string{"status", "communities"},
+		},
+		"RefreshStatus": schema{
+			"type": "object",
+			"properties": schema{
+				"running":       schema{"type": "boolean"},
+				"stage":         schema{"type": "string", "enum": []string{stageDownloading, stageIndexing}, "description": "How far along the refresh that's running is."},
+				"started_at":    schema{"type": "string", "format": "date-time"},
+				"last_success":  schema{"type": "string", "format": "date-time"},
+				"last_changes":  schema{"type": "object", "properties": schema{"added": schema{"type": "integer"}, "updated": schema{"type": "integer"}, "removed": schema{"type": "integer"}}},
+				"last_error":    schema{"type": "string"},
+				"last_error_at": schema{"type": "string", "format": "date-time"},
+			},
+			"required": []string{"running"},
+		},
+		"RefreshEvent": schema{
+			"type":        "object",
+			"description": "The data of each refresh event, which is sent with the version as its ID.",
+			"properties": schema{
+				"version":   schema{"type": "string", "description": "The same version that's in the lists' ETags."},
+				"loaded_at": schema{"type": "string", "format": "date-time"},
+				"added":     schema{"type": "integer"},
+				"updated":   schema{"type": "integer"},
+				"removed":   schema{"type": "integer"},
+			},
+			"required": []string{"version", "loaded_at", "added", "updated", "removed"},
+		},
+		"LiveSearchResult": schema{
+			"description": "Each message that's sent back on the live search's WebSocket, which is the page of results for the last term that was sent.",
+			"allOf": []schema{
+				ref("SearchPage"),
+				{"type": "object", "properties": schema{"q": schema{"type": "string", "description": "The term that the results are for."}}, "required": []string{"q"}},
+			},
+		},
+		"Token": schema{
+			"type": "object",
+			"properties": schema{
+				"token":      schema{"type": "string"},
+				"expires_at": schema{"type": "string", "format": "date-time", "description": "When it can't be used to connect anymore."},
+			},
+			"required": []string{"token", "expires_at"},
+		},
+		"Metrics": schema{"type": "string", "description": "Counters in Prometheus' text format."},
+		"OpenAPI": schema{"type": "object", "description": "An OpenAPI " + openAPIVersion + " document."},
+	}
+
+	delete(community, "$schema")
+	if defs, ok := community["$defs"].(map[string]any); ok {
+		for name, def := range defs {
+			schemas[name] = def
+		}
+		delete(community, "$defs")
+	}
+	moveDefs(community)
+
+	return schemas, nil
+}
+
+// moveDefs points the references to the community's $defs to where
+// they've been moved to.
+func moveDefs(v any) {
+	switch v := v.(type) {
+	case schema:
+		moveDefs(map[string]any(v))
+	case map[string]any:
+		if r, ok := v["$ref"].(string); ok {
+			if name, ok := strings.CutPrefix(r, "#/$defs/"); ok {
+				v["$ref"] = "#/components/schemas/" + name
+			}
+		}
+		for _, child := range v {
+			moveDefs(child)
+		}
+	case []any:
+		for _, child := range v {
+			moveDefs(child)
+		}
+	}
+}
+
+var apiVersionSchema = schema{"type": "string", "enum": []string{v1}, "description": "The version of the API the response is from."}
+
+// page is the schema of a page of the items.
+func page(items string, item schema) schema {
+	return schema{
+		"type": "object",
+		"properties": schema{
+			"api_version": apiVersionSchema,
+			"total":       schema{"type": "integer", "description": "How many there are on every page."},
+			"offset":      schema{"type": "integer"},
+			"limit":       schema{"type": "integer"},
+			items:         schema{"type": "array", "items": item},
+			"next":        schema{"type": "string", "format": "uri-reference", "description": "The page after this one. Left out on the last page."},
+		},
+		"required": []string{"api_version", "total", "offset", "limit", items},
+	}
+}
+
+// searchPageSchema is a page of results, with the names that were probably
+// meant when nothing was found.
+func searchPageSchema() schema {
+	s := page("results", ref("SearchResult"))
+	s["properties"].(schema)["did_you_mean"] = schema{
+		"type":        "array",
+		"items":       schema{"type": "string"},
+		"description": "Community and county names close to the term, when nothing was found.",
+	}
+	return s
+}
+
+func (s *Server) getOpenAPI(rw http.ResponseWriter, r *http.Request) {
+	doc, err := openAPIDocument()
+	if err != nil {
+		s.log.ErrorContext(r.Context(), "could not make the OpenAPI document", "err", err, "request_id", requestID(r.Context()))
+		s.writeError(rw, r, http.StatusInternalServerError, problem.Internal, "could not make the OpenAPI document")
+		return
+	}
+
+	rw.Header().Set("Content-Type", jsonContentType)
+	rw.Write(doc)
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGetOpenAPI(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	rw := get(s, "/openapi.json")
+	if rw.Code != http.StatusOK || rw.Header().Get("Content-Type") != jsonContentType {
+		t.Fatalf("expected JSON, got %d %s", rw.Code, rw.Header().Get("Content-Type"))
+	}
+
+	var doc struct {
+		OpenAPI    string                                `json:"openapi"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rw.Body.Bytes(), &doc); err != nil || doc.OpenAPI != openAPIVersion {
+		t.Fatalf("expected an OpenAPI %s document, got %s %v", openAPIVersion, doc.OpenAPI, err)
+	}
+
+	for _, rt := range routes() {
+		if _, ok := doc.Paths[rt.fullPath()][strings.ToLower(rt.method)]; !ok {
+			t.Errorf("expected %s %s to be in the document", rt.method, rt.fullPath())
+		}
+	}
+
+	// Every reference has to be to one of the schemas
+	for _, match := range regexp.MustCompile(`"\$ref": "([^"]*)"`).FindAllStringSubmatch(rw.Body.String(), -1) {
+		name, ok := strings.CutPrefix(match[1], "#/components/schemas/")
+		if _, found := doc.Components.Schemas[name]; !ok || !found {
+			t.Errorf("expected %s to be one of the schemas", match[1])
+		}
+	}
+
+	if !strings.Contains(rw.Body.String(), `"name": "curr_eff_map_date_from"`) {
+		t.Errorf("expected the date ranges to be described")
+	}
+}
+
+// The list parameters in the document have to be the ones that parseQuery
+// reads, so every one that isn't any string has to be checked by it.
+func TestListParametersAreParsed(t *testing.T) {
+	for _, param := range listParameters() {
+		typ, format := param.Schema["type"], param.Schema["format"]
+		if typ == "string" && format == nil && param.Schema["enum"] == nil {
+			continue
+		}
+
+		if _, err := parseQuery(url.Values{param.Name: {"invalid"}}); err == nil {
+			t.Errorf("expected %s to be parsed", param.Name)
+		}
+	}
+}
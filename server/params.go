@@ -0,0 +1,161 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"nfip-community-book/data"
+)
+
+// How many communities are on a page if a limit isn't given,
+// and the most that can be asked for at once.
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+// The layout of the dates in the date range parameters, which
+// is the same as the dates in a community's JSON.
+const dateLayout = "2006-01-02"
+
+var sortFields = map[string]data.SortField{
+	"relevance": data.Relevance,
+	"name":      data.Name,
+	"cid":       data.CID,
+	"crs_class": data.CRSClass,
+}
+
+// The dates that can be filtered by a range, by their names in the JSON.
+// Each one has a "_from" and "_to" parameter, which both include the day.
+var dateParams = []struct {
+	name  string
+	field data.DateField
+}{
+	{"fhbm_identified", data.FHBMIdentifiedField},
+	{"firm_identified", data.FIRMIdentifiedField},
+	{"curr_eff_map_date", data.CurrEffMapDateField},
+	{"reg_emer_date", data.RegEmerDateField},
+	{"crs_entry_date", data.CRSEntryDateField},
+	{"curr_eff_date", data.CurrEffDateField},
+}
+
+// The latest a date range goes to when it only has a "_from".
+var endOfTime = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.Local)
+
+// parseQuery builds a query from the paging, sorting and filtering
+// parameters that every list of communities takes.
+func parseQuery(values url.Values) (*data.Query, error) {
+	q := data.NewQuery().Limit(defaultLimit)
+
+	if s := values.Get("limit"); len(s) > 0 {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit < 1 || limit > maxLimit {
+			return nil, fmt.Errorf("invalid limit %s, it has to be from 1 to %d", strconv.Quote(s), maxLimit)
+		}
+		q.Limit(limit)
+	}
+
+	if s := values.Get("offset"); len(s) > 0 {
+		offset, err := strconv.Atoi(s)
+		if err != nil || offset < 0 {
+			return nil, fmt.Errorf("invalid offset %s", strconv.Quote(s))
+		}
+		q.Offset(offset)
+	}
+
+	if s := values.Get("sort"); len(s) > 0 {
+		field, ok := sortFields[s]
+		if !ok {
+			return nil, fmt.Errorf("invalid sort %s, it has to be relevance, name, cid or crs_class", strconv.Quote(s))
+		}
+		q.SortBy(field)
+	}
+
+	if state := values.Get("state"); len(state) > 0 {
+		q.State(state)
+	}
+
+	for _, param := range []struct {
+		name   string
+		filter func(bool) data.Filter
+	}{
+		{"participating", data.Participating},
+		{"tribal", data.Tribal},
+	} {
+		s := values.Get(param.name)
+		if len(s) == 0 {
+			continue
+		}
+
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %s, it has to be true or false", param.name, strconv.Quote(s))
+		}
+		q.Where(param.filter(b))
+	}
+
+	if s := values.Get("crs_class_max"); len(s) > 0 {
+		class, err := strconv.Atoi(s)
+		if err != nil || class < 1 || class > 10 {
+			return nil, fmt.Errorf("invalid crs_class_max %s, it has to be from 1 to 10", strconv.Quote(s))
+		}
+		q.CRSClassAtMost(class)
+	}
+
+	for _, param := range dateParams {
+		from, err := parseDateParam(values, param.name+"_from")
+		if err != nil {
+			return nil, err
+		}
+
+		to, err := parseDateParam(values, param.name+"_to")
+		if err != nil {
+			return nil, err
+		}
+
+		if from == nil && to == nil {
+			continue
+		}
+
+		if from == nil {
+			from = &time.Time{}
+		}
+		if to == nil {
+			to = &endOfTime
+		}
+		q.Where(data.DateBetween(param.field, *from, *to))
+	}
+
+	return q, nil
+}
+
+// Dates are in the local time zone, the same as the communities' dates are.
+func parseDateParam(values url.Values, name string) (*time.Time, error) {
+	s := values.Get(name)
+	if len(s) == 0 {
+		return nil, nil
+	}
+
+	t, err := time.ParseInLocation(dateLayout, s, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %s, it has to be a date like 2006-01-02", name, strconv.Quote(s))
+	}
+
+	return &t, nil
+}
+
+// nextPage is the link to the page after this one, or nothing if it's the last.
+func nextPage(u *url.URL, page data.SearchPage) string {
+	next := page.Offset + len(page.Results)
+	if next >= page.Total || len(page.Results) == 0 {
+		return ""
+	}
+
+	values := u.Query()
+	values.Set("offset", strconv.Itoa(next))
+	values.Set("limit", strconv.Itoa(page.Limit))
+
+	return (&url.URL{Path: u.Path, RawQuery: values.Encode()}).String()
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"net/url"
+	"testing"
+
+	"nfip-community-book/data"
+)
+
+func TestParseQuery(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+
+	tests := []struct {
+		query string
+		keep  func(c data.NFIPCommunityStatus) bool
+	}{
+		{"state=TX", func(c data.NFIPCommunityStatus) bool { return c.State == "TX" }},
+		{"participating=false", func(c data.NFIPCommunityStatus) bool { return !c.ParticipatingCommunity }},
+		{"tribal=true", func(c data.NFIPCommunityStatus) bool { return c.Tribal }},
+		{"crs_class_max=5", func(c data.NFIPCommunityStatus) bool { return c.IsCRSParticipant() && *c.CurClass <= 5 }},
+		{"curr_eff_map_date_from=2010-01-01", func(c data.NFIPCommunityStatus) bool {
+			return c.CurrEffMapDate != nil && c.CurrEffMapDate.Year() >= 2010
+		}},
+		{"curr_eff_map_date_from=2000-01-01&curr_eff_map_date_to=2009-12-31", func(c data.NFIPCommunityStatus) bool {
+			return c.CurrEffMapDate != nil && c.CurrEffMapDate.Year() >= 2000 && c.CurrEffMapDate.Year() <= 2009
+		}},
+	}
+
+	for _, test := range tests {
+		values, _ := url.ParseQuery(test.query)
+		q, err := parseQuery(values)
+		if err != nil {
+			t.Errorf("expected %s to be parsed, got %s", test.query, err)
+			continue
+		}
+
+		page := q.Limit(maxLimit).Run(communities)
+
+		want := 0
+		for _, c := range communities {
+			if test.keep(c) {
+				want++
+			}
+		}
+
+		if page.Total != want {
+			t.Errorf("expected %d communities for %s, got %d", want, test.query, page.Total)
+		}
+
+		for _, result := range page.Results {
+			if !test.keep(result.Community) {
+				t.Errorf("expected %d not to be kept for %s", result.Community.CID, test.query)
+			}
+		}
+	}
+}
+
+func TestParseQueryInvalid(t *testing.T) {
+	for _, query := range []string{
+		"limit=0",
+		"limit=1001",
+		"limit=ten",
+		"offset=-1",
+		"sort=state",
+		"participating=maybe",
+		"crs_class_max=11",
+		"firm_identified_from=01/02/2006",
+	} {
+		values, _ := url.ParseQuery(query)
+		if _, err := parseQuery(values); err == nil {
+			t.Errorf("expected %s to be invalid", query)
+		}
+	}
+}
+
+func TestNextPage(t *testing.T) {
+	u, _ := url.Parse("/search?q=houston&limit=2")
+
+	tests := []struct {
+		page data.SearchPage
+		next string
+	}{
+		{data.SearchPage{Total: 5, Offset: 0, Limit: 2, Results: make(data.SearchResults, 2)}, "/search?limit=2&offset=2&q=houston"},
+		{data.SearchPage{Total: 5, Offset: 4, Limit: 2, Results: make(data.SearchResults, 1)}, ""},
+		{data.SearchPage{Total: 0, Offset: 0, Limit: 2}, ""},
+	}
+
+	for _, test := range tests {
+		if next := nextPage(u, test.page); next != test.next {
+			t.Errorf("expected %q, got %q", test.next, next)
+		}
+	}
+}
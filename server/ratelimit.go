@@ -0,0 +1,198 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"nfip-community-book/problem"
+)
+
+var ErrInvalidRateLimit = fmt.Errorf("invalid rate limit")
+
+// Clients that haven't made a request in this long are forgotten, and
+// they're looked for this often.
+const (
+	idleClient      = 3 * time.Minute
+	forgetIdleEvery = time.Minute
+)
+
+// WithIPRateLimit lets each client IP make perSecond requests a second,
+// with bursts of up to burst at once. Search boxes that autocomplete as
+// they're typed in make a lot of requests, so burst has to leave room
+// for them.
+func WithIPRateLimit(perSecond float64, burst int) Option {
+	return func(s *Server) {
+		s.ipLimits = &ipLimits{limit: rate.Limit(perSecond), burst: burst, clients: make(map[string]*client)}
+	}
+}
+
+// WithGlobalRateLimit lets every client together make perSecond requests
+// a second, with bursts of up to burst at once.
+func WithGlobalRateLimit(perSecond float64, burst int) Option {
+	return func(s *Server) {
+		s.globalLimit = rate.NewLimiter(rate.Limit(perSecond), burst)
+	}
+}
+
+// ipLimits is a token bucket for each client IP.
+type ipLimits struct {
+	mu         sync.Mutex
+	limit      rate.Limit
+	burst      int
+	clients    map[string]*client
+	lastForgot time.Time
+}
+
+type client struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func (il *ipLimits) reserve(ip string, now time.Time) *rate.Reservation {
+	il.mu.Lock()
+	defer il.mu.Unlock()
+
+	if now.Sub(il.lastForgot) > forgetIdleEvery {
+		for ip, c := range il.clients {
+			if now.Sub(c.lastSeen) > idleClient {
+				delete(il.clients, ip)
+			}
+		}
+		il.lastForgot = now
+	}
+
+	c, ok := il.clients[ip]
+	if !ok {
+		c = &client{limiter: rate.NewLimiter(il.limit, il.burst)}
+		il.clients[ip] = c
+	}
+	c.lastSeen = now
+
+	return c.limiter.ReserveN(now, 1)
+}
+
+// limitRate turns away requests over the limits with a 429. Health checks
+// and metrics are never turned away, since a busy server isn't an
+// unhealthy one.
+func (s *Server) limitRate(next http.Handler) http.Handler {
+	if s.ipLimits == nil && s.globalLimit == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if isProbe(r.URL.Path) {
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		now := time.Now()
+		if s.tooManyRequests(rw, r, now, s.reserve(clientIP(r), now)...) {
+			return
+		}
+
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// reserve takes a request from the IP's limit and the global one.
+func (s *Server) reserve(ip string, now time.Time) []*rate.Reservation {
+	var reservations []*rate.Reservation
+	if s.ipLimits != nil {
+		reservations = append(reservations, s.ipLimits.reserve(ip, now))
+	}
+	if s.globalLimit != nil {
+		reservations = append(reservations, s.globalLimit.ReserveN(now, 1))
+	}
+
+	return reservations
+}
+
+// isProbe is whether the path is one that monitoring polls.
+func isProbe(path string) bool {
+	return path == "/healthz" || path == "/readyz" || path == "/metrics"
+}
+
+// tooManyRequests writes back a 429 if any of the reservations would have
+// to wait, and says how long to wait before trying again in Retry-After.
+// Requests that are turned away don't use up any of the limits.
+func (s *Server) tooManyRequests(rw http.ResponseWriter, r *http.Request, now time.Time, reservations ...*rate.Reservation) bool {
+	seconds := retryAfter(now, reservations...)
+	if seconds == 0 {
+		return false
+	}
+
+	rw.Header().Set("Retry-After", strconv.Itoa(seconds))
+	s.writeError(rw, r, http.StatusTooManyRequests, problem.RateLimited, "too many requests, try again in "+strconv.Itoa(seconds)+"s")
+
+	return true
+}
+
+// retryAfter is how many seconds to wait before trying again if any of
+// the reservations would have to wait, which are then cancelled, or 0 if
+// none of them do.
+func retryAfter(now time.Time, reservations ...*rate.Reservation) int {
+	var wait time.Duration
+	for _, res := range reservations {
+		if !res.OK() {
+			wait = math.MaxInt64
+		} else if delay := res.DelayFrom(now); delay > wait {
+			wait = delay
+		}
+	}
+
+	if wait == 0 {
+		return 0
+	}
+
+	for _, res := range reservations {
+		res.CancelAt(now)
+	}
+
+	// A limit without any burst can never be met, so it's
+	// as long as anyone should wait
+	if wait == math.MaxInt64 {
+		return 60
+	}
+	return int(math.Ceil(wait.Seconds()))
+}
+
+// ParseRateLimit reads a rate limit as requests a second, like 10, with
+// an optional burst after a comma, like 10,50. Without a burst, it's a
+// second's worth of requests.
+func ParseRateLimit(s string) (float64, int, error) {
+	invalid := fmt.Errorf("%w %s, it has to be requests a second like 10, or with a burst like 10,50", ErrInvalidRateLimit, strconv.Quote(s))
+
+	rateString, burstString, hasBurst := strings.Cut(s, ",")
+	perSecond, err := strconv.ParseFloat(strings.TrimSpace(rateString), 64)
+	if err != nil || perSecond <= 0 || math.IsInf(perSecond, 0) {
+		return 0, 0, invalid
+	}
+
+	burst := int(math.Ceil(perSecond))
+	if hasBurst {
+		burst, err = strconv.Atoi(strings.TrimSpace(burstString))
+		if err != nil || burst < 1 {
+			return 0, 0, invalid
+		}
+	}
+
+	return perSecond, burst, nil
+}
+
+// clientIP is the IP that the request came from. Requests through a
+// proxy are all from the proxy.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+func getFrom(s http.Handler, target, remoteAddr string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	r.RemoteAddr = remoteAddr
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, r)
+	return rw
+}
+
+func TestIPRateLimit(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	s := New(store.NewMemory(communities), discardLogs, WithIPRateLimit(0.01, 2))
+
+	for i := 0; i < 2; i++ {
+		if rw := getFrom(s, "/search?q=houston", "10.0.0.1:1234"); rw.Code != http.StatusOK {
+			t.Fatalf("expected the burst to be let through, got %d on %d", rw.Code, i)
+		}
+	}
+
+	rw := getFrom(s, "/search?q=houston", "10.0.0.1:5678")
+	if rw.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected a 429 after the burst, got %d", rw.Code)
+	}
+
+	// A token comes back every 100 seconds
+	if seconds, err := strconv.Atoi(rw.Header().Get("Retry-After")); err != nil || seconds < 1 || seconds > 100 {
+		t.Errorf("expected to retry within 100 seconds, got %s", rw.Header().Get("Retry-After"))
+	}
+
+	if rw := getFrom(s, "/search?q=houston", "10.0.0.2:1234"); rw.Code != http.StatusOK {
+		t.Errorf("expected another IP to have its own limit, got %d", rw.Code)
+	}
+
+	if rw := getFrom(s, "/healthz", "10.0.0.1:1234"); rw.Code != http.StatusOK {
+		t.Errorf("expected health checks to never be limited, got %d", rw.Code)
+	}
+}
+
+func TestGlobalRateLimit(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	s := New(store.NewMemory(communities), discardLogs, WithIPRateLimit(100, 100), WithGlobalRateLimit(0.01, 3))
+
+	for i, ip := range []string{"10.0.0.1:1", "10.0.0.2:1", "10.0.0.3:1"} {
+		if rw := getFrom(s, "/communities", ip); rw.Code != http.StatusOK {
+			t.Fatalf("expected the burst to be let through, got %d on %d", rw.Code, i)
+		}
+	}
+
+	if rw := getFrom(s, "/communities", "10.0.0.4:1"); rw.Code != http.StatusTooManyRequests || len(rw.Header().Get("Retry-After")) == 0 {
+		t.Errorf("expected a 429 for everyone after the burst, got %d", rw.Code)
+	}
+
+	// Requests that were turned away don't count against the IP
+	if remaining := s.ipLimits.clients["10.0.0.4"].limiter.Tokens(); remaining < 99 {
+		t.Errorf("expected the IP's tokens to be given back, got %f", remaining)
+	}
+}
+
+func TestRateLimitHandlers(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	legacy := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {})
+	s := New(store.NewMemory(communities), discardLogs, WithIPRateLimit(0.01, 1), WithHandler("/autocomplete", legacy))
+
+	if rw := getFrom(s, "/autocomplete?prefix=hou", "10.0.0.1:1234"); rw.Code != http.StatusOK {
+		t.Fatalf("expected the handler to be served, got %d", rw.Code)
+	}
+
+	if rw := getFrom(s, "/autocomplete?prefix=hous", "10.0.0.1:1234"); rw.Code != http.StatusTooManyRequests {
+		t.Errorf("expected the handler to be limited too, got %d", rw.Code)
+	}
+}
+
+func TestRateLimitGRPC(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	s := New(store.NewMemory(communities), discardLogs, WithIPRateLimit(0.01, 1))
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234}})
+	unary := s.UnaryInterceptor()
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+
+	if _, err := unary(ctx, nil, &grpc.UnaryServerInfo{}, handler); err != nil {
+		t.Fatalf("expected the burst to be let through, got %s", err)
+	}
+
+	if _, err := unary(ctx, nil, &grpc.UnaryServerInfo{}, handler); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected the call after the burst to be resource exhausted, got %v", err)
+	}
+
+	// Streams take from the same limit
+	stream := s.StreamInterceptor()
+	err := stream(nil, &testStream{ctx: ctx}, &grpc.StreamServerInfo{}, func(any, grpc.ServerStream) error { return nil })
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected the stream to be resource exhausted, got %v", err)
+	}
+
+	other := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 1234}})
+	if _, err := unary(other, nil, &grpc.UnaryServerInfo{}, handler); err != nil {
+		t.Errorf("expected another IP to have its own limit, got %s", err)
+	}
+}
+
+// A testStream is a grpc.ServerStream with just a context.
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ts *testStream) Context() context.Context { return ts.ctx }
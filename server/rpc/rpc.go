@@ -0,0 +1,84 @@
+// Package rpc is the gRPC service for the status book, for services that
+// would rather use gRPC than the REST API. It answers from the same
+// store as the REST API.
+package rpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"nfip-community-book/pb"
+	"nfip-community-book/store"
+)
+
+// A Service looks up and searches the communities in the store.
+type Service struct {
+	pb.UnimplementedCommunityStatusServiceServer
+
+	store store.Snapshot
+}
+
+func NewService(st store.Snapshot) *Service {
+	return &Service{store: st}
+}
+
+// NewServer is a gRPC server with the service registered on it.
+func NewServer(st store.Snapshot, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(opts...)
+	pb.RegisterCommunityStatusServiceServer(s, NewService(st))
+	return s
+}
+
+func (s *Service) GetCommunity(ctx context.Context, req *pb.GetCommunityRequest) (*pb.CommunityStatus, error) {
+	c, ok, err := s.store.Get(ctx, int(req.GetCid()))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "could not look up the community: %s", err)
+	}
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no community with the CID %d", req.GetCid())
+	}
+
+	return c.ToProto(), nil
+}
+
+func (s *Service) SearchCommunities(req *pb.SearchCommunitiesRequest, stream grpc.ServerStreamingServer[pb.SearchResult]) error {
+	if len(req.GetQuery()) == 0 {
+		return status.Error(codes.InvalidArgument, "missing the query")
+	}
+
+	if req.GetLimit() < 0 {
+		return status.Errorf(codes.InvalidArgument, "invalid limit %d", req.GetLimit())
+	}
+
+	results, err := s.store.Search(stream.Context(), req.GetQuery())
+	if err != nil {
+		return status.Errorf(codes.Internal, "could not search: %s", err)
+	}
+	if limit := int(req.GetLimit()); limit > 0 && limit < len(results) {
+		results = results[:limit]
+	}
+
+	for _, result := range results {
+		// Stop when the client has gone away instead of sending the rest
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		if err := stream.Send(&pb.SearchResult{Community: result.Community.ToProto(), Score: result.Score}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) GetDatasetInfo(ctx context.Context, req *pb.GetDatasetInfoRequest) (*pb.DatasetInfo, error) {
+	return &pb.DatasetInfo{
+		Communities: int32(len(s.store.Index().Communities())),
+		LoadedAt:    timestamppb.New(s.store.Loaded()),
+	}, nil
+}
@@ -0,0 +1,101 @@
+package rpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"nfip-community-book/data"
+	"nfip-community-book/pb"
+	"nfip-community-book/store"
+)
+
+func newTestClient(t *testing.T) (pb.CommunityStatusServiceClient, *store.Memory) {
+	t.Helper()
+
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	memory := store.NewMemory(communities)
+
+	lis := bufconn.Listen(1 << 20)
+	s := NewServer(memory)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("expected a connection, got %s", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewCommunityStatusServiceClient(conn), memory
+}
+
+func TestGetCommunity(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	c, err := client.GetCommunity(ctx, &pb.GetCommunityRequest{Cid: 480296})
+	if err != nil || c.GetBaseName() != "HOUSTON" || c.GetCurrEffMapDate() == nil {
+		t.Errorf("expected Houston, got %v %v", c, err)
+	}
+
+	if _, err := client.GetCommunity(ctx, &pb.GetCommunityRequest{Cid: 999999}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected a community that isn't there to be not found, got %v", err)
+	}
+}
+
+func TestSearchCommunities(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	stream, err := client.SearchCommunities(ctx, &pb.SearchCommunitiesRequest{Query: "county", Limit: 3})
+	if err != nil {
+		t.Fatalf("expected a stream, got %s", err)
+	}
+
+	var results []*pb.SearchResult
+	for {
+		result, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("expected the results, got %s", err)
+		}
+		results = append(results, result)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	for i := 1; i < len(results); i++ {
+		if results[i].GetScore() > results[i-1].GetScore() {
+			t.Errorf("expected the best matches first, got %v", results)
+		}
+	}
+
+	stream, _ = client.SearchCommunities(ctx, &pb.SearchCommunitiesRequest{})
+	if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected a search without a query to be invalid, got %v", err)
+	}
+}
+
+func TestGetDatasetInfo(t *testing.T) {
+	client, memory := newTestClient(t)
+
+	info, err := client.GetDatasetInfo(context.Background(), &pb.GetDatasetInfoRequest{})
+	if err != nil || int(info.GetCommunities()) != len(memory.Index().Communities()) || !info.GetLoadedAt().AsTime().Equal(memory.Loaded()) {
+		t.Errorf("expected %d communities loaded at %s, got %v %v", len(memory.Index().Communities()), memory.Loaded(), info, err)
+	}
+}
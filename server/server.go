@@ -0,0 +1,607 @@
+// Package server is a REST API for the status book, so that it can be
+// looked up over HTTP without wrapping the data package in another server.
+// Everything is JSON, the same as the communities are encoded everywhere else.
+//
+//	GET /v1/communities          every community, a page at a time
+//	GET /v1/communities/{cid}    the community with the CID
+//	POST /v1/communities/lookup  the communities with each of the CIDs in the body
+//	GET /v1/search?q=houston     the communities that match the term, best first
+//	GET /v1/search/live          a WebSocket that searches as the term is typed
+//	GET /v1/events               a server-sent event every time they're refreshed
+//	POST /v1/tokens              a token for the API key, for the events and live search from a browser
+//	GET /graphql                 a GraphQL query, which can be POSTed too
+//	GET /healthz                 whether the server is up
+//	GET /readyz                  whether there are communities, and if they're fresh
+//	POST /admin/refresh          start refreshing the communities, for admins
+//	GET /metrics                 how much each API key has been used
+//	GET /openapi.json            the OpenAPI document that describes all of this
+//	GET /                        a page for searching them in a browser, with WithUI
+//
+// The endpoints that return communities are versioned, so that their shape
+// can change in a new version without breaking the clients of the old one.
+// They're at the same paths without /v1 too, from before there were versions.
+//
+// Lists can be paged, sorted and filtered with the parameters in parseQuery,
+// like /v1/communities?state=TX&participating=true&sort=name&limit=50, and
+// they can be CSV, NDJSON or YAML instead of JSON. See negotiate. The
+// communities can be trimmed to some of their fields, like
+// fields=cid,community_name, in any of them. See parseFields. They're
+// tagged with the version of the communities, so that clients that poll
+// them get a 304 until the communities change.
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+
+	"nfip-community-book/data"
+	"nfip-community-book/problem"
+	"nfip-community-book/store"
+)
+
+const jsonContentType = "application/json; charset=utf-8"
+
+// The most CIDs that can be looked up at once, and the
+// biggest body that a list of them can be in.
+const (
+	maxLookup     = 1000
+	maxLookupBody = 64 << 10
+)
+
+// A Server answers the API's requests from the communities in the store.
+// They're looked up again for every request, so a refresh of the store
+// is seen as soon as it's done.
+type Server struct {
+	log     *slog.Logger
+	tracer  trace.Tracer
+	store   store.Snapshot
+	mux     *http.ServeMux
+	handler http.Handler
+	maxAge  time.Duration
+
+	ipLimits    *ipLimits
+	globalLimit *rate.Limiter
+
+	cors     *CORS
+	ui       http.Handler
+	handlers []handler
+
+	refresher Refresher
+	refreshes refreshState
+	events    events
+
+	// Closed once the server starts shutting down
+	shuttingDown chan struct{}
+	shutdownOnce sync.Once
+
+	apiKeys      map[[sha256.Size]byte]*apiKey
+	apiKeyNames  []string
+	tokenPaths   map[string]bool
+	unauthorized atomic.Int64
+
+	// The GraphQL schema is made the first time it's used
+	graphqlOnce sync.Once
+	graphql     graphql.Schema
+	graphqlErr  error
+}
+
+// The versions of the API. A version's responses only ever get new fields,
+// and anything that would break its clients goes in the next version, which
+// is served alongside it. The paths without a version are legacyVersion's,
+// since they were there before versions were, and they always will be.
+const (
+	v1            = "v1"
+	legacyVersion = v1
+)
+
+// A route is an endpoint and how it's described in the OpenAPI document.
+// Endpoints are only added from here, so that the document can't leave
+// any of them out.
+type route struct {
+	method  string
+	path    string
+	handler func(*Server, http.ResponseWriter, *http.Request)
+	summary string
+
+	// The version of the API that the endpoint is in, which its path is
+	// under, or nothing for the ones that aren't part of the API itself,
+	// like the health checks
+	version string
+
+	// Whether the endpoint takes the list parameters in parseQuery,
+	// and can be written in any of the formats
+	list   bool
+	params []parameter
+
+	// The schemas in the document's components of the body it takes,
+	// if any, and what it returns, and the errors it can return other
+	// than a 500
+	request  string
+	response string
+	errors   []int
+
+	// What it returns if it isn't JSON, and its status if it isn't a 200
+	contentType string
+	status      int
+
+	// Whether a token from /tokens can be used instead of an API key
+	tokens bool
+}
+
+// fullPath is where the endpoint is, with its version.
+func (rt route) fullPath() string {
+	if len(rt.version) == 0 {
+		return rt.path
+	}
+	return "/" + rt.version + rt.path
+}
+
+// routes are every endpoint in the API.
+func routes() []route {
+	return []route{
+		{
+			method:   http.MethodGet,
+			path:     "/communities",
+			version:  v1,
+			handler:  (*Server).getCommunities,
+			summary:  "Every community, a page at a time",
+			list:     true,
+			response: "CommunitiesPage",
+			errors:   []int{http.StatusBadRequest, http.StatusNotAcceptable, http.StatusServiceUnavailable},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/communities/{cid}",
+			version: v1,
+			handler: (*Server).getCommunity,
+			summary: "The community with the CID",
+			params: []parameter{
+				{Name: "cid", In: "path", Description: "The community's ID.", Required: true, Schema: schema{"type": "integer", "minimum": 0}},
+			},
+			response: "Community",
+			errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusServiceUnavailable},
+		},
+		{
+			method:   http.MethodPost,
+			path:     "/communities/lookup",
+			version:  v1,
+			handler:  (*Server).lookup,
+			summary:  "The communities with each of the CIDs",
+			request:  "LookupRequest",
+			response: "LookupResult",
+			errors:   []int{http.StatusBadRequest, http.StatusServiceUnavailable},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/search",
+			version: v1,
+			handler: (*Server).search,
+			summary: "The communities that match the term, best first",
+			list:    true,
+			params: []parameter{
+				{Name: "q", In: "query", Description: "What to search for, in the community's name, county, state or CID.", Required: true, Schema: schema{"type": "string"}},
+			},
+			response: "SearchPage",
+			errors:   []int{http.StatusBadRequest, http.StatusNotAcceptable, http.StatusServiceUnavailable},
+		},
+		{
+			method:   http.MethodGet,
+			path:     "/search/live",
+			version:  v1,
+			handler:  (*Server).liveSearch,
+			summary:  "Search as the term is typed, over a WebSocket",
+			params:   append(listParameters(), fieldsParameter()),
+			response: "LiveSearchResult",
+			status:   http.StatusSwitchingProtocols,
+			errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusServiceUnavailable},
+			tokens:   true,
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/graphql",
+			handler: (*Server).graphQL,
+			summary: "Run a GraphQL query",
+			params: []parameter{
+				{Name: "query", In: "query", Description: "The GraphQL query.", Required: true, Schema: schema{"type": "string"}},
+				{Name: "variables", In: "query", Description: "The query's variables as a JSON object.", Schema: schema{"type": "string"}},
+				{Name: "operationName", In: "query", Description: "Which operation in the query to run.", Schema: schema{"type": "string"}},
+			},
+			response: "GraphQLResponse",
+			errors:   []int{http.StatusBadRequest},
+		},
+		{
+			method:   http.MethodPost,
+			path:     "/graphql",
+			handler:  (*Server).graphQL,
+			summary:  "Run a GraphQL query",
+			request:  "GraphQLRequest",
+			response: "GraphQLResponse",
+			errors:   []int{http.StatusBadRequest},
+		},
+		{
+			method:   http.MethodGet,
+			path:     "/healthz",
+			handler:  (*Server).getHealth,
+			summary:  "Whether the server is up",
+			response: "Health",
+		},
+		{
+			method:   http.MethodGet,
+			path:     "/readyz",
+			handler:  (*Server).getReadiness,
+			summary:  "Whether there are communities to look up, and how fresh they are",
+			response: "Readiness",
+			errors:   []int{http.StatusServiceUnavailable},
+		},
+		{
+			method:   http.MethodPost,
+			path:     "/admin/refresh",
+			handler:  (*Server).postRefresh,
+			summary:  "Start refreshing the communities, which needs an admin's API key",
+			response: "RefreshStatus",
+			status:   http.StatusAccepted,
+			errors:   []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusConflict, http.StatusNotImplemented, http.StatusServiceUnavailable},
+		},
+		{
+			method:   http.MethodGet,
+			path:     "/admin/refresh/status",
+			handler:  (*Server).getRefreshStatus,
+			summary:  "How the refresh that's running, and the last one, went",
+			response: "RefreshStatus",
+			errors:   []int{http.StatusUnauthorized, http.StatusForbidden},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/events",
+			version:     v1,
+			handler:     (*Server).getEvents,
+			summary:     "Server-sent events for every refresh, with what changed",
+			response:    "RefreshEvent",
+			contentType: eventStreamContentType,
+			tokens:      true,
+		},
+		{
+			method:   http.MethodPost,
+			path:     "/tokens",
+			version:  v1,
+			handler:  (*Server).postToken,
+			summary:  "A token for the API key that can be given as access_token, for browsers that can't send the key",
+			response: "Token",
+			errors:   []int{http.StatusUnauthorized, http.StatusNotFound},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/metrics",
+			handler:     (*Server).getMetrics,
+			summary:     "How much each API key has been used, for Prometheus",
+			response:    "Metrics",
+			contentType: metricsContentType,
+		},
+		{
+			method:   http.MethodGet,
+			path:     "/openapi.json",
+			handler:  (*Server).getOpenAPI,
+			summary:  "This document",
+			response: "OpenAPI",
+		},
+	}
+}
+
+// New makes a Server for the communities in the store. Every request is
+// logged as a line of JSON on stdout unless WithLogHandler says otherwise.
+func New(st store.Snapshot, opts ...Option) *Server {
+	s := &Server{
+		log:    slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+		tracer: defaultTracer(),
+		store:  st,
+		mux:    http.NewServeMux(),
+
+		tokenPaths: make(map[string]bool),
+
+		shuttingDown: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.handler = s.trace(s.logRequests(s.allowCORS(s.compress(s.limitRate(s.authenticate(http.HandlerFunc(s.route)))))))
+
+	for _, rt := range routes() {
+		handler := func(rw http.ResponseWriter, r *http.Request) {
+			traceRoute(r, r.Pattern)
+			rt.handler(s, rw, r)
+		}
+
+		s.mux.HandleFunc(rt.method+" "+rt.fullPath(), handler)
+		s.tokenPaths[rt.fullPath()] = rt.tokens
+		if rt.version == legacyVersion {
+			s.mux.HandleFunc(rt.method+" "+rt.path, handler)
+			s.tokenPaths[rt.path] = rt.tokens
+		}
+	}
+
+	for _, h := range s.handlers {
+		s.mux.Handle(h.pattern, h.traced())
+	}
+
+	if s.ui != nil {
+		s.mux.HandleFunc("GET /{$}", s.serveUI)
+		s.mux.HandleFunc("GET /ui/", s.serveUI)
+	}
+
+	return s
+}
+
+func (s *Server) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(rw, r)
+}
+
+// route hands the request to its route. Requests that don't have one get
+// a problem too, rather than the mux's plain text, with the same status
+// that the mux would give them: a 405 with the methods in Allow if the path
+// is one of the routes', or a 404 if it isn't.
+func (s *Server) route(rw http.ResponseWriter, r *http.Request) {
+	h, pattern := s.mux.Handler(r)
+	if len(pattern) > 0 {
+		s.mux.ServeHTTP(rw, r)
+		return
+	}
+
+	unrouted := &headerWriter{header: http.Header{}}
+	h.ServeHTTP(unrouted, r)
+
+	if unrouted.status == http.StatusMethodNotAllowed {
+		rw.Header().Set("Allow", unrouted.header.Get("Allow"))
+		s.writeError(rw, r, http.StatusMethodNotAllowed, problem.MethodNotAllowed, r.Method+" isn't allowed for "+r.URL.Path+", only "+unrouted.header.Get("Allow"))
+		return
+	}
+
+	s.writeError(rw, r, http.StatusNotFound, problem.NotFound, "there's nothing at "+r.URL.Path)
+}
+
+// A headerWriter only keeps the headers and status that are written to it.
+type headerWriter struct {
+	header http.Header
+	status int
+}
+
+func (hw *headerWriter) Header() http.Header         { return hw.header }
+func (hw *headerWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (hw *headerWriter) WriteHeader(status int)      { hw.status = status }
+
+func (s *Server) getCommunities(rw http.ResponseWriter, r *http.Request) {
+	if s.notReady(rw, r) {
+		return
+	}
+
+	f, ok := s.negotiate(rw, r)
+	if !ok {
+		return
+	}
+
+	q, err := parseQuery(r.URL.Query())
+	if err != nil {
+		s.writeError(rw, r, http.StatusBadRequest, problem.InvalidParameter, err.Error())
+		return
+	}
+
+	fields, err := parseFields(r.URL.Query())
+	if err != nil {
+		s.writeError(rw, r, http.StatusBadRequest, problem.InvalidParameter, err.Error())
+		return
+	}
+
+	if s.notModified(rw, r, f) {
+		return
+	}
+
+	_, span := s.startSpan(r.Context(), "list communities")
+	page := q.Run(s.store.Index().Communities())
+	span.SetAttributes(attribute.Int("nfip.total", page.Total))
+	span.End()
+
+	communities, next := page.Results.Communities(), nextPage(r.URL, page)
+	countResults(r, len(communities))
+
+	s.writePage(rw, r, f, communitiesPage{
+		APIVersion: v1,
+		StatusPage: data.StatusPage{Total: page.Total, Offset: page.Offset, Limit: page.Limit, Communities: communities},
+		Next:       next,
+	}, communities, page.Total, next, fields)
+}
+
+func (s *Server) getCommunity(rw http.ResponseWriter, r *http.Request) {
+	if s.notReady(rw, r) {
+		return
+	}
+
+	cid, err := strconv.Atoi(r.PathValue("cid"))
+	if err != nil || cid < 0 {
+		s.writeError(rw, r, http.StatusBadRequest, problem.InvalidParameter, "invalid CID "+strconv.Quote(r.PathValue("cid")))
+		return
+	}
+
+	c, ok, err := s.store.Get(r.Context(), cid)
+	if err != nil {
+		s.writeError(rw, r, http.StatusInternalServerError, problem.Internal, "could not look up the community")
+		return
+	}
+	if !ok {
+		s.writeError(rw, r, http.StatusNotFound, problem.CommunityNotFound, "no community with the CID "+strconv.Itoa(cid))
+		return
+	}
+
+	countResults(r, 1)
+	s.writeJSON(rw, http.StatusOK, c)
+}
+
+// A lookupResult is the communities that were found, in the order their
+// CIDs were given, and the CIDs that weren't.
+type lookupResult struct {
+	APIVersion  string                     `json:"api_version"`
+	Communities data.NFIPCommunityStatuses `json:"communities"`
+	Unknown     []int                      `json:"unknown"`
+}
+
+// lookup looks up a batch of CIDs at once, so that a job with a CID for
+// every policy doesn't have to make a request for each one. CIDs that are
+// there more than once are only looked up the first time.
+func (s *Server) lookup(rw http.ResponseWriter, r *http.Request) {
+	if s.notReady(rw, r) {
+		return
+	}
+
+	var cids []int
+	if err := json.NewDecoder(http.MaxBytesReader(rw, r.Body, maxLookupBody)).Decode(&cids); err != nil {
+		s.writeError(rw, r, http.StatusBadRequest, problem.InvalidBody, "invalid lookup, it has to be a JSON array of CIDs: "+err.Error())
+		return
+	}
+
+	if len(cids) > maxLookup {
+		s.writeError(rw, r, http.StatusBadRequest, problem.InvalidBody, "too many CIDs, up to "+strconv.Itoa(maxLookup)+" can be looked up at once")
+		return
+	}
+
+	result := lookupResult{APIVersion: v1, Communities: data.NFIPCommunityStatuses{}, Unknown: []int{}}
+	seen := make(map[int]bool, len(cids))
+	for _, cid := range cids {
+		if seen[cid] {
+			continue
+		}
+		seen[cid] = true
+
+		c, ok, err := s.store.Get(r.Context(), cid)
+		if err != nil {
+			s.writeError(rw, r, http.StatusInternalServerError, problem.Internal, "could not look up the communities")
+			return
+		}
+
+		if ok {
+			result.Communities = append(result.Communities, c)
+		} else {
+			result.Unknown = append(result.Unknown, cid)
+		}
+	}
+
+	countResults(r, len(result.Communities))
+	s.writeJSON(rw, http.StatusOK, result)
+}
+
+func (s *Server) search(rw http.ResponseWriter, r *http.Request) {
+	if s.notReady(rw, r) {
+		return
+	}
+
+	values := r.URL.Query()
+	term := values.Get("q")
+	if len(term) == 0 {
+		s.writeError(rw, r, http.StatusBadRequest, problem.MissingParameter, "missing the search term in q")
+		return
+	}
+
+	f, ok := s.negotiate(rw, r)
+	if !ok {
+		return
+	}
+
+	q, err := parseQuery(values)
+	if err != nil {
+		s.writeError(rw, r, http.StatusBadRequest, problem.InvalidParameter, err.Error())
+		return
+	}
+
+	fields, err := parseFields(values)
+	if err != nil {
+		s.writeError(rw, r, http.StatusBadRequest, problem.InvalidParameter, err.Error())
+		return
+	}
+
+	if s.notModified(rw, r, f) {
+		return
+	}
+
+	ctx, span := s.startSpan(r.Context(), "search", attribute.String("nfip.search.term", term))
+	results, err := s.store.Search(ctx, term)
+	if err != nil {
+		span.End()
+		s.writeError(rw, r, http.StatusInternalServerError, problem.Internal, "could not search the communities")
+		return
+	}
+	page := q.RunResults(results)
+	span.SetAttributes(attribute.Int("nfip.total", page.Total))
+	span.End()
+
+	if page.Total == 0 {
+		page.DidYouMean = s.store.Index().DidYouMean(term, data.DefaultSuggestions)
+	}
+
+	next := nextPage(r.URL, page)
+	countResults(r, len(page.Results))
+	s.writePage(rw, r, f, searchPage{v1, page, next}, page.Results.Communities(), page.Total, next, fields)
+}
+
+// notModified tags the response with the version of the communities, and
+// writes back a 304 if the client already has it. The same URL is the same
+// page for as long as the communities don't change, so polling for them
+// doesn't download them again. Each format is tagged differently, since
+// they're different bytes. The version is read before the communities are,
+// so a refresh in between is downloaded again next time rather than missed.
+func (s *Server) notModified(rw http.ResponseWriter, r *http.Request, f format) bool {
+	etag := `"` + s.store.Version() + "-" + f.name + `"`
+	rw.Header().Set("ETag", etag)
+
+	for _, tag := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == etag || tag == "*" {
+			rw.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
+
+// Lists of communities and search results are written out a page at a
+// time, with a link to the next page if there is one. They say which
+// version of the API they're from, so that a client that's been pointed at
+// the wrong one can tell.
+type communitiesPage struct {
+	APIVersion string `json:"api_version"`
+	data.StatusPage
+	Next string `json:"next,omitempty"`
+}
+
+type searchPage struct {
+	APIVersion string `json:"api_version"`
+	data.SearchPage
+	Next string `json:"next,omitempty"`
+}
+
+func (s *Server) writeJSON(rw http.ResponseWriter, status int, v any) {
+	rw.Header().Set("Content-Type", jsonContentType)
+	rw.WriteHeader(status)
+
+	if err := json.NewEncoder(rw).Encode(v); err != nil {
+		s.log.Error("could not write the response", "err", err)
+	}
+}
+
+// writeError writes the error out as a problem, with a code that says what
+// went wrong. See the problem package.
+func (s *Server) writeError(rw http.ResponseWriter, r *http.Request, status int, code problem.Code, message string) {
+	if err := problem.Write(rw, r, status, code, message); err != nil {
+		s.log.ErrorContext(r.Context(), "could not write the error", "err", err, "request_id", requestID(r.Context()))
+	}
+}
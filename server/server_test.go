@@ -0,0 +1,323 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"nfip-community-book/data"
+	"nfip-community-book/problem"
+	"nfip-community-book/store"
+)
+
+func newTestServer(t *testing.T) (*Server, data.NFIPCommunityStatuses) {
+	t.Helper()
+
+	communities, err := data.GetDemoNFIPCommunityStatusBook()
+	if err != nil {
+		t.Fatalf("expected the demo status book, got %s", err)
+	}
+
+	return New(store.NewMemory(communities), discardLogs), communities
+}
+
+var discardLogs = WithLogHandler(slog.NewJSONHandler(io.Discard, nil))
+
+func get(s http.Handler, target string) *httptest.ResponseRecorder {
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, target, nil))
+	return rw
+}
+
+func TestGetCommunities(t *testing.T) {
+	s, communities := newTestServer(t)
+
+	rw := get(s, "/communities")
+	if rw.Code != http.StatusOK || rw.Header().Get("Content-Type") != jsonContentType {
+		t.Fatalf("expected JSON, got %d %s", rw.Code, rw.Header().Get("Content-Type"))
+	}
+
+	var page communitiesPage
+	if err := json.Unmarshal(rw.Body.Bytes(), &page); err != nil || page.Total != len(communities) || len(page.Communities) != len(communities) || len(page.Next) > 0 {
+		t.Errorf("expected %d communities on one page, got %d of %d %v", len(communities), len(page.Communities), page.Total, err)
+	}
+
+	rw = get(s, "/communities?participating=true&sort=cid&limit=10")
+	page = communitiesPage{}
+	json.Unmarshal(rw.Body.Bytes(), &page)
+	if page.Limit != 10 || len(page.Communities) != 10 || page.Next != "/communities?limit=10&offset=10&participating=true&sort=cid" {
+		t.Fatalf("expected the first page of 10, got %d with the next page %s", len(page.Communities), page.Next)
+	}
+
+	for i, c := range page.Communities {
+		if !c.ParticipatingCommunity || (i > 0 && c.CID < page.Communities[i-1].CID) {
+			t.Errorf("expected participating communities sorted by CID, got %d at %d", c.CID, i)
+		}
+	}
+
+	// Following the links goes through every page
+	seen := len(page.Communities)
+	for len(page.Next) > 0 {
+		next := page.Next
+		page = communitiesPage{}
+		json.Unmarshal(get(s, next).Body.Bytes(), &page)
+		seen += len(page.Communities)
+	}
+
+	if seen != page.Total {
+		t.Errorf("expected %d communities over every page, got %d", page.Total, seen)
+	}
+}
+
+func TestGetCommunity(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	rw := get(s, "/communities/480296")
+	var c data.NFIPCommunityStatus
+	if err := json.Unmarshal(rw.Body.Bytes(), &c); rw.Code != http.StatusOK || err != nil || c.BaseName != "HOUSTON" {
+		t.Errorf("expected Houston, got %d %s", rw.Code, rw.Body)
+	}
+
+	tests := []struct {
+		target string
+		status int
+		code   problem.Code
+	}{
+		{"/communities/999999", http.StatusNotFound, problem.CommunityNotFound},
+		{"/communities/houston", http.StatusBadRequest, problem.InvalidParameter},
+		{"/communities/-1", http.StatusBadRequest, problem.InvalidParameter},
+	}
+
+	for _, test := range tests {
+		rw := get(s, test.target)
+
+		var p problem.Problem
+		if err := json.Unmarshal(rw.Body.Bytes(), &p); rw.Code != test.status || err != nil || p.Code != test.code || len(p.Detail) == 0 {
+			t.Errorf("expected %d with %s for %s, got %d %s", test.status, test.code, test.target, rw.Code, rw.Body)
+		}
+		if rw.Header().Get("Content-Type") != problem.ContentType || p.Status != test.status || p.Instance != test.target {
+			t.Errorf("expected a problem for %s, got %s %s", test.target, rw.Header().Get("Content-Type"), rw.Body)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	s, communities := newTestServer(t)
+
+	lookup := func(body string) *httptest.ResponseRecorder {
+		rw := httptest.NewRecorder()
+		s.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/communities/lookup", strings.NewReader(body)))
+		return rw
+	}
+
+	body := fmt.Sprintf("[%d, 999999, %d, %d]", communities[1].CID, communities[0].CID, communities[1].CID)
+	rw := lookup(body)
+
+	var result lookupResult
+	if err := json.Unmarshal(rw.Body.Bytes(), &result); rw.Code != http.StatusOK || err != nil {
+		t.Fatalf("expected the communities, got %d %s", rw.Code, rw.Body)
+	}
+
+	if len(result.Communities) != 2 || result.Communities[0].CID != communities[1].CID || result.Communities[1].CID != communities[0].CID {
+		t.Errorf("expected the 2 communities in the order they were given, got %v", result.Communities)
+	}
+	if len(result.Unknown) != 1 || result.Unknown[0] != 999999 {
+		t.Errorf("expected 999999 to be unknown, got %v", result.Unknown)
+	}
+
+	// Nothing to look up is nothing found, rather than null
+	if rw := lookup("[]"); rw.Code != http.StatusOK || strings.TrimSpace(rw.Body.String()) != `{"api_version":"v1","communities":[],"unknown":[]}` {
+		t.Errorf("expected empty lists, got %d %s", rw.Code, rw.Body)
+	}
+
+	tooMany := "[" + strings.Repeat("1,", maxLookup) + "1]"
+	for _, body := range []string{"", "480296", `["480296"]`, tooMany} {
+		if rw := lookup(body); rw.Code != http.StatusBadRequest {
+			t.Errorf("expected a 400 for %.20s, got %d", body, rw.Code)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	rw := get(s, "/search?q=houston")
+	var page searchPage
+	if err := json.Unmarshal(rw.Body.Bytes(), &page); rw.Code != http.StatusOK || err != nil || len(page.Results) == 0 || page.Results[0].Community.CID != 480296 {
+		t.Errorf("expected Houston first, got %d %s", rw.Code, rw.Body)
+	}
+
+	page = searchPage{}
+	json.Unmarshal(get(s, "/search?q=houston&state=LA").Body.Bytes(), &page)
+	if page.Total != 0 {
+		t.Errorf("expected nothing in Louisiana, got %d", page.Total)
+	}
+
+	page = searchPage{}
+	json.Unmarshal(get(s, "/search?q=pulasky").Body.Bytes(), &page)
+	if page.Total != 0 || len(page.DidYouMean) == 0 || page.DidYouMean[0] != "PULASKI COUNTY" {
+		t.Errorf("expected Pulaski County to be suggested, got %d %v", page.Total, page.DidYouMean)
+	}
+
+	page = searchPage{}
+	json.Unmarshal(get(s, "/search?q=houston").Body.Bytes(), &page)
+	if len(page.DidYouMean) > 0 {
+		t.Errorf("expected nothing to be suggested when something's found, got %v", page.DidYouMean)
+	}
+
+	if rw := get(s, "/search"); rw.Code != http.StatusBadRequest {
+		t.Errorf("expected a search without a term to be a bad request, got %d", rw.Code)
+	}
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/search?q=houston", nil))
+	if rw.Code != http.StatusMethodNotAllowed || !strings.Contains(rw.Header().Get("Allow"), http.MethodGet) || !strings.Contains(rw.Body.String(), `"code":"method_not_allowed"`) {
+		t.Errorf("expected only GET to be allowed, got %d %s %s", rw.Code, rw.Header().Get("Allow"), rw.Body)
+	}
+}
+
+// A searchingStore counts the searches that go through it, and fails
+// them with err if it's set.
+type searchingStore struct {
+	*store.Memory
+	searches int
+	err      error
+}
+
+func (ss *searchingStore) Search(ctx context.Context, term string) (data.SearchResults, error) {
+	ss.searches++
+	if ss.err != nil {
+		return nil, ss.err
+	}
+	return ss.Memory.Search(ctx, term)
+}
+
+func TestSearchStore(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	st := &searchingStore{Memory: store.NewMemory(communities)}
+	s := New(st, discardLogs)
+
+	var page searchPage
+	json.Unmarshal(get(s, "/search?q=county&sort=cid&limit=2").Body.Bytes(), &page)
+	if st.searches != 1 || len(page.Results) != 2 || page.Results[0].Community.CID > page.Results[1].Community.CID {
+		t.Errorf("expected the store's results sorted by CID, got %d searches and %v", st.searches, page.Results.Communities())
+	}
+
+	st.err = fmt.Errorf("the store is down")
+	if rw := get(s, "/search?q=county"); rw.Code != http.StatusInternalServerError || !strings.Contains(rw.Body.String(), `"code":"internal_error"`) {
+		t.Errorf("expected a search that fails to be a 500, got %d %s", rw.Code, rw.Body)
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	s, communities := newTestServer(t)
+
+	conditional := func(target, etag string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		r.Header.Set("If-None-Match", etag)
+
+		rw := httptest.NewRecorder()
+		s.ServeHTTP(rw, r)
+		return rw
+	}
+
+	rw := get(s, "/communities?state=TX")
+	etag := rw.Header().Get("ETag")
+	if rw.Code != http.StatusOK || etag != `"`+store.Version(communities)+`-json"` {
+		t.Fatalf("expected the communities' version as the ETag, got %d %s", rw.Code, etag)
+	}
+
+	for _, match := range []string{etag, "W/" + etag, `"old", ` + etag, "*"} {
+		if rw := conditional("/communities?state=TX", match); rw.Code != http.StatusNotModified || rw.Body.Len() > 0 {
+			t.Errorf("expected a 304 for %s, got %d", match, rw.Code)
+		}
+	}
+
+	// Other formats are different bytes, so they're tagged differently
+	if rw := conditional("/communities?state=TX&format=csv", etag); rw.Code != http.StatusOK || rw.Header().Get("ETag") == etag {
+		t.Errorf("expected the CSV to be sent, got %d %s", rw.Code, rw.Header().Get("ETag"))
+	}
+
+	search := get(s, "/search?q=houston")
+	if rw := conditional("/search?q=houston", search.Header().Get("ETag")); rw.Code != http.StatusNotModified {
+		t.Errorf("expected a 304 for the same search, got %d", rw.Code)
+	}
+
+	// Once the communities change, the same ETag doesn't match anymore
+	s.store.Refresh(context.Background(), communities[1:])
+	if rw := conditional("/communities?state=TX", etag); rw.Code != http.StatusOK || rw.Header().Get("ETag") == etag {
+		t.Errorf("expected the refreshed communities, got %d %s", rw.Code, rw.Header().Get("ETag"))
+	}
+}
+
+func TestVersions(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	for _, target := range []string{"/v1/communities?state=TX", "/v1/search?q=houston"} {
+		rw := get(s, target)
+
+		var page struct {
+			APIVersion string `json:"api_version"`
+		}
+		if err := json.Unmarshal(rw.Body.Bytes(), &page); rw.Code != http.StatusOK || err != nil || page.APIVersion != v1 {
+			t.Errorf("expected %s to be from %s, got %d %s", target, v1, rw.Code, rw.Body)
+		}
+
+		// The same as it was before there were versions
+		legacy := get(s, strings.TrimPrefix(target, "/v1"))
+		if legacy.Code != http.StatusOK || legacy.Body.String() != rw.Body.String() {
+			t.Errorf("expected %s to be the same without its version, got %d %s", target, legacy.Code, legacy.Body)
+		}
+	}
+
+	if rw := get(s, "/v1/communities?state=TX&limit=1"); !strings.Contains(rw.Body.String(), `"next":"/v1/communities?`) {
+		t.Errorf("expected the next page to be in the same version, got %s", rw.Body)
+	}
+
+	for _, target := range []string{"/v1/healthz", "/v2/communities"} {
+		if rw := get(s, target); rw.Code != http.StatusNotFound {
+			t.Errorf("expected a 404 for %s, got %d", target, rw.Code)
+		}
+	}
+}
+
+func TestProblems(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	tests := []struct {
+		target string
+		status int
+		code   problem.Code
+	}{
+		{"/v1/search", http.StatusBadRequest, problem.MissingParameter},
+		{"/v1/communities?limit=many", http.StatusBadRequest, problem.InvalidParameter},
+		{"/v1/counties", http.StatusNotFound, problem.NotFound},
+	}
+
+	for _, test := range tests {
+		rw := get(s, test.target)
+
+		var p problem.Problem
+		if err := json.Unmarshal(rw.Body.Bytes(), &p); rw.Code != test.status || err != nil || p.Code != test.code {
+			t.Errorf("expected %d with %s for %s, got %d %s", test.status, test.code, test.target, rw.Code, rw.Body)
+		}
+		if p.Error != p.Detail || len(p.RequestID) == 0 {
+			t.Errorf("expected the error and request ID to be kept for old clients, got %s", rw.Body)
+		}
+	}
+
+	// Nothing can be looked up before the communities are loaded
+	empty := New(store.NewMemory(nil), discardLogs)
+	for _, target := range []string{"/v1/communities", "/v1/communities/480296", "/v1/search?q=houston"} {
+		rw := get(empty, target)
+		if rw.Code != http.StatusServiceUnavailable || !strings.Contains(rw.Body.String(), `"code":"not_ready"`) {
+			t.Errorf("expected %s to not be ready, got %d %s", target, rw.Code, rw.Body)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package server
+
+import "context"
+
+// Shutdown gets the API ready for its HTTP server to shut down. It should
+// be called along with http.Server's Shutdown, which waits on every request
+// that's still being answered. Event streams are ended, since they'd never
+// finish on their own, and readiness checks start failing. No more refreshes
+// can be started, and one that's running is waited on until the context is
+// done, since it could be in the middle of saving to the store.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.shutdownOnce.Do(func() {
+		close(s.shuttingDown)
+	})
+
+	finished := s.refreshes.stop()
+	if finished == nil {
+		return nil
+	}
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Server) isShuttingDown() bool {
+	select {
+	case <-s.shuttingDown:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+func TestShutdown(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+
+	release := make(chan struct{})
+	s := New(store.NewMemory(communities), discardLogs,
+		WithAPIKeys([]APIKey{{Name: "ops", Key: "ops-key", Admin: true}}),
+		WithRefresher(func(ctx context.Context, current data.NFIPCommunityStatuses) (data.NFIPCommunityStatuses, error) {
+			<-release
+			return current, nil
+		}),
+	)
+
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	request := func(method, path string) *http.Response {
+		req, _ := http.NewRequest(method, ts.URL+path, nil)
+		req.Header.Set("X-API-Key", "ops-key")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("expected a response to %s, got %s", path, err)
+		}
+		return resp
+	}
+
+	events := request(http.MethodGet, "/events")
+	defer events.Body.Close()
+
+	if resp := request(http.MethodPost, "/admin/refresh"); resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected the refresh to start, got %d", resp.StatusCode)
+	}
+
+	// It gives up on the refresh when the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected to give up waiting on the refresh, got %v", err)
+	}
+
+	// The event stream ends rather than holding up the server
+	ended := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(io.Discard, events.Body)
+		ended <- err
+	}()
+	select {
+	case err := <-ended:
+		if err != nil {
+			t.Errorf("expected the event stream to end, got %s", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("expected the event stream to end")
+	}
+
+	if resp := request(http.MethodGet, "/readyz"); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected it not to be ready while shutting down, got %d", resp.StatusCode)
+	}
+	if resp := request(http.MethodPost, "/admin/refresh"); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected no more refreshes while shutting down, got %d", resp.StatusCode)
+	}
+
+	// And waits on it otherwise
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- s.Shutdown(context.Background())
+	}()
+	select {
+	case err := <-shutdown:
+		t.Fatalf("expected to wait on the refresh, got %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-shutdown; err != nil {
+		t.Errorf("expected to shut down once the refresh finished, got %s", err)
+	}
+	if status := s.refreshes.get(); status.Running || status.LastSuccess == nil {
+		t.Errorf("expected the refresh to have finished, got %+v", status)
+	}
+}
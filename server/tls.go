@@ -0,0 +1,214 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+var ErrInvalidTLS = fmt.Errorf("invalid TLS configuration")
+
+// TLS is the certificate that the server is served with, and the CAs that
+// clients' certificates have to be signed by if they need one. The files
+// are looked at again every so often, and read again when they've changed,
+// so that rotated certificates are used without restarting the server.
+// Certificates that can't be read then are logged to the ErrorLog, and the
+// old ones are kept.
+type TLS struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+	ErrorLog     *log.Logger
+}
+
+// How often the files are looked at to see if they've changed.
+const tlsReloadEvery = 10 * time.Second
+
+// Only the TLS 1.2 cipher suites with forward secrecy and AEAD. TLS 1.3's
+// can't be changed, and they're all fine.
+var tlsCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+}
+
+// Config is a tls.Config for the HTTP and gRPC servers, with at least TLS
+// 1.2 and only modern cipher suites. Clients have to have a certificate
+// that's signed by one of the client CAs if there are any.
+func (t TLS) Config() (*tls.Config, error) {
+	certs, err := newCertReloader(t)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion:       tls.VersionTLS12,
+		CipherSuites:     tlsCipherSuites,
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256},
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert, _ := certs.get()
+			return cert, nil
+		},
+	}
+
+	// Clients' certificates are verified here rather than with ClientCAs,
+	// so that the CAs can be read again, and the config can still be
+	// cloned, like gRPC does
+	if len(t.ClientCAFile) > 0 {
+		config.ClientAuth = tls.RequireAnyClientCert
+		config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			_, clientCAs := certs.get()
+			return verifyClientCert(rawCerts, clientCAs)
+		}
+	}
+
+	return config, nil
+}
+
+// verifyClientCert checks that the client's certificate is signed by one of
+// the CAs, the same way that tls.RequireAndVerifyClientCert would.
+func verifyClientCert(rawCerts [][]byte, clientCAs *x509.CertPool) error {
+	var chain []*x509.Certificate
+	for _, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return err
+		}
+		chain = append(chain, cert)
+	}
+	if len(chain) == 0 {
+		return fmt.Errorf("the client didn't send a certificate")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := chain[0].Verify(x509.VerifyOptions{
+		Roots:         clientCAs,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	return err
+}
+
+// A certReloader keeps the certificate and client CAs, and reads them
+// again when the files have changed since they were read.
+type certReloader struct {
+	tls        TLS
+	checkEvery time.Duration
+
+	mu        sync.Mutex
+	checked   time.Time
+	modTimes  []time.Time
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+}
+
+func newCertReloader(t TLS) (*certReloader, error) {
+	if len(t.CertFile) == 0 || len(t.KeyFile) == 0 {
+		return nil, fmt.Errorf("%w: it needs both a certificate and a key", ErrInvalidTLS)
+	}
+
+	r := &certReloader{tls: t, checkEvery: tlsReloadEvery}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	r.checked = time.Now()
+
+	return r, nil
+}
+
+func (r *certReloader) files() []string {
+	files := []string{r.tls.CertFile, r.tls.KeyFile}
+	if len(r.tls.ClientCAFile) > 0 {
+		files = append(files, r.tls.ClientCAFile)
+	}
+	return files
+}
+
+// readModTimes is when each of the files was last changed.
+func (r *certReloader) readModTimes() ([]time.Time, error) {
+	var modTimes []time.Time
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		modTimes = append(modTimes, info.ModTime())
+	}
+	return modTimes, nil
+}
+
+// load reads the files. It's called with the lock held, or before
+// anything else can use the reloader.
+func (r *certReloader) load() error {
+	modTimes, err := r.readModTimes()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidTLS, err)
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.tls.CertFile, r.tls.KeyFile)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidTLS, err)
+	}
+
+	var clientCAs *x509.CertPool
+	if len(r.tls.ClientCAFile) > 0 {
+		pem, err := os.ReadFile(r.tls.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidTLS, err)
+		}
+
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("%w: there aren't any certificates in %s", ErrInvalidTLS, r.tls.ClientCAFile)
+		}
+	}
+
+	r.modTimes, r.cert, r.clientCAs = modTimes, &cert, clientCAs
+	return nil
+}
+
+// get is the certificate and client CAs, after reading them again if it's
+// time to look at the files and they've changed.
+func (r *certReloader) get() (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.checked) < r.checkEvery {
+		return r.cert, r.clientCAs
+	}
+	r.checked = time.Now()
+
+	modTimes, err := r.readModTimes()
+	if err == nil && !changed(r.modTimes, modTimes) {
+		return r.cert, r.clientCAs
+	}
+
+	if err == nil {
+		err = r.load()
+	}
+	if err != nil && r.tls.ErrorLog != nil {
+		r.tls.ErrorLog.Printf("Keeping the TLS certificate that was loaded before: %s\n", err)
+	}
+
+	return r.cert, r.clientCAs
+}
+
+func changed(before, after []time.Time) bool {
+	for i := range before {
+		if !before[i].Equal(after[i]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+// testCA signs certificates for the tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("expected to make a CA, got %s", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	return &testCA{cert, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue writes a certificate and its key for the name to files in the dir.
+func (ca *testCA) issue(t *testing.T, dir, name string, usage x509.ExtKeyUsage) (string, string) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("expected to issue a certificate, got %s", err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+
+	certFile, keyFile := filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	return certFile, keyFile
+}
+
+func TestTLSConfig(t *testing.T) {
+	if _, err := (TLS{CertFile: "server.crt"}).Config(); !errors.Is(err, ErrInvalidTLS) {
+		t.Errorf("expected a certificate without a key to be invalid, got %v", err)
+	}
+	if _, err := (TLS{CertFile: "missing.crt", KeyFile: "missing.key"}).Config(); !errors.Is(err, ErrInvalidTLS) {
+		t.Errorf("expected missing files to be invalid, got %v", err)
+	}
+
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	certFile, keyFile := ca.issue(t, dir, "server", x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, dir, "client", x509.ExtKeyUsageClientAuth)
+	caFile := filepath.Join(dir, "ca.crt")
+	os.WriteFile(caFile, ca.pem, 0o600)
+
+	config, err := TLS{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile}.Config()
+	if err != nil {
+		t.Fatalf("expected a TLS config, got %s", err)
+	}
+	if config.MinVersion != tls.VersionTLS12 {
+		t.Errorf("expected at least TLS 1.2, got %x", config.MinVersion)
+	}
+
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	ts := httptest.NewUnstartedServer(New(store.NewMemory(communities), discardLogs))
+	ts.TLS = config
+	ts.Config.ErrorLog = log.New(io.Discard, "", 0)
+	ts.StartTLS()
+	defer ts.Close()
+
+	// The server name is sent, since httptest's own certificate is used
+	// for connections without one
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.pem)
+	get := func(certs ...tls.Certificate) error {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, ServerName: "server", Certificates: certs}}}
+		resp, err := client.Get(ts.URL + "/healthz")
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	if err := get(); err == nil {
+		t.Errorf("expected a client without a certificate to be turned away")
+	}
+
+	cert, _ := tls.LoadX509KeyPair(clientCert, clientKey)
+	if err := get(cert); err != nil {
+		t.Errorf("expected a client with a certificate to be let in, got %s", err)
+	}
+
+	otherCert, otherKey := newTestCA(t).issue(t, dir, "other", x509.ExtKeyUsageClientAuth)
+	other, _ := tls.LoadX509KeyPair(otherCert, otherKey)
+	if err := get(other); err == nil {
+		t.Errorf("expected a client with a certificate from another CA to be turned away")
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	certFile, keyFile := ca.issue(t, dir, "server", x509.ExtKeyUsageServerAuth)
+
+	r, err := newCertReloader(TLS{CertFile: certFile, KeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("expected to load the certificate, got %s", err)
+	}
+	r.checkEvery = 0
+
+	first, _ := r.get()
+	if same, _ := r.get(); same != first {
+		t.Errorf("expected the same certificate while the files haven't changed")
+	}
+
+	// Rotated files are read again
+	later := time.Now().Add(time.Minute)
+	ca.issue(t, dir, "server", x509.ExtKeyUsageServerAuth)
+	os.Chtimes(certFile, later, later)
+	os.Chtimes(keyFile, later, later)
+
+	rotated, _ := r.get()
+	if rotated == first || rotated.Leaf.SerialNumber.Cmp(first.Leaf.SerialNumber) == 0 {
+		t.Errorf("expected the rotated certificate")
+	}
+
+	// And ones that are broken are skipped
+	os.WriteFile(certFile, []byte("not a certificate"), 0o600)
+	later = later.Add(time.Minute)
+	os.Chtimes(certFile, later, later)
+
+	if kept, _ := r.get(); kept != rotated {
+		t.Errorf("expected to keep the certificate when the new one is broken")
+	}
+}
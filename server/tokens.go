@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"nfip-community-book/problem"
+)
+
+// How long a token from POST /v1/tokens can be used to connect for.
+// Connections that were made with one stay open after it expires.
+const tokenLifetime = 5 * time.Minute
+
+// Where a token can be given instead of an API key, for browsers, which
+// can't send headers with an EventSource or a WebSocket.
+const (
+	tokenParameter = "access_token"
+	tokenCookie    = "nfip_token"
+)
+
+// A token stands in for the API key that it was made with until it
+// expires. It's signed with the key's hash, so it can't be made without
+// the key, and every server with the same keys takes it.
+type token struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// postToken makes a token for the request's API key, which is set as a
+// cookie too, so that an EventSource or a WebSocket on the same origin
+// sends it.
+func (s *Server) postToken(rw http.ResponseWriter, r *http.Request) {
+	key, ok := r.Context().Value(apiKeyContextKey{}).(*apiKey)
+	if !ok {
+		s.writeError(rw, r, http.StatusNotFound, problem.NotFound, "tokens are only needed when the server has API keys")
+		return
+	}
+
+	expires := time.Now().Add(tokenLifetime).Truncate(time.Second)
+	t := token{Token: key.token(expires), ExpiresAt: expires.UTC()}
+
+	http.SetCookie(rw, &http.Cookie{
+		Name:     tokenCookie,
+		Value:    t.Token,
+		Path:     "/",
+		Expires:  expires,
+		MaxAge:   int(tokenLifetime.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+	s.writeJSON(rw, http.StatusOK, t)
+}
+
+// requestToken is the token in the request's query or cookie, if any.
+func requestToken(r *http.Request) string {
+	if t := r.URL.Query().Get(tokenParameter); len(t) > 0 {
+		return t
+	}
+
+	if c, err := r.Cookie(tokenCookie); err == nil {
+		return c.Value
+	}
+	return ""
+}
+
+// token is the key's name and when the token expires, signed.
+func (k *apiKey) token(expires time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(k.name)) + "." + strconv.FormatInt(expires.Unix(), 10)
+	return payload + "." + k.sign(payload)
+}
+
+func (k *apiKey) sign(payload string) string {
+	mac := hmac.New(sha256.New, k.hash[:])
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// keyForToken is the API key that the token was made with, or nil if
+// there isn't one, or the token has expired or wasn't signed by it.
+func (s *Server) keyForToken(t string, now time.Time) *apiKey {
+	payload, signature, ok := cutLast(t, ".")
+	if !ok {
+		return nil
+	}
+
+	encodedName, expiresString, ok := strings.Cut(payload, ".")
+	if !ok {
+		return nil
+	}
+
+	name, err := base64.RawURLEncoding.DecodeString(encodedName)
+	if err != nil {
+		return nil
+	}
+
+	expires, err := strconv.ParseInt(expiresString, 10, 64)
+	if err != nil || now.Unix() > expires {
+		return nil
+	}
+
+	for _, key := range s.apiKeys {
+		if key.name == string(name) && hmac.Equal([]byte(key.sign(payload)), []byte(signature)) {
+			return key
+		}
+	}
+	return nil
+}
+
+func cutLast(s, sep string) (string, string, bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i], s[i+len(sep):], true
+}
+
+// apiKeyContextKey is where authenticate puts the request's API key.
+type apiKeyContextKey struct{}
+
+func withAPIKey(ctx context.Context, key *apiKey) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+func TestTokens(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	s := New(store.NewMemory(communities), discardLogs, WithAPIKeys([]APIKey{{Name: "website", Key: "website-key"}}))
+
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	post := func(header, value string) *http.Response {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/v1/tokens", nil)
+		if len(header) > 0 {
+			req.Header.Set(header, value)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("expected a response, got %s", err)
+		}
+		return resp
+	}
+
+	resp := post("", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected a 401 for a token without a key, got %d", resp.StatusCode)
+	}
+
+	resp = post("X-API-Key", "website-key")
+	var tok token
+	err := json.NewDecoder(resp.Body).Decode(&tok)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || err != nil || len(tok.Token) == 0 || time.Until(tok.ExpiresAt) > tokenLifetime {
+		t.Fatalf("expected a token, got %d %+v %v", resp.StatusCode, tok, err)
+	}
+
+	cookies := resp.Cookies()
+	if len(cookies) != 1 || cookies[0].Name != tokenCookie || cookies[0].Value != tok.Token || !cookies[0].HttpOnly {
+		t.Errorf("expected the token in a cookie too, got %v", cookies)
+	}
+
+	connect := func(target string, cookie *http.Cookie) int {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+target, nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("expected a response, got %s", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := connect("/v1/events?"+tokenParameter+"="+url.QueryEscape(tok.Token), nil); code != http.StatusOK {
+		t.Errorf("expected the token in the query to be let through to the events, got %d", code)
+	}
+	if code := connect("/v1/events", cookies[0]); code != http.StatusOK {
+		t.Errorf("expected the token in the cookie to be let through to the events, got %d", code)
+	}
+
+	conn := dialLiveSearch(t, ts, "?"+tokenParameter+"="+url.QueryEscape(tok.Token))
+	conn.Write(context.Background(), websocket.MessageText, []byte("houston"))
+	if page := readLiveSearch(t, conn); string(page["q"]) != `"houston"` {
+		t.Errorf("expected the token to be let through to the live search, got %s", page["q"])
+	}
+
+	// Tokens are only for connecting to the endpoints that browsers can't send keys to
+	if code := connect("/v1/communities?"+tokenParameter+"="+url.QueryEscape(tok.Token), nil); code != http.StatusUnauthorized {
+		t.Errorf("expected a token to not be let through to the communities, got %d", code)
+	}
+
+	if key := s.keyForToken(tok.Token, time.Now().Add(tokenLifetime+time.Second)); key != nil {
+		t.Errorf("expected an expired token to not be taken")
+	}
+	if key := s.keyForToken(tok.Token+"x", time.Now()); key != nil {
+		t.Errorf("expected a token that's been changed to not be taken")
+	}
+
+	other := New(store.NewMemory(communities), discardLogs, WithAPIKeys([]APIKey{{Name: "website", Key: "another-key"}}))
+	if key := other.keyForToken(tok.Token, time.Now()); key != nil {
+		t.Errorf("expected a token to only be taken with the key it was made with")
+	}
+}
+
+func TestTokensWithoutKeys(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	s := New(store.NewMemory(communities), discardLogs)
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/tokens", nil)
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, r)
+	if rw.Code != http.StatusNotFound {
+		t.Errorf("expected a 404 without keys, got %d", rw.Code)
+	}
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "nfip-community-book/server"
+
+// WithTracerProvider traces requests with the provider rather than the
+// global one, which doesn't send them anywhere unless it's been set.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(s *Server) {
+		s.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// The trace context that requests come with, in the W3C traceparent and
+// baggage headers, is continued rather than starting a new trace, so that
+// the API's spans show up in the traces of the services that call it.
+var tracePropagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// trace starts a span for every request. It's named by its method at
+// first, and by its route once it's been routed, so that traces of the
+// same endpoint are grouped together rather than by each CID.
+func (s *Server) trace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		ctx := tracePropagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := s.tracer.Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.URLPath(r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		sw := &statusWriter{ResponseWriter: rw}
+		next.ServeHTTP(sw, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(sw.Status()))
+		if sw.Status() >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(sw.Status()))
+		}
+	})
+}
+
+// traceRoute names the request's span after the route that it matched.
+func traceRoute(r *http.Request, pattern string) {
+	span := trace.SpanFromContext(r.Context())
+	span.SetName(pattern)
+	span.SetAttributes(semconv.HTTPRoute(pattern))
+}
+
+// startSpan starts a span for part of answering a request, like running
+// a search, so that it can be told apart from the rest of the request.
+func (s *Server) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+func defaultTracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+func TestTrace(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	spans := tracetest.NewSpanRecorder()
+	s := New(store.NewMemory(communities), discardLogs, WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))))
+
+	// The trace that the request came with is continued
+	r := httptest.NewRequest(http.MethodGet, "/v1/search?q=houston", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.Header.Set(requestIDHeader, "abc123")
+	s.ServeHTTP(httptest.NewRecorder(), r)
+
+	ended := spans.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("expected a span for the request and one for the search, got %d", len(ended))
+	}
+
+	search, request := ended[0], ended[1]
+	if request.Name() != "GET /v1/search" || request.SpanKind() != trace.SpanKindServer {
+		t.Errorf("expected the request's span to be named after its route, got %s", request.Name())
+	}
+	if request.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || request.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("expected the request to be in the trace it came with, got %s", request.SpanContext().TraceID())
+	}
+	if !slices.Contains(request.Attributes(), attribute.String("nfip.request_id", "abc123")) {
+		t.Errorf("expected the request's ID on its span, got %v", request.Attributes())
+	}
+	if search.Name() != "search" || search.Parent().SpanID() != request.SpanContext().SpanID() {
+		t.Errorf("expected the search to be in the request's span, got %s", search.Name())
+	}
+
+	// Every CID is the same route
+	s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/communities/480296", nil))
+	if ended := spans.Ended(); ended[len(ended)-1].Name() != "GET /v1/communities/{cid}" {
+		t.Errorf("expected the route rather than the path, got %s", ended[len(ended)-1].Name())
+	}
+}
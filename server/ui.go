@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+)
+
+// WithUI serves a page for browsers at /, with its files under /ui/, like
+// web.Handler. The page doesn't need an API key, but the API does, so it
+// asks for one when the server has them.
+func WithUI(h http.Handler) Option {
+	return func(s *Server) {
+		s.ui = h
+	}
+}
+
+// isUI is whether the path is the page or one of its files.
+func isUI(path string) bool {
+	return path == "/" || strings.HasPrefix(path, "/ui/")
+}
+
+func (s *Server) serveUI(rw http.ResponseWriter, r *http.Request) {
+	traceRoute(r, r.Pattern)
+	s.ui.ServeHTTP(rw, r)
+}
+
+// WithHandler serves h at the pattern alongside the API, behind the same
+// rate limits and API keys, for the endpoints from before there was an
+// API, like /status. They aren't in the OpenAPI document.
+func WithHandler(pattern string, h http.Handler) Option {
+	return func(s *Server) {
+		s.handlers = append(s.handlers, handler{pattern, h})
+	}
+}
+
+type handler struct {
+	pattern string
+	handler http.Handler
+}
+
+func (h handler) traced() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		traceRoute(r, r.Pattern)
+		h.handler.ServeHTTP(rw, r)
+	})
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+func TestUI(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	page := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("page"))
+	})
+	s := New(store.NewMemory(communities), discardLogs, WithUI(page), WithAPIKeys([]APIKey{{Name: "acme", Key: "acme-key"}}))
+
+	// The page doesn't need a key, but the API still does
+	for _, target := range []string{"/", "/ui/app.js"} {
+		if rw := get(s, target); rw.Code != http.StatusOK || rw.Body.String() != "page" {
+			t.Errorf("expected %s to be the page's, got %d %s", target, rw.Code, rw.Body)
+		}
+	}
+	if rw := get(s, "/v1/communities"); rw.Code != http.StatusUnauthorized {
+		t.Errorf("expected the API to need a key, got %d", rw.Code)
+	}
+
+	// Without it there's nothing at /
+	s, _ = newTestServer(t)
+	if rw := get(s, "/"); rw.Code != http.StatusNotFound {
+		t.Errorf("expected a 404 without the page, got %d", rw.Code)
+	}
+}
@@ -0,0 +1,130 @@
+package states
+
+import (
+	"strconv"
+	"strings"
+)
+
+type State struct {
+	FIPS         int    `json:"fips"`
+	Abbreviation string `json:"abbreviation"`
+	Name         string `json:"name"`
+}
+
+// Every state, the District of Columbia, and the territories
+// that can take part in the NFIP, ordered by FIPS code.
+var all = []State{
+	{1, "AL", "Alabama"},
+	{2, "AK", "Alaska"},
+	{4, "AZ", "Arizona"},
+	{5, "AR", "Arkansas"},
+	{6, "CA", "California"},
+	{8, "CO", "Colorado"},
+	{9, "CT", "Connecticut"},
+	{10, "DE", "Delaware"},
+	{11, "DC", "District of Columbia"},
+	{12, "FL", "Florida"},
+	{13, "GA", "Georgia"},
+	{15, "HI", "Hawaii"},
+	{16, "ID", "Idaho"},
+	{17, "IL", "Illinois"},
+	{18, "IN", "Indiana"},
+	{19, "IA", "Iowa"},
+	{20, "KS", "Kansas"},
+	{21, "KY", "Kentucky"},
+	{22, "LA", "Louisiana"},
+	{23, "ME", "Maine"},
+	{24, "MD", "Maryland"},
+	{25, "MA", "Massachusetts"},
+	{26, "MI", "Michigan"},
+	{27, "MN", "Minnesota"},
+	{28, "MS", "Mississippi"},
+	{29, "MO", "Missouri"},
+	{30, "MT", "Montana"},
+	{31, "NE", "Nebraska"},
+	{32, "NV", "Nevada"},
+	{33, "NH", "New Hampshire"},
+	{34, "NJ", "New Jersey"},
+	{35, "NM", "New Mexico"},
+	{36, "NY", "New York"},
+	{37, "NC", "North Carolina"},
+	{38, "ND", "North Dakota"},
+	{39, "OH", "Ohio"},
+	{40, "OK", "Oklahoma"},
+	{41, "OR", "Oregon"},
+	{42, "PA", "Pennsylvania"},
+	{44, "RI", "Rhode Island"},
+	{45, "SC", "South Carolina"},
+	{46, "SD", "South Dakota"},
+	{47, "TN", "Tennessee"},
+	{48, "TX", "Texas"},
+	{49, "UT", "Utah"},
+	{50, "VT", "Vermont"},
+	{51, "VA", "Virginia"},
+	{53, "WA", "Washington"},
+	{54, "WV", "West Virginia"},
+	{55, "WI", "Wisconsin"},
+	{56, "WY", "Wyoming"},
+	{60, "AS", "American Samoa"},
+	{64, "FM", "Federated States of Micronesia"},
+	{66, "GU", "Guam"},
+	{68, "MH", "Marshall Islands"},
+	{69, "MP", "Northern Mariana Islands"},
+	{70, "PW", "Palau"},
+	{72, "PR", "Puerto Rico"},
+	{78, "VI", "Virgin Islands"},
+}
+
+var byFIPS = make(map[int]State)
+var byAbbreviation = make(map[string]State)
+var byName = make(map[string]State)
+
+func init() {
+	for _, s := range all {
+		byFIPS[s.FIPS] = s
+		byAbbreviation[s.Abbreviation] = s
+		byName[strings.ToLower(s.Name)] = s
+	}
+}
+
+// All returns every state ordered by FIPS code.
+func All() []State {
+	states := make([]State, len(all))
+	copy(states, all)
+	return states
+}
+
+func ByFIPS(fips int) (State, bool) {
+	s, ok := byFIPS[fips]
+	return s, ok
+}
+
+// ByAbbreviation looks up a state by its USPS abbreviation, ignoring case.
+func ByAbbreviation(abbreviation string) (State, bool) {
+	s, ok := byAbbreviation[strings.ToUpper(strings.TrimSpace(abbreviation))]
+	return s, ok
+}
+
+// ByName looks up a state by its full name, ignoring case.
+func ByName(name string) (State, bool) {
+	s, ok := byName[strings.ToLower(strings.TrimSpace(name))]
+	return s, ok
+}
+
+// Lookup finds a state however it's written: by its abbreviation ("FL"),
+// its name ("Florida") or its FIPS code ("12" or "012").
+func Lookup(state string) (State, bool) {
+	if s, ok := ByAbbreviation(state); ok {
+		return s, true
+	}
+
+	if s, ok := ByName(state); ok {
+		return s, true
+	}
+
+	if fips, err := strconv.Atoi(strings.TrimSpace(state)); err == nil {
+		return ByFIPS(fips)
+	}
+
+	return State{}, false
+}
@@ -0,0 +1,40 @@
+package states
+
+import "testing"
+
+func TestLookups(t *testing.T) {
+	s, ok := ByFIPS(12)
+	if !ok || s.Abbreviation != "FL" || s.Name != "Florida" {
+		t.Errorf("expected FIPS 12 to be Florida, got %v", s)
+	}
+
+	// Lookups by abbreviation and name should ignore case
+	s, ok = ByAbbreviation("pr")
+	if !ok || s.FIPS != 72 {
+		t.Errorf("expected \"pr\" to be Puerto Rico, got %v", s)
+	}
+
+	s, ok = ByName("new york")
+	if !ok || s.FIPS != 36 {
+		t.Errorf("expected \"new york\" to be New York, got %v", s)
+	}
+
+	// FIPS codes that aren't assigned to a state shouldn't be found
+	if _, ok = ByFIPS(3); ok {
+		t.Errorf("expected FIPS 3 to not be a state")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, state := range []string{"FL", "fl", "Florida", " FLORIDA ", "12", "012"} {
+		if s, ok := Lookup(state); !ok || s.FIPS != 12 {
+			t.Errorf("expected \"%s\" to be Florida, got %v", state, s)
+		}
+	}
+
+	for _, state := range []string{"", "XX", "Atlantis", "3", "-12"} {
+		if s, ok := Lookup(state); ok {
+			t.Errorf("expected \"%s\" to not be a state, got %v", state, s)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"nfip-community-book/data"
+)
+
+// stats are how many communities there are, and how they're spread out.
+type stats struct {
+	Communities   int                  `json:"communities"`
+	Participating int                  `json:"participating"`
+	CRS           int                  `json:"crs"`
+	Tribal        int                  `json:"tribal"`
+	States        []data.StateCount    `json:"states"`
+	Programs      []data.ProgramCount  `json:"programs"`
+	CRSClasses    []data.CRSClassCount `json:"crs_classes"`
+}
+
+func (c *cli) statsCommand() *cobra.Command {
+	var filters filterFlags
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Count the communities by state, program and CRS class",
+		Long: `Count the communities, or the ones that pass the filters, and how many of
+them are in each state, program and CRS class.`,
+		Example: "  nfip stats --state TX",
+		Args:    checkArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.runStats(&filters)
+		},
+	}
+
+	filters.add(cmd)
+	return cmd
+}
+
+func (c *cli) runStats(filters *filterFlags) error {
+	communities, err := c.communities()
+	if err != nil {
+		return err
+	}
+
+	communities, err = filters.apply(communities)
+	if err != nil {
+		return err
+	}
+
+	s := countStats(communities)
+	return c.write(s, s.rows())
+}
+
+func countStats(communities data.NFIPCommunityStatuses) stats {
+	s := stats{
+		Communities: len(communities),
+		States:      communities.CountByState(),
+		Programs:    communities.CountByProgram(),
+		CRSClasses:  communities.CountByCRSClass(),
+	}
+
+	for _, community := range communities {
+		if community.ParticipatingCommunity {
+			s.Participating++
+		}
+		if community.IsCRSParticipant() {
+			s.CRS++
+		}
+		if community.Tribal {
+			s.Tribal++
+		}
+	}
+
+	return s
+}
+
+// rows are the counts as a table, with what was counted by and what it
+// was counted for, like the state and TX.
+func (s stats) rows() [][]string {
+	rows := [][]string{
+		{"BY", "WHICH", "COUNT"},
+		{"all", "communities", strconv.Itoa(s.Communities)},
+		{"all", "participating", strconv.Itoa(s.Participating)},
+		{"all", "crs", strconv.Itoa(s.CRS)},
+		{"all", "tribal", strconv.Itoa(s.Tribal)},
+	}
+
+	for _, sc := range s.States {
+		rows = append(rows, []string{"state", sc.State, strconv.Itoa(sc.Count)})
+	}
+	for _, pc := range s.Programs {
+		rows = append(rows, []string{"program", pc.Program.String(), strconv.Itoa(pc.Count)})
+	}
+	for _, cc := range s.CRSClasses {
+		rows = append(rows, []string{"crs class", strconv.Itoa(cc.Class), strconv.Itoa(cc.Count)})
+	}
+
+	return rows
+}
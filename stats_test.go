@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestStatsCommand(t *testing.T) {
+	for _, tc := range []commandTest{
+		{name: "json", args: []string{"--demo", "stats"}, code: exitOK, contains: []string{`"communities": 34`, `"states": [`}},
+		{name: "state", args: []string{"--demo", "stats", "--state", "TX", "-o", "csv"}, code: exitOK, contains: []string{
+			"all,communities,3\n",
+			"all,participating,2\n",
+			"all,crs,2\n",
+			"state,TX,3\n",
+			"program,Not Participating,1\n",
+		}},
+		{name: "yaml", args: []string{"--demo", "stats", "--state", "TX", "-o", "yaml"}, code: exitOK, contains: []string{"communities: 3\n"}},
+		{name: "query", args: []string{"--demo", "stats", "-q", "county:harris", "-o", "csv"}, code: exitOK, contains: []string{"all,communities,2\n"}},
+		{name: "unknown state", args: []string{"--demo", "stats", "--state", "XX"}, code: exitUsage},
+	} {
+		t.Run(tc.name, tc.run)
+	}
+}
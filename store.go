@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"nfip-community-book/data"
+	"nfip-community-book/server"
+	"nfip-community-book/store"
+	"nfip-community-book/store/bolt"
+	"nfip-community-book/store/postgres"
+	"nfip-community-book/store/redis"
+	"nfip-community-book/store/sqlite"
+)
+
+// The communities are kept in the store in this environment variable
+// if it's set, rather than being parsed from the status book every time.
+// It's "sqlite:<file>", "bolt:<file>", a PostgreSQL connection string
+// or a Redis URL.
+const StoreEnv = "NFIP_STORE"
+
+// openStore opens the store that's described by the spec. Stores that keep
+// a copy of the communities in memory index it with the options.
+func openStore(ctx context.Context, spec string, opts ...data.IndexOption) (store.Store, error) {
+	kind, location, _ := strings.Cut(spec, ":")
+
+	switch kind {
+	case "sqlite":
+		return sqlite.Open(location)
+	case "bolt":
+		return bolt.Open(location)
+	case "postgres", "postgresql":
+		return postgres.Open(ctx, spec)
+	case "redis", "rediss":
+		return redis.Open(ctx, spec, redis.WithIndexOptions(opts...))
+	}
+
+	return nil, fmt.Errorf("unknown store \"%s\" in %s", kind, StoreEnv)
+}
+
+// loadCommunities gets the communities from the store if there is one,
+// and from the status book if there isn't.
+func loadCommunities(l *log.Logger) (data.NFIPCommunityStatuses, error) {
+	ctx := context.Background()
+	s, err := openCommunities(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+	defer closeStore(s)
+
+	return s.Load(ctx)
+}
+
+// openCommunities opens the store that the communities are looked up in,
+// which is the one in NFIP_STORE if it's set, or the status book in memory
+// indexed with the options if it isn't. A store that's empty is filled from
+// the status book first. It has to be closed with closeStore.
+func openCommunities(ctx context.Context, l *log.Logger, opts ...data.IndexOption) (store.Store, error) {
+	spec := os.Getenv(StoreEnv)
+	if len(spec) == 0 {
+		communities, err := data.GetNFIPCommunityStatusBook(l)
+		if err != nil {
+			return nil, err
+		}
+
+		// They're as old as the status book that was downloaded
+		memory := store.NewMemory(communities, opts...)
+		downloaded, err := data.NFIPCommunityStatusBookDownloaded()
+		if err != nil {
+			return nil, err
+		}
+		memory.SetUpdated(downloaded)
+
+		return memory, nil
+	}
+
+	s, err := openStore(ctx, spec, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fillStore(ctx, l, s); err != nil {
+		closeStore(s)
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// openSnapshot opens the store that the server answers from, which stays
+// open until it's closed with closeStore. Stores that don't keep a copy of
+// the communities in memory themselves, which the server lists them from,
+// are cached in memory. The index is built with the options.
+func openSnapshot(ctx context.Context, l *log.Logger, opts ...data.IndexOption) (store.Snapshot, error) {
+	s, err := openCommunities(ctx, l, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if snapshot, ok := s.(store.Snapshot); ok {
+		// Redis only has its copy once it's been loaded
+		if _, err := snapshot.Load(ctx); err != nil {
+			closeStore(s)
+			return nil, err
+		}
+		return snapshot, nil
+	}
+
+	cached, err := store.NewCached(ctx, s, opts...)
+	if err != nil {
+		closeStore(s)
+		return nil, err
+	}
+
+	return cached, nil
+}
+
+// A watcher is a store that's told when another instance of the service
+// refreshes the communities, like Redis, until the context is done.
+type watcher interface {
+	Watch(ctx context.Context, l *log.Logger) error
+}
+
+var _ watcher = (*redis.Store)(nil)
+
+// fillStore fills the store from the status book if it's empty.
+func fillStore(ctx context.Context, l *log.Logger, s store.Store) error {
+	empty, err := isEmpty(ctx, s)
+	if err != nil || !empty {
+		return err
+	}
+
+	l.Println("Store is empty. Loading it from the NFIP Community book...")
+	communities, err := data.GetNFIPCommunityStatusBook(l)
+	if err != nil {
+		return err
+	}
+
+	changes, err := s.Refresh(ctx, communities)
+	if err != nil {
+		return err
+	}
+
+	l.Printf("Loaded %d communities into the store\n", changes.Added)
+	return nil
+}
+
+// isEmpty is whether there aren't any communities in the store. They're
+// counted rather than loaded if the store can count them.
+func isEmpty(ctx context.Context, s store.Store) (bool, error) {
+	switch s := s.(type) {
+	case interface {
+		Len(context.Context) (int, error)
+	}:
+		n, err := s.Len(ctx)
+		return n == 0, err
+	case interface{ Len() (int, error) }:
+		n, err := s.Len()
+		return n == 0, err
+	}
+
+	communities, err := s.Load(ctx)
+	return len(communities) == 0, err
+}
+
+// The rules that a status book that's downloaded again has to pass
+// before it's used, like min-records=20000,max-record-loss=1. See
+// data.ParseRefreshRules.
+const RefreshRulesEnv = "NFIP_REFRESH_RULES"
+
+// downloadCommunities downloads the status book again for the server,
+// which refreshes its own store with it.
+func downloadCommunities(l *log.Logger, rules []data.RefreshRule) server.Refresher {
+	return func(ctx context.Context, current data.NFIPCommunityStatuses) (data.NFIPCommunityStatuses, error) {
+		next, err := data.RefreshNFIPCommunityStatusBookContext(ctx, l, current, rules...)
+		if err != nil {
+			return nil, err
+		}
+
+		return next, recordHistory(l, current, next)
+	}
+}
+
+// refreshCommunities downloads the status book again and saves it the
+// same places that loadCommunities reads it from.
+func refreshCommunities(l *log.Logger, rules []data.RefreshRule) server.Refresher {
+	return func(ctx context.Context, current data.NFIPCommunityStatuses) (data.NFIPCommunityStatuses, error) {
+		next, err := data.RefreshNFIPCommunityStatusBookContext(ctx, l, current, rules...)
+		if err != nil {
+			return nil, err
+		}
+
+		if spec := os.Getenv(StoreEnv); len(spec) > 0 {
+			s, err := openStore(ctx, spec)
+			if err != nil {
+				return nil, err
+			}
+			defer closeStore(s)
+
+			changes, err := s.Refresh(ctx, next)
+			if err != nil {
+				return nil, err
+			}
+			l.Printf("Refreshed the store: %d added, %d updated, %d removed\n", changes.Added, changes.Updated, changes.Removed)
+		}
+
+		return next, recordHistory(l, current, next)
+	}
+}
+
+// closeStore closes the store if it has to be closed. The Postgres store's
+// Close doesn't return anything, so it's closed on its own.
+func closeStore(s store.Store) {
+	switch c := s.(type) {
+	case io.Closer:
+		c.Close()
+	case interface{ Close() }:
+		c.Close()
+	}
+}
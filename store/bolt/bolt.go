@@ -0,0 +1,385 @@
+// Package bolt keeps the status book in a bbolt file, an embedded key-value
+// store, so that communities can be looked up by CID, state and the start
+// of their name without a database server or parsing the status book again.
+//
+// Communities are kept as JSON under their CID. The indexes are buckets of
+// keys that end with the CID, like "48/480296" in the state index, so that
+// finding everything with a prefix is a seek and a scan. The order that
+// they're in in the status book is a bucket of their CIDs, under where they
+// are in it, so that they're loaded the same as they're parsed.
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"nfip-community-book/data"
+	"nfip-community-book/states"
+	"nfip-community-book/store"
+)
+
+var (
+	communitiesBucket = []byte("communities")
+	stateBucket       = []byte("by-state")
+	nameBucket        = []byte("by-name")
+	orderBucket       = []byte("in-order")
+	metaBucket        = []byte("meta")
+)
+
+// When the store was last refreshed is kept in the meta bucket.
+var refreshedKey = []byte("refreshed-at")
+
+// How long Open waits for another process to let go of the file.
+const openTimeout = time.Second
+
+// A Store is the status book in a bbolt file.
+type Store struct {
+	db *bolt.DB
+}
+
+var _ store.Store = (*Store)(nil)
+var _ store.Dated = (*Store)(nil)
+
+// Open opens the file, creating it and its buckets if they aren't there
+// yet. Only one process can have the file open at a time.
+func Open(filename string) (*Store, error) {
+	db, err := bolt.Open(filename, 0o600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{communitiesBucket, stateBucket, nameBucket, orderBucket, metaBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Refresh makes the store the same as the communities, writing only the
+// ones that changed and deleting the ones that aren't there anymore. It's
+// all one transaction, so lookups see either the old communities or the new.
+func (s *Store) Refresh(ctx context.Context, communities data.NFIPCommunityStatuses) (store.Changes, error) {
+	var changes store.Changes
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(communitiesBucket)
+
+		// The last of a community that's in the status book more
+		// than once is the one that's kept, and where it's kept
+		last := make(map[int]int, len(communities))
+		for i, c := range communities {
+			last[c.CID] = i
+		}
+
+		var order [][]byte
+		seen := make(map[string]bool, len(communities))
+		for i, c := range communities {
+			key := cidKey(c.CID)
+			if last[c.CID] == i {
+				order = append(order, key)
+			}
+
+			value, err := json.Marshal(c)
+			if err != nil {
+				return err
+			}
+
+			old := b.Get(key)
+			switch {
+			case seen[string(key)]:
+				// The status book has it more than once, and the last one wins
+			case old == nil:
+				changes.Added++
+			case !bytes.Equal(old, value):
+				changes.Updated++
+			default:
+				seen[string(key)] = true
+				continue
+			}
+			seen[string(key)] = true
+
+			if err := b.Put(key, value); err != nil {
+				return err
+			}
+		}
+
+		var removed [][]byte
+		b.ForEach(func(k, v []byte) error {
+			if !seen[string(k)] {
+				removed = append(removed, k)
+			}
+			return nil
+		})
+
+		for _, k := range removed {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		changes.Removed = len(removed)
+
+		// Communities that only moved in the status book
+		// aren't changes, but they're still put where they are now
+		if err := reorder(tx, order); err != nil {
+			return err
+		}
+
+		if err := tx.Bucket(metaBucket).Put(refreshedKey, []byte(time.Now().UTC().Format(time.RFC3339Nano))); err != nil {
+			return err
+		}
+
+		if changes == (store.Changes{}) {
+			return nil
+		}
+
+		return reindex(tx)
+	})
+
+	return changes, err
+}
+
+// reindex makes the indexes over again from the communities.
+func reindex(tx *bolt.Tx) error {
+	for _, bucket := range [][]byte{stateBucket, nameBucket} {
+		if err := tx.DeleteBucket(bucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucket(bucket); err != nil {
+			return err
+		}
+	}
+
+	byState, byName := tx.Bucket(stateBucket), tx.Bucket(nameBucket)
+	return tx.Bucket(communitiesBucket).ForEach(func(k, v []byte) error {
+		var c data.NFIPCommunityStatus
+		if err := json.Unmarshal(v, &c); err != nil {
+			return err
+		}
+
+		if err := byState.Put(indexKey(strconv.Itoa(c.StateFIPS), k), nil); err != nil {
+			return err
+		}
+
+		// Both "HOUSTON, CITY OF" and "HOUSTON" are
+		// found by their names, which are usually the same
+		for _, name := range []string{c.CommunityName, c.BaseName} {
+			if len(name) == 0 {
+				continue
+			}
+
+			if err := byName.Put(indexKey(nameKey(name), k), nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// reorder writes the order of the communities over again if it isn't the
+// same as what's there.
+func reorder(tx *bolt.Tx, order [][]byte) error {
+	b := tx.Bucket(orderBucket)
+
+	same := b.Stats().KeyN == len(order)
+	for i := 0; same && i < len(order); i++ {
+		same = bytes.Equal(b.Get(positionKey(i)), order[i])
+	}
+	if same {
+		return nil
+	}
+
+	if err := tx.DeleteBucket(orderBucket); err != nil {
+		return err
+	}
+	b, err := tx.CreateBucket(orderBucket)
+	if err != nil {
+		return err
+	}
+
+	for i, key := range order {
+		if err := b.Put(positionKey(i), key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// positionKey is where a community is in the status book, big endian so
+// that the keys are in the same order.
+func positionKey(i int) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(i))
+}
+
+func cidKey(cid int) []byte {
+	return []byte(fmt.Sprintf("%06d", cid))
+}
+
+// indexKey is the value that's indexed, then a slash, then the CID.
+func indexKey(value string, cid []byte) []byte {
+	return append([]byte(value+"/"), cid...)
+}
+
+// nameKey is how names are indexed, so that they're
+// found by their start no matter its case or spacing.
+func nameKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(strings.ReplaceAll(name, "/", " ")), " "))
+}
+
+// Refreshed is when the store was last refreshed.
+func (s *Store) Refreshed(ctx context.Context) (time.Time, error) {
+	var refreshed time.Time
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(metaBucket).Get(refreshedKey)
+		if v == nil {
+			return nil
+		}
+
+		var err error
+		refreshed, err = time.Parse(time.RFC3339Nano, string(v))
+		return err
+	})
+
+	return refreshed, err
+}
+
+// Len is how many communities are in the store.
+func (s *Store) Len() (int, error) {
+	n := 0
+	err := s.db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(communitiesBucket).Stats().KeyN
+		return nil
+	})
+
+	return n, err
+}
+
+// Get finds the community with the CID.
+func (s *Store) Get(ctx context.Context, cid int) (data.NFIPCommunityStatus, bool, error) {
+	var c data.NFIPCommunityStatus
+	found := false
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(communitiesBucket).Get(cidKey(cid))
+		if v == nil {
+			return nil
+		}
+
+		found = true
+		return json.Unmarshal(v, &c)
+	})
+
+	return c, found && err == nil, err
+}
+
+// Load is all of the communities, in the same order as they're in the
+// status book. Files that were refreshed before the order was kept are
+// loaded by CID until they're refreshed again.
+func (s *Store) Load(ctx context.Context) (data.NFIPCommunityStatuses, error) {
+	communities := data.NFIPCommunityStatuses{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(communitiesBucket)
+
+		load := func(v []byte) error {
+			var c data.NFIPCommunityStatus
+			if err := json.Unmarshal(v, &c); err != nil {
+				return err
+			}
+
+			communities = append(communities, c)
+			return nil
+		}
+
+		order := tx.Bucket(orderBucket)
+		if order.Stats().KeyN == 0 {
+			return b.ForEach(func(k, v []byte) error {
+				return load(v)
+			})
+		}
+
+		return order.ForEach(func(k, cid []byte) error {
+			return load(b.Get(cid))
+		})
+	})
+
+	return communities, err
+}
+
+// Search ranks all of the communities, the same as data's Search does.
+// There isn't an index for it, but reading them all is quick.
+func (s *Store) Search(ctx context.Context, term string) (data.SearchResults, error) {
+	communities, err := s.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return communities.Search(term), nil
+}
+
+// ByState finds the communities in the state, however it's written, by CID.
+// See states.Lookup.
+func (s *Store) ByState(state string) (data.NFIPCommunityStatuses, error) {
+	st, ok := states.Lookup(state)
+	if !ok {
+		return data.NFIPCommunityStatuses{}, nil
+	}
+
+	return s.scan(stateBucket, strconv.Itoa(st.FIPS)+"/")
+}
+
+// ByNamePrefix finds the communities with a name that starts with the prefix,
+// without caring about case, in order by name. Communities are found by their
+// full names and their base names, so "houston" finds "HOUSTON, CITY OF".
+func (s *Store) ByNamePrefix(prefix string) (data.NFIPCommunityStatuses, error) {
+	return s.scan(nameBucket, nameKey(prefix))
+}
+
+// scan finds the communities with index keys that start with the prefix.
+func (s *Store) scan(index []byte, prefix string) (data.NFIPCommunityStatuses, error) {
+	communities := data.NFIPCommunityStatuses{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(communitiesBucket)
+		seen := make(map[string]bool)
+
+		c := tx.Bucket(index).Cursor()
+		for k, _ := c.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, _ = c.Next() {
+			cid := k[bytes.LastIndexByte(k, '/')+1:]
+			if seen[string(cid)] {
+				continue
+			}
+			seen[string(cid)] = true
+
+			var community data.NFIPCommunityStatus
+			if err := json.Unmarshal(b.Get(cid), &community); err != nil {
+				return err
+			}
+			communities = append(communities, community)
+		}
+
+		return nil
+	})
+
+	return communities, err
+}
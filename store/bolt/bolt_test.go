@@ -0,0 +1,179 @@
+package bolt
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+func openTestStore(t *testing.T) (*Store, data.NFIPCommunityStatuses) {
+	t.Helper()
+
+	s, err := Open(filepath.Join(t.TempDir(), "nfip.db"))
+	if err != nil {
+		t.Fatalf("expected the store to be opened, got %s", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	if _, err := s.Refresh(context.Background(), communities); err != nil {
+		t.Fatalf("expected the communities to be stored, got %s", err)
+	}
+
+	return s, communities
+}
+
+func sortedCIDs(communities data.NFIPCommunityStatuses) []int {
+	cids := []int{}
+	for _, c := range communities {
+		cids = append(cids, c.CID)
+	}
+	sort.Ints(cids)
+
+	return cids
+}
+
+func TestLoad(t *testing.T) {
+	s, communities := openTestStore(t)
+
+	stored, err := s.Load(context.Background())
+	if err != nil {
+		t.Fatalf("expected the communities to be read, got %s", err)
+	}
+
+	if !reflect.DeepEqual(sortedCIDs(stored), sortedCIDs(communities)) {
+		t.Errorf("expected %v, got %v", sortedCIDs(communities), sortedCIDs(stored))
+	}
+
+	// They're loaded in the same order as the status book
+	for i := range stored {
+		if stored[i].CID != communities[i].CID {
+			t.Fatalf("expected community %d at %d, got %d", communities[i].CID, i, stored[i].CID)
+		}
+	}
+
+	if n, _ := s.Len(); n != len(communities) {
+		t.Errorf("expected %d communities, got %d", len(communities), n)
+	}
+}
+
+func TestGet(t *testing.T) {
+	s, _ := openTestStore(t)
+
+	c, ok, err := s.Get(context.Background(), 480296)
+	if err != nil || !ok || c.BaseName != "HOUSTON" || c.CurrEffMapDate == nil {
+		t.Errorf("expected Houston, got %v %v %v", c, ok, err)
+	}
+
+	if _, ok, err := s.Get(context.Background(), 999999); ok || err != nil {
+		t.Errorf("expected nothing for a CID that isn't there, got %v %v", ok, err)
+	}
+}
+
+func TestByState(t *testing.T) {
+	s, communities := openTestStore(t)
+
+	for _, state := range []string{"TX", "Illinois", "6", "PR"} {
+		found, err := s.ByState(state)
+		if err != nil {
+			t.Fatalf("expected the communities in %s to be found, got %s", state, err)
+		}
+
+		expected := communities.Refine().Filter(data.ByState(state)).Communities()
+		if len(expected) == 0 || !reflect.DeepEqual(sortedCIDs(found), sortedCIDs(expected)) {
+			t.Errorf("expected %v in %s, got %v", sortedCIDs(expected), state, sortedCIDs(found))
+		}
+	}
+
+	// Alabama is 1, which is the start of 12 (Florida) too
+	found, _ := s.ByState("AL")
+	for _, c := range found {
+		if c.State != "AL" {
+			t.Errorf("expected only Alabama, got %s", c.State)
+		}
+	}
+
+	if found, _ := s.ByState("Atlantis"); len(found) != 0 {
+		t.Errorf("expected no communities in a state that doesn't exist, got %d", len(found))
+	}
+}
+
+func TestByNamePrefix(t *testing.T) {
+	s, _ := openTestStore(t)
+
+	tests := []struct {
+		prefix   string
+		expected []int
+	}{
+		{"houston", []int{480296}},
+		{"HOUSTON, CITY", []int{480296}},
+		{"new", []int{225203, 280341, 360497}},
+		{"miami", []int{120651, 125098}},
+		{"zzz", []int{}},
+	}
+
+	for _, tt := range tests {
+		found, err := s.ByNamePrefix(tt.prefix)
+		if err != nil {
+			t.Fatalf("expected names starting with %s to be found, got %s", tt.prefix, err)
+		}
+
+		if cids := sortedCIDs(found); !reflect.DeepEqual(cids, tt.expected) {
+			t.Errorf("expected %v for %s, got %v", tt.expected, tt.prefix, cids)
+		}
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	s, communities := openTestStore(t)
+
+	changes, err := s.Refresh(context.Background(), communities)
+	if err != nil || changes != (store.Changes{}) {
+		t.Errorf("expected nothing to change, got %v %v", changes, err)
+	}
+
+	next := append(data.NFIPCommunityStatuses{}, communities[1:]...)
+	next[0].CommunityName = "RENAMED, CITY OF"
+	next[0].BaseName = "RENAMED"
+	next = append(next, data.NFIPCommunityStatus{CID: 480999, CommunityName: "NEW, TOWN OF", BaseName: "NEW", State: "TX", StateFIPS: 48})
+
+	changes, err = s.Refresh(context.Background(), next)
+	if err != nil {
+		t.Fatalf("expected the communities to be refreshed, got %s", err)
+	}
+
+	if expected := (store.Changes{Added: 1, Updated: 1, Removed: 1}); changes != expected {
+		t.Errorf("expected %v, got %v", expected, changes)
+	}
+
+	// The indexes follow the communities
+	if found, _ := s.ByNamePrefix("renamed"); len(found) != 1 || found[0].CID != next[0].CID {
+		t.Errorf("expected the renamed community to be found by its new name, got %v", sortedCIDs(found))
+	}
+
+	if found, _ := s.ByState("TX"); !reflect.DeepEqual(sortedCIDs(found), []int{480287, 480296, 480999, 481658}) {
+		t.Errorf("expected the new community in Texas, got %v", sortedCIDs(found))
+	}
+
+	if _, ok, _ := s.Get(context.Background(), communities[0].CID); ok {
+		t.Errorf("expected the removed community to be gone")
+	}
+
+	// Moving communities around isn't a change, but they're loaded
+	// where they are now
+	moved := append(data.NFIPCommunityStatuses{next[len(next)-1]}, next[:len(next)-1]...)
+	changes, err = s.Refresh(context.Background(), moved)
+	if err != nil || changes != (store.Changes{}) {
+		t.Errorf("expected nothing to change, got %v %v", changes, err)
+	}
+
+	stored, _ := s.Load(context.Background())
+	if len(stored) != len(moved) || stored[0].CID != 480999 || stored[1].CID != moved[1].CID {
+		t.Errorf("expected the new community first, got %v", stored[:2])
+	}
+}
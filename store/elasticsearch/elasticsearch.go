@@ -0,0 +1,426 @@
+// Package elasticsearch indexes the status book's communities into an
+// Elasticsearch or OpenSearch index, for organizations that already search
+// everything there. It only needs their REST API, so it works with either
+// one. Indexing it again only sends the communities that changed.
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+// DefaultIndex is the index that the communities are kept in if it isn't given.
+const DefaultIndex = "nfip-communities"
+
+// How many communities are sent in each bulk request, and
+// how many are read at a time when looking for changes.
+const batchSize = 1000
+
+var ErrElasticsearch = fmt.Errorf("elasticsearch error")
+
+// The mapping for the communities. CIDs are keywords since they're IDs
+// rather than numbers to do math on, names can be searched as text or
+// matched and sorted as keywords, and the dates are dates. row_hash is a
+// hash of the rest, so that communities that haven't changed can be skipped.
+var mapping = map[string]any{
+	"dynamic": false,
+	"properties": map[string]any{
+		"cid":            keyword,
+		"community_name": textAndKeyword,
+		"flags": map[string]any{
+			"properties": map[string]any{
+				"suspended":             boolean,
+				"withdrawn":             boolean,
+				"minimally_flood_prone": boolean,
+				"non_flood_prone":       boolean,
+			},
+		},
+		"kind":                    keyword,
+		"base_name":               textAndKeyword,
+		"type":                    keyword,
+		"county":                  textAndKeyword,
+		"state":                   keyword,
+		"state_fips":              map[string]any{"type": "short"},
+		"fhbm_identified":         date,
+		"firm_identified":         date,
+		"curr_eff_map_date":       date,
+		"reg_emer_date":           date,
+		"tribal":                  boolean,
+		"crs_entry_date":          date,
+		"curr_eff_date":           date,
+		"cur_class":               map[string]any{"type": "byte"},
+		"percent_disc_sfha":       keyword,
+		"percent_non_sfha":        keyword,
+		"program":                 keyword,
+		"participating_community": boolean,
+		"row_hash":                keyword,
+	},
+}
+
+var (
+	keyword        = map[string]any{"type": "keyword"}
+	boolean        = map[string]any{"type": "boolean"}
+	date           = map[string]any{"type": "date", "format": "yyyy-MM-dd"}
+	textAndKeyword = map[string]any{
+		"type":   "text",
+		"fields": map[string]any{"keyword": map[string]any{"type": "keyword", "ignore_above": 256}},
+	}
+)
+
+// An Option changes how an Indexer is set up.
+type Option func(*Indexer)
+
+func WithIndex(index string) Option {
+	return func(ix *Indexer) {
+		ix.index = index
+	}
+}
+
+// WithAPIKey authenticates with an API key, in the
+// base64 encoded form that Elasticsearch hands out.
+func WithAPIKey(key string) Option {
+	return func(ix *Indexer) {
+		ix.apiKey = key
+	}
+}
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(ix *Indexer) {
+		ix.client = client
+	}
+}
+
+// An Indexer keeps an index of the communities up to date.
+type Indexer struct {
+	client   *http.Client
+	base     *url.URL
+	index    string
+	apiKey   string
+	user     string
+	password string
+	hasUser  bool
+}
+
+// New makes an Indexer for the cluster at the URL. A user and
+// password in the URL are used for basic authentication.
+func New(rawURL string, opts ...Option) (*Indexer, error) {
+	base, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	ix := &Indexer{client: http.DefaultClient, index: DefaultIndex}
+	if base.User != nil {
+		ix.user = base.User.Username()
+		ix.password, _ = base.User.Password()
+		ix.hasUser = true
+		base.User = nil
+	}
+	base.Path = strings.TrimSuffix(base.Path, "/")
+	ix.base = base
+
+	for _, opt := range opts {
+		opt(ix)
+	}
+
+	return ix, nil
+}
+
+// Index is the name of the index that the communities are kept in.
+func (ix *Indexer) Index() string {
+	return ix.index
+}
+
+// CreateIndex creates the index with its mapping if it isn't there yet.
+func (ix *Indexer) CreateIndex(ctx context.Context) error {
+	resp, err := ix.do(ctx, http.MethodHead, "/"+ix.index, "", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]any{"mappings": mapping})
+	if err != nil {
+		return err
+	}
+
+	return ix.send(ctx, http.MethodPut, "/"+ix.index, "application/json", body, nil)
+}
+
+// Refresh makes the index the same as the communities, creating it if it
+// has to be. Only the communities that were added or changed are indexed,
+// and the ones that are gone are deleted.
+func (ix *Indexer) Refresh(ctx context.Context, communities data.NFIPCommunityStatuses) (store.Changes, error) {
+	var changes store.Changes
+
+	if err := ix.CreateIndex(ctx); err != nil {
+		return changes, err
+	}
+
+	hashes, err := ix.hashes(ctx)
+	if err != nil {
+		return changes, err
+	}
+
+	var bulk bytes.Buffer
+	pending := 0
+	flush := func() error {
+		if pending == 0 {
+			return nil
+		}
+
+		err := ix.bulk(ctx, bulk.Bytes())
+		bulk.Reset()
+		pending = 0
+		return err
+	}
+
+	add := func(action, id string, doc []byte) error {
+		writeAction(&bulk, action, ix.index, id)
+		if doc != nil {
+			bulk.Write(doc)
+			bulk.WriteByte('\n')
+		}
+
+		if pending++; pending < batchSize {
+			return nil
+		}
+
+		return flush()
+	}
+
+	docs, order, err := documents(communities)
+	if err != nil {
+		return changes, err
+	}
+
+	for _, id := range order {
+		current, ok := hashes[id]
+		switch {
+		case !ok:
+			changes.Added++
+		case current != docs[id].hash:
+			changes.Updated++
+		default:
+			continue
+		}
+
+		if err := add("index", id, docs[id].body); err != nil {
+			return changes, err
+		}
+	}
+
+	// Sort them so that they're deleted in the same order every time
+	var removed []string
+	for id := range hashes {
+		if _, ok := docs[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	sort.Strings(removed)
+
+	for _, id := range removed {
+		changes.Removed++
+		if err := add("delete", id, nil); err != nil {
+			return changes, err
+		}
+	}
+
+	return changes, flush()
+}
+
+type document struct {
+	body []byte
+	hash string
+}
+
+// documents are what each community is indexed as by its ID, which is
+// its JSON with its hash added to it, along with the IDs in the order
+// they're first found in. When a CID is there more than once, the last
+// one is kept, the same as it would be if they were indexed one by one.
+func documents(communities data.NFIPCommunityStatuses) (map[string]document, []string, error) {
+	docs := make(map[string]document, len(communities))
+	var order []string
+
+	for _, c := range communities {
+		b, err := json.Marshal(c)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		sum := sha256.Sum256(b)
+		hash := hex.EncodeToString(sum[:])
+
+		body := append(b[:len(b)-1:len(b)-1], `,"row_hash":"`...)
+		body = append(body, hash...)
+		body = append(body, `"}`...)
+
+		id := strconv.Itoa(c.CID)
+		if _, ok := docs[id]; !ok {
+			order = append(order, id)
+		}
+		docs[id] = document{body, hash}
+	}
+
+	return docs, order, nil
+}
+
+func writeAction(w *bytes.Buffer, action, index, id string) {
+	b, _ := json.Marshal(map[string]any{action: map[string]string{"_index": index, "_id": id}})
+	w.Write(b)
+	w.WriteByte('\n')
+}
+
+type searchResponse struct {
+	Hits struct {
+		Hits []struct {
+			ID     string `json:"_id"`
+			Source struct {
+				RowHash string `json:"row_hash"`
+			} `json:"_source"`
+			Sort []any `json:"sort"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// hashes are the row hashes of every community in the index by their IDs.
+// They're read a page at a time in order of their CIDs.
+func (ix *Indexer) hashes(ctx context.Context) (map[string]string, error) {
+	hashes := make(map[string]string)
+
+	var after []any
+	for {
+		query := map[string]any{
+			"size":    batchSize,
+			"_source": []string{"row_hash"},
+			"sort":    []any{map[string]string{"cid": "asc"}},
+		}
+		if after != nil {
+			query["search_after"] = after
+		}
+
+		body, err := json.Marshal(query)
+		if err != nil {
+			return nil, err
+		}
+
+		var resp searchResponse
+		if err := ix.send(ctx, http.MethodPost, "/"+ix.index+"/_search", "application/json", body, &resp); err != nil {
+			return nil, err
+		}
+
+		hits := resp.Hits.Hits
+		for _, hit := range hits {
+			hashes[hit.ID] = hit.Source.RowHash
+		}
+
+		if len(hits) < batchSize {
+			return hashes, nil
+		}
+		after = hits[len(hits)-1].Sort
+	}
+}
+
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		ID     string `json:"_id"`
+		Status int    `json:"status"`
+		Error  *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// bulk sends the actions to the bulk API. Bulk requests succeed even if
+// some of the actions in them don't, so the first of those is the error.
+func (ix *Indexer) bulk(ctx context.Context, actions []byte) error {
+	var resp bulkResponse
+	if err := ix.send(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", actions, &resp); err != nil {
+		return err
+	}
+
+	if !resp.Errors {
+		return nil
+	}
+
+	for _, item := range resp.Items {
+		for action, result := range item {
+			// Deleting something that's already gone is fine
+			if result.Error == nil || (action == "delete" && result.Status == http.StatusNotFound) {
+				continue
+			}
+
+			return fmt.Errorf("%w: could not %s %s: %s: %s", ErrElasticsearch, action, result.ID, result.Error.Type, result.Error.Reason)
+		}
+	}
+
+	return nil
+}
+
+// send makes the request and decodes the JSON that comes back into v,
+// if it isn't nil. Anything but a 2xx status is an error.
+func (ix *Indexer) send(ctx context.Context, method, path, contentType string, body []byte, v any) error {
+	resp, err := ix.do(ctx, method, path, contentType, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%w: %s %s returned %s: %s", ErrElasticsearch, method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (ix *Indexer) do(ctx context.Context, method, path, contentType string, body []byte) (*http.Response, error) {
+	u := *ix.base
+	u.Path += path
+
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(contentType) > 0 {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	switch {
+	case len(ix.apiKey) > 0:
+		req.Header.Set("Authorization", "ApiKey "+ix.apiKey)
+	case ix.hasUser:
+		req.SetBasicAuth(ix.user, ix.password)
+	}
+
+	return ix.client.Do(req)
+}
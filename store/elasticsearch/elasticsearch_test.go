@@ -0,0 +1,204 @@
+package elasticsearch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+// fakeCluster is just enough of Elasticsearch's API for indexing:
+// creating an index, bulk requests and searching for the row hashes.
+type fakeCluster struct {
+	mu       sync.Mutex
+	mappings map[string]json.RawMessage
+	docs     map[string]map[string]json.RawMessage
+	bulks    int
+	auth     string
+}
+
+func newFakeCluster(t *testing.T) (*fakeCluster, *httptest.Server) {
+	fc := &fakeCluster{mappings: make(map[string]json.RawMessage), docs: make(map[string]map[string]json.RawMessage)}
+	ts := httptest.NewServer(fc)
+	t.Cleanup(ts.Close)
+	return fc, ts
+}
+
+func (fc *fakeCluster) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.auth = r.Header.Get("Authorization")
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch {
+	case r.Method == http.MethodHead && len(path) == 1:
+		if _, ok := fc.mappings[path[0]]; !ok {
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	case r.Method == http.MethodPut && len(path) == 1:
+		var body struct {
+			Mappings json.RawMessage `json:"mappings"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		fc.mappings[path[0]] = body.Mappings
+		fc.docs[path[0]] = make(map[string]json.RawMessage)
+		rw.Write([]byte(`{"acknowledged":true}`))
+	case r.Method == http.MethodPost && path[0] == "_bulk":
+		fc.bulks++
+		scanner := bufio.NewScanner(r.Body)
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			var action map[string]struct {
+				Index string `json:"_index"`
+				ID    string `json:"_id"`
+			}
+			json.Unmarshal(scanner.Bytes(), &action)
+
+			if a, ok := action["delete"]; ok {
+				delete(fc.docs[a.Index], a.ID)
+				continue
+			}
+
+			scanner.Scan()
+			a := action["index"]
+			fc.docs[a.Index][a.ID] = append(json.RawMessage(nil), scanner.Bytes()...)
+		}
+		rw.Write([]byte(`{"errors":false,"items":[]}`))
+	case r.Method == http.MethodPost && len(path) == 2 && path[1] == "_search":
+		var query struct {
+			Size        int      `json:"size"`
+			SearchAfter []string `json:"search_after"`
+		}
+		json.NewDecoder(r.Body).Decode(&query)
+
+		var ids []string
+		for id := range fc.docs[path[0]] {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		if len(query.SearchAfter) > 0 {
+			ids = ids[sort.SearchStrings(ids, query.SearchAfter[0]+"\x00"):]
+		}
+		if len(ids) > query.Size {
+			ids = ids[:query.Size]
+		}
+
+		type hit struct {
+			ID     string          `json:"_id"`
+			Source json.RawMessage `json:"_source"`
+			Sort   []string        `json:"sort"`
+		}
+		var resp struct {
+			Hits struct {
+				Hits []hit `json:"hits"`
+			} `json:"hits"`
+		}
+		resp.Hits.Hits = []hit{}
+		for _, id := range ids {
+			resp.Hits.Hits = append(resp.Hits.Hits, hit{id, fc.docs[path[0]][id], []string{id}})
+		}
+		json.NewEncoder(rw).Encode(resp)
+	default:
+		http.Error(rw, `{"error":"unknown"}`, http.StatusBadRequest)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	ctx := context.Background()
+	fc, ts := newFakeCluster(t)
+
+	ix, err := New(ts.URL+"/", WithIndex("communities"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	changes, err := ix.Refresh(ctx, communities)
+	if err != nil || changes != (store.Changes{Added: len(communities)}) {
+		t.Fatalf("expected every community to be added, got %v %v", changes, err)
+	}
+
+	if len(fc.docs["communities"]) != len(communities) {
+		t.Fatalf("expected %d documents, got %d", len(communities), len(fc.docs["communities"]))
+	}
+
+	var mapped struct {
+		Properties map[string]struct {
+			Type   string `json:"type"`
+			Format string `json:"format"`
+			Fields map[string]struct {
+				Type string `json:"type"`
+			} `json:"fields"`
+		} `json:"properties"`
+	}
+	json.Unmarshal(fc.mappings["communities"], &mapped)
+	cid, name, date := mapped.Properties["cid"], mapped.Properties["community_name"], mapped.Properties["curr_eff_map_date"]
+	if cid.Type != "keyword" || name.Type != "text" || name.Fields["keyword"].Type != "keyword" || date.Type != "date" || date.Format != "yyyy-MM-dd" {
+		t.Errorf("expected the mapping to have keyword CIDs, text names and dates, got %v %v %v", cid, name, date)
+	}
+
+	var houston data.NFIPCommunityStatus
+	if err := json.Unmarshal(fc.docs["communities"]["480296"], &houston); err != nil || houston.BaseName != "HOUSTON" {
+		t.Errorf("expected Houston to be indexed, got %v %v", houston, err)
+	}
+	if !strings.Contains(string(fc.docs["communities"]["480296"]), `"row_hash":"`) {
+		t.Errorf("expected the row hash to be indexed, got %s", fc.docs["communities"]["480296"])
+	}
+
+	// Nothing's sent when nothing has changed
+	bulks := fc.bulks
+	if changes, err := ix.Refresh(ctx, communities); err != nil || changes != (store.Changes{}) || fc.bulks != bulks {
+		t.Errorf("expected nothing to change, got %v %v with %d bulk requests", changes, err, fc.bulks-bulks)
+	}
+
+	next := append(data.NFIPCommunityStatuses{}, communities[1:]...)
+	next[0].CommunityName = "RENAMED"
+	changes, err = ix.Refresh(ctx, next)
+	if err != nil || changes != (store.Changes{Updated: 1, Removed: 1}) {
+		t.Errorf("expected one community to be updated and one removed, got %v %v", changes, err)
+	}
+
+	if _, ok := fc.docs["communities"][strconv.Itoa(communities[0].CID)]; ok {
+		t.Errorf("expected the removed community to be deleted")
+	}
+}
+
+func TestRefreshAuthentication(t *testing.T) {
+	fc, ts := newFakeCluster(t)
+
+	ix, _ := New(strings.Replace(ts.URL, "http://", "http://elastic:secret@", 1))
+	ix.Refresh(context.Background(), nil)
+	if fc.auth != "Basic ZWxhc3RpYzpzZWNyZXQ=" {
+		t.Errorf("expected basic authentication, got %s", fc.auth)
+	}
+
+	ix, _ = New(ts.URL, WithAPIKey("a2V5"))
+	ix.Refresh(context.Background(), nil)
+	if fc.auth != "ApiKey a2V5" {
+		t.Errorf("expected an API key, got %s", fc.auth)
+	}
+}
+
+func TestBulkErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(`{"errors":true,"items":[{"delete":{"_id":"1","status":404}},{"index":{"_id":"480296","status":400,"error":{"type":"mapper_parsing_exception","reason":"failed to parse"}}}]}`))
+	}))
+	defer ts.Close()
+
+	ix, _ := New(ts.URL)
+	err := ix.bulk(context.Background(), []byte("{}\n"))
+	if err == nil || !strings.Contains(err.Error(), "480296") || !strings.Contains(err.Error(), "mapper_parsing_exception") {
+		t.Errorf("expected the failed action to be the error, got %v", err)
+	}
+}
@@ -0,0 +1,280 @@
+// Package history keeps every version of the status book that's been
+// refreshed, so that a community's status can be looked up as it stood on
+// any date since, like for an underwriting audit. Each refresh appends the
+// communities that changed to a file of JSON lines, and nothing in the
+// file is ever rewritten.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+var ErrInvalidHistory = fmt.Errorf("invalid history")
+var ErrOutOfOrder = fmt.Errorf("revision is older than the history")
+
+// A Revision is a community as it was from when it took effect until
+// its next revision. Community is nil when it was removed.
+type Revision struct {
+	Effective time.Time                 `json:"effective"`
+	CID       int                       `json:"cid"`
+	Community *data.NFIPCommunityStatus `json:"community"`
+}
+
+// A History is every revision of every community in the file, and the
+// communities as they are now. The communities as they are now can be
+// looked up and searched like any other store.
+type History struct {
+	mu        sync.RWMutex
+	f         *os.File
+	revisions map[int][]Revision
+	latest    map[int][]byte
+	effective time.Time
+	current   *store.Memory
+}
+
+var _ store.Store = (*History)(nil)
+
+// Open reads the history in the file, creating it if it isn't there yet.
+// Revisions that are recorded are appended to it.
+func Open(filename string) (*History, error) {
+	f, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &History{f: f, revisions: make(map[int][]Revision), latest: make(map[int][]byte)}
+	if err := h.read(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%w: %s: %s", ErrInvalidHistory, filename, err)
+	}
+
+	h.current = store.NewMemory(h.asOf(h.effective))
+	return h, nil
+}
+
+func (h *History) read(r io.Reader) error {
+	d := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var rev Revision
+		err := d.Decode(&rev)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// A revision that was written after a later one, like by a clock
+		// that was set back, took effect with it, so that the history can
+		// always be read
+		if rev.Effective.Before(h.effective) {
+			rev.Effective = h.effective
+		}
+
+		if err := h.add(rev); err != nil {
+			return err
+		}
+	}
+}
+
+// add adds the revision to the ones in memory. They have to be added in
+// the order they took effect.
+func (h *History) add(rev Revision) error {
+	if rev.Effective.Before(h.effective) {
+		return fmt.Errorf("%w: %d took effect %s, before %s", ErrOutOfOrder, rev.CID, rev.Effective.Format(time.RFC3339), h.effective.Format(time.RFC3339))
+	}
+
+	h.revisions[rev.CID] = append(h.revisions[rev.CID], rev)
+	h.effective = rev.Effective
+
+	if rev.Community == nil {
+		delete(h.latest, rev.CID)
+		return nil
+	}
+
+	b, err := json.Marshal(rev.Community)
+	if err != nil {
+		return err
+	}
+	h.latest[rev.CID] = b
+
+	return nil
+}
+
+func (h *History) Close() error {
+	return h.f.Close()
+}
+
+// Record adds the communities to the history as they are from the time
+// they took effect. Only the communities that were added, changed or
+// removed since the last revision are written. Revisions can't take
+// effect before the ones that are already in the history.
+func (h *History) Record(communities data.NFIPCommunityStatuses, effective time.Time) (store.Changes, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var changes store.Changes
+	if effective.Before(h.effective) {
+		return changes, fmt.Errorf("%w: %s is before %s", ErrOutOfOrder, effective.Format(time.RFC3339), h.effective.Format(time.RFC3339))
+	}
+
+	// When a CID is there more than once, the last one is kept
+	next := make(map[int]*data.NFIPCommunityStatus, len(communities))
+	var cids []int
+	for i := range communities {
+		c := &communities[i]
+		if _, ok := next[c.CID]; !ok {
+			cids = append(cids, c.CID)
+		}
+		next[c.CID] = c
+	}
+
+	var revisions []Revision
+	for _, cid := range cids {
+		b, err := json.Marshal(next[cid])
+		if err != nil {
+			return changes, err
+		}
+
+		current, ok := h.latest[cid]
+		switch {
+		case !ok:
+			changes.Added++
+		case !bytes.Equal(current, b):
+			changes.Updated++
+		default:
+			continue
+		}
+
+		c := *next[cid]
+		revisions = append(revisions, Revision{effective, cid, &c})
+	}
+
+	var removed []int
+	for cid := range h.latest {
+		if _, ok := next[cid]; !ok {
+			removed = append(removed, cid)
+		}
+	}
+	sort.Ints(removed)
+
+	for _, cid := range removed {
+		changes.Removed++
+		revisions = append(revisions, Revision{Effective: effective, CID: cid})
+	}
+
+	// They're all written at once, so that a refresh isn't half recorded
+	var buf bytes.Buffer
+	e := json.NewEncoder(&buf)
+	for _, rev := range revisions {
+		if err := e.Encode(rev); err != nil {
+			return changes, err
+		}
+	}
+
+	if _, err := h.f.Write(buf.Bytes()); err != nil {
+		return changes, err
+	}
+
+	if err := h.f.Sync(); err != nil {
+		return changes, err
+	}
+
+	for _, rev := range revisions {
+		if err := h.add(rev); err != nil {
+			return changes, err
+		}
+	}
+	h.effective = effective
+
+	if _, err := h.current.Refresh(context.Background(), communities); err != nil {
+		return changes, err
+	}
+
+	return changes, nil
+}
+
+// AsOf is every community as it stood at the time, in order of their CIDs.
+func (h *History) AsOf(t time.Time) data.NFIPCommunityStatuses {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.asOf(t)
+}
+
+func (h *History) asOf(t time.Time) data.NFIPCommunityStatuses {
+	var cids []int
+	for cid := range h.revisions {
+		cids = append(cids, cid)
+	}
+	sort.Ints(cids)
+
+	var communities data.NFIPCommunityStatuses
+	for _, cid := range cids {
+		if c, ok := h.communityAsOf(cid, t); ok {
+			communities = append(communities, c)
+		}
+	}
+
+	return communities
+}
+
+// CommunityAsOf is the community with the CID as it stood at the time. It
+// isn't found if it wasn't in the status book then.
+func (h *History) CommunityAsOf(cid int, t time.Time) (data.NFIPCommunityStatus, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.communityAsOf(cid, t)
+}
+
+func (h *History) communityAsOf(cid int, t time.Time) (data.NFIPCommunityStatus, bool) {
+	revisions := h.revisions[cid]
+
+	// The first revision that took effect after the time is the one after it
+	i := sort.Search(len(revisions), func(i int) bool {
+		return revisions[i].Effective.After(t)
+	})
+	if i == 0 || revisions[i-1].Community == nil {
+		return data.NFIPCommunityStatus{}, false
+	}
+
+	return *revisions[i-1].Community, true
+}
+
+// Revisions are every revision of the community with the CID, oldest first.
+func (h *History) Revisions(cid int) []Revision {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return append([]Revision(nil), h.revisions[cid]...)
+}
+
+func (h *History) Load(ctx context.Context) (data.NFIPCommunityStatuses, error) {
+	return h.current.Load(ctx)
+}
+
+func (h *History) Get(ctx context.Context, cid int) (data.NFIPCommunityStatus, bool, error) {
+	return h.current.Get(ctx, cid)
+}
+
+func (h *History) Search(ctx context.Context, term string) (data.SearchResults, error) {
+	return h.current.Search(ctx, term)
+}
+
+// Refresh records the communities as taking effect now. See Record.
+func (h *History) Refresh(ctx context.Context, communities data.NFIPCommunityStatuses) (store.Changes, error) {
+	return h.Record(communities, time.Now())
+}
@@ -0,0 +1,150 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+var (
+	january  = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	february = time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)
+	march    = time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// recordTestHistory records the demo status book in January, with Houston
+// suspended in February and the first community removed in March.
+func recordTestHistory(t *testing.T, filename string) (*History, data.NFIPCommunityStatuses) {
+	t.Helper()
+
+	h, err := Open(filename)
+	if err != nil {
+		t.Fatalf("expected the history to be opened, got %s", err)
+	}
+
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	if changes, err := h.Record(communities, january); err != nil || changes != (store.Changes{Added: len(communities)}) {
+		t.Fatalf("expected every community to be added, got %v %v", changes, err)
+	}
+
+	suspended := append(data.NFIPCommunityStatuses{}, communities...)
+	for i := range suspended {
+		if suspended[i].CID == 480296 {
+			suspended[i].Flags.Suspended = true
+		}
+	}
+	if changes, err := h.Record(suspended, february); err != nil || changes != (store.Changes{Updated: 1}) {
+		t.Fatalf("expected Houston to be updated, got %v %v", changes, err)
+	}
+
+	if changes, err := h.Record(suspended[1:], march); err != nil || changes != (store.Changes{Removed: 1}) {
+		t.Fatalf("expected a community to be removed, got %v %v", changes, err)
+	}
+
+	return h, communities
+}
+
+func TestAsOf(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "history.jsonl")
+	h, communities := recordTestHistory(t, filename)
+	defer h.Close()
+
+	tests := []struct {
+		t         time.Time
+		count     int
+		suspended bool
+	}{
+		{january.Add(-time.Hour), 0, false},
+		{january, len(communities), false},
+		{february.Add(-time.Nanosecond), len(communities), false},
+		{february.Add(24 * time.Hour), len(communities), true},
+		{march, len(communities) - 1, true},
+	}
+
+	for _, test := range tests {
+		if n := len(h.AsOf(test.t)); n != test.count {
+			t.Errorf("expected %d communities as of %s, got %d", test.count, test.t, n)
+		}
+
+		c, ok := h.CommunityAsOf(480296, test.t)
+		if ok != (test.count > 0) || c.Flags.Suspended != test.suspended {
+			t.Errorf("expected Houston to be suspended %v as of %s, got %v %v", test.suspended, test.t, ok, c.Flags)
+		}
+	}
+
+	removed := communities[0].CID
+	if _, ok := h.CommunityAsOf(removed, february); !ok {
+		t.Errorf("expected %d to be found before it was removed", removed)
+	}
+	if _, ok := h.CommunityAsOf(removed, march); ok {
+		t.Errorf("expected %d to be gone after it was removed", removed)
+	}
+
+	if revisions := h.Revisions(480296); len(revisions) != 2 || !revisions[1].Effective.Equal(february) {
+		t.Errorf("expected Houston to have two revisions, got %v", revisions)
+	}
+
+	if c, ok, _ := h.Get(context.Background(), 480296); !ok || !c.Flags.Suspended {
+		t.Errorf("expected Houston to be suspended now, got %v %v", c.Flags, ok)
+	}
+}
+
+func TestReopen(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "history.jsonl")
+	h, communities := recordTestHistory(t, filename)
+	h.Close()
+
+	h, err := Open(filename)
+	if err != nil {
+		t.Fatalf("expected the history to be opened again, got %s", err)
+	}
+	defer h.Close()
+
+	if c, ok := h.CommunityAsOf(480296, january); !ok || c.Flags.Suspended || c.CurrEffMapDate == nil {
+		t.Errorf("expected Houston as it was in January, got %v %v", c, ok)
+	}
+
+	if current, _ := h.Load(context.Background()); len(current) != len(communities)-1 {
+		t.Errorf("expected %d communities now, got %d", len(communities)-1, len(current))
+	}
+
+	// The same communities again don't add anything to the file
+	before, _ := os.Stat(filename)
+	current, _ := h.Load(context.Background())
+	if changes, err := h.Record(current, march.Add(time.Hour)); err != nil || changes != (store.Changes{}) {
+		t.Errorf("expected nothing to change, got %v %v", changes, err)
+	}
+	if after, _ := os.Stat(filename); after.Size() != before.Size() {
+		t.Errorf("expected the file to stay %d bytes, got %d", before.Size(), after.Size())
+	}
+
+	if _, err := h.Record(current, february); !errors.Is(err, ErrOutOfOrder) {
+		t.Errorf("expected a revision before the history to be rejected, got %v", err)
+	}
+}
+
+func TestOpenInvalid(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "history.jsonl")
+	os.WriteFile(filename, []byte(`{"effective":"2024-02-01T00:00:00Z","cid":1,"community":null}`+"\n"+`{"effective":"2024-01-01T00:00:00Z","cid":2,"community":null}`+"\n"), 0o600)
+
+	// Revisions out of order are read as taking effect with the one before
+	h, err := Open(filename)
+	if err != nil {
+		t.Fatalf("expected revisions out of order to be read, got %s", err)
+	}
+	if revisions := h.Revisions(2); len(revisions) != 1 || !revisions[0].Effective.Equal(february) {
+		t.Errorf("expected the revision to take effect in February, got %v", revisions)
+	}
+	h.Close()
+
+	os.WriteFile(filename, []byte(`{"effective":"2024-02-01T00:00:00Z","cid":1,"commu`), 0o600)
+	if _, err := Open(filename); !errors.Is(err, ErrInvalidHistory) {
+		t.Errorf("expected a revision that was cut off to be invalid, got %v", err)
+	}
+}
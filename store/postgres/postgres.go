@@ -0,0 +1,442 @@
+// Package postgres keeps a table of the status book's communities in a
+// PostgreSQL database up to date. The whole status book is copied in with
+// COPY, and syncing it again only touches the communities that changed, so
+// it can be run after every refresh to keep the table authoritative.
+package postgres
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"nfip-community-book/data"
+	"nfip-community-book/states"
+	"nfip-community-book/store"
+)
+
+// DefaultTable is the table that the communities are kept in if it isn't given.
+const DefaultTable = "nfip_communities"
+
+// The table's columns, in the order they're copied in. The flags are a
+// column each so that they can be filtered on. position is where the
+// community is in the status book, so that the communities are loaded in
+// the same order as they're parsed in, and row_hash is a hash of the rest,
+// so that communities that haven't changed can be skipped.
+var columns = []struct {
+	name string
+	typ  string
+}{
+	{"cid", "INTEGER PRIMARY KEY"},
+	{"community_name", "TEXT NOT NULL"},
+	{"suspended", "BOOLEAN NOT NULL"},
+	{"withdrawn", "BOOLEAN NOT NULL"},
+	{"minimally_flood_prone", "BOOLEAN NOT NULL"},
+	{"non_flood_prone", "BOOLEAN NOT NULL"},
+	{"kind", "TEXT NOT NULL"},
+	{"base_name", "TEXT NOT NULL"},
+	{"type", "TEXT NOT NULL"},
+	{"county", "TEXT NOT NULL"},
+	{"state", "TEXT NOT NULL"},
+	{"state_fips", "INTEGER NOT NULL"},
+	{"fhbm_identified", "DATE"},
+	{"firm_identified", "DATE"},
+	{"curr_eff_map_date", "DATE"},
+	{"reg_emer_date", "DATE"},
+	{"tribal", "BOOLEAN NOT NULL"},
+	{"crs_entry_date", "DATE"},
+	{"curr_eff_date", "DATE"},
+	{"cur_class", "INTEGER"},
+	{"percent_disc_sfha", "TEXT NOT NULL"},
+	{"percent_non_sfha", "TEXT NOT NULL"},
+	{"program", "TEXT NOT NULL"},
+	{"participating_community", "BOOLEAN NOT NULL"},
+	{"position", "INTEGER NOT NULL DEFAULT 0"},
+	{"row_hash", "TEXT NOT NULL"},
+}
+
+// The columns that a community is scanned from, which is all but
+// position and row_hash.
+var communityColumns = columns[:len(columns)-2]
+
+// An Option changes how a Store is set up.
+type Option func(*Store)
+
+// WithTable is the table that the communities are kept in. It can have
+// a schema in front of it, like "warehouse.nfip_communities".
+func WithTable(table string) Option {
+	return func(s *Store) {
+		s.table = pgx.Identifier(strings.Split(table, "."))
+	}
+}
+
+// A Store is the table of communities in a PostgreSQL database.
+type Store struct {
+	pool  *pgxpool.Pool
+	table pgx.Identifier
+}
+
+var _ store.Store = (*Store)(nil)
+var _ store.Dated = (*Store)(nil)
+
+// When the table was last refreshed is kept in its meta table.
+const refreshedKey = "refreshed_at"
+
+// Open connects to the database and creates the table
+// and its indexes if they aren't there yet.
+func Open(ctx context.Context, connString string, opts ...Option) (*Store, error) {
+	pool, err := pgxpool.New(ctx, connString)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{pool: pool, table: pgx.Identifier{DefaultTable}}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if _, err := pool.Exec(ctx, s.createTable()); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("could not create the communities table: %w", err)
+	}
+
+	return s, nil
+}
+
+func (s *Store) Close() {
+	s.pool.Close()
+}
+
+// Pool is the connection pool, for running SQL that the Store doesn't.
+func (s *Store) Pool() *pgxpool.Pool {
+	return s.pool
+}
+
+// createTable is the DDL for the table and the indexes on
+// what communities are usually looked up by.
+func (s *Store) createTable() string {
+	definitions := make([]string, len(columns))
+	for i, c := range columns {
+		definitions[i] = pgx.Identifier{c.name}.Sanitize() + " " + c.typ
+	}
+
+	table := s.table.Sanitize()
+	name := s.table[len(s.table)-1]
+
+	ddl := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n\t%s\n);\n", table, strings.Join(definitions, ",\n\t"))
+
+	// Tables that were made before there was a position don't have one
+	ddl += fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS \"position\" INTEGER NOT NULL DEFAULT 0;\n", table)
+
+	for _, column := range []string{"state_fips", "county", "community_name", "position"} {
+		index := pgx.Identifier{name + "_" + column}.Sanitize()
+		ddl += fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s);\n", index, table, pgx.Identifier{column}.Sanitize())
+	}
+
+	ddl += fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n\t\"key\" TEXT PRIMARY KEY,\n\t\"value\" TIMESTAMPTZ NOT NULL\n);\n", s.metaTable().Sanitize())
+
+	return ddl
+}
+
+// metaTable is the table that's kept next to the communities' table for
+// what's known about it, like when it was last refreshed.
+func (s *Store) metaTable() pgx.Identifier {
+	meta := append(pgx.Identifier(nil), s.table...)
+	meta[len(meta)-1] += "_meta"
+	return meta
+}
+
+// Refresh makes the table the same as the communities. They're copied into
+// a temporary table first, and then only the ones with a different hash are
+// written to the table, so refreshing with the same status book again
+// doesn't change anything. Communities that aren't in it anymore are
+// deleted. It's all one transaction, so nobody sees the table half synced.
+func (s *Store) Refresh(ctx context.Context, communities data.NFIPCommunityStatuses) (store.Changes, error) {
+	var changes store.Changes
+
+	rows, err := copyRows(communities)
+	if err != nil {
+		return changes, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return changes, err
+	}
+	defer tx.Rollback(ctx)
+
+	table := s.table.Sanitize()
+	staging := pgx.Identifier{"nfip_staging"}
+
+	_, err = tx.Exec(ctx, fmt.Sprintf("CREATE TEMPORARY TABLE %s (LIKE %s) ON COMMIT DROP", staging.Sanitize(), table))
+	if err != nil {
+		return changes, err
+	}
+
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+
+	if _, err := tx.CopyFrom(ctx, staging, names, pgx.CopyFromRows(rows)); err != nil {
+		return changes, fmt.Errorf("could not copy the communities: %w", err)
+	}
+
+	// xmax is 0 for rows that were inserted rather than updated
+	upserted, err := tx.Query(ctx, s.upsert(staging, names))
+	if err != nil {
+		return changes, err
+	}
+	for upserted.Next() {
+		var inserted bool
+		if err := upserted.Scan(&inserted); err != nil {
+			upserted.Close()
+			return changes, err
+		}
+
+		if inserted {
+			changes.Added++
+		} else {
+			changes.Updated++
+		}
+	}
+	upserted.Close()
+	if err := upserted.Err(); err != nil {
+		return changes, err
+	}
+
+	// Communities that only moved in the status book aren't changes,
+	// but they're still put where they are now
+	_, err = tx.Exec(ctx, fmt.Sprintf(`UPDATE %[1]s AS t SET "position" = s."position" FROM %[2]s AS s
+WHERE t.cid = s.cid AND t."position" <> s."position"`, table, staging.Sanitize()))
+	if err != nil {
+		return changes, err
+	}
+
+	deleted, err := tx.Exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE cid NOT IN (SELECT cid FROM %s)", table, staging.Sanitize()))
+	if err != nil {
+		return changes, err
+	}
+	changes.Removed = int(deleted.RowsAffected())
+
+	_, err = tx.Exec(ctx, fmt.Sprintf(`INSERT INTO %s ("key", "value") VALUES ($1, now())
+ON CONFLICT ("key") DO UPDATE SET "value" = excluded."value"`, s.metaTable().Sanitize()), refreshedKey)
+	if err != nil {
+		return changes, err
+	}
+
+	return changes, tx.Commit(ctx)
+}
+
+// Refreshed is when the table was last refreshed.
+func (s *Store) Refreshed(ctx context.Context) (time.Time, error) {
+	var refreshed time.Time
+	err := s.pool.QueryRow(ctx, fmt.Sprintf(`SELECT "value" FROM %s WHERE "key" = $1`, s.metaTable().Sanitize()), refreshedKey).Scan(&refreshed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, nil
+	}
+
+	return refreshed, err
+}
+
+// upsert copies the communities from the staging table that are new
+// or have changed since they were last synced.
+func (s *Store) upsert(staging pgx.Identifier, names []string) string {
+	var updates []string
+	for _, name := range names[1:] {
+		column := pgx.Identifier{name}.Sanitize()
+		updates = append(updates, column+" = excluded."+column)
+	}
+
+	return fmt.Sprintf(`INSERT INTO %[1]s AS t SELECT * FROM %[2]s
+ON CONFLICT (cid) DO UPDATE SET %[3]s
+WHERE t.row_hash <> excluded.row_hash
+RETURNING (xmax = 0)`, s.table.Sanitize(), staging.Sanitize(), strings.Join(updates, ", "))
+}
+
+// copyRows are the communities as rows of the table. A community that's
+// in the status book more than once is only copied once, as the last of
+// them, since a row can't be upserted twice in the same statement.
+func copyRows(communities data.NFIPCommunityStatuses) ([][]interface{}, error) {
+	last := make(map[int]int, len(communities))
+	for i, c := range communities {
+		last[c.CID] = i
+	}
+
+	rows := make([][]interface{}, 0, len(last))
+	for i, c := range communities {
+		if last[c.CID] != i {
+			continue
+		}
+
+		hash, err := rowHash(c)
+		if err != nil {
+			return nil, err
+		}
+
+		var class interface{}
+		if c.CurClass != nil {
+			class = *c.CurClass
+		}
+
+		rows = append(rows, []interface{}{
+			c.CID, c.CommunityName,
+			c.Flags.Suspended, c.Flags.Withdrawn, c.Flags.MinimallyFloodProne, c.Flags.NonFloodProne,
+			string(c.Kind), c.BaseName, string(c.Type), c.County, c.State, c.StateFIPS,
+			c.FHBMIdentified, c.FIRMIdentified, c.CurrEffMapDate, c.RegEmerDate,
+			c.Tribal, c.CRSEntryDate, c.CurrEffDate, class,
+			c.PercentDiscSFHA, c.PercentNonSFHA, c.Program.String(), c.ParticipatingCommunity,
+			i, hash,
+		})
+	}
+
+	return rows, nil
+}
+
+// rowHash is a hash of the community's JSON, which has all of its fields.
+func rowHash(c data.NFIPCommunityStatus) (string, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Get finds the community with the CID.
+func (s *Store) Get(ctx context.Context, cid int) (data.NFIPCommunityStatus, bool, error) {
+	communities, err := s.query(ctx, "WHERE cid = $1", cid)
+	if err != nil || len(communities) == 0 {
+		return data.NFIPCommunityStatus{}, false, err
+	}
+
+	return communities[0], true, nil
+}
+
+// Len is how many communities are in the table.
+func (s *Store) Len(ctx context.Context) (int, error) {
+	var n int
+	err := s.pool.QueryRow(ctx, "SELECT COUNT(*) FROM "+s.table.Sanitize()).Scan(&n)
+	return n, err
+}
+
+// Load is all of the communities in the table, in the same order as
+// they're in the status book.
+func (s *Store) Load(ctx context.Context) (data.NFIPCommunityStatuses, error) {
+	return s.query(ctx, `ORDER BY "position"`)
+}
+
+// Search finds the communities with every word of the term in their name,
+// county, state or CID, and then ranks them the same as data's Search does.
+// Since the database only finds communities with the words themselves,
+// synonyms of them aren't found unless the community has the word too.
+// Queries, like state:TX, aren't words, so they're run over every community.
+func (s *Store) Search(ctx context.Context, term string) (data.SearchResults, error) {
+	if data.IsQuery(term) {
+		communities, err := s.Load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return communities.Search(term), nil
+	}
+
+	var where []string
+	var args []interface{}
+
+	for _, word := range strings.Fields(strings.ReplaceAll(term, "\"", " ")) {
+		// Words can be states, like "il" in "cook county il"
+		fips := 0
+		if state, ok := states.Lookup(word); ok {
+			fips = state.FIPS
+		}
+
+		n := len(args)
+		where = append(where, fmt.Sprintf("(community_name ILIKE $%d OR county ILIKE $%d OR state_fips = $%d OR lpad(cid::text, 6, '0') = $%d)", n+1, n+1, n+2, n+3))
+		args = append(args, "%"+escapeLike(word)+"%", fips, word)
+	}
+
+	if len(where) == 0 {
+		return data.SearchResults{}, nil
+	}
+
+	candidates, err := s.query(ctx, "WHERE "+strings.Join(where, " AND ")+` ORDER BY "position"`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return candidates.Search(term), nil
+}
+
+// escapeLike escapes the wildcards in s so that LIKE matches them as they are.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func (s *Store) query(ctx context.Context, clauses string, args ...interface{}) (data.NFIPCommunityStatuses, error) {
+	names := make([]string, len(communityColumns))
+	for i, c := range communityColumns {
+		names[i] = pgx.Identifier{c.name}.Sanitize()
+	}
+
+	q := fmt.Sprintf("SELECT %s FROM %s %s", strings.Join(names, ", "), s.table.Sanitize(), clauses)
+	rows, err := s.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	communities := data.NFIPCommunityStatuses{}
+	for rows.Next() {
+		c, err := scanCommunity(rows)
+		if err != nil {
+			return nil, err
+		}
+		communities = append(communities, c)
+	}
+
+	return communities, rows.Err()
+}
+
+func scanCommunity(row pgx.Row) (data.NFIPCommunityStatus, error) {
+	var c data.NFIPCommunityStatus
+	var kind, typ, program string
+	var dates [6]*time.Time
+
+	err := row.Scan(
+		&c.CID, &c.CommunityName,
+		&c.Flags.Suspended, &c.Flags.Withdrawn, &c.Flags.MinimallyFloodProne, &c.Flags.NonFloodProne,
+		&kind, &c.BaseName, &typ, &c.County, &c.State, &c.StateFIPS,
+		&dates[0], &dates[1], &dates[2], &dates[3],
+		&c.Tribal, &dates[4], &dates[5], &c.CurClass,
+		&c.PercentDiscSFHA, &c.PercentNonSFHA, &program, &c.ParticipatingCommunity,
+	)
+	if err != nil {
+		return c, err
+	}
+
+	c.Kind, c.Type = data.NameKind(kind), data.CommunityType(typ)
+
+	// Programs that aren't known are written as "Unknown", which parses back to it
+	c.Program, _ = data.ParseProgramKind(program)
+
+	// Dates come back in UTC, but they're in the local time
+	// zone when they're parsed from the status book
+	for i, t := range dates {
+		if t != nil {
+			local := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+			dates[i] = &local
+		}
+	}
+	c.FHBMIdentified, c.FIRMIdentified, c.CurrEffMapDate, c.RegEmerDate = dates[0], dates[1], dates[2], dates[3]
+	c.CRSEntryDate, c.CurrEffDate = dates[4], dates[5]
+
+	return c, nil
+}
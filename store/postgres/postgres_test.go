@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+// Tests that need a database are skipped unless this is set to a connection
+// string for one. They make and drop their own table.
+const testDatabaseEnv = "NFIP_TEST_POSTGRES"
+
+func TestCreateTable(t *testing.T) {
+	s := &Store{table: pgx.Identifier{DefaultTable}}
+	WithTable("warehouse.nfip")(s)
+
+	ddl := s.createTable()
+	if !strings.HasPrefix(ddl, "CREATE TABLE IF NOT EXISTS \"warehouse\".\"nfip\" (\n\t\"cid\" INTEGER PRIMARY KEY,") {
+		t.Errorf("expected the table to be created in the warehouse schema, got %s", ddl)
+	}
+
+	if !strings.Contains(ddl, "CREATE INDEX IF NOT EXISTS \"nfip_state_fips\" ON \"warehouse\".\"nfip\" (\"state_fips\");") {
+		t.Errorf("expected an index on the state, got %s", ddl)
+	}
+
+	if !strings.Contains(ddl, "ALTER TABLE \"warehouse\".\"nfip\" ADD COLUMN IF NOT EXISTS \"position\"") {
+		t.Errorf("expected the position to be added to tables without it, got %s", ddl)
+	}
+
+	if !strings.Contains(ddl, "CREATE TABLE IF NOT EXISTS \"warehouse\".\"nfip_meta\" (") || s.table[1] != "nfip" {
+		t.Errorf("expected a meta table next to the table, got %s", ddl)
+	}
+}
+
+func TestCopyRows(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	renamed := communities[0]
+	renamed.CommunityName = "RENAMED"
+	communities = append(communities, renamed)
+
+	rows, err := copyRows(communities)
+	if err != nil {
+		t.Fatalf("expected the communities to be rows, got %s", err)
+	}
+
+	if len(rows) != len(communities)-1 {
+		t.Fatalf("expected the duplicate to only be copied once, got %d rows", len(rows))
+	}
+
+	for _, row := range rows {
+		if len(row) != len(columns) {
+			t.Fatalf("expected %d columns, got %d", len(columns), len(row))
+		}
+	}
+
+	// The last of the duplicates is the one that's kept, where it is
+	last := rows[len(rows)-1]
+	if last[0] != renamed.CID || last[1] != "RENAMED" || last[len(columns)-2] != len(communities)-1 {
+		t.Errorf("expected the renamed community last, got %v", last)
+	}
+
+	// The first community was the duplicate, so the second is first
+	if position := rows[0][len(columns)-2]; position != 1 {
+		t.Errorf("expected the second community to be at 1, got %v", position)
+	}
+}
+
+func TestRowHash(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	c := communities[0]
+
+	before, _ := rowHash(c)
+	again, _ := rowHash(c)
+	if before != again || len(before) != 64 {
+		t.Errorf("expected the same hash every time, got %s and %s", before, again)
+	}
+
+	c.County = "SOMEWHERE ELSE"
+	if after, _ := rowHash(c); after == before {
+		t.Errorf("expected the hash to change with the community")
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	connString := os.Getenv(testDatabaseEnv)
+	if len(connString) == 0 {
+		t.Skipf("%s isn't set", testDatabaseEnv)
+	}
+
+	ctx := context.Background()
+	s, err := Open(ctx, connString, WithTable("nfip_test_communities"))
+	if err != nil {
+		t.Fatalf("expected the database to be opened, got %s", err)
+	}
+	defer s.Close()
+	defer s.Pool().Exec(ctx, "DROP TABLE nfip_test_communities, nfip_test_communities_meta")
+
+	if refreshed, err := s.Refreshed(ctx); err != nil || !refreshed.IsZero() {
+		t.Errorf("expected the table not to have been refreshed, got %s %v", refreshed, err)
+	}
+
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	changes, err := s.Refresh(ctx, communities)
+	if err != nil || changes != (store.Changes{Added: len(communities)}) {
+		t.Fatalf("expected every community to be added, got %v %v", changes, err)
+	}
+
+	if refreshed, err := s.Refreshed(ctx); err != nil || time.Since(refreshed) > time.Minute {
+		t.Errorf("expected the table to have just been refreshed, got %s %v", refreshed, err)
+	}
+
+	changes, err = s.Refresh(ctx, communities)
+	if err != nil || changes != (store.Changes{}) {
+		t.Errorf("expected nothing to change, got %v %v", changes, err)
+	}
+
+	next := append(data.NFIPCommunityStatuses{}, communities[1:]...)
+	next[0].CommunityName = "RENAMED, CITY OF"
+	changes, err = s.Refresh(ctx, next)
+	if err != nil || changes != (store.Changes{Updated: 1, Removed: 1}) {
+		t.Errorf("expected one update and one removal, got %v %v", changes, err)
+	}
+
+	for _, term := range []string{"cook county il", "state:TX", "county:harris AND crs", "NOT state:TX"} {
+		results, err := s.Search(ctx, term)
+		if expected := next.Search(term); err != nil || len(results) != len(expected) {
+			t.Errorf("expected %d results for %s, got %d %v", len(expected), term, len(results), err)
+		}
+	}
+
+	c, ok, err := s.Get(ctx, next[0].CID)
+	if err != nil || !ok || c.CommunityName != "RENAMED, CITY OF" {
+		t.Errorf("expected the renamed community, got %v %v %v", c, ok, err)
+	}
+
+	stored, err := s.Load(ctx)
+	if err != nil || len(stored) != len(next) {
+		t.Fatalf("expected %d communities, got %d %v", len(next), len(stored), err)
+	}
+
+	hashes := make(map[int]string)
+	for _, c := range next {
+		hashes[c.CID], _ = rowHash(c)
+	}
+
+	for i, c := range stored {
+		if hash, _ := rowHash(c); hash != hashes[c.CID] {
+			t.Errorf("expected community %d to be read back the same", c.CID)
+		}
+
+		// They're loaded in the same order as the status book
+		if c.CID != next[i].CID {
+			t.Errorf("expected community %d at %d, got %d", next[i].CID, i, c.CID)
+		}
+	}
+}
@@ -0,0 +1,368 @@
+// Package redis shares the communities between every instance of the
+// service through Redis, so that only one of them has to download and
+// parse the status book. Each instance keeps its own copy in memory to
+// search, and a refresh on any of them is published to the rest, which
+// load the new communities from Redis.
+//
+// The communities are kept under a version that's a hash of them, and
+// searches are cached under the version too, so a refresh makes the
+// searches from before it miss without having to delete them.
+package redis
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+// DefaultPrefix is put in front of every key, so that Redis can be shared.
+const DefaultPrefix = "nfip:"
+
+// DefaultSearchTTL is how long searches are cached for.
+const DefaultSearchTTL = 10 * time.Minute
+
+// How long the communities from before a refresh are kept around for
+// the instances that were in the middle of loading them.
+const staleDatasetTTL = time.Hour
+
+// An Option changes how a Store is set up.
+type Option func(*Store)
+
+// WithPrefix is put in front of every key and the channel refreshes are published on.
+func WithPrefix(prefix string) Option {
+	return func(s *Store) {
+		s.prefix = prefix
+	}
+}
+
+// WithSearchTTL is how long searches are cached for. Searches
+// aren't cached in Redis at all if it's 0.
+func WithSearchTTL(ttl time.Duration) Option {
+	return func(s *Store) {
+		s.searchTTL = ttl
+	}
+}
+
+// WithIndexOptions are the options that the copy in memory is indexed
+// with, like a search cache.
+func WithIndexOptions(opts ...data.IndexOption) Option {
+	return func(s *Store) {
+		s.indexOptions = opts
+	}
+}
+
+// A Store is the communities in Redis, with a copy of them in memory.
+type Store struct {
+	client       *goredis.Client
+	prefix       string
+	searchTTL    time.Duration
+	indexOptions []data.IndexOption
+	memory       *store.Memory
+
+	mu      sync.Mutex
+	version string
+}
+
+var _ store.Snapshot = (*Store)(nil)
+var _ store.Dated = (*Store)(nil)
+
+// Open connects to Redis at the URL, like "redis://localhost:6379/0".
+func Open(ctx context.Context, url string, opts ...Option) (*Store, error) {
+	options, err := goredis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	client := goredis.NewClient(options)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return New(client, opts...), nil
+}
+
+// New shares the communities through the client.
+func New(client *goredis.Client, opts ...Option) *Store {
+	s := &Store{
+		client:    client,
+		prefix:    DefaultPrefix,
+		searchTTL: DefaultSearchTTL,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.memory = store.NewMemory(nil, s.indexOptions...)
+	return s
+}
+
+func (s *Store) Close() error {
+	return s.client.Close()
+}
+
+func (s *Store) versionKey() string {
+	return s.prefix + "version"
+}
+
+func (s *Store) refreshedKey() string {
+	return s.prefix + "refreshed"
+}
+
+func (s *Store) datasetKey(version string) string {
+	return s.prefix + "dataset:" + version
+}
+
+func (s *Store) searchKey(version, term string) string {
+	return s.prefix + "search:" + version + ":" + term
+}
+
+func (s *Store) channel() string {
+	return s.prefix + "refresh"
+}
+
+// Version is the version of the communities in memory. It's empty
+// until they've been loaded or refreshed.
+func (s *Store) Version() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.version
+}
+
+// Index is the index of the communities in memory.
+func (s *Store) Index() *data.CommunityIndex {
+	return s.memory.Index()
+}
+
+// Loaded is when the communities in memory were loaded.
+func (s *Store) Loaded() time.Time {
+	return s.memory.Loaded()
+}
+
+// Updated is when the communities were last refreshed by any instance,
+// as of when they were loaded.
+func (s *Store) Updated() time.Time {
+	return s.memory.Updated()
+}
+
+// Refreshed is when the communities in Redis were last refreshed.
+func (s *Store) Refreshed(ctx context.Context) (time.Time, error) {
+	refreshed, err := s.client.Get(ctx, s.refreshedKey()).Time()
+	if errors.Is(err, goredis.Nil) {
+		return time.Time{}, nil
+	}
+
+	return refreshed, err
+}
+
+// Load is the communities in Redis, which are only read from
+// it if they're a different version than the ones in memory.
+func (s *Store) Load(ctx context.Context) (data.NFIPCommunityStatuses, error) {
+	if err := s.sync(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.memory.Load(ctx)
+}
+
+// sync loads the communities from Redis if there's a new version of them,
+// and when they were last refreshed whether there is or not.
+func (s *Store) sync(ctx context.Context) error {
+	version, err := s.client.Get(ctx, s.versionKey()).Result()
+	if errors.Is(err, goredis.Nil) {
+		// Nobody has put the communities in Redis yet
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	refreshed, err := s.Refreshed(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if version == s.version {
+		if !refreshed.IsZero() {
+			s.memory.SetUpdated(refreshed)
+		}
+		return nil
+	}
+
+	b, err := s.client.Get(ctx, s.datasetKey(version)).Bytes()
+	if err != nil {
+		return err
+	}
+
+	communities, err := decodeDataset(b)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.memory.Refresh(ctx, communities); err != nil {
+		return err
+	}
+	if !refreshed.IsZero() {
+		s.memory.SetUpdated(refreshed)
+	}
+
+	s.version = version
+	return nil
+}
+
+func (s *Store) Get(ctx context.Context, cid int) (data.NFIPCommunityStatus, bool, error) {
+	return s.memory.Get(ctx, cid)
+}
+
+// Search looks for the search in Redis first, in case another instance has
+// already done it. Redis being down doesn't stop searches, it just makes
+// every instance search on its own.
+func (s *Store) Search(ctx context.Context, term string) (data.SearchResults, error) {
+	version := s.Version()
+	if s.searchTTL <= 0 || len(version) == 0 {
+		return s.memory.Search(ctx, term)
+	}
+
+	key := s.searchKey(version, term)
+	if b, err := s.client.Get(ctx, key).Bytes(); err == nil {
+		var results data.SearchResults
+		if err := json.Unmarshal(b, &results); err == nil {
+			return results, nil
+		}
+	}
+
+	results, err := s.memory.Search(ctx, term)
+	if err != nil {
+		return nil, err
+	}
+
+	if b, err := json.Marshal(results); err == nil {
+		s.client.Set(ctx, key, b, s.searchTTL)
+	}
+
+	return results, nil
+}
+
+// Refresh puts the communities in Redis as a new version and tells
+// every other instance about it, so that they all load them.
+func (s *Store) Refresh(ctx context.Context, communities data.NFIPCommunityStatuses) (store.Changes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.memory.Load(ctx)
+	if err != nil {
+		return store.Changes{}, err
+	}
+
+	changes, err := store.Diff(current, communities)
+	if err != nil {
+		return changes, err
+	}
+
+	b, version, err := encodeDataset(communities)
+	if err != nil {
+		return changes, err
+	}
+
+	old := s.version
+	refreshed := time.Now()
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Set(ctx, s.datasetKey(version), b, 0)
+		pipe.Set(ctx, s.versionKey(), version, 0)
+		pipe.Set(ctx, s.refreshedKey(), refreshed, 0)
+		if len(old) > 0 && old != version {
+			pipe.Expire(ctx, s.datasetKey(old), staleDatasetTTL)
+		}
+		pipe.Publish(ctx, s.channel(), version)
+		return nil
+	})
+	if err != nil {
+		return changes, err
+	}
+
+	if _, err := s.memory.Refresh(ctx, communities); err != nil {
+		return changes, err
+	}
+	s.memory.SetUpdated(refreshed)
+
+	s.version = version
+	return changes, nil
+}
+
+// Watch loads the communities whenever another instance refreshes them,
+// until the context is done. It should be run in its own goroutine.
+func (s *Store) Watch(ctx context.Context, l *log.Logger) error {
+	sub := s.client.Subscribe(ctx, s.channel())
+	defer sub.Close()
+
+	// Make sure the subscription is there before anything is missed
+	if _, err := sub.Receive(ctx); err != nil {
+		return err
+	}
+
+	messages := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+
+			// A refresh that didn't change anything still makes
+			// them newer
+			loaded := msg.Payload == s.Version()
+			if err := s.sync(ctx); err != nil {
+				l.Printf("** Err - could not load version %s of the communities from Redis: %s\n", msg.Payload, err)
+				continue
+			}
+			if !loaded {
+				l.Printf("Loaded version %s of the communities from Redis\n", msg.Payload)
+			}
+		}
+	}
+}
+
+// encodeDataset is the communities as gzipped JSON, and the version
+// they're kept under, which is a hash of them.
+func encodeDataset(communities data.NFIPCommunityStatuses) ([]byte, string, error) {
+	var buf bytes.Buffer
+	if err := communities.ToJSON(&buf, data.WithGzip()); err != nil {
+		return nil, "", err
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	return buf.Bytes(), hex.EncodeToString(sum[:8]), nil
+}
+
+func decodeDataset(b []byte) (data.NFIPCommunityStatuses, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var communities data.NFIPCommunityStatuses
+	if err := json.NewDecoder(gz).Decode(&communities); err != nil {
+		return nil, err
+	}
+
+	return communities, nil
+}
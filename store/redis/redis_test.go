@@ -0,0 +1,192 @@
+package redis
+
+import (
+	"context"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+func newTestStores(t *testing.T, n int) (*miniredis.Miniredis, []*Store) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	var stores []*Store
+	for i := 0; i < n; i++ {
+		s := New(goredis.NewClient(&goredis.Options{Addr: mr.Addr()}))
+		t.Cleanup(func() { s.Close() })
+		stores = append(stores, s)
+	}
+
+	return mr, stores
+}
+
+func TestRefreshAndLoad(t *testing.T) {
+	ctx := context.Background()
+	_, stores := newTestStores(t, 2)
+	a, b := stores[0], stores[1]
+
+	if communities, err := b.Load(ctx); err != nil || len(communities) != 0 {
+		t.Fatalf("expected nothing before the communities are put in Redis, got %d %v", len(communities), err)
+	}
+
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	changes, err := a.Refresh(ctx, communities)
+	if err != nil || changes != (store.Changes{Added: len(communities)}) {
+		t.Fatalf("expected every community to be added, got %v %v", changes, err)
+	}
+
+	loaded, err := b.Load(ctx)
+	if err != nil || len(loaded) != len(communities) {
+		t.Fatalf("expected %d communities from Redis, got %d %v", len(communities), len(loaded), err)
+	}
+
+	if a.Version() == "" || a.Version() != b.Version() {
+		t.Errorf("expected both to have the same version, got %s and %s", a.Version(), b.Version())
+	}
+
+	// They're as old as the refresh, not as when they were loaded
+	refreshed, err := b.Refreshed(ctx)
+	if err != nil || refreshed.IsZero() || !b.Updated().Equal(refreshed) || !a.Updated().Equal(refreshed) {
+		t.Errorf("expected both to be updated at %s, got %s and %s %v", refreshed, a.Updated(), b.Updated(), err)
+	}
+
+	c, ok, err := b.Get(ctx, 480296)
+	if err != nil || !ok || c.BaseName != "HOUSTON" || c.CurrEffMapDate == nil {
+		t.Errorf("expected Houston from Redis, got %v %v %v", c, ok, err)
+	}
+
+	// The same communities are the same version, so nothing changes
+	version := a.Version()
+	if changes, _ := a.Refresh(ctx, communities); changes != (store.Changes{}) || a.Version() != version {
+		t.Errorf("expected nothing to change, got %v in version %s", changes, a.Version())
+	}
+}
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, stores := newTestStores(t, 2)
+	a, b := stores[0], stores[1]
+
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	a.Refresh(ctx, communities)
+	b.Load(ctx)
+
+	watching := make(chan error, 1)
+	go func() { watching <- b.Watch(ctx, log.New(io.Discard, "", 0)) }()
+
+	// Give the subscription a moment to be made
+	time.Sleep(50 * time.Millisecond)
+
+	next := communities[1:]
+	if _, err := a.Refresh(ctx, next); err != nil {
+		t.Fatalf("expected the communities to be refreshed, got %s", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for b.Version() != a.Version() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if b.Version() != a.Version() {
+		t.Fatalf("expected the refresh to be seen, got version %s instead of %s", b.Version(), a.Version())
+	}
+
+	if _, ok, _ := b.Get(ctx, communities[0].CID); ok {
+		t.Errorf("expected the removed community to be gone")
+	}
+
+	// A refresh that doesn't change anything still makes them newer
+	updated := b.Updated()
+	time.Sleep(10 * time.Millisecond)
+	a.Refresh(ctx, next)
+
+	deadline = time.Now().Add(2 * time.Second)
+	for !b.Updated().After(updated) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if !b.Updated().After(updated) {
+		t.Errorf("expected the refresh to make them newer than %s, got %s", updated, b.Updated())
+	}
+
+	cancel()
+	if err := <-watching; err != context.Canceled {
+		t.Errorf("expected watching to stop when it's canceled, got %v", err)
+	}
+}
+
+func TestSearchCache(t *testing.T) {
+	ctx := context.Background()
+	mr, stores := newTestStores(t, 2)
+	a, b := stores[0], stores[1]
+
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	a.Refresh(ctx, communities)
+	b.Load(ctx)
+
+	results, err := a.Search(ctx, "houston")
+	if err != nil || len(results) == 0 {
+		t.Fatalf("expected Houston to be found, got %v %v", results, err)
+	}
+
+	key := a.searchKey(a.Version(), "houston")
+	if !mr.Exists(key) {
+		t.Fatalf("expected the search to be cached in Redis, got %v", mr.Keys())
+	}
+
+	if ttl := mr.TTL(key); ttl != DefaultSearchTTL {
+		t.Errorf("expected the search to expire in %s, got %s", DefaultSearchTTL, ttl)
+	}
+
+	// Another instance gets the same results from the cache
+	cached, err := b.Search(ctx, "houston")
+	if err != nil || len(cached) != len(results) || cached[0].Community.CID != results[0].Community.CID || cached[0].Score != results[0].Score {
+		t.Errorf("expected %v, got %v %v", results, cached, err)
+	}
+
+	// A refresh makes a new version, so the old searches aren't used
+	a.Refresh(ctx, communities[:1])
+	if results, _ := a.Search(ctx, "houston"); len(results) != 0 {
+		t.Errorf("expected Houston to be gone after the refresh, got %v", results)
+	}
+
+	for _, k := range mr.Keys() {
+		if strings.HasPrefix(k, DefaultPrefix+"dataset:") && k != a.datasetKey(a.Version()) && mr.TTL(k) != staleDatasetTTL {
+			t.Errorf("expected the old communities to expire, got %s", mr.TTL(k))
+		}
+	}
+}
+
+func TestIndexOptions(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+
+	cache := data.NewSearchCache(10)
+	s := New(goredis.NewClient(&goredis.Options{Addr: mr.Addr()}), WithSearchTTL(0), WithIndexOptions(data.WithSearchCache(cache)))
+	defer s.Close()
+
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	if _, err := s.Refresh(ctx, communities); err != nil {
+		t.Fatalf("expected the communities to be refreshed, got %s", err)
+	}
+
+	if results, err := s.Search(ctx, "houston"); err != nil || len(results) == 0 {
+		t.Fatalf("expected Houston to be found, got %v %v", results, err)
+	}
+
+	if cache.Len() != 1 {
+		t.Errorf("expected the search to be cached in memory, got %d", cache.Len())
+	}
+}
@@ -0,0 +1,396 @@
+// Package sqlite keeps the status book in an SQLite database, so that it
+// doesn't have to be downloaded and parsed again every time the service
+// starts, and so that it can be looked through with plain SQL.
+//
+// Each community is a row in the communities table, with the fields that
+// it's searched by in their own indexed columns and all of it as JSON.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"nfip-community-book/data"
+	"nfip-community-book/states"
+	"nfip-community-book/store"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS communities (
+	cid            INTEGER PRIMARY KEY,
+	position       INTEGER NOT NULL,
+	community_name TEXT NOT NULL,
+	base_name      TEXT NOT NULL,
+	county         TEXT NOT NULL,
+	state          TEXT NOT NULL,
+	state_fips     INTEGER NOT NULL,
+	tribal         BOOLEAN NOT NULL,
+	cur_class      INTEGER,
+	program        TEXT NOT NULL,
+	participating  BOOLEAN NOT NULL,
+	json           TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS communities_state ON communities (state_fips);
+CREATE INDEX IF NOT EXISTS communities_county ON communities (county COLLATE NOCASE);
+CREATE INDEX IF NOT EXISTS communities_name ON communities (community_name COLLATE NOCASE);
+CREATE INDEX IF NOT EXISTS communities_position ON communities (position);
+
+CREATE TABLE IF NOT EXISTS meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+`
+
+// When the database was last refreshed is kept in the meta table.
+const refreshedKey = "refreshed_at"
+
+const upsert = `
+INSERT INTO communities (cid, position, community_name, base_name, county, state, state_fips, tribal, cur_class, program, participating, json)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (cid) DO UPDATE SET
+	position = excluded.position,
+	community_name = excluded.community_name,
+	base_name = excluded.base_name,
+	county = excluded.county,
+	state = excluded.state,
+	state_fips = excluded.state_fips,
+	tribal = excluded.tribal,
+	cur_class = excluded.cur_class,
+	program = excluded.program,
+	participating = excluded.participating,
+	json = excluded.json
+`
+
+// A Store is the status book in an SQLite database.
+type Store struct {
+	db *sql.DB
+}
+
+var _ store.Store = (*Store)(nil)
+var _ store.Dated = (*Store)(nil)
+
+// Open opens the database in the file, creating it and
+// the communities table if they aren't there yet.
+func Open(filename string) (*Store, error) {
+	db, err := sql.Open("sqlite", filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not create the communities table: %w", err)
+	}
+
+	return &Store{db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// DB is the database itself, for running SQL that the Store doesn't.
+func (s *Store) DB() *sql.DB {
+	return s.db
+}
+
+// Refresh makes the database the same as the communities. Only the rows
+// that changed are written, so refreshing with the same status book again
+// is cheap. Communities that aren't in it anymore are deleted. It's all
+// one transaction, so searches see either the old communities or the new.
+func (s *Store) Refresh(ctx context.Context, communities data.NFIPCommunityStatuses) (store.Changes, error) {
+	var changes store.Changes
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return changes, err
+	}
+	defer tx.Rollback()
+
+	type row struct {
+		position int
+		json     string
+	}
+
+	existing := make(map[int]row)
+	rows, err := tx.QueryContext(ctx, "SELECT cid, position, json FROM communities")
+	if err != nil {
+		return changes, err
+	}
+	for rows.Next() {
+		var cid int
+		var r row
+		if err := rows.Scan(&cid, &r.position, &r.json); err != nil {
+			rows.Close()
+			return changes, err
+		}
+		existing[cid] = r
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return changes, err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, upsert)
+	if err != nil {
+		return changes, err
+	}
+	defer stmt.Close()
+
+	seen := make(map[int]bool, len(communities))
+	for i, c := range communities {
+		b, err := json.Marshal(c)
+		if err != nil {
+			return changes, err
+		}
+
+		old, ok := existing[c.CID]
+		switch {
+		case seen[c.CID]:
+			// The status book has it more than once, and the last one wins
+		case !ok:
+			changes.Added++
+		case old.json != string(b):
+			changes.Updated++
+		case old.position == i:
+			seen[c.CID] = true
+			continue
+		}
+		seen[c.CID] = true
+
+		var class interface{}
+		if c.CurClass != nil {
+			class = *c.CurClass
+		}
+
+		_, err = stmt.ExecContext(ctx, c.CID, i, c.CommunityName, c.BaseName, c.County, c.State, c.StateFIPS,
+			c.Tribal, class, c.Program.String(), c.ParticipatingCommunity, string(b))
+		if err != nil {
+			return changes, fmt.Errorf("could not save community %06d: %w", c.CID, err)
+		}
+	}
+
+	for cid := range existing {
+		if seen[cid] {
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, "DELETE FROM communities WHERE cid = ?", cid); err != nil {
+			return changes, err
+		}
+		changes.Removed++
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO meta (key, value) VALUES (?, ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value",
+		refreshedKey, time.Now().UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return changes, err
+	}
+
+	return changes, tx.Commit()
+}
+
+// Refreshed is when the database was last refreshed.
+func (s *Store) Refreshed(ctx context.Context) (time.Time, error) {
+	var value string
+	err := s.db.QueryRowContext(ctx, "SELECT value FROM meta WHERE key = ?", refreshedKey).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+// Len is how many communities are in the database.
+func (s *Store) Len(ctx context.Context) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM communities").Scan(&n)
+	return n, err
+}
+
+// Load is all of the communities, in the same order as the status book.
+func (s *Store) Load(ctx context.Context) (data.NFIPCommunityStatuses, error) {
+	return s.query(ctx, "SELECT json FROM communities ORDER BY position")
+}
+
+// Get finds the community with the CID.
+func (s *Store) Get(ctx context.Context, cid int) (data.NFIPCommunityStatus, bool, error) {
+	var b string
+	err := s.db.QueryRowContext(ctx, "SELECT json FROM communities WHERE cid = ?", cid).Scan(&b)
+	if errors.Is(err, sql.ErrNoRows) {
+		return data.NFIPCommunityStatus{}, false, nil
+	} else if err != nil {
+		return data.NFIPCommunityStatus{}, false, err
+	}
+
+	var c data.NFIPCommunityStatus
+	if err := json.Unmarshal([]byte(b), &c); err != nil {
+		return c, false, err
+	}
+
+	return c, true, nil
+}
+
+// Criteria narrow down the communities that Find finds. Anything that
+// isn't set doesn't narrow them down, and every one that is has to match.
+type Criteria struct {
+	// Names and counties that contain these, without caring about case
+	Name   string
+	County string
+
+	// The state, however it's written. See states.Lookup.
+	State string
+
+	Program       data.ProgramKind
+	Participating *bool
+	Tribal        *bool
+
+	// CRS communities with a class that's at least this good
+	CRSClassAtMost int
+
+	// Offset and Limit page the communities. There's no limit if it's 0.
+	Offset int
+	Limit  int
+}
+
+// Find finds the communities that meet the criteria, in the same order as the status book.
+func (s *Store) Find(ctx context.Context, cr Criteria) (data.NFIPCommunityStatuses, error) {
+	var where []string
+	var args []interface{}
+
+	if len(cr.Name) > 0 {
+		where = append(where, `community_name LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(cr.Name)+"%")
+	}
+
+	if len(cr.County) > 0 {
+		where = append(where, `county LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(cr.County)+"%")
+	}
+
+	if len(cr.State) > 0 {
+		state, ok := states.Lookup(cr.State)
+		if !ok {
+			return data.NFIPCommunityStatuses{}, nil
+		}
+		where = append(where, "state_fips = ?")
+		args = append(args, state.FIPS)
+	}
+
+	if cr.Program != data.ProgramUnknown {
+		where = append(where, "program = ?")
+		args = append(args, cr.Program.String())
+	}
+
+	if cr.Participating != nil {
+		where = append(where, "participating = ?")
+		args = append(args, *cr.Participating)
+	}
+
+	if cr.Tribal != nil {
+		where = append(where, "tribal = ?")
+		args = append(args, *cr.Tribal)
+	}
+
+	if cr.CRSClassAtMost > 0 {
+		where = append(where, "cur_class <= ?")
+		args = append(args, cr.CRSClassAtMost)
+	}
+
+	q := "SELECT json FROM communities"
+	if len(where) > 0 {
+		q += " WHERE " + strings.Join(where, " AND ")
+	}
+	q += " ORDER BY position"
+
+	if cr.Limit > 0 || cr.Offset > 0 {
+		limit := cr.Limit
+		if limit == 0 {
+			limit = -1
+		}
+		q += " LIMIT ? OFFSET ?"
+		args = append(args, limit, cr.Offset)
+	}
+
+	return s.query(ctx, q, args...)
+}
+
+// Search finds the communities with every word of the term in their name,
+// county, state or CID, and then ranks them the same as data's Search does.
+// Since the database only finds communities with the words themselves,
+// synonyms of them aren't found unless the community has the word too.
+// Queries, like state:TX, aren't words, so they're run over every community.
+func (s *Store) Search(ctx context.Context, term string) (data.SearchResults, error) {
+	if data.IsQuery(term) {
+		communities, err := s.Load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return communities.Search(term), nil
+	}
+
+	var where []string
+	var args []interface{}
+
+	for _, word := range strings.Fields(strings.ReplaceAll(term, "\"", " ")) {
+		// Words can be states, like "il" in "cook county il"
+		fips := 0
+		if state, ok := states.Lookup(word); ok {
+			fips = state.FIPS
+		}
+
+		like := "%" + escapeLike(word) + "%"
+		where = append(where, `(community_name LIKE ? ESCAPE '\' OR county LIKE ? ESCAPE '\' OR state_fips = ? OR printf('%06d', cid) = ?)`)
+		args = append(args, like, like, fips, word)
+	}
+
+	if len(where) == 0 {
+		return data.SearchResults{}, nil
+	}
+
+	candidates, err := s.query(ctx, "SELECT json FROM communities WHERE "+strings.Join(where, " AND ")+" ORDER BY position", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return candidates.Search(term), nil
+}
+
+func (s *Store) query(ctx context.Context, q string, args ...interface{}) (data.NFIPCommunityStatuses, error) {
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	communities := data.NFIPCommunityStatuses{}
+	for rows.Next() {
+		var b string
+		if err := rows.Scan(&b); err != nil {
+			return nil, err
+		}
+
+		var c data.NFIPCommunityStatus
+		if err := json.Unmarshal([]byte(b), &c); err != nil {
+			return nil, err
+		}
+		communities = append(communities, c)
+	}
+
+	return communities, rows.Err()
+}
+
+// escapeLike escapes the wildcards in s so that LIKE matches them as they are.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
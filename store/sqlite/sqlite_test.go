@@ -0,0 +1,179 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+)
+
+func openTestStore(t *testing.T) (*Store, data.NFIPCommunityStatuses) {
+	t.Helper()
+
+	s, err := Open(filepath.Join(t.TempDir(), "nfip.db"))
+	if err != nil {
+		t.Fatalf("expected the database to be opened, got %s", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	if _, err := s.Refresh(context.Background(), communities); err != nil {
+		t.Fatalf("expected the communities to be loaded, got %s", err)
+	}
+
+	return s, communities
+}
+
+func cids(communities data.NFIPCommunityStatuses) []int {
+	var cids []int
+	for _, c := range communities {
+		cids = append(cids, c.CID)
+	}
+
+	return cids
+}
+
+func TestLoad(t *testing.T) {
+	s, communities := openTestStore(t)
+
+	stored, err := s.Load(context.Background())
+	if err != nil {
+		t.Fatalf("expected the communities to be read, got %s", err)
+	}
+
+	if !reflect.DeepEqual(cids(stored), cids(communities)) {
+		t.Fatalf("expected %v, got %v", cids(communities), cids(stored))
+	}
+
+	for i := range communities {
+		if stored[i].CommunityName != communities[i].CommunityName || !reflect.DeepEqual(stored[i].CurClass, communities[i].CurClass) {
+			t.Errorf("expected %v, got %v", communities[i], stored[i])
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	s, _ := openTestStore(t)
+
+	c, ok, err := s.Get(context.Background(), 480296)
+	if err != nil || !ok || c.BaseName != "HOUSTON" {
+		t.Errorf("expected Houston, got %v %v %v", c, ok, err)
+	}
+
+	if _, ok, err := s.Get(context.Background(), 999999); ok || err != nil {
+		t.Errorf("expected nothing for a CID that isn't there, got %v %v", ok, err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	s, communities := openTestStore(t)
+	yes := true
+
+	tests := []struct {
+		name     string
+		criteria Criteria
+		filter   data.Filter
+	}{
+		{"state", Criteria{State: "Texas"}, data.ByState("TX")},
+		{"name", Criteria{Name: "city of"}, data.NameContains("city of")},
+		{"county", Criteria{County: "cook"}, data.CountyContains("cook")},
+		{"participating", Criteria{Participating: &yes}, data.Participating(true)},
+		{"tribal", Criteria{Tribal: &yes}, data.Tribal(true)},
+		{"program", Criteria{Program: data.ProgramEmergency}, data.InProgram(data.ProgramEmergency)},
+		{"class", Criteria{CRSClassAtMost: 6}, data.CRSClassAtMost(6)},
+		{"everything", Criteria{State: "IL", Name: "city", Participating: &yes}, data.All(data.ByState("IL"), data.NameContains("city"), data.Participating(true))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := s.Find(context.Background(), tt.criteria)
+			if err != nil {
+				t.Fatalf("expected the communities to be found, got %s", err)
+			}
+
+			expected := communities.Refine().Filter(tt.filter).Communities()
+			if len(expected) == 0 {
+				t.Fatalf("expected the demo to have communities for %v", tt.criteria)
+			}
+
+			if !reflect.DeepEqual(cids(found), cids(expected)) {
+				t.Errorf("expected %v, got %v", cids(expected), cids(found))
+			}
+		})
+	}
+}
+
+func TestFindPages(t *testing.T) {
+	s, communities := openTestStore(t)
+
+	found, err := s.Find(context.Background(), Criteria{Offset: 2, Limit: 3})
+	if err != nil {
+		t.Fatalf("expected the communities to be found, got %s", err)
+	}
+
+	if !reflect.DeepEqual(cids(found), cids(communities[2:5])) {
+		t.Errorf("expected %v, got %v", cids(communities[2:5]), cids(found))
+	}
+
+	found, _ = s.Find(context.Background(), Criteria{Offset: len(communities) - 1})
+	if len(found) != 1 {
+		t.Errorf("expected only the last community, got %v", cids(found))
+	}
+}
+
+func TestSearch(t *testing.T) {
+	s, communities := openTestStore(t)
+
+	for _, term := range []string{"miami", "cook county il", "480296", "new york", "100%", "state:TX", "county:harris AND crs", "NOT state:TX"} {
+		results, err := s.Search(context.Background(), term)
+		if err != nil {
+			t.Fatalf("expected %s to be searched, got %s", term, err)
+		}
+
+		expected := communities.Search(term)
+		if len(results) != len(expected) {
+			t.Errorf("expected %d results for %s, got %d", len(expected), term, len(results))
+			continue
+		}
+
+		for i := range expected {
+			if results[i].Community.CID != expected[i].Community.CID || results[i].Score != expected[i].Score {
+				t.Errorf("expected %v for %s, got %v", expected[i], term, results[i])
+			}
+		}
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	s, communities := openTestStore(t)
+
+	changes, err := s.Refresh(context.Background(), communities)
+	if err != nil || changes != (store.Changes{}) {
+		t.Errorf("expected nothing to change, got %v %v", changes, err)
+	}
+
+	next := append(data.NFIPCommunityStatuses{}, communities[1:]...)
+	next[0].CommunityName = "RENAMED, CITY OF"
+	next = append(next, data.NFIPCommunityStatus{CID: 999999, CommunityName: "NEW, TOWN OF", State: "TX", StateFIPS: 48})
+
+	changes, err = s.Refresh(context.Background(), next)
+	if err != nil {
+		t.Fatalf("expected the communities to be refreshed, got %s", err)
+	}
+
+	if expected := (store.Changes{Added: 1, Updated: 1, Removed: 1}); changes != expected {
+		t.Errorf("expected %v, got %v", expected, changes)
+	}
+
+	stored, _ := s.Load(context.Background())
+	if !reflect.DeepEqual(cids(stored), cids(next)) {
+		t.Errorf("expected %v, got %v", cids(next), cids(stored))
+	}
+
+	if n, _ := s.Len(context.Background()); n != len(next) {
+		t.Errorf("expected %d communities, got %d", len(next), n)
+	}
+}
@@ -0,0 +1,307 @@
+// Package store is where the communities are kept between refreshes. A
+// Store can be the status book in memory, which is what the service uses
+// by default, or one of the databases in the packages under this one.
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"nfip-community-book/data"
+)
+
+var tracer = otel.Tracer("nfip-community-book/store")
+
+// A Store keeps the communities so that they can be looked up and
+// searched, and can be refreshed with a new status book.
+type Store interface {
+	// Load is all of the communities in the store.
+	Load(ctx context.Context) (data.NFIPCommunityStatuses, error)
+
+	// Get finds the community with the CID.
+	Get(ctx context.Context, cid int) (data.NFIPCommunityStatus, bool, error)
+
+	// Search ranks the communities by how well they match the term, the
+	// same as NFIPCommunityStatuses.Search.
+	Search(ctx context.Context, term string) (data.SearchResults, error)
+
+	// Refresh makes the store the same as the communities.
+	Refresh(ctx context.Context, communities data.NFIPCommunityStatuses) (Changes, error)
+}
+
+// A Snapshot is a Store that keeps a copy of the communities in memory
+// too, which is what the server lists them from and tags them with. A
+// Memory store is one, and Cached makes any other store one.
+type Snapshot interface {
+	Store
+
+	// Index is the index of the communities that are in memory right now.
+	Index() *data.CommunityIndex
+
+	// Version is different whenever the communities are.
+	Version() string
+
+	// Loaded is when the communities in memory were loaded.
+	Loaded() time.Time
+
+	// Updated is when the communities were downloaded, or the store
+	// that they're from was last refreshed, which is how old they are.
+	Updated() time.Time
+}
+
+// A Dated store knows when it was last refreshed. It's the zero time if
+// it never has been.
+type Dated interface {
+	Refreshed(ctx context.Context) (time.Time, error)
+}
+
+var _ Snapshot = (*Memory)(nil)
+var _ Snapshot = (*Cached)(nil)
+
+// Changes are how many communities were added, updated and removed by
+// a refresh. Communities that didn't change aren't counted.
+type Changes struct {
+	Added   int `json:"added"`
+	Updated int `json:"updated"`
+	Removed int `json:"removed"`
+}
+
+// Diff counts the changes between the communities that are in a store and
+// the ones it's being refreshed with. Communities are matched up by CID,
+// and they've changed if their JSON has.
+func Diff(current, next data.NFIPCommunityStatuses) (Changes, error) {
+	var changes Changes
+
+	before := make(map[int][]byte, len(current))
+	for _, c := range current {
+		b, err := json.Marshal(c)
+		if err != nil {
+			return changes, err
+		}
+		before[c.CID] = b
+	}
+
+	after := make(map[int][]byte, len(next))
+	for _, c := range next {
+		b, err := json.Marshal(c)
+		if err != nil {
+			return changes, err
+		}
+		after[c.CID] = b
+	}
+
+	for cid, b := range after {
+		old, ok := before[cid]
+		switch {
+		case !ok:
+			changes.Added++
+		case !bytes.Equal(old, b):
+			changes.Updated++
+		}
+	}
+
+	for cid := range before {
+		if _, ok := after[cid]; !ok {
+			changes.Removed++
+		}
+	}
+
+	return changes, nil
+}
+
+// Version is a hash of the communities' JSON in the order they're in,
+// which is different whenever any of them are.
+func Version(communities data.NFIPCommunityStatuses) string {
+	h := sha256.New()
+
+	// Communities are always encoded, and hashes can't fail to be written
+	e := json.NewEncoder(h)
+	for _, c := range communities {
+		e.Encode(c)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// A Memory store keeps the communities in memory, with an index
+// to search them. It's gone when the service stops.
+type Memory struct {
+	mu      sync.RWMutex
+	idx     *data.CommunityIndex
+	opts    []data.IndexOption
+	loaded  time.Time
+	updated time.Time
+	version string
+}
+
+// NewMemory keeps the communities in memory. The options are
+// used to build the index every time it's refreshed. They're
+// taken to be new unless SetUpdated says when they're from.
+func NewMemory(communities data.NFIPCommunityStatuses, opts ...data.IndexOption) *Memory {
+	now := time.Now()
+	return &Memory{idx: data.NewCommunityIndex(communities, opts...), opts: opts, loaded: now, updated: now, version: Version(communities)}
+}
+
+// Index is the index of the communities that are in memory right now.
+func (m *Memory) Index() *data.CommunityIndex {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.idx
+}
+
+// Loaded is when the communities were put in memory, by NewMemory or
+// the last Refresh.
+func (m *Memory) Loaded() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.loaded
+}
+
+// Updated is when the communities were downloaded, which is when they
+// were loaded unless SetUpdated says otherwise.
+func (m *Memory) Updated() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.updated
+}
+
+// SetUpdated is when the communities in memory were downloaded, or the
+// store they're from was refreshed, if they're older than when they were
+// loaded.
+func (m *Memory) SetUpdated(updated time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.updated = updated
+}
+
+// Version is the Version of the communities that are in memory right now.
+func (m *Memory) Version() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.version
+}
+
+func (m *Memory) Load(ctx context.Context) (data.NFIPCommunityStatuses, error) {
+	return m.Index().Communities(), nil
+}
+
+func (m *Memory) Get(ctx context.Context, cid int) (data.NFIPCommunityStatus, bool, error) {
+	c, ok := m.Index().ByCID(cid)
+	if !ok {
+		return data.NFIPCommunityStatus{}, false, nil
+	}
+
+	return *c, true, nil
+}
+
+func (m *Memory) Search(ctx context.Context, term string) (data.SearchResults, error) {
+	_, span := tracer.Start(ctx, "search", trace.WithAttributes(attribute.String("nfip.search.term", term)))
+	defer span.End()
+
+	return m.Index().Search(term), nil
+}
+
+// Refresh builds a new index for the communities and swaps it in.
+func (m *Memory) Refresh(ctx context.Context, communities data.NFIPCommunityStatuses) (Changes, error) {
+	_, span := tracer.Start(ctx, "index communities", trace.WithAttributes(attribute.Int("nfip.communities", len(communities))))
+	defer span.End()
+
+	changes, err := Diff(m.Index().Communities(), communities)
+	if err != nil {
+		return changes, err
+	}
+
+	idx := data.NewCommunityIndex(communities, m.opts...)
+	version := Version(communities)
+
+	now := time.Now()
+	m.mu.Lock()
+	m.idx = idx
+	m.loaded, m.updated = now, now
+	m.version = version
+	m.mu.Unlock()
+
+	return changes, nil
+}
+
+// A Cached store keeps a copy of another store's communities in memory to
+// list them from, and looks them up and searches them in the other store.
+// They're both refreshed together.
+type Cached struct {
+	*Memory
+	backend Store
+}
+
+// NewCached loads the store's communities into memory. The options are
+// used to build the index, the same as NewMemory. They're as old as the
+// store's last refresh if it's Dated.
+func NewCached(ctx context.Context, backend Store, opts ...data.IndexOption) (*Cached, error) {
+	communities, err := backend.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	memory := NewMemory(communities, opts...)
+	if dated, ok := backend.(Dated); ok {
+		refreshed, err := dated.Refreshed(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !refreshed.IsZero() {
+			memory.SetUpdated(refreshed)
+		}
+	}
+
+	return &Cached{memory, backend}, nil
+}
+
+func (c *Cached) Get(ctx context.Context, cid int) (data.NFIPCommunityStatus, bool, error) {
+	return c.backend.Get(ctx, cid)
+}
+
+func (c *Cached) Search(ctx context.Context, term string) (data.SearchResults, error) {
+	return c.backend.Search(ctx, term)
+}
+
+// Refresh refreshes the other store, and then the communities in memory.
+// The changes are the other store's.
+func (c *Cached) Refresh(ctx context.Context, communities data.NFIPCommunityStatuses) (Changes, error) {
+	changes, err := c.backend.Refresh(ctx, communities)
+	if err != nil {
+		return changes, err
+	}
+
+	if _, err := c.Memory.Refresh(ctx, communities); err != nil {
+		return changes, err
+	}
+
+	return changes, nil
+}
+
+// Close closes the other store if it has to be closed. The Postgres
+// store's Close doesn't return anything, so it's closed on its own.
+func (c *Cached) Close() error {
+	switch b := c.backend.(type) {
+	case io.Closer:
+		return b.Close()
+	case interface{ Close() }:
+		b.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package store_test
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"nfip-community-book/data"
+	"nfip-community-book/store"
+	"nfip-community-book/store/bolt"
+	"nfip-community-book/store/sqlite"
+)
+
+// Every store that can be made without a server behaves the same way.
+func testStores(t *testing.T) map[string]store.Store {
+	dir := t.TempDir()
+
+	sq, err := sqlite.Open(filepath.Join(dir, "nfip.sqlite"))
+	if err != nil {
+		t.Fatalf("expected the SQLite store to be opened, got %s", err)
+	}
+	t.Cleanup(func() { sq.Close() })
+
+	bo, err := bolt.Open(filepath.Join(dir, "nfip.bolt"))
+	if err != nil {
+		t.Fatalf("expected the bolt store to be opened, got %s", err)
+	}
+	t.Cleanup(func() { bo.Close() })
+
+	backend, err := sqlite.Open(filepath.Join(dir, "cached.sqlite"))
+	if err != nil {
+		t.Fatalf("expected the cached store to be opened, got %s", err)
+	}
+	cached, err := store.NewCached(context.Background(), backend)
+	if err != nil {
+		t.Fatalf("expected the cached store to be loaded, got %s", err)
+	}
+	t.Cleanup(func() { cached.Close() })
+
+	return map[string]store.Store{
+		"memory": store.NewMemory(nil),
+		"sqlite": sq,
+		"bolt":   bo,
+		"cached": cached,
+	}
+}
+
+func sortedCIDs(communities data.NFIPCommunityStatuses) []int {
+	cids := []int{}
+	for _, c := range communities {
+		cids = append(cids, c.CID)
+	}
+	sort.Ints(cids)
+
+	return cids
+}
+
+func TestStores(t *testing.T) {
+	ctx := context.Background()
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+
+	for name, s := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			dated, isDated := s.(store.Dated)
+			if isDated {
+				if refreshed, err := dated.Refreshed(ctx); err != nil || !refreshed.IsZero() {
+					t.Errorf("expected the store not to have been refreshed, got %s %v", refreshed, err)
+				}
+			}
+
+			changes, err := s.Refresh(ctx, communities)
+			if err != nil || changes != (store.Changes{Added: len(communities)}) {
+				t.Fatalf("expected every community to be added, got %v %v", changes, err)
+			}
+
+			if isDated {
+				if refreshed, err := dated.Refreshed(ctx); err != nil || time.Since(refreshed) > time.Minute {
+					t.Errorf("expected the store to have just been refreshed, got %s %v", refreshed, err)
+				}
+			}
+
+			loaded, err := s.Load(ctx)
+			if err != nil || !reflect.DeepEqual(sortedCIDs(loaded), sortedCIDs(communities)) {
+				t.Errorf("expected every community to be loaded, got %v %v", sortedCIDs(loaded), err)
+			}
+
+			c, ok, err := s.Get(ctx, 480296)
+			if err != nil || !ok || c.BaseName != "HOUSTON" {
+				t.Errorf("expected Houston, got %v %v %v", c, ok, err)
+			}
+
+			// Communities that rank the same can be in a different order
+			results, err := s.Search(ctx, "miami")
+			if expected := communities.Search("miami"); err != nil || !reflect.DeepEqual(sortedCIDs(results.Communities()), sortedCIDs(expected.Communities())) {
+				t.Errorf("expected %v, got %v %v", sortedCIDs(expected.Communities()), sortedCIDs(results.Communities()), err)
+			}
+
+			next := append(data.NFIPCommunityStatuses{}, communities[1:]...)
+			next[0].County = "SOMEWHERE ELSE"
+
+			changes, err = s.Refresh(ctx, next)
+			if err != nil || changes != (store.Changes{Updated: 1, Removed: 1}) {
+				t.Errorf("expected one update and one removal, got %v %v", changes, err)
+			}
+
+			if _, ok, _ := s.Get(ctx, communities[0].CID); ok {
+				t.Errorf("expected the removed community to be gone")
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+
+	changes, err := store.Diff(communities, communities)
+	if err != nil || changes != (store.Changes{}) {
+		t.Errorf("expected nothing to change, got %v %v", changes, err)
+	}
+
+	next := append(data.NFIPCommunityStatuses{}, communities[2:]...)
+	next[0].CommunityName = "RENAMED"
+	next = append(next, data.NFIPCommunityStatus{CID: 999999})
+
+	changes, _ = store.Diff(communities, next)
+	if expected := (store.Changes{Added: 1, Updated: 1, Removed: 2}); changes != expected {
+		t.Errorf("expected %v, got %v", expected, changes)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+
+	version := store.Version(communities)
+	if len(version) != 64 || version != store.Version(communities) {
+		t.Fatalf("expected the same hash every time, got %s", version)
+	}
+
+	changed := append(data.NFIPCommunityStatuses{}, communities...)
+	changed[0].CommunityName = "RENAMED"
+	if store.Version(changed) == version {
+		t.Errorf("expected a different version when a community changes")
+	}
+
+	if store.Version(communities[:len(communities)-1]) == version {
+		t.Errorf("expected a different version when a community is removed")
+	}
+}
+
+// The memory store's index is swapped out when it's refreshed,
+// and searches that were cached aren't found anymore.
+func TestMemoryRefresh(t *testing.T) {
+	ctx := context.Background()
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+
+	cache := data.NewSearchCache(10)
+	m := store.NewMemory(communities, data.WithSearchCache(cache))
+	before, loaded, version := m.Index(), m.Loaded(), m.Version()
+
+	if results, _ := m.Search(ctx, "houston"); len(results) == 0 {
+		t.Fatalf("expected Houston to be found")
+	}
+
+	m.Refresh(ctx, communities[:1])
+	if m.Index() == before || cache.Len() != 0 {
+		t.Errorf("expected a new index and an empty cache")
+	}
+
+	if m.Loaded().Before(loaded) {
+		t.Errorf("expected the refresh to be when it was loaded, got %s before %s", m.Loaded(), loaded)
+	}
+
+	if m.Version() == version || m.Version() != store.Version(communities[:1]) {
+		t.Errorf("expected the version of the communities it was refreshed with, got %s", m.Version())
+	}
+
+	if results, _ := m.Search(ctx, "houston"); len(results) != 0 {
+		t.Errorf("expected Houston to be gone, got %v", results)
+	}
+}
+
+func TestCached(t *testing.T) {
+	ctx := context.Background()
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+
+	backend, err := bolt.Open(filepath.Join(t.TempDir(), "nfip.bolt"))
+	if err != nil {
+		t.Fatalf("expected the bolt store to be opened, got %s", err)
+	}
+	backend.Refresh(ctx, communities)
+
+	cached, err := store.NewCached(ctx, backend)
+	if err != nil {
+		t.Fatalf("expected the communities to be loaded, got %s", err)
+	}
+	defer cached.Close()
+
+	// They're as old as the bolt store's last refresh
+	if refreshed, _ := backend.Refreshed(ctx); !cached.Updated().Equal(refreshed) {
+		t.Errorf("expected them to be updated at %s, got %s", refreshed, cached.Updated())
+	}
+
+	if n := len(cached.Index().Communities()); n != len(communities) {
+		t.Errorf("expected %d communities in memory, got %d", len(communities), n)
+	}
+
+	// Both of them are refreshed
+	changes, err := cached.Refresh(ctx, communities[1:])
+	if err != nil || changes != (store.Changes{Removed: 1}) {
+		t.Errorf("expected a community to be removed, got %v %v", changes, err)
+	}
+
+	if n, _ := backend.Len(); n != len(communities)-1 {
+		t.Errorf("expected the bolt store to be refreshed, got %d communities", n)
+	}
+	if n := len(cached.Index().Communities()); n != len(communities)-1 {
+		t.Errorf("expected the communities in memory to be refreshed, got %d", n)
+	}
+	if cached.Version() != store.Version(communities[1:]) {
+		t.Errorf("expected the version of the new communities")
+	}
+
+	if _, ok, err := cached.Get(ctx, communities[0].CID); ok || err != nil {
+		t.Errorf("expected the removed community to be gone, got %v %v", ok, err)
+	}
+
+	// Closing it closes the bolt store
+	cached.Close()
+	if _, err := backend.Len(); err == nil {
+		t.Errorf("expected the bolt store to be closed")
+	}
+}
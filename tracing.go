@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Traces are sent with OTLP over HTTP if either of these is set, like
+// http://localhost:4318. The exporter reads the rest of the standard OTEL_
+// variables itself, like OTEL_EXPORTER_OTLP_HEADERS, and OTEL_SERVICE_NAME
+// and OTEL_TRACES_SAMPLER are read too.
+const (
+	OTLPEndpointEnv       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	OTLPTracesEndpointEnv = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+)
+
+const serviceName = "nfip-community-book"
+
+// setupTracing sends traces to the collector if there is one, and returns
+// what flushes the ones that haven't been sent yet when the server stops.
+// Without one, nothing is traced.
+func setupTracing(l *log.Logger) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if len(os.Getenv(OTLPEndpointEnv)) == 0 && len(os.Getenv(OTLPTracesEndpointEnv)) == 0 {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	ctx := context.Background()
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// OTEL_SERVICE_NAME wins over the default name, since it's read after
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(serviceName)),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+
+	l.Println("Sending traces with OTLP")
+	return tp.Shutdown, nil
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"nfip-community-book/data"
+)
+
+// The keys that do something other than type in the search box.
+const tuiHelp = "↑/↓ move · pgup/pgdn page · ctrl+y copy CID · ctrl+s export CSV · ctrl+o export JSON · esc quit"
+
+var (
+	tuiSelectedStyle = lipgloss.NewStyle().Reverse(true)
+	tuiDimStyle      = lipgloss.NewStyle().Faint(true)
+	tuiPaneStyle     = lipgloss.NewStyle().Border(lipgloss.NormalBorder()).Padding(0, 1)
+	tuiHeaderStyle   = lipgloss.NewStyle().Bold(true)
+)
+
+func (c *cli) tuiCommand() *cobra.Command {
+	var filters filterFlags
+
+	cmd := &cobra.Command{
+		Use:   "tui",
+		Short: "Browse and search the communities in the terminal",
+		Long: `Browse and search the communities in the terminal. What's typed is searched
+as it's typed, the arrow keys pick a community, and all of its fields are
+shown next to the list.
+
+The CID of the community that's picked can be copied with ctrl+y, and the
+communities that match the search can be exported to a CSV with ctrl+s or
+to JSON with ctrl+o, in the current directory.`,
+		Example: "  nfip tui --state TX",
+		Args:    checkArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.runTUI(&filters)
+		},
+	}
+
+	filters.add(cmd)
+	return cmd
+}
+
+func (c *cli) runTUI(filters *filterFlags) error {
+	communities, err := c.communities()
+	if err != nil {
+		return err
+	}
+
+	communities, err = filters.apply(communities)
+	if err != nil {
+		return err
+	}
+
+	_, err = tea.NewProgram(newTUIModel(communities), tea.WithAltScreen()).Run()
+	return err
+}
+
+// A tuiModel is the search box, the communities that match what's in it,
+// and which of them is picked.
+type tuiModel struct {
+	idx     *data.CommunityIndex
+	input   textinput.Model
+	columns []data.Column
+
+	results data.NFIPCommunityStatuses
+	cursor  int
+
+	// The first result that's on the screen
+	top int
+
+	width  int
+	height int
+
+	// What happened with the last thing that was done, like an export
+	status string
+
+	// Copies text to the clipboard
+	copy func(string) error
+}
+
+func newTUIModel(communities data.NFIPCommunityStatuses) tuiModel {
+	input := textinput.New()
+	input.Prompt = "Search: "
+	input.Placeholder = "a name, county or CID"
+	input.Focus()
+
+	// Every field has a column, so they can't fail to be found
+	columns, _ := data.FieldColumns(data.FieldNames()...)
+
+	return tuiModel{
+		idx:     data.NewCommunityIndex(communities),
+		input:   input,
+		columns: columns,
+		results: communities,
+		copy:    clipboard.WriteAll,
+	}
+}
+
+func (m tuiModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.move(0)
+		return m, nil
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			return m, tea.Quit
+		case "up", "ctrl+p":
+			m.move(-1)
+			return m, nil
+		case "down", "ctrl+n":
+			m.move(1)
+			return m, nil
+		case "pgup":
+			m.move(-m.listHeight())
+			return m, nil
+		case "pgdown":
+			m.move(m.listHeight())
+			return m, nil
+		case "ctrl+y":
+			m.status = m.copyCID()
+			return m, nil
+		case "ctrl+s":
+			m.status = m.export("csv")
+			return m, nil
+		case "ctrl+o":
+			m.status = m.export("json")
+			return m, nil
+		}
+	}
+
+	term := m.input.Value()
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != term {
+		m.search()
+	}
+
+	return m, cmd
+}
+
+// search finds the communities that match what's in the search box, or
+// all of them if it's blank, and picks the best match.
+func (m *tuiModel) search() {
+	term := strings.TrimSpace(m.input.Value())
+	if len(term) == 0 {
+		m.results = m.idx.Communities()
+	} else {
+		m.results = m.idx.Search(term).Communities()
+	}
+
+	m.cursor, m.top = 0, 0
+	m.status = ""
+}
+
+// move moves the cursor by n results, and scrolls the list so that it's
+// still on the screen.
+func (m *tuiModel) move(n int) {
+	m.cursor = max(0, min(m.cursor+n, len(m.results)-1))
+
+	height := m.listHeight()
+	if m.cursor < m.top {
+		m.top = m.cursor
+	} else if m.cursor >= m.top+height {
+		m.top = m.cursor - height + 1
+	}
+}
+
+func (m tuiModel) selected() (data.NFIPCommunityStatus, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.results) {
+		return data.NFIPCommunityStatus{}, false
+	}
+
+	return m.results[m.cursor], true
+}
+
+func (m tuiModel) copyCID() string {
+	c, ok := m.selected()
+	if !ok {
+		return "There isn't a community to copy"
+	}
+
+	cid := fmt.Sprintf("%06d", c.CID)
+	if err := m.copy(cid); err != nil {
+		return "Couldn't copy the CID: " + err.Error()
+	}
+
+	return "Copied " + cid
+}
+
+// export writes the communities that match the search to a file named
+// after it.
+func (m tuiModel) export(format string) string {
+	f, err := pickExportFormat(format, "")
+	if err != nil {
+		return err.Error()
+	}
+
+	filename := exportFilename(m.input.Value()) + "." + f.name
+	if err := exportFile(filename, f, m.results); err != nil {
+		return "Couldn't export: " + err.Error()
+	}
+
+	return fmt.Sprintf("Exported %d communities to %s", len(m.results), filename)
+}
+
+// exportFilename is the name of the file that the results for the term are
+// exported to, like communities-harris-county.
+func exportFilename(term string) string {
+	words := strings.FieldsFunc(strings.ToLower(term), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	return strings.Join(append([]string{"communities"}, words...), "-")
+}
+
+// The lines that aren't the list: the search box, the help, the status,
+// and the list's border.
+const tuiChrome = 5
+
+func (m tuiModel) listHeight() int {
+	return max(1, m.height-tuiChrome)
+}
+
+func (m tuiModel) View() string {
+	if m.width == 0 {
+		return ""
+	}
+
+	count := tuiDimStyle.Render(fmt.Sprintf("%d of %d", len(m.results), len(m.idx.Communities())))
+	search := lipgloss.JoinHorizontal(lipgloss.Top, m.input.View(), "  ", count)
+
+	listWidth := m.width / 2
+	detailWidth := m.width - listWidth
+	panes := lipgloss.JoinHorizontal(lipgloss.Top, m.listView(listWidth), m.detailView(detailWidth))
+
+	status := m.status
+	if len(status) == 0 {
+		status = tuiDimStyle.Render(truncate(tuiHelp, m.width))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, search, panes, status)
+}
+
+// listView is the results that are on the screen, with the selected one
+// picked out.
+func (m tuiModel) listView(width int) string {
+	// The border and padding take up 4 columns
+	inner := max(1, width-4)
+	height := m.listHeight()
+
+	var rows []string
+	for i := m.top; i < len(m.results) && i < m.top+height; i++ {
+		c := m.results[i]
+		row := truncate(fmt.Sprintf("%06d  %-2s  %s", c.CID, c.State, c.CommunityName), inner)
+		if i == m.cursor {
+			row = tuiSelectedStyle.Render(row)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		rows = append(rows, tuiDimStyle.Render("Nothing matches"))
+	}
+
+	return tuiPaneStyle.Width(width - 2).Height(height).Render(strings.Join(rows, "\n"))
+}
+
+// detailView is every field of the selected community.
+func (m tuiModel) detailView(width int) string {
+	inner := max(1, width-4)
+	height := m.listHeight()
+
+	c, ok := m.selected()
+	if !ok {
+		return tuiPaneStyle.Width(width - 2).Height(height).Render("")
+	}
+
+	headerWidth := 0
+	for _, col := range m.columns {
+		headerWidth = max(headerWidth, len(col.Header))
+	}
+
+	var lines []string
+	for _, col := range m.columns {
+		header := tuiHeaderStyle.Render(fmt.Sprintf("%-*s", headerWidth, col.Header))
+		lines = append(lines, header+"  "+truncate(col.Value(c), inner-headerWidth-2))
+	}
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+
+	return tuiPaneStyle.Width(width - 2).Height(height).Render(strings.Join(lines, "\n"))
+}
+
+// truncate cuts the text off at width characters, ending it with an
+// ellipsis if it was.
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if width <= 0 {
+		return ""
+	}
+	if len(runes) <= width {
+		return s
+	}
+
+	return string(runes[:width-1]) + "…"
+}
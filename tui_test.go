@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"nfip-community-book/data"
+)
+
+// press sends the messages to the model one after another, like the keys
+// were pressed.
+func press(t *testing.T, m tuiModel, msgs ...tea.Msg) (tuiModel, tea.Cmd) {
+	t.Helper()
+
+	var cmd tea.Cmd
+	for _, msg := range msgs {
+		var model tea.Model
+		model, cmd = m.Update(msg)
+		m = model.(tuiModel)
+	}
+
+	return m, cmd
+}
+
+func typed(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func newTestTUIModel(t *testing.T) tuiModel {
+	t.Helper()
+
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	m := newTUIModel(communities)
+	m, _ = press(t, m, tea.WindowSizeMsg{Width: 120, Height: 20})
+	return m
+}
+
+func TestTUISearch(t *testing.T) {
+	m := newTestTUIModel(t)
+	all := len(m.results)
+
+	for _, tc := range []struct {
+		name   string
+		keys   []tea.Msg
+		term   string
+		count  int
+		cursor int
+		cid    int
+	}{
+		{name: "typed", keys: []tea.Msg{typed("houston")}, term: "houston", count: 1, cid: 480296},
+		{name: "a key at a time", keys: []tea.Msg{typed("h"), typed("a"), typed("r"), typed("r"), typed("i"), typed("s")}, term: "harris", count: 2},
+		{name: "down", keys: []tea.Msg{typed("harris"), tea.KeyMsg{Type: tea.KeyDown}}, term: "harris", count: 2, cursor: 1},
+		{name: "down past the end", keys: []tea.Msg{typed("harris"), tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyDown}}, term: "harris", count: 2, cursor: 1},
+		{name: "up past the start", keys: []tea.Msg{typed("harris"), tea.KeyMsg{Type: tea.KeyUp}}, term: "harris", count: 2},
+		{name: "page down", keys: []tea.Msg{tea.KeyMsg{Type: tea.KeyPgDown}}, count: all, cursor: 15},
+		{name: "typing starts over", keys: []tea.Msg{tea.KeyMsg{Type: tea.KeyPgDown}, typed("houston")}, term: "houston", count: 1, cid: 480296},
+		{name: "backspace", keys: []tea.Msg{typed("houstonx"), tea.KeyMsg{Type: tea.KeyBackspace}}, term: "houston", count: 1, cid: 480296},
+		{name: "cleared", keys: []tea.Msg{typed("houston"), tea.KeyMsg{Type: tea.KeyCtrlU}}, count: all},
+		{name: "nothing", keys: []tea.Msg{typed("zzzzzz")}, term: "zzzzzz", count: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m, _ := press(t, m, tc.keys...)
+
+			if m.input.Value() != tc.term || len(m.results) != tc.count || m.cursor != tc.cursor {
+				t.Errorf("expected %d results for %q with %d picked, got %d for %q with %d", tc.count, tc.term, tc.cursor, len(m.results), m.input.Value(), m.cursor)
+			}
+
+			if c, ok := m.selected(); tc.cid > 0 && (!ok || c.CID != tc.cid) {
+				t.Errorf("expected %d to be picked, got %d", tc.cid, c.CID)
+			}
+		})
+	}
+}
+
+func TestTUIView(t *testing.T) {
+	m, _ := press(t, newTestTUIModel(t), typed("houston"))
+
+	view := m.View()
+	for _, s := range []string{"1 of 34", "480296  TX  HOUSTON, CITY OF", "HARRIS COUNTY"} {
+		if !strings.Contains(view, s) {
+			t.Errorf("expected %q in the view, got\n%s", s, view)
+		}
+	}
+
+	m, _ = press(t, m, typed("zzz"))
+	if view := m.View(); !strings.Contains(view, "Nothing matches") {
+		t.Errorf("expected nothing to match, got\n%s", view)
+	}
+}
+
+func TestTUICopy(t *testing.T) {
+	var copied string
+	m := newTestTUIModel(t)
+	m.copy = func(s string) error {
+		copied = s
+		return nil
+	}
+
+	m, _ = press(t, m, typed("houston"), tea.KeyMsg{Type: tea.KeyCtrlY})
+	if copied != "480296" || m.status != "Copied 480296" {
+		t.Errorf("expected Houston's CID to be copied, got %q %q", copied, m.status)
+	}
+
+	// Searching again clears what happened
+	m, _ = press(t, m, typed("x"))
+	if len(m.status) > 0 {
+		t.Errorf("expected the status to be cleared, got %q", m.status)
+	}
+
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyCtrlY})
+	if m.status != "There isn't a community to copy" {
+		t.Errorf("expected nothing to be copied without a community, got %q", m.status)
+	}
+
+	m.copy = func(string) error { return errors.New("no clipboard") }
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyBackspace}, tea.KeyMsg{Type: tea.KeyCtrlY})
+	if m.status != "Couldn't copy the CID: no clipboard" {
+		t.Errorf("expected the clipboard's error, got %q", m.status)
+	}
+}
+
+func TestTUIExport(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	m, _ := press(t, newTestTUIModel(t), typed("harris county"), tea.KeyMsg{Type: tea.KeyCtrlS})
+	if m.status != "Exported 2 communities to communities-harris-county.csv" {
+		t.Errorf("expected the results to be exported to a CSV, got %q", m.status)
+	}
+
+	b, err := os.ReadFile("communities-harris-county.csv")
+	if err != nil || strings.Count(string(b), "\n") != 3 || !strings.Contains(string(b), `="480287",HARRIS COUNTY *`) {
+		t.Errorf("expected the header and the 2 communities in the CSV, got %s %v", b, err)
+	}
+
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyCtrlO})
+	if m.status != "Exported 2 communities to communities-harris-county.json" {
+		t.Errorf("expected the results to be exported to JSON, got %q", m.status)
+	}
+
+	f, err := os.Open("communities-harris-county.json")
+	if err != nil {
+		t.Fatalf("expected the JSON to be exported, got %s", err)
+	}
+	defer f.Close()
+
+	var communities data.NFIPCommunityStatuses
+	if err := json.NewDecoder(f).Decode(&communities); err != nil || len(communities) != 2 || communities[0].CID != m.results[0].CID {
+		t.Errorf("expected the 2 communities in the JSON in the same order, got %v %v", communities, err)
+	}
+}
+
+func TestTUIQuit(t *testing.T) {
+	for _, key := range []tea.KeyMsg{{Type: tea.KeyEsc}, {Type: tea.KeyCtrlC}} {
+		_, cmd := press(t, newTestTUIModel(t), key)
+		if cmd == nil {
+			t.Fatalf("expected %s to quit", key)
+		}
+		if _, ok := cmd().(tea.QuitMsg); !ok {
+			t.Errorf("expected %s to quit", key)
+		}
+	}
+}
+
+func TestExportFilename(t *testing.T) {
+	for _, tc := range []struct {
+		term     string
+		filename string
+	}{
+		{"", "communities"},
+		{"Harris County", "communities-harris-county"},
+		{"  st. louis/county ", "communities-st-louis-county"},
+		{"480296", "communities-480296"},
+	} {
+		if filename := exportFilename(tc.term); filename != tc.filename {
+			t.Errorf("expected %q to be exported to %s, got %s", tc.term, tc.filename, filename)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"nfip-community-book/data"
+)
+
+func (c *cli) validateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate [file]",
+		Short: "Check a status book for values that can't be right",
+		Long: `Check a status book for values that can't be right, like a CID outside of
+any state or a FIRM before its FHBM, values that couldn't be parsed, and
+CIDs that are in it more than once. Each one is written with its CID and
+field, and it exits with 4 if there are any. Columns that were renamed or that aren't
+known are warned about, but aren't counted.
+
+Without a file, the cached status book is checked. Files can be CSV like
+nation.csv, or JSON from export.`,
+		Example: "  nfip validate nation.csv",
+		Args:    checkArgs(cobra.MaximumNArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.runValidate(args)
+		},
+	}
+}
+
+func (c *cli) runValidate(args []string) error {
+	var (
+		result data.ParseResult
+		err    error
+	)
+	switch {
+	case len(args) > 0:
+		result, err = readStatusBookFile(args[0])
+	case c.demo:
+		result.Communities, err = data.GetDemoNFIPCommunityStatusBook()
+	default:
+		var filename string
+		filename, err = data.EnsureNFIPCommunityStatusBook(c.stderr())
+		if err == nil {
+			result, err = readStatusBookFile(filename)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	l := c.stderr()
+	for _, sc := range result.SchemaChanges {
+		l.Println("** Warning -", sc)
+	}
+
+	report := result.Communities.Validate()
+
+	// Values that couldn't be parsed and duplicates are written the same way
+	// as the rest, so that they can be read all together
+	issues := report.Issues
+	for _, uv := range result.Unparsable {
+		issues = append(issues, data.ValidationIssue{CID: uv.CID, Field: uv.Column, Message: fmt.Sprintf("\"%s\" on line %d couldn't be parsed", uv.Value, uv.Line)})
+	}
+	for _, d := range result.Duplicates {
+		issues = append(issues, data.ValidationIssue{CID: d.CID, Field: "cid", Message: fmt.Sprintf("in the status book %d times", len(d.Indexes))})
+	}
+	if issues == nil {
+		issues = []data.ValidationIssue{}
+	}
+
+	rows := [][]string{{"CID", "FIELD", "ISSUE"}}
+	for _, issue := range issues {
+		rows = append(rows, []string{fmt.Sprintf("%06d", issue.CID), issue.Field, issue.Message})
+	}
+
+	if err := c.write(issues, rows); err != nil {
+		return err
+	}
+
+	if len(issues) > 0 {
+		return commandError{exitInvalid, fmt.Errorf("found %d issues in %d communities", len(issues), report.Checked)}
+	}
+
+	l.Printf("Checked %d communities, and they're all valid\n", report.Checked)
+	return nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+
+	"nfip-community-book/data"
+)
+
+func TestValidateCommand(t *testing.T) {
+	communities, _ := data.GetDemoNFIPCommunityStatusBook()
+	valid := writeCommunitiesFile(t, "valid.json", communities)
+
+	// A CID that isn't in any state
+	invalid := append(data.NFIPCommunityStatuses{}, communities...)
+	invalid[0].CID = 990001
+	withIssues := writeCommunitiesFile(t, "invalid.json", invalid)
+
+	for _, tc := range []commandTest{
+		{name: "demo", args: []string{"--demo", "validate"}, code: exitOK, contains: []string{"[]"}},
+		{name: "file", args: []string{"validate", valid}, code: exitOK, contains: []string{"[]"}},
+		{name: "issues", args: []string{"validate", "-o", "csv", withIssues}, code: exitInvalid, contains: []string{"CID,FIELD,ISSUE\n", "990001,cid,99 is not a state FIPS code\n"}},
+		{name: "two files", args: []string{"validate", valid, withIssues}, code: exitUsage},
+	} {
+		t.Run(tc.name, tc.run)
+	}
+}
@@ -0,0 +1,248 @@
+// The page only uses the API, the same as any other client would.
+"use strict";
+
+const pageSize = 50;
+const csvPageSize = 1000;
+const keyStorage = "nfip-api-key";
+
+const form = document.getElementById("search");
+const term = document.getElementById("q");
+const suggestions = document.getElementById("suggestions");
+const results = document.getElementById("results");
+const rows = results.querySelector("tbody");
+const count = document.getElementById("count");
+const more = document.getElementById("more");
+const csv = document.getElementById("csv");
+const error = document.getElementById("error");
+const keyForm = document.getElementById("key");
+
+// The list that's shown, and the next page of it
+let current = null;
+let next = null;
+
+function headers() {
+  const key = localStorage.getItem(keyStorage);
+  return key ? { "X-API-Key": key } : {};
+}
+
+// get fetches from the API, asking for a key if it needs one.
+async function get(url) {
+  const resp = await fetch(url, { headers: headers() });
+  if (resp.status === 401) {
+    keyForm.hidden = false;
+    throw new Error("This server needs an API key.");
+  }
+  if (!resp.ok) {
+    let message = resp.statusText;
+    try {
+      message = (await resp.json()).detail || message;
+    } catch (e) {}
+
+    // The ID finds the request in the server's logs
+    const id = resp.headers.get("X-Request-ID");
+    throw new Error(id ? `${message} (request ${id})` : message);
+  }
+  return resp;
+}
+
+// listURL is the search or list that the form is filled out for.
+function listURL(limit) {
+  const params = new URLSearchParams();
+  const q = term.value.trim();
+  if (q) {
+    params.set("q", q);
+  }
+  for (const name of ["state", "participating", "crs_class_max"]) {
+    const value = document.getElementById(name).value;
+    if (value) {
+      params.set(name, value);
+    }
+  }
+  params.set("limit", limit);
+
+  return (q ? "/v1/search?" : "/v1/communities?") + params;
+}
+
+function showError(err) {
+  error.textContent = err.message;
+  error.hidden = false;
+}
+
+function cell(row, text, className) {
+  const td = row.insertCell();
+  td.textContent = text === null || text === undefined ? "" : text;
+  if (className) {
+    td.className = className;
+  }
+}
+
+function showPage(page, append) {
+  if (!append) {
+    rows.replaceChildren();
+  }
+
+  const communities = page.communities || page.results.map((result) => result.community);
+  for (const c of communities) {
+    const row = rows.insertRow();
+    cell(row, String(c.cid).padStart(6, "0"), "number");
+    cell(row, c.community_name);
+    cell(row, c.county);
+    cell(row, c.state);
+    cell(row, c.participating_community ? "Yes" : "No");
+    cell(row, c.program);
+    cell(row, c.cur_class, "number");
+    cell(row, c.curr_eff_map_date);
+  }
+
+  const shown = rows.rows.length;
+  count.textContent = page.total === 1 ? "1 community" : `${page.total.toLocaleString()} communities`;
+  if (shown < page.total) {
+    count.textContent += `, showing ${shown.toLocaleString()}`;
+  }
+
+  next = page.next || null;
+  results.hidden = shown === 0;
+  more.hidden = !next;
+  csv.hidden = page.total === 0;
+}
+
+async function search(event) {
+  if (event) {
+    event.preventDefault();
+  }
+  error.hidden = true;
+
+  current = listURL(pageSize);
+  history.replaceState(null, "", "?" + new URL(current, location.href).searchParams);
+
+  try {
+    const page = await (await get(current)).json();
+    showPage(page, false);
+  } catch (err) {
+    showError(err);
+  }
+}
+
+async function showMore() {
+  if (!next) {
+    return;
+  }
+
+  more.disabled = true;
+  try {
+    showPage(await (await get(next)).json(), true);
+  } catch (err) {
+    showError(err);
+  } finally {
+    more.disabled = false;
+  }
+}
+
+// downloadCSV downloads every page of the list as one CSV file, rather
+// than just the ones that are shown.
+async function downloadCSV() {
+  csv.disabled = true;
+  csv.textContent = "Downloading...";
+
+  try {
+    const url = new URL(listURL(csvPageSize), location.href);
+    url.searchParams.set("format", "csv");
+
+    let body = "";
+    let link = url.pathname + url.search;
+    while (link) {
+      const resp = await get(link);
+      let text = await resp.text();
+
+      // Each page has the header, but it's only needed once
+      if (body) {
+        text = text.slice(text.indexOf("\n") + 1);
+      }
+      body += text;
+
+      const match = /<([^>]+)>;\s*rel="next"/.exec(resp.headers.get("Link") || "");
+      link = match ? match[1] : null;
+    }
+
+    const a = document.createElement("a");
+    a.href = URL.createObjectURL(new Blob([body], { type: "text/csv" }));
+    a.download = "communities.csv";
+    a.click();
+    URL.revokeObjectURL(a.href);
+  } catch (err) {
+    showError(err);
+  } finally {
+    csv.disabled = false;
+    csv.textContent = "Download CSV";
+  }
+}
+
+// suggest fills in names as they're typed. Suggestions are only a help,
+// so they're skipped if they can't be had.
+let suggesting = null;
+function suggest() {
+  clearTimeout(suggesting);
+  suggesting = setTimeout(async () => {
+    const prefix = term.value.trim();
+    if (prefix.length < 2 || /^\d+$/.test(prefix)) {
+      suggestions.replaceChildren();
+      return;
+    }
+
+    try {
+      const resp = await fetch("/autocomplete?" + new URLSearchParams({ prefix, limit: 8 }), {
+        headers: headers(),
+      });
+      if (!resp.ok) {
+        return;
+      }
+      const names = await resp.json();
+      suggestions.replaceChildren(
+        ...(names || []).map((name) => {
+          const option = document.createElement("option");
+          option.value = name;
+          return option;
+        }),
+      );
+    } catch (e) {}
+  }, 150);
+}
+
+async function loadStates() {
+  const select = document.getElementById("state");
+  try {
+    const states = await (await fetch("/ui/states.json")).json();
+    for (const state of states) {
+      select.add(new Option(state.name, state.abbreviation));
+    }
+  } catch (e) {}
+}
+
+// The form is filled out from the URL, so that searches can be shared.
+async function start() {
+  await loadStates();
+
+  const params = new URLSearchParams(location.search);
+  for (const name of ["q", "state", "participating", "crs_class_max"]) {
+    if (params.has(name)) {
+      document.getElementById(name).value = params.get(name);
+    }
+  }
+
+  if (params.has("q")) {
+    search();
+  }
+}
+
+document.getElementById("savekey").addEventListener("click", () => {
+  localStorage.setItem(keyStorage, document.getElementById("apikey").value.trim());
+  keyForm.hidden = true;
+  search();
+});
+
+form.addEventListener("submit", search);
+term.addEventListener("input", suggest);
+more.addEventListener("click", showMore);
+csv.addEventListener("click", downloadCSV);
+
+start();
@@ -0,0 +1,44 @@
+// Package web is a page for searching the communities from a browser, for
+// the people who'd rather not call the API themselves. It only uses the
+// API, the same as any other client, so it's just files.
+package web
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"sort"
+
+	"nfip-community-book/states"
+)
+
+//go:embed static
+var static embed.FS
+
+// Handler serves the page at / and its files under /ui/, along with the
+// states for its filter at /ui/states.json.
+func Handler() http.Handler {
+	files, _ := fs.Sub(static, "static")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(rw http.ResponseWriter, r *http.Request) {
+		http.ServeFileFS(rw, r, files, "index.html")
+	})
+	mux.HandleFunc("GET /ui/states.json", getStates)
+	mux.Handle("GET /ui/", http.StripPrefix("/ui/", http.FileServerFS(files)))
+
+	return mux
+}
+
+// getStates is every state, sorted by name for the filter.
+func getStates(rw http.ResponseWriter, r *http.Request) {
+	all := states.All()
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name < all[j].Name
+	})
+
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	rw.Header().Set("Cache-Control", "max-age=86400")
+	json.NewEncoder(rw).Encode(all)
+}
@@ -0,0 +1,47 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"nfip-community-book/states"
+)
+
+func get(target string) *httptest.ResponseRecorder {
+	rw := httptest.NewRecorder()
+	Handler().ServeHTTP(rw, httptest.NewRequest(http.MethodGet, target, nil))
+	return rw
+}
+
+func TestHandler(t *testing.T) {
+	rw := get("/")
+	if rw.Code != http.StatusOK || !strings.HasPrefix(rw.Header().Get("Content-Type"), "text/html") || !strings.Contains(rw.Body.String(), `src="/ui/app.js"`) {
+		t.Fatalf("expected the page, got %d %s", rw.Code, rw.Header().Get("Content-Type"))
+	}
+
+	for _, target := range []string{"/ui/app.js", "/ui/style.css"} {
+		if rw := get(target); rw.Code != http.StatusOK || rw.Body.Len() == 0 {
+			t.Errorf("expected %s, got %d", target, rw.Code)
+		}
+	}
+
+	if rw := get("/missing"); rw.Code != http.StatusNotFound {
+		t.Errorf("expected only the page at /, got %d", rw.Code)
+	}
+}
+
+func TestGetStates(t *testing.T) {
+	var got []states.State
+	if err := json.Unmarshal(get("/ui/states.json").Body.Bytes(), &got); err != nil || len(got) != len(states.All()) {
+		t.Fatalf("expected every state, got %d %v", len(got), err)
+	}
+
+	for i := 1; i < len(got); i++ {
+		if got[i-1].Name > got[i].Name {
+			t.Errorf("expected the states to be sorted by name, got %s before %s", got[i-1].Name, got[i].Name)
+		}
+	}
+}